@@ -2,6 +2,8 @@ package cli
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -9,8 +11,13 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/kubex-ecosystem/grompt/internal/engine"
+	"github.com/kubex-ecosystem/grompt/internal/examples"
 	i "github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"github.com/kubex-ecosystem/grompt/internal/lint"
 	p "github.com/kubex-ecosystem/grompt/internal/providers"
+	"github.com/kubex-ecosystem/grompt/internal/rag"
+	"github.com/kubex-ecosystem/grompt/internal/strategy"
 	t "github.com/kubex-ecosystem/grompt/internal/types"
 	"github.com/kubex-ecosystem/grompt/utils"
 	l "github.com/kubex-ecosystem/logz"
@@ -113,6 +120,11 @@ func askCommand() *cobra.Command {
 		model      string
 		maxTokens  int
 		configFile string
+		tags       []string
+		attach     []string
+		// Repository questions
+		repo       repoOptions
+		showPrompt bool
 		// API Keys
 		apiKey         string
 		ollamaEndpoint string
@@ -123,10 +135,23 @@ func askCommand() *cobra.Command {
 		Short: "Ask a direct question to an AI provider",
 		Long: `Send a direct prompt to an AI provider without starting the server.
 
+With --repo, the question is answered from a code base: a local directory, a
+git URL or a lookatni extraction (JSON). The repository is extracted with
+lookatni, or with the built-in Go extractor when lookatni is not installed,
+and the --k fragments most relevant to the question are sent along with it,
+numbered for citation by file and line range. Fragments are ranked with BM25,
+fused with embedding similarity when --embeddings names a provider; the
+embeddings are kept in a local vector index and only recomputed for
+fragments that changed. The sources are cut to fit the model's context
+window less --max-tokens, or --max-context when smaller.
+
 Examples:
   grompt ask --prompt "What is Go programming?" --provider gemini
   grompt ask --prompt "Explain REST APIs" --provider openai --model gpt-4
-  grompt ask --prompt "Write a poem about code" --provider claude --max-tokens 500`,
+  grompt ask --prompt "Write a poem about code" --provider claude --max-tokens 500
+  grompt ask --prompt "Review this screen for accessibility issues" --provider openai --attach screenshot.png
+  grompt ask --repo . --prompt "How is the response cache keyed?" --provider claude
+  grompt ask --repo ./service --prompt "Where are retries configured?" --embeddings openai --show-prompt`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if debug {
 				l.GetLogger("Grompt")
@@ -143,6 +168,16 @@ Examples:
 				gl.Log("fatal", err.Error())
 			}
 
+			if repo.repo != "" && showPrompt {
+				rp, err := repoPrompt(cmd.Context(), cfg, repo, provider, model, prompt, maxTokens)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%s\n\n", rp.Text())
+				printCitations(cmd.OutOrStdout(), rp.Citations)
+				return nil
+			}
+
 			// Setup provider
 			apiConfig, provider, err := setupProvider(cfg, provider, apiKey)
 			if err != nil {
@@ -164,13 +199,30 @@ Examples:
 
 			gl.Log("info", fmt.Sprintf("🤖 Asking %s: %s", provider, truncateString(prompt, 60)))
 
-			response, err := apiConfig.Complete(prompt, maxTokens, model)
+			var citations []rag.Citation
+			if repo.repo != "" {
+				rp, err := repoPrompt(cmd.Context(), cfg, repo, provider, model, prompt, maxTokens)
+				if err != nil {
+					return err
+				}
+				prompt, citations = rp.Text(), rp.Citations
+				tags = append(tags, "repo")
+			}
+
+			var response string
+			if len(attach) > 0 {
+				response, err = askWithAttachments(cmd.Context(), apiConfig, provider, model, prompt, maxTokens, attach)
+			} else {
+				response, err = apiConfig.Complete(prompt, maxTokens, model)
+			}
 			if err != nil {
 				return fmt.Errorf("failed to get response from %s: %v", provider, err)
 			}
+			recordHistory(provider, model, prompt, response, tags)
 
 			fmt.Printf("\n🎯 **%s Response (%s):**\n\n%s\n\n",
 				strings.ToUpper(provider), model, response)
+			printCitations(os.Stdout, citations)
 
 			return nil
 		},
@@ -182,6 +234,17 @@ Examples:
 	cmd.Flags().StringVarP(&model, "model", "m", "", "Model to use (provider specific)")
 	cmd.Flags().IntVarP(&maxTokens, "max-tokens", "t", 1000, "Maximum tokens in response")
 	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+	cmd.Flags().StringSliceVar(&tags, "tag", []string{}, "Tag the history entry (comma-separated or multiple flags)")
+	cmd.Flags().StringSliceVarP(&attach, "attach", "a", []string{}, "Image or file to send with the prompt: a local path or an image URL (repeatable)")
+
+	// Repository flags
+	cmd.Flags().StringVar(&repo.repo, "repo", "", "Answer from a code base: a directory, a git URL or a lookatni extraction (JSON)")
+	cmd.Flags().IntVarP(&repo.k, "k", "k", rag.DefaultK, "Fragments of the repository to retrieve (with --repo)")
+	cmd.Flags().IntVar(&repo.maxContext, "max-context", 0, "Maximum tokens of repository sources (default: the model's context window)")
+	cmd.Flags().StringVar(&repo.embeddings, "embeddings", "", "Provider computing fragment embeddings for retrieval (default: BM25 only)")
+	cmd.Flags().StringVar(&repo.embedModel, "embed-model", "", "Embedding model (default: the provider's)")
+	cmd.Flags().StringVar(&repo.index, "index", "", "Vector index of the embeddings (default: one per repository and provider)")
+	cmd.Flags().BoolVar(&showPrompt, "show-prompt", false, "Print the assembled repository prompt and its sources without asking")
 
 	// API Key flags
 	cmd.Flags().StringVar(&apiKey, "apikey", "", "API key")
@@ -192,6 +255,38 @@ Examples:
 	return cmd
 }
 
+// askWithAttachments sends prompt with images or files through the
+// provider's chat, which rejects inputs the provider cannot take
+func askWithAttachments(ctx context.Context, apiConfig i.IAPIConfig, provider, model, prompt string, maxTokens int, attach []string) (string, error) {
+	msg := i.Message{Role: "user", Content: prompt}
+	for _, ref := range attach {
+		part, err := i.PartFrom(ref)
+		if err != nil {
+			return "", err
+		}
+		msg.Parts = append(msg.Parts, part)
+	}
+
+	chat := &t.ProviderImpl{VName: provider, VAPI: apiConfig}
+	stream, err := chat.Chat(ctx, i.ChatRequest{
+		Provider: provider,
+		Model:    model,
+		Messages: []i.Message{msg},
+		Meta:     map[string]any{"max_tokens": maxTokens},
+	})
+	if err != nil {
+		return "", err
+	}
+	var response strings.Builder
+	for chunk := range stream {
+		if chunk.Error != "" {
+			return "", errors.New(chunk.Error)
+		}
+		response.WriteString(chunk.Content)
+	}
+	return response.String(), nil
+}
+
 // generateCommand handles prompt engineering from ideas
 func generateCommand() *cobra.Command {
 	var (
@@ -205,6 +300,16 @@ func generateCommand() *cobra.Command {
 		model       string
 		configFile  string
 		output      string
+		tags        []string
+		lintOutput  bool
+		stratName   string
+		maxExamples int
+		// Refine loop
+		refine        bool
+		critic        string
+		criticModel   string
+		threshold     float64
+		maxIterations int
 		// API Keys
 		apiKey         string
 		ollamaEndpoint string
@@ -215,10 +320,29 @@ func generateCommand() *cobra.Command {
 		Short: "Generate professional prompts from raw ideas using prompt engineering",
 		Long: `Transform raw, unorganized ideas into structured, professional prompts using AI-powered prompt engineering.
 
+The prompt is engineered by the strategy of the purpose type (see --strategy):
+  meta              one call with the meta-prompt of the language and purpose (default)
+  chain_of_thought  data analysis, reasoning, math: adds a reasoning scaffold
+  few_shot          classification, extraction: adds library or generated demonstrations
+  role_constraints  code: role, task, context, constraints and output format
+  agent             agent: a system prompt with capabilities and boundaries
+
+Up to --examples input/output pairs of the example library (see "grompt
+examples") that are relevant to the ideas and purpose type are appended to
+the prompt as demonstrations.
+
+With --refine, a critic model scores the generated prompt against a rubric
+(clarity, completeness, output format) and the prompt is regenerated with the
+critique until the score reaches --threshold or --max-iterations is hit. The
+best scoring iteration is printed; every iteration and its scores are logged
+and recorded in history tagged "refine".
+
 Examples:
   grompt generate --ideas "API design,REST,security" --purpose "Tutorial" --provider gemini
   grompt generate --ideas "machine learning,python,beginners" --purpose-type "Educational" --lang "english"
-  grompt generate --ideas "docker,kubernetes,deployment" --output prompt.md --provider claude`,
+  grompt generate --ideas "docker,kubernetes,deployment" --output prompt.md --provider claude
+  grompt generate --ideas "sql,indexes" --lint
+  grompt generate --ideas "sql,indexes" --refine --critic claude --threshold 0.85`,
 		Run: func(cmd *cobra.Command, args []string) {
 			if debug {
 				l.GetLogger("Grompt")
@@ -260,14 +384,76 @@ Examples:
 				}
 			}
 
-			gl.Log("info", fmt.Sprintf("🔨 Engineering prompt from %d ideas using %s", len(ideas), strings.ToTitleSpecial(unicode.CaseRanges, provider)))
+			s, err := strategy.Select(stratName, purposeType)
+			if err != nil {
+				gl.Log("fatal", err.Error())
+			}
+			if refine {
+				s, _ = strategy.Lookup(strategy.Default)
+			}
 
-			// Use the same prompt engineering logic from the server
-			engineeringPrompt := cfg.GetBaseGenerationPrompt(ideas, purpose, purposeType, lang, maxTokens)
+			gl.Log("info", fmt.Sprintf("🔨 Engineering prompt from %d ideas using %s (%s strategy)", len(ideas), strings.ToTitleSpecial(unicode.CaseRanges, provider), s.Name()))
+
+			var response string
+			if refine {
+				// Use the same prompt engineering logic from the server
+				engineeringPrompt := cfg.GetBaseGenerationPrompt(ideas, purpose, purposeType, lang, maxTokens)
+
+				eng := engine.NewEngine(cfg)
+				defer eng.Close()
+				ref, err := eng.Refine(context.Background(), engineeringPrompt, i.RefineOptions{
+					Provider:      provider,
+					Model:         model,
+					Critic:        critic,
+					CriticModel:   criticModel,
+					Threshold:     threshold,
+					MaxIterations: maxIterations,
+					MaxTokens:     maxTokens,
+					Tags:          append([]string{"generate"}, tags...),
+				})
+				if err != nil {
+					gl.Log("fatal", fmt.Sprintf("Error refining prompt: %v", err))
+				}
+				for _, it := range ref.Iterations {
+					gl.Log("info", fmt.Sprintf("🔁 Iteration %d scored %.2f %v: %s", it.Iteration, it.Critique.Score, it.Critique.Scores, it.Critique.Feedback))
+				}
+				if ref.Converged {
+					gl.Log("success", fmt.Sprintf("✅ Iteration %d reached %.2f (threshold %.2f)", ref.Best, ref.Score, ref.Threshold))
+				} else {
+					gl.Log("warn", fmt.Sprintf("Threshold %.2f not reached after %d iterations; keeping iteration %d (%.2f)", ref.Threshold, len(ref.Iterations), ref.Best, ref.Score))
+				}
+				response = ref.Prompt
+			} else {
+				req := i.GenerationRequest{Ideas: ideas, Purpose: purpose, PurposeType: purposeType, Lang: lang, MaxLength: maxTokens}
+				if maxExamples > 0 {
+					req.Examples = examples.Open().Retrieve(context.Background(), i.ExampleQuery{
+						Text:    strings.Join(append(append([]string{}, ideas...), purpose), "\n"),
+						Purpose: purposeType,
+						K:       maxExamples,
+					})
+				}
+				gen, err := strategy.Run(context.Background(), s, req, func(ctx context.Context, prompt string) (string, error) {
+					return apiConfig.Complete(prompt, maxTokens, model)
+				})
+				if err != nil {
+					gl.Log("fatal", fmt.Sprintf("Error generating prompt: %v", err))
+				}
+				response = gen.Prompt
+				if len(gen.Examples) > 0 {
+					gl.Log("info", fmt.Sprintf("📚 Injected examples %s", strings.Join(gen.Examples, ", ")))
+				}
+				recordHistory(provider, model, gen.Request, response, append([]string{"generate", gen.Strategy}, tags...))
+			}
 
-			response, err := apiConfig.Complete(engineeringPrompt, maxTokens, model)
-			if err != nil {
-				gl.Log("fatal", fmt.Sprintf("Error generating prompt: %v", err))
+			if lintOutput {
+				// Findings go to the log so stdout stays the bare prompt
+				report := lint.Lint(response, lint.Options{})
+				for _, f := range report.Findings {
+					gl.Log("warn", fmt.Sprintf("lint %d:%d %s %s: %s", f.Position.Line, f.Position.Column, f.Severity, f.Rule, f.Message))
+				}
+				if len(report.Findings) == 0 {
+					gl.Log("info", "✅ Generated prompt passed lint")
+				}
 			}
 
 			result := fmt.Sprintf("# Generated Prompt (%s - %s)\n\n%s", provider, model, response)
@@ -295,6 +481,15 @@ Examples:
 	cmd.Flags().StringVarP(&model, "model", "m", "", "Model to use")
 	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
 	cmd.Flags().StringVarP(&output, "output", "o", "", "Output file (default: stdout)")
+	cmd.Flags().StringSliceVar(&tags, "tag", []string{}, "Tag the history entry (comma-separated or multiple flags)")
+	cmd.Flags().BoolVar(&lintOutput, "lint", false, "Lint the generated prompt and log the findings")
+	cmd.Flags().StringVar(&stratName, "strategy", "", "Prompt engineering strategy (default: by purpose type; ignored with --refine)")
+	cmd.Flags().IntVar(&maxExamples, "examples", examples.DefaultK, "Library examples to inject as demonstrations (0 for none; ignored with --refine)")
+	cmd.Flags().BoolVar(&refine, "refine", false, "Critique and regenerate the prompt until it scores above --threshold")
+	cmd.Flags().StringVar(&critic, "critic", "", "Provider that scores each iteration (default: --provider)")
+	cmd.Flags().StringVar(&criticModel, "critic-model", "", "Model of the critic provider")
+	cmd.Flags().Float64Var(&threshold, "threshold", engine.DefaultRefineThreshold, "Rubric score, 0..1, that ends the refine loop")
+	cmd.Flags().IntVar(&maxIterations, "max-iterations", engine.DefaultRefineIterations, "Maximum refine iterations")
 
 	// API Key flags
 	cmd.Flags().StringVar(&apiKey, "apikey", "", "API key")
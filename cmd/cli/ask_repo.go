@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	i "github.com/kubex-ecosystem/grompt/internal/interfaces"
+	p "github.com/kubex-ecosystem/grompt/internal/providers"
+	"github.com/kubex-ecosystem/grompt/internal/rag"
+	"github.com/kubex-ecosystem/grompt/internal/vectorindex"
+	gl "github.com/kubex-ecosystem/logz/logger"
+)
+
+// repoOptions are the ask flags that answer from a repository
+type repoOptions struct {
+	repo       string
+	k          int
+	maxContext int
+	embeddings string // provider computing fragment embeddings; empty for BM25 only
+	embedModel string
+	index      string // vector index of the embeddings; empty derives one from the repository
+}
+
+// repoPrompt extracts the repository, retrieves the fragments relevant to
+// question and assembles the prompt citing them, sized for provider and
+// model with maxTokens kept for the answer
+func repoPrompt(ctx context.Context, cfg i.IConfig, opts repoOptions, provider, model, question string, maxTokens int) (*rag.Prompt, error) {
+	project, err := rag.Load(ctx, opts.repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract %s: %w", opts.repo, err)
+	}
+	idx := rag.NewIndex(project)
+	gl.Log("info", fmt.Sprintf("📚 Indexed %d fragments of %s", idx.Len(), project.ProjectName))
+
+	if opts.embeddings != "" {
+		embedder, ok := i.EmbedderOf(p.NewProvider(opts.embeddings, "", "", cfg))
+		if !ok {
+			return nil, fmt.Errorf("provider %s does not compute embeddings", opts.embeddings)
+		}
+		name := opts.index
+		if name == "" {
+			name = rag.IndexName(opts.repo, opts.embeddings)
+		}
+		store, err := vectorindex.NewCatalog("").Open("", name)
+		if err != nil {
+			return nil, err
+		}
+		if err := idx.UseEmbeddings(ctx, store, opts.embeddings, embedder, opts.embedModel); err != nil {
+			return nil, fmt.Errorf("failed to embed the fragments of %s: %w", opts.repo, err)
+		}
+		gl.Log("info", fmt.Sprintf("🧭 Using %s embeddings from index %s", opts.embeddings, name))
+	}
+
+	rp, err := idx.Prompt(ctx, question, rag.Options{
+		Provider:         provider,
+		Model:            model,
+		K:                opts.k,
+		MaxContextTokens: opts.maxContext,
+		ReserveTokens:    maxTokens,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(rp.Citations) == 0 {
+		gl.Log("warn", "No fragment of the repository is relevant to the question")
+	}
+	if rp.Omitted > 0 {
+		gl.Log("warn", fmt.Sprintf("%d relevant fragments did not fit the %d-token context budget", rp.Omitted, rp.Budget))
+	}
+	return rp, nil
+}
+
+func printCitations(out io.Writer, citations []rag.Citation) {
+	if len(citations) == 0 {
+		return
+	}
+	fmt.Fprintln(out, "📎 Sources:")
+	for _, c := range citations {
+		line := fmt.Sprintf("  [%d] %s", c.N, c.String())
+		if c.Name != "" {
+			line += " " + strings.TrimSpace(c.Type+" "+c.Name)
+		}
+		if c.Truncated {
+			line += " (truncated)"
+		}
+		fmt.Fprintln(out, line)
+	}
+}
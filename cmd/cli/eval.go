@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubex-ecosystem/grompt/internal/cache"
+	"github.com/kubex-ecosystem/grompt/internal/engine"
+	"github.com/kubex-ecosystem/grompt/internal/eval"
+	l "github.com/kubex-ecosystem/logz"
+	gl "github.com/kubex-ecosystem/logz/logger"
+)
+
+// EvalCmd returns the prompt evaluation command
+func EvalCmd() *cobra.Command {
+	var (
+		debug       bool
+		dataset     string
+		template    string
+		providers   []string
+		judge       string
+		assertions  []string
+		format      string
+		output      string
+		concurrency int
+		noCache     bool
+		configFile  string
+		// API Keys
+		apiKey         string
+		ollamaEndpoint string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "eval",
+		Short: "Evaluate a prompt template against a dataset on one or more providers",
+		Long: `Run a template against every row of a dataset on one or more providers and
+check each output with assertions.
+
+The dataset is a JSONL file (one object of variables per line) or a YAML/JSON
+file holding either a list of rows or a full suite:
+
+  name: code-review
+  template: review/go            # stored template name or inline template
+  providers: [openai, claude:claude-3-haiku-20240307]
+  judge: openai                  # provider for llm_judge assertions
+  assert:                        # applied to every case
+    - {type: max_length, max: 1200}
+  cases:
+    - name: nil-map
+      vars: {lang: Go, diff: "..."}
+      assert:
+        - {type: contains, value: "nil map", ignore_case: true}
+        - {type: regex, value: "(?m)^## Issues"}
+        - {type: json_schema, schema: review.schema.json}
+        - {type: llm_judge, rubric: "Points out the panic", threshold: 0.8}
+
+Flags override or extend the suite. The command exits with an error when any
+case fails, so it can gate CI pipelines.
+
+With GROMPT_CACHE=disk, unchanged low-temperature calls are replayed from the
+response cache across runs; --no-cache sends every call to the providers.`,
+		Example: `  grompt eval --dataset evals/review.yaml
+  grompt eval --dataset cases.jsonl --template review/go --provider openai --provider gemini --assert contains=TODO
+  grompt eval --dataset evals/review.yaml --format junit --output report.xml`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if debug {
+				l.GetLogger("Grompt")
+				gl.SetDebugMode(true)
+			}
+
+			suite, err := eval.LoadSuite(dataset)
+			if err != nil {
+				return err
+			}
+			if template != "" {
+				suite.Template = template
+			}
+			if len(providers) > 0 {
+				suite.Providers = providers
+			}
+			if judge != "" {
+				suite.Judge = judge
+			}
+			for _, spec := range assertions {
+				a, err := eval.ParseAssertion(spec)
+				if err != nil {
+					return err
+				}
+				suite.Assertions = append(suite.Assertions, a)
+			}
+
+			// --apikey applies to the first provider under test
+			keyProvider := ""
+			if len(suite.Providers) > 0 {
+				keyProvider, _, _ = strings.Cut(suite.Providers[0], ":")
+			}
+			cfg, err := setupConfig(configFile, keyProvider, apiKey, ollamaEndpoint)
+			if err != nil {
+				return err
+			}
+			eng := engine.NewEngine(cfg)
+			defer eng.Close()
+
+			if len(suite.Providers) == 0 {
+				for _, p := range eng.GetProviders() {
+					suite.Providers = append(suite.Providers, p.Name())
+				}
+			}
+
+			gl.Log("info", fmt.Sprintf("🧪 Evaluating %s: %d cases on %v", suite.Name, len(suite.Cases), suite.Providers))
+
+			runner := &eval.Runner{Engine: eng, Concurrency: concurrency}
+			ctx := context.Background()
+			if noCache {
+				ctx = cache.Bypass(ctx)
+			}
+			report, err := runner.Run(ctx, suite)
+			if err != nil {
+				return err
+			}
+
+			var out io.Writer = cmd.OutOrStdout()
+			if output != "" {
+				f, err := os.Create(output)
+				if err != nil {
+					return fmt.Errorf("error creating %s: %w", output, err)
+				}
+				defer f.Close()
+				out = f
+			}
+			if err := report.Write(out, format); err != nil {
+				return err
+			}
+
+			if !report.OK() {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%d of %d evaluations did not pass (%d failed, %d errors)",
+					report.Total-report.Passed, report.Total, report.Failed, report.Errors)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&debug, "debug", "D", false, "Enable debug mode")
+	cmd.Flags().StringVarP(&dataset, "dataset", "d", "", "Dataset file (.jsonl, .yaml, .yml or .json)")
+	cmd.Flags().StringVarP(&template, "template", "T", "", "Template name or inline template (overrides the suite)")
+	cmd.Flags().StringSliceVarP(&providers, "provider", "P", []string{}, "Provider to evaluate, optionally provider:model (repeatable; default: suite providers, else all configured)")
+	cmd.Flags().StringVar(&judge, "judge", "", "Provider (or provider:model) for llm_judge assertions")
+	cmd.Flags().StringArrayVarP(&assertions, "assert", "a", []string{}, "Extra assertion applied to every case: contains=, regex=, json_schema=<file>, max_length=, llm_judge=<rubric>")
+	cmd.Flags().StringVarP(&format, "format", "f", eval.FormatTable, "Report format (table, json, junit)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Report file (default: stdout)")
+	cmd.Flags().IntVar(&concurrency, "concurrency", 4, "Maximum provider calls in flight")
+	cmd.Flags().BoolVar(&noCache, "no-cache", false, "Bypass the response cache")
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+
+	// API Key flags
+	cmd.Flags().StringVar(&apiKey, "apikey", "", "API key")
+	cmd.Flags().StringVar(&ollamaEndpoint, "ollama-endpoint", "http://localhost:11434", "Ollama endpoint")
+
+	cmd.MarkFlagRequired("dataset")
+
+	return cmd
+}
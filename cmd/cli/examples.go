@@ -0,0 +1,328 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubex-ecosystem/grompt/internal/examples"
+	i "github.com/kubex-ecosystem/grompt/internal/interfaces"
+	gl "github.com/kubex-ecosystem/logz/logger"
+)
+
+// ExamplesCmd returns the example library command group
+func ExamplesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "examples",
+		Short: "Manage the few-shot example library",
+		Long: fmt.Sprintf(`Manage the input/output examples injected as demonstrations into generated
+prompts and rendered templates.
+
+Examples are tagged by purpose type; an example without one applies to every
+purpose. The most relevant ones are retrieved with BM25, or with the
+embeddings of the provider named by GROMPT_EXAMPLES_EMBEDDER.
+
+The library is stored as JSON Lines in %s
+(override with GROMPT_EXAMPLES_FILE).`, examples.DefaultPath()),
+	}
+
+	cmd.AddCommand(
+		examplesAddCommand(),
+		examplesListCommand(),
+		examplesShowCommand(),
+		examplesRemoveCommand(),
+		examplesSearchCommand(),
+		examplesImportCommand(),
+	)
+
+	return cmd
+}
+
+func examplesAddCommand() *cobra.Command {
+	var (
+		input   string
+		output  string
+		purpose string
+		tags    []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Add an input/output example",
+		Long:  "Add an example to the library. Prefix --input or --output with @ to read it from a file.",
+		Example: `  grompt examples add --purpose code --input "Add a retry" --output @retry.md
+  grompt examples add --input "Summarize the release" --output @summary.txt --tag release`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			in, err := readArg(input)
+			if err != nil {
+				return err
+			}
+			out, err := readArg(output)
+			if err != nil {
+				return err
+			}
+			ex, err := examples.Open().Add(i.Example{Input: in, Output: out, Purpose: purpose, Tags: tags})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), ex.ID)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&input, "input", "i", "", "Example input, or @file (required)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Example output, or @file (required)")
+	cmd.Flags().StringVarP(&purpose, "purpose", "p", "", "Purpose type (default: every purpose)")
+	cmd.Flags().StringSliceVar(&tags, "tag", []string{}, "Tag the example (comma-separated or multiple flags)")
+	cmd.MarkFlagRequired("input")
+	cmd.MarkFlagRequired("output")
+
+	return cmd
+}
+
+func examplesListCommand() *cobra.Command {
+	var (
+		purpose string
+		tags    []string
+		asJSON  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the examples of the library",
+		Example: `  grompt examples list
+  grompt examples list --purpose code --tag backend`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			found := examples.Open().List(purpose, tags)
+			if asJSON {
+				return printJSON(cmd.OutOrStdout(), found)
+			}
+			matches := make([]i.ExampleMatch, len(found))
+			for n, ex := range found {
+				matches[n] = i.ExampleMatch{Example: ex}
+			}
+			return printExamples(cmd.OutOrStdout(), matches, false)
+		},
+	}
+
+	cmd.Flags().StringVarP(&purpose, "purpose", "p", "", "Only examples of this purpose type")
+	cmd.Flags().StringSliceVar(&tags, "tag", []string{}, "Only examples with these tags")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the examples as JSON")
+
+	return cmd
+}
+
+func examplesShowCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "show <id>",
+		Short:   "Show an example in full",
+		Long:    "Show an example as JSON. A unique prefix of the ID is enough.",
+		Example: `  grompt examples show ex_1736942400`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ex, ok := examples.Open().Get(args[0])
+			if !ok {
+				return fmt.Errorf("example %s not found (or the prefix is ambiguous)", args[0])
+			}
+			return printJSON(cmd.OutOrStdout(), ex)
+		},
+	}
+
+	return cmd
+}
+
+func examplesRemoveCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "remove <id>...",
+		Aliases: []string{"rm"},
+		Short:   "Remove examples from the library",
+		Example: `  grompt examples remove ex_1736942400`,
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store := examples.Open()
+			for _, id := range args {
+				ex, err := store.Remove(id)
+				if err != nil {
+					return err
+				}
+				gl.Log("success", fmt.Sprintf("✅ Removed %s", ex.ID))
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func examplesSearchCommand() *cobra.Command {
+	var (
+		purpose string
+		tags    []string
+		k       int
+		asJSON  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "search <text>",
+		Short: "Show the examples that would be injected for a text",
+		Example: `  grompt examples search "retry the http client" --purpose code
+  grompt examples search "quarterly report" -k 5 --json`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			matches := examples.Open().Search(context.Background(), i.ExampleQuery{
+				Text:    strings.Join(args, " "),
+				Purpose: purpose,
+				Tags:    tags,
+				K:       k,
+			})
+			if asJSON {
+				return printJSON(cmd.OutOrStdout(), matches)
+			}
+			return printExamples(cmd.OutOrStdout(), matches, true)
+		},
+	}
+
+	cmd.Flags().StringVarP(&purpose, "purpose", "p", "", "Only examples of this purpose type")
+	cmd.Flags().StringSliceVar(&tags, "tag", []string{}, "Only examples with these tags")
+	cmd.Flags().IntVarP(&k, "k", "k", examples.DefaultK, "Maximum number of examples")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the matches as JSON")
+
+	return cmd
+}
+
+func examplesImportCommand() *cobra.Command {
+	var (
+		purpose string
+		tags    []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Import examples from a JSON array or JSON Lines file",
+		Long: `Import examples from a file holding a JSON array of examples or one example
+per line. Each needs "input" and "output"; "purpose" and "tags" are optional
+and default to the flags. Use - to read from stdin.`,
+		Example: `  grompt examples import house-style.jsonl --tag house-style
+  cat examples.json | grompt examples import - --purpose code`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var r io.Reader = cmd.InOrStdin()
+			if args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				r = f
+			}
+			items, err := decodeExamples(r)
+			if err != nil {
+				return fmt.Errorf("invalid examples in %s: %w", args[0], err)
+			}
+
+			store := examples.Open()
+			for n, ex := range items {
+				if ex.Purpose == "" {
+					ex.Purpose = purpose
+				}
+				ex.Tags = append(ex.Tags, tags...)
+				if _, err := store.Add(ex); err != nil {
+					return fmt.Errorf("example %d: %w", n+1, err)
+				}
+			}
+			gl.Log("success", fmt.Sprintf("✅ Imported %d examples into %s", len(items), store.Path()))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&purpose, "purpose", "p", "", "Purpose type of examples without one")
+	cmd.Flags().StringSliceVar(&tags, "tag", []string{}, "Add these tags to every example")
+
+	return cmd
+}
+
+// decodeExamples reads a JSON array of examples or JSON Lines
+func decodeExamples(r io.Reader) ([]i.Example, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("[")) {
+		var items []i.Example
+		err := json.Unmarshal(data, &items)
+		return items, err
+	}
+
+	var items []i.Example
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var ex i.Example
+		if err := json.Unmarshal(text, &ex); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		items = append(items, ex)
+	}
+	return items, scanner.Err()
+}
+
+// readArg returns v, or the content of the file v names when it starts with @
+func readArg(v string) (string, error) {
+	if !strings.HasPrefix(v, "@") {
+		return v, nil
+	}
+	data, err := os.ReadFile(strings.TrimPrefix(v, "@"))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func printExamples(out io.Writer, matches []i.ExampleMatch, scored bool) error {
+	if len(matches) == 0 {
+		fmt.Fprintln(out, "No examples found.")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	if scored {
+		fmt.Fprint(tw, "SCORE\t")
+	}
+	fmt.Fprintln(tw, "ID\tPURPOSE\tTAGS\tINPUT\tOUTPUT")
+	for _, m := range matches {
+		if scored {
+			fmt.Fprintf(tw, "%.3f\t", m.Score)
+		}
+		purpose := m.Purpose
+		if purpose == "" {
+			purpose = "*"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			m.ID,
+			purpose,
+			strings.Join(m.Tags, ","),
+			truncateString(strings.Join(strings.Fields(m.Input), " "), 40),
+			truncateString(strings.Join(strings.Fields(m.Output), " "), 40),
+		)
+	}
+	return tw.Flush()
+}
+
+func printJSON(out io.Writer, v any) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
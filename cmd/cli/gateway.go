@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/kubex-ecosystem/grompt/internal/engine"
+	"github.com/kubex-ecosystem/grompt/internal/gateway/middleware"
 	"github.com/kubex-ecosystem/grompt/internal/interfaces"
 	"github.com/spf13/cobra"
 )
@@ -64,6 +65,7 @@ func GatewayCmd() *cobra.Command {
 	}
 
 	gatewayCmd.AddCommand(startGatewayServerCmd())
+	gatewayCmd.AddCommand(hashKeyCmd())
 
 	return gatewayCmd
 }
@@ -85,3 +87,29 @@ func startGatewayServerCmd() *cobra.Command {
 
 	return startCmd
 }
+
+func hashKeyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "hash-key [key]",
+		Short: "Hash a client API key for the gateway auth config",
+		Long: `Prints the hash to put under auth.keys in the gateway middleware config.
+Without a key, a new random one is generated and printed too; hand it to the
+client, it is not stored anywhere.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var key string
+			if len(args) == 1 {
+				key = args[0]
+			} else {
+				generated, err := middleware.GenerateAPIKey()
+				if err != nil {
+					return err
+				}
+				key = generated
+				fmt.Printf("key:  %s\n", key)
+			}
+			fmt.Printf("hash: %s\n", middleware.HashAPIKey(key))
+			return nil
+		},
+	}
+}
@@ -0,0 +1,329 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubex-ecosystem/grompt/internal/engine"
+	"github.com/kubex-ecosystem/grompt/internal/history"
+	i "github.com/kubex-ecosystem/grompt/internal/interfaces"
+	gl "github.com/kubex-ecosystem/logz/logger"
+)
+
+// historyFilters holds the query flags shared by list, search and export
+type historyFilters struct {
+	provider string
+	model    string
+	tag      string
+	since    string
+	until    string
+	offset   int
+	limit    int
+}
+
+func (f *historyFilters) bind(cmd *cobra.Command, defaultLimit int) {
+	cmd.Flags().StringVarP(&f.provider, "provider", "P", "", "Only entries from this provider")
+	cmd.Flags().StringVarP(&f.model, "model", "m", "", "Only entries from this model")
+	cmd.Flags().StringVar(&f.tag, "tag", "", "Only entries with this tag")
+	cmd.Flags().StringVar(&f.since, "since", "", "Only entries at or after this time (RFC 3339 or YYYY-MM-DD)")
+	cmd.Flags().StringVar(&f.until, "until", "", "Only entries at or before this time (RFC 3339 or YYYY-MM-DD)")
+	cmd.Flags().IntVar(&f.offset, "offset", 0, "Number of matching entries to skip")
+	cmd.Flags().IntVarP(&f.limit, "limit", "n", defaultLimit, "Maximum number of entries (0 for all)")
+}
+
+func (f *historyFilters) query(text string) (i.HistoryQuery, error) {
+	q := i.HistoryQuery{
+		Provider: f.provider,
+		Model:    f.model,
+		Tag:      f.tag,
+		Text:     text,
+		Offset:   f.offset,
+		Limit:    f.limit,
+	}
+
+	var err error
+	if q.Since, err = history.ParseTime(f.since, false); err != nil {
+		return q, fmt.Errorf("invalid --since: %w", err)
+	}
+	if q.Until, err = history.ParseTime(f.until, true); err != nil {
+		return q, fmt.Errorf("invalid --until: %w", err)
+	}
+	return q, nil
+}
+
+// HistoryCmd returns the history command group
+func HistoryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Browse, search, re-run and export past prompts",
+		Long: fmt.Sprintf(`Browse the prompt history recorded by grompt.
+
+History is stored as JSON Lines in %s
+(override with GROMPT_HISTORY_FILE).`, history.DefaultPath()),
+	}
+
+	cmd.AddCommand(
+		historyListCommand(),
+		historyShowCommand(),
+		historySearchCommand(),
+		historyRerunCommand(),
+		historyExportCommand(),
+	)
+
+	return cmd
+}
+
+func historyListCommand() *cobra.Command {
+	var (
+		filters historyFilters
+		asJSON  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List recent history entries, newest first",
+		Example: `  grompt history list
+  grompt history list --provider claude --since 2025-01-01 --limit 50`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			q, err := filters.query("")
+			if err != nil {
+				return err
+			}
+			return printHistoryPage(cmd.OutOrStdout(), history.Open().Query(q), asJSON)
+		},
+	}
+
+	filters.bind(cmd, 20)
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the page as JSON")
+
+	return cmd
+}
+
+func historySearchCommand() *cobra.Command {
+	var (
+		filters historyFilters
+		asJSON  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "search <text>",
+		Short: "Search prompts and responses",
+		Long:  "Full-text search over prompts and responses. Every word must match, case-insensitively.",
+		Example: `  grompt history search "docker compose"
+  grompt history search retry --tag backend --provider openai`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			q, err := filters.query(strings.Join(args, " "))
+			if err != nil {
+				return err
+			}
+			return printHistoryPage(cmd.OutOrStdout(), history.Open().Query(q), asJSON)
+		},
+	}
+
+	filters.bind(cmd, 20)
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the page as JSON")
+
+	return cmd
+}
+
+func historyShowCommand() *cobra.Command {
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:     "show <id>",
+		Short:   "Show a history entry in full",
+		Long:    "Show a history entry in full. A unique prefix of the ID is enough.",
+		Example: `  grompt history show prompt_1736942400`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entry, ok := history.Open().Get(args[0])
+			if !ok {
+				return fmt.Errorf("history entry %s not found (or the prefix is ambiguous)", args[0])
+			}
+
+			out := cmd.OutOrStdout()
+			if asJSON {
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+				return enc.Encode(entry)
+			}
+			return history.Export(out, []i.Result{*entry}, history.FormatMarkdown)
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the entry as JSON")
+
+	return cmd
+}
+
+func historyRerunCommand() *cobra.Command {
+	var (
+		debug      bool
+		provider   string
+		model      string
+		configFile string
+		// API Keys
+		apiKey         string
+		ollamaEndpoint string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "rerun <id>",
+		Short: "Replay a history entry against the same or another provider",
+		Long: `Send the prompt of a history entry again. Without --provider the entry's
+provider and model are reused. The new response is recorded in history.`,
+		Example: `  grompt history rerun prompt_1736942400
+  grompt history rerun prompt_1736942400 --provider claude --model claude-3-haiku-20240307`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if debug {
+				gl.SetDebugMode(true)
+			}
+
+			entry, ok := history.Open().Get(args[0])
+			if !ok {
+				return fmt.Errorf("history entry %s not found (or the prefix is ambiguous)", args[0])
+			}
+
+			target := provider
+			if target == "" {
+				target = entry.Provider
+			}
+			cfg, err := setupConfig(configFile, target, apiKey, ollamaEndpoint)
+			if err != nil {
+				return err
+			}
+
+			eng := engine.NewEngine(cfg)
+			defer eng.Close()
+
+			gl.Log("info", fmt.Sprintf("🔁 Re-running %s on %s: %s", entry.ID, target, truncateString(entry.Prompt, 60)))
+
+			result, err := eng.Rerun(context.Background(), entry.ID, provider, model)
+			if err != nil {
+				return fmt.Errorf("failed to re-run %s: %w", entry.ID, err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "\n🎯 **%s Response (%s):**\n\n%s\n\n",
+				strings.ToUpper(result.Provider), result.Model, result.Response)
+			gl.Log("info", fmt.Sprintf("Recorded as %s", result.ID))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&debug, "debug", "D", false, "Enable debug mode")
+	cmd.Flags().StringVarP(&provider, "provider", "P", "", "AI provider (default: the entry's provider)")
+	cmd.Flags().StringVarP(&model, "model", "m", "", "Model to use (default: the entry's model when the provider is unchanged)")
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+
+	// API Key flags
+	cmd.Flags().StringVar(&apiKey, "apikey", "", "API key")
+	cmd.Flags().StringVar(&ollamaEndpoint, "ollama-endpoint", "http://localhost:11434", "Ollama endpoint")
+
+	return cmd
+}
+
+func historyExportCommand() *cobra.Command {
+	var (
+		filters historyFilters
+		text    string
+		format  string
+		output  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export history entries as Markdown, JSON or CSV",
+		Example: `  grompt history export --format markdown --output history.md
+  grompt history export --format csv --provider openai --since 2025-01-01 > openai.csv`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			q, err := filters.query(text)
+			if err != nil {
+				return err
+			}
+			page := history.Open().Query(q)
+
+			var out io.Writer = cmd.OutOrStdout()
+			if output != "" {
+				f, err := os.Create(output)
+				if err != nil {
+					return fmt.Errorf("error creating %s: %w", output, err)
+				}
+				defer f.Close()
+				out = f
+			}
+
+			if err := history.Export(out, page.Entries, format); err != nil {
+				return err
+			}
+			if output != "" {
+				gl.Log("success", fmt.Sprintf("✅ Exported %d entries to %s", len(page.Entries), output))
+			}
+			return nil
+		},
+	}
+
+	filters.bind(cmd, 0)
+	cmd.Flags().StringVarP(&text, "query", "q", "", "Only entries matching this full-text query")
+	cmd.Flags().StringVarP(&format, "format", "f", history.FormatMarkdown, "Export format (markdown, json, csv)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output file (default: stdout)")
+
+	return cmd
+}
+
+// recordHistory stores a CLI response so it can be found with `grompt history`
+func recordHistory(provider, model, prompt, response string, tags []string) {
+	history.Open().Add(i.Result{
+		ID:        fmt.Sprintf("prompt_%d", time.Now().UnixNano()),
+		Prompt:    prompt,
+		Response:  response,
+		Provider:  provider,
+		Model:     model,
+		Tags:      tags,
+		Timestamp: time.Now(),
+	})
+}
+
+func printHistoryPage(out io.Writer, page i.HistoryPage, asJSON bool) error {
+	if asJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(page)
+	}
+
+	if len(page.Entries) == 0 {
+		fmt.Fprintln(out, "No history entries found.")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tDATE\tPROVIDER\tMODEL\tTAGS\tPROMPT")
+	for _, e := range page.Entries {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.ID,
+			e.Timestamp.Local().Format("2006-01-02 15:04"),
+			e.Provider,
+			e.Model,
+			strings.Join(e.Tags, ","),
+			truncateString(strings.Join(strings.Fields(e.Prompt), " "), 60),
+		)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if shown := page.Offset + len(page.Entries); shown < page.Total {
+		fmt.Fprintf(out, "\nShowing %d-%d of %d entries (use --offset %d for more)\n", page.Offset+1, shown, page.Total, shown)
+	}
+	return nil
+}
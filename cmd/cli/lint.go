@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubex-ecosystem/grompt/internal/lint"
+)
+
+// LintCmd returns the static prompt linter command
+func LintCmd() *cobra.Command {
+	var (
+		prompt    string
+		format    string
+		maxTokens int
+		disable   []string
+		vars      []string
+		failOn    string
+		listRules bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "lint [file...]",
+		Short: "Check prompts for common mistakes without calling an LLM",
+		Long: `Analyse prompt text for common authoring mistakes. No provider is called.
+
+Rules:
+  GP001 missing-output-format     the answer's shape is never described
+  GP002 missing-role              no role or persona is given
+  GP003 ambiguous-pronoun         a paragraph opens with a pronoun that has no antecedent
+  GP004 conflicting-instructions  two instructions contradict each other
+  GP005 unresolved-placeholder    a {{.var}} placeholder was left in the text
+  GP006 excessive-length          the prompt exceeds --max-tokens
+
+Reads the given files, --prompt, or stdin ("-" or no arguments). The command
+exits with an error when a finding reaches the --fail-on severity.`,
+		Example: `  grompt lint prompt.md
+  grompt lint --prompt "Summarise the article" --format json
+  grompt generate --ideas "api,security" | grompt lint --disable GP003
+  grompt lint template.tmpl --var topic --var lang`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			if listRules {
+				w := tabwriter.NewWriter(out, 0, 2, 2, ' ', 0)
+				fmt.Fprintln(w, "ID\tNAME\tSEVERITY\tDESCRIPTION")
+				for _, r := range lint.Rules() {
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.ID, r.Name, r.Severity, r.Description)
+				}
+				return w.Flush()
+			}
+
+			threshold := lint.Severity(strings.ToLower(failOn))
+			switch threshold {
+			case lint.SeverityInfo, lint.SeverityWarning, lint.SeverityError, "none":
+			default:
+				return fmt.Errorf("invalid --fail-on %q (info, warning, error, none)", failOn)
+			}
+
+			opts := lint.Options{MaxTokens: maxTokens, Disable: disable, Vars: map[string]any{}}
+			for _, v := range vars {
+				name, _, _ := strings.Cut(v, "=")
+				opts.Vars[strings.TrimSpace(name)] = true
+			}
+
+			inputs, err := lintInputs(cmd.InOrStdin(), prompt, args)
+			if err != nil {
+				return err
+			}
+
+			results := make([]lintResult, 0, len(inputs))
+			failed := 0
+			for _, in := range inputs {
+				report := lint.Lint(in.text, opts)
+				if threshold != "none" && report.Has(threshold) {
+					failed++
+				}
+				results = append(results, lintResult{Source: in.source, Report: report})
+			}
+
+			switch format {
+			case "json":
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(results); err != nil {
+					return err
+				}
+			case "table", "":
+				printLintResults(out, results)
+			default:
+				return fmt.Errorf("unsupported format %q (table, json)", format)
+			}
+
+			if failed > 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%d of %d prompts have findings at or above %s", failed, len(results), threshold)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&prompt, "prompt", "", "Prompt text to lint")
+	cmd.Flags().StringVarP(&format, "format", "f", "table", "Output format (table, json)")
+	cmd.Flags().IntVar(&maxTokens, "max-tokens", 3000, "Estimated token budget for GP006")
+	cmd.Flags().StringSliceVar(&disable, "disable", []string{}, "Rule IDs or names to skip (comma-separated or multiple flags)")
+	cmd.Flags().StringSliceVar(&vars, "var", []string{}, "Variable supplied at render time; its placeholders are not reported")
+	cmd.Flags().StringVar(&failOn, "fail-on", "error", "Exit with an error at this severity (info, warning, error, none)")
+	cmd.Flags().BoolVar(&listRules, "rules", false, "List the available rules and exit")
+
+	return cmd
+}
+
+type lintInput struct {
+	source string
+	text   string
+}
+
+type lintResult struct {
+	Source string `json:"source"`
+	lint.Report
+}
+
+func lintInputs(stdin io.Reader, prompt string, files []string) ([]lintInput, error) {
+	var inputs []lintInput
+	if prompt != "" {
+		inputs = append(inputs, lintInput{source: "--prompt", text: prompt})
+	}
+	if len(files) == 0 && prompt == "" {
+		files = []string{"-"}
+	}
+	for _, name := range files {
+		var data []byte
+		var err error
+		if name == "-" {
+			data, err = io.ReadAll(stdin)
+			name = "stdin"
+		} else {
+			data, err = os.ReadFile(name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", name, err)
+		}
+		inputs = append(inputs, lintInput{source: name, text: string(data)})
+	}
+	return inputs, nil
+}
+
+func printLintResults(out io.Writer, results []lintResult) {
+	w := tabwriter.NewWriter(out, 0, 2, 2, ' ', 0)
+	for _, r := range results {
+		for _, f := range r.Findings {
+			fmt.Fprintf(w, "%s:%d:%d\t%s\t%s\t%s\n", r.Source, f.Position.Line, f.Position.Column, f.Severity, f.Rule, f.Message)
+		}
+	}
+	w.Flush()
+	for _, r := range results {
+		if len(r.Findings) == 0 {
+			fmt.Fprintf(out, "✅ %s: no findings (~%d tokens)\n", r.Source, r.Tokens)
+		} else {
+			fmt.Fprintf(out, "%s: %d errors, %d warnings, %d findings (~%d tokens)\n",
+				r.Source, r.Errors, r.Warnings, len(r.Findings), r.Tokens)
+		}
+	}
+}
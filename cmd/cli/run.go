@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubex-ecosystem/grompt/internal/cache"
+	"github.com/kubex-ecosystem/grompt/internal/engine"
+	"github.com/kubex-ecosystem/grompt/internal/pipeline"
+	l "github.com/kubex-ecosystem/logz"
+	gl "github.com/kubex-ecosystem/logz/logger"
+)
+
+// RunCmd returns the pipeline runner command
+func RunCmd() *cobra.Command {
+	var (
+		debug      bool
+		inputs     []string
+		format     string
+		noCache    bool
+		configFile string
+		// API Keys
+		apiKey         string
+		ollamaEndpoint string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "run <pipeline.yml>",
+		Short: "Run a multi-step prompt pipeline",
+		Long: `Run a pipeline of prompt steps declared in YAML. Each step renders an inline
+prompt or a stored template and sends it to a provider; later steps consume
+earlier outputs through their inputs:
+
+  name: article
+  provider: openai
+  inputs: {topic: Go generics}
+  steps:
+    - id: ideas
+      prompt: "List five angles on {{.topic}}"
+    - id: draft
+      template: writing/draft
+      inputs: {ideas: "{{.steps.ideas.output}}"}
+    - id: reviews
+      parallel:
+        - {id: style, prompt: "Review the style: {{.d}}", inputs: {d: "{{.steps.draft.output}}"}}
+        - {id: facts, prompt: "Check the facts: {{.d}}", inputs: {d: "{{.steps.draft.output}}"}}
+    - id: final
+      if: '{{contains .steps.reviews.output "REVISE"}}'
+      prompt: "Revise {{.d}} following {{.notes}}"
+      inputs: {d: "{{.steps.draft.output}}", notes: "{{.steps.reviews.output}}"}
+
+Every step is recorded in history tagged "pipeline" and the pipeline name
+(see 'grompt history list --tag pipeline').`,
+		Example: `  grompt run article.yml --input topic="Go generics"
+  grompt run article.yml --format json > trace.json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if debug {
+				l.GetLogger("Grompt")
+				gl.SetDebugMode(true)
+			}
+
+			p, err := pipeline.Load(args[0])
+			if err != nil {
+				return err
+			}
+			vars := map[string]any{}
+			for _, in := range inputs {
+				key, value, ok := strings.Cut(in, "=")
+				if !ok || key == "" {
+					return fmt.Errorf("invalid --input %q, expected key=value", in)
+				}
+				vars[key] = value
+			}
+
+			cfg, err := setupConfig(configFile, p.Provider, apiKey, ollamaEndpoint)
+			if err != nil {
+				return err
+			}
+			eng := engine.NewEngine(cfg)
+			defer eng.Close()
+
+			gl.Log("info", fmt.Sprintf("🔗 Running pipeline %s (%d steps)", p.Name, len(p.Steps)))
+
+			ctx := context.Background()
+			if noCache {
+				ctx = cache.Bypass(ctx)
+			}
+			run, runErr := (&pipeline.Runner{Engine: eng}).Run(ctx, p, vars)
+			if run == nil {
+				return runErr
+			}
+
+			out := cmd.OutOrStdout()
+			switch format {
+			case "json":
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(run); err != nil {
+					return err
+				}
+			case "text":
+				errOut := cmd.ErrOrStderr()
+				for _, s := range run.Steps {
+					switch {
+					case s.Skipped:
+						fmt.Fprintf(errOut, "⏭️  %s skipped\n", s.ID)
+					case s.Error != "":
+						fmt.Fprintf(errOut, "❌ %s: %s\n", s.ID, s.Error)
+					default:
+						fmt.Fprintf(errOut, "✅ %s (%s, %dms)\n", s.ID, s.Provider, s.LatencyMs)
+					}
+				}
+				if runErr == nil {
+					fmt.Fprintln(out, run.Output)
+				}
+			default:
+				return fmt.Errorf("unknown format %s (text, json)", format)
+			}
+
+			if runErr != nil {
+				cmd.SilenceUsage = true
+			}
+			return runErr
+		},
+	}
+
+	cmd.Flags().BoolVarP(&debug, "debug", "D", false, "Enable debug mode")
+	cmd.Flags().StringArrayVarP(&inputs, "input", "i", []string{}, "Pipeline input as key=value (repeatable)")
+	cmd.Flags().StringVarP(&format, "format", "f", "text", "Output format (text, json)")
+	cmd.Flags().BoolVar(&noCache, "no-cache", false, "Bypass the response cache")
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+
+	// API Key flags
+	cmd.Flags().StringVar(&apiKey, "apikey", "", "API key")
+	cmd.Flags().StringVar(&ollamaEndpoint, "ollama-endpoint", "http://localhost:11434", "Ollama endpoint")
+
+	return cmd
+}
@@ -8,4 +8,13 @@ import (
 
 type Engine = interfaces.IEngine
 
+type HistoryQuery = interfaces.HistoryQuery
+type HistoryPage = interfaces.HistoryPage
+
+type CompareTarget = interfaces.CompareTarget
+type Comparison = interfaces.Comparison
+
+type GenerationRequest = interfaces.GenerationRequest
+type Generation = interfaces.Generation
+
 func NewEngine(config interfaces.IConfig) interfaces.IEngine { return engine.NewEngine(config) }
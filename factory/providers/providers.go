@@ -14,6 +14,15 @@ type Capabilities = i.Capabilities
 
 type Pricing = i.Pricing
 
+type Embedder = i.Embedder
+
+type EmbeddingRequest = i.EmbeddingRequest
+
+type Embeddings = i.Embeddings
+
+// EmbedderOf returns the embedding capability of a provider, if it has one.
+func EmbedderOf(p Provider) (Embedder, bool) { return i.EmbedderOf(p) }
+
 // Initialize mirrors the legacy helper, wiring a prompt engine and returning the available providers.
 func Initialize(
 	port string,
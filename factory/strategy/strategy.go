@@ -0,0 +1,41 @@
+// Package strategy exposes the prompt engineering strategy registry, so
+// other programs can add their own ways of turning ideas into prompts.
+package strategy
+
+import (
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+	is "github.com/kubex-ecosystem/grompt/internal/strategy"
+)
+
+// Strategy turns ideas into a prompt, possibly with several model calls
+type Strategy = interfaces.Strategy
+
+// GenerationRequest describes the prompt to engineer from raw ideas
+type GenerationRequest = interfaces.GenerationRequest
+
+// Generation is a prompt produced by a strategy
+type Generation = interfaces.Generation
+
+// Example is a library input/output pair injected as a demonstration
+type Example = interfaces.Example
+
+// LLM sends one prompt to the model a strategy runs on
+type LLM = interfaces.LLM
+
+// Info describes a registered strategy
+type Info = is.Info
+
+// Default is the strategy used for purpose types without one of their own
+const Default = is.Default
+
+// Register adds s and makes it the strategy of the given purpose types
+func Register(s Strategy, purposes ...string) { is.Register(s, purposes...) }
+
+// Lookup returns the strategy registered under name
+func Lookup(name string) (Strategy, bool) { return is.Lookup(name) }
+
+// List describes the registered strategies
+func List() []Info { return is.List() }
+
+// Demonstrations renders examples as a few-shot section of a prompt
+func Demonstrations(lang string, examples []Example) string { return is.Demonstrations(lang, examples) }
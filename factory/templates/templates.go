@@ -1,72 +1,36 @@
-// Package templates defines interfaces for template management.
+// Package templates exposes the prompt template library.
 package templates
 
-import "fmt"
+import (
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+	it "github.com/kubex-ecosystem/grompt/internal/templates"
+)
 
 // Manager handles prompt templates
-type Manager interface {
-	// Process processes a template with variables
-	Process(template string, vars map[string]interface{}) (string, error)
-
-	// LoadTemplate loads a template by name
-	LoadTemplate(name string) (string, error)
-
-	// SaveTemplate saves a template with a name
-	SaveTemplate(name, content string) error
-
-	// ListTemplates returns all available template names
-	ListTemplates() []string
-
-	// DeleteTemplate removes a template
-	DeleteTemplate(name string) error
-}
+type Manager = interfaces.Manager
 
 // Template represents a prompt template
-type Template struct {
-	Name        string                 `json:"name"`
-	Content     string                 `json:"content"`
-	Variables   []string               `json:"variables"`
-	Description string                 `json:"description"`
-	Category    string                 `json:"category"`
-	Metadata    map[string]interface{} `json:"metadata,omitempty"`
-}
+type Template = interfaces.Template
 
-// NewManager creates a new template manager
-func NewManager(templatesPath string) Manager {
-	// This will be implemented in internal/templates with concrete implementation
-	// For now, return a simple implementation to avoid compilation errors
-	return &simpleManager{path: templatesPath}
-}
+// VariableReport describes how a set of variables matches a template
+type VariableReport = interfaces.VariableReport
 
-// simpleManager is a basic implementation to prevent compilation errors
-type simpleManager struct {
-	path string
-}
+// VariableError lists every variable violation found while rendering a template
+type VariableError = it.VariableError
 
-func (sm *simpleManager) Process(template string, vars map[string]interface{}) (string, error) {
-	// Simple template processing - replace {{.key}} with values
-	result := template
-	for key, value := range vars {
-		placeholder := fmt.Sprintf("{{.%s}}", key)
-		valueStr := fmt.Sprintf("%v", value)
-		// Simple string replacement for now
-		result = template + " [" + placeholder + "=" + valueStr + "]"
-	}
-	return result, nil
-}
+// Variable declares a typed template variable
+type Variable = interfaces.Variable
 
-func (sm *simpleManager) LoadTemplate(name string) (string, error) {
-	return "", fmt.Errorf("not implemented yet")
-}
+// Violation describes a single variable that does not satisfy its declaration
+type Violation = it.Violation
 
-func (sm *simpleManager) SaveTemplate(name, content string) error {
-	return fmt.Errorf("not implemented yet")
-}
+// ErrTemplateNotFound is returned when a named template does not exist
+var ErrTemplateNotFound = it.ErrTemplateNotFound
 
-func (sm *simpleManager) ListTemplates() []string {
-	return []string{}
+// NewManager creates a filesystem-backed template manager rooted at templatesPath
+func NewManager(templatesPath string) Manager {
+	return it.NewManager(templatesPath)
 }
 
-func (sm *simpleManager) DeleteTemplate(name string) error {
-	return fmt.Errorf("not implemented yet")
-}
+// Variables returns the top-level variables referenced by a template text
+func Variables(text string) ([]string, error) { return it.Variables(text) }
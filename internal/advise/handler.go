@@ -2,17 +2,35 @@
 package advise
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/kubex-ecosystem/grompt/internal/gateway/middleware"
 	"github.com/kubex-ecosystem/grompt/internal/gateway/registry"
 	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"github.com/kubex-ecosystem/grompt/internal/structured"
+	"github.com/kubex-ecosystem/grompt/internal/tokenizer"
+	"github.com/kubex-ecosystem/grompt/internal/types"
+	gl "github.com/kubex-ecosystem/logz/logger"
 )
 
-type Handler struct{ reg *registry.Registry }
+type Handler struct {
+	reg    *registry.Registry
+	budget *middleware.BudgetManager
+}
+
+// New returns the advise handler. budget may be nil to disable spend limits.
+func New(reg *registry.Registry, budget *middleware.BudgetManager) *Handler {
+	return &Handler{reg: reg, budget: budget}
+}
 
-func New(reg *registry.Registry) *Handler { return &Handler{reg: reg} }
+// errBudget is returned for attempts the spend limits do not allow
+var errBudget = errors.New("budget exhausted")
 
 type adviseReq struct {
 	Mode        string         `json:"mode"`
@@ -21,6 +39,31 @@ type adviseReq struct {
 	Scorecard   map[string]any `json:"scorecard"`
 	Hotspots    []string       `json:"hotspots"`
 	Temperature float32        `json:"temperature"`
+	// JSONSchema asks for the advice as a JSON document matching it instead
+	// of streamed text; ?format=json uses AdviceSchema
+	JSONSchema json.RawMessage `json:"json_schema,omitempty"`
+}
+
+// AdviceSchema is the document returned by ?format=json
+var AdviceSchema = map[string]any{
+	"type":     "object",
+	"required": []any{"summary", "recommendations"},
+	"properties": map[string]any{
+		"summary": map[string]any{"type": "string"},
+		"recommendations": map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type":     "object",
+				"required": []any{"title", "priority", "details"},
+				"properties": map[string]any{
+					"title":    map[string]any{"type": "string"},
+					"priority": map[string]any{"type": "string", "enum": []any{"high", "medium", "low"}},
+					"details":  map[string]any{"type": "string"},
+					"area":     map[string]any{"type": "string"},
+				},
+			},
+		},
+	},
 }
 
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -41,28 +84,50 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	tenant, user := r.Header.Get("x-tenant-id"), r.Header.Get("x-user-id")
 	sys := systemPrompt(in.Mode)
-	user := userPrompt(in.Scorecard, in.Hotspots)
+	prompt := userPrompt(in.Scorecard, in.Hotspots)
 
 	headers := map[string]string{
 		"x-external-api-key": r.Header.Get("x-external-api-key"),
-		"x-tenant-id":        r.Header.Get("x-tenant-id"),
-		"x-user-id":          r.Header.Get("x-user-id"),
+		"x-tenant-id":        tenant,
+		"x-user-id":          user,
 	}
 
-	ch, err := p.Chat(r.Context(), interfaces.ChatRequest{
+	req := interfaces.ChatRequest{
 		Provider: in.Provider,
 		Model:    in.Model,
 		Temp:     in.Temperature,
 		Stream:   true,
 		Messages: []interfaces.Message{
 			{Role: "system", Content: sys},
-			{Role: "user", Content: user},
+			{Role: "user", Content: prompt},
 		},
 		Meta:    map[string]any{},
 		Headers: headers,
-	})
+	}
+
+	schema, err := structured.SchemaFrom(in.JSONSchema)
 	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if schema == nil && r.URL.Query().Get("format") == "json" {
+		schema = AdviceSchema
+	}
+	if schema != nil {
+		h.serveJSON(w, r, p, req, schema, mode, tenant, user)
+		return
+	}
+
+	reserved, err := h.reserve(w, tenant, user, p, &req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusPaymentRequired)
+		return
+	}
+	ch, err := p.Chat(r.Context(), req)
+	if err != nil {
+		h.settle(r.Context(), tenant, user, reserved, p, req.Model, nil)
 		http.Error(w, err.Error(), http.StatusBadGateway)
 		return
 	}
@@ -73,7 +138,13 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	enc := func(v any) []byte { b, _ := json.Marshal(v); return b }
 	start := time.Now()
+	var reply strings.Builder
+	var usage *interfaces.Usage
 	for c := range ch {
+		reply.WriteString(c.Content)
+		if c.Usage != nil {
+			usage = c.Usage
+		}
 		if c.Content != "" {
 			w.Write([]byte("data: "))
 			w.Write(enc(map[string]any{"content": c.Content}))
@@ -91,6 +162,106 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		_ = start
 
 	}
+	if usage == nil {
+		usage = estimateUsage(p, req.Model, req.Messages, reply.String())
+	}
+	h.settle(r.Context(), tenant, user, reserved, p, req.Model, usage)
+}
+
+// serveJSON answers with the advice as a document validated against schema,
+// re-prompting with the validation errors when the model strays from it.
+// Every attempt is checked against the budget and charged on its own.
+func (h *Handler) serveJSON(w http.ResponseWriter, r *http.Request, p interfaces.Provider, req interfaces.ChatRequest, schema map[string]any, mode, tenant, user string) {
+	req.Stream = false
+	doc, err := structured.Generate(r.Context(), func(ctx context.Context, messages []interfaces.Message) (string, error) {
+		attempt := req
+		attempt.Messages = messages
+		reserved, err := h.reserve(w, tenant, user, p, &attempt)
+		if err != nil {
+			return "", err
+		}
+		req.Model = attempt.Model // a downgrade holds for the next attempts
+		ch, err := p.Chat(ctx, attempt)
+		if err != nil {
+			h.settle(ctx, tenant, user, reserved, p, attempt.Model, nil)
+			return "", err
+		}
+		var reply strings.Builder
+		var usage *interfaces.Usage
+		var failure string
+		for c := range ch {
+			reply.WriteString(c.Content)
+			if c.Usage != nil {
+				usage = c.Usage
+			}
+			if c.Error != "" && failure == "" {
+				failure = c.Error
+			}
+		}
+		if failure != "" {
+			h.settle(ctx, tenant, user, reserved, p, attempt.Model, nil)
+			return "", errors.New(failure)
+		}
+		if usage == nil {
+			usage = estimateUsage(p, attempt.Model, messages, reply.String())
+		}
+		h.settle(ctx, tenant, user, reserved, p, attempt.Model, usage)
+		return reply.String(), nil
+	}, req.Messages, schema, structured.DefaultRetries)
+
+	if errors.Is(err, errBudget) {
+		http.Error(w, err.Error(), http.StatusPaymentRequired)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		var invalid *structured.Error
+		if errors.As(err, &invalid) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(map[string]any{"error": err.Error(), "attempts": invalid.Attempts, "last_reply": invalid.LastReply})
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]any{"mode": mode, "advice": doc.Data, "attempts": doc.Attempts})
+}
+
+// reserve checks an attempt of req on p against the spend limits of tenant
+// and user, holding its worst case, the prompt and a full completion, until
+// settle. A downgrade decided by the budget is applied to req.Model.
+func (h *Handler) reserve(w http.ResponseWriter, tenant, user string, p interfaces.Provider, req *interfaces.ChatRequest) (float64, error) {
+	if h.budget == nil {
+		return 0, nil
+	}
+	prompt := tokenizer.CountMessages(tokenizer.For(p.Name(), req.Model), req.Messages)
+	worst := &interfaces.Usage{Prompt: prompt, Completion: types.ChatMaxTokens(*req)}
+	decision := h.budget.Check(tenant, user, p.Name(), req.Model, h.budget.Cost(p.Name(), req.Model, worst))
+	if !decision.Allowed {
+		return 0, fmt.Errorf("%w: %s", errBudget, decision.Reason)
+	}
+	if decision.Downgraded {
+		w.Header().Set("X-Grompt-Budget", "downgraded; "+decision.Reason)
+		req.Model = decision.Model
+	}
+	return decision.ReservedUSD, nil
+}
+
+// settle releases what reserve held for an attempt and records the cost of
+// usage, which is nil for attempts that failed
+func (h *Handler) settle(ctx context.Context, tenant, user string, reserved float64, p interfaces.Provider, model string, usage *interfaces.Usage) {
+	if h.budget == nil {
+		return
+	}
+	if err := h.budget.Settle(context.WithoutCancel(ctx), tenant, user, reserved, h.budget.Cost(p.Name(), model, usage)); err != nil {
+		gl.Log("warn", fmt.Sprintf("failed to record spend: %v", err))
+	}
+}
+
+// estimateUsage counts the tokens of an attempt whose provider reported no usage
+func estimateUsage(p interfaces.Provider, model string, messages []interfaces.Message, reply string) *interfaces.Usage {
+	tk := tokenizer.For(p.Name(), model)
+	return &interfaces.Usage{Prompt: tokenizer.CountMessages(tk, messages), Completion: tk.Count(reply)}
 }
 
 // systemPrompt retorna o prompt de sistema apropriado para o modo requerido.
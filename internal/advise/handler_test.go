@@ -0,0 +1,45 @@
+package advise
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kubex-ecosystem/grompt/internal/gateway/middleware"
+	"github.com/kubex-ecosystem/grompt/internal/gateway/registry"
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"github.com/kubex-ecosystem/grompt/internal/types"
+)
+
+func TestAdviseRejectsTenantOverBudget(t *testing.T) {
+	t.Setenv("GROMPT_ADVISE_TEST_KEY", "sk-test")
+	reg, err := registry.FromConfig(&types.Config{Providers: map[string]interfaces.Provider{
+		"openai": &types.ProviderImpl{VName: "openai", VType: "openai", VKeyEnv: "GROMPT_ADVISE_TEST_KEY"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	budget, err := middleware.NewBudgetManager(middleware.BudgetConfig{Enabled: true, Tenant: middleware.BudgetLimit{DailyUSD: 1}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	budget.Record(context.Background(), "acme", "", 1)
+	h := New(reg, budget)
+
+	// Both the streamed advice and every JSON attempt go through the budget
+	for _, target := range []string{"/v1/advise?mode=code", "/v1/advise?mode=code&format=json"} {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(`{"provider": "openai", "model": "gpt-4o-mini"}`))
+		req.Header.Set("x-tenant-id", "acme")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusPaymentRequired || !strings.Contains(rec.Body.String(), "budget") {
+			t.Fatalf("%s: expected 402 for a tenant over its limit, got %d %s", target, rec.Code, rec.Body.String())
+		}
+	}
+	if status := budget.Status("acme", ""); status[0].ReservedUSD != 0 {
+		t.Fatalf("refused requests must not hold a reservation, got %+v", status)
+	}
+}
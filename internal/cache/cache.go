@@ -0,0 +1,338 @@
+// Package cache implements the response cache placed in front of providers,
+// so repeated low-temperature requests are answered without a round-trip.
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	gl "github.com/kubex-ecosystem/logz/logger"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"github.com/kubex-ecosystem/grompt/internal/module/kbx"
+)
+
+// Config configures the response cache
+type Config struct {
+	// Backend is "memory" or "disk"; empty disables the cache
+	Backend string `yaml:"backend" json:"backend"`
+	// Dir holds the entries of the disk backend
+	Dir        string        `yaml:"dir" json:"dir,omitempty"`
+	TTL        time.Duration `yaml:"ttl" json:"ttl"`
+	MaxEntries int           `yaml:"max_entries" json:"max_entries"`
+	MaxBytes   int64         `yaml:"max_bytes" json:"max_bytes"`
+	// MaxTemperature is the highest temperature still considered
+	// deterministic enough to cache
+	MaxTemperature float32 `yaml:"max_temperature" json:"max_temperature"`
+}
+
+// DefaultConfig returns the defaults, with the cache disabled
+func DefaultConfig() Config {
+	return Config{
+		Dir:            os.ExpandEnv(kbx.DefaultGromptCacheDir),
+		TTL:            24 * time.Hour,
+		MaxEntries:     1000,
+		MaxBytes:       64 << 20,
+		MaxTemperature: 0.3,
+	}
+}
+
+// ConfigFromEnv reads the cache configuration from GROMPT_CACHE ("memory",
+// "disk" or "off"), GROMPT_CACHE_DIR, GROMPT_CACHE_TTL,
+// GROMPT_CACHE_MAX_ENTRIES, GROMPT_CACHE_MAX_BYTES and
+// GROMPT_CACHE_MAX_TEMPERATURE over DefaultConfig.
+func ConfigFromEnv() (Config, error) {
+	cfg := DefaultConfig()
+	switch backend := strings.ToLower(strings.TrimSpace(os.Getenv("GROMPT_CACHE"))); backend {
+	case "", "off", "false", "0":
+	default:
+		cfg.Backend = backend
+	}
+	if dir := os.Getenv("GROMPT_CACHE_DIR"); dir != "" {
+		cfg.Dir = dir
+	}
+	if v := os.Getenv("GROMPT_CACHE_TTL"); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid GROMPT_CACHE_TTL %q: %w", v, err)
+		}
+		cfg.TTL = ttl
+	}
+	if v := os.Getenv("GROMPT_CACHE_MAX_ENTRIES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid GROMPT_CACHE_MAX_ENTRIES %q: %w", v, err)
+		}
+		cfg.MaxEntries = n
+	}
+	if v := os.Getenv("GROMPT_CACHE_MAX_BYTES"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid GROMPT_CACHE_MAX_BYTES %q: %w", v, err)
+		}
+		cfg.MaxBytes = n
+	}
+	if v := os.Getenv("GROMPT_CACHE_MAX_TEMPERATURE"); v != "" {
+		t, err := strconv.ParseFloat(v, 32)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid GROMPT_CACHE_MAX_TEMPERATURE %q: %w", v, err)
+		}
+		cfg.MaxTemperature = float32(t)
+	}
+	return cfg, nil
+}
+
+// Entry is a cached response
+type Entry struct {
+	Key       string            `json:"key"`
+	Provider  string            `json:"provider"`
+	Model     string            `json:"model,omitempty"`
+	Content   string            `json:"content"`
+	Usage     *interfaces.Usage `json:"usage,omitempty"`
+	CachedAt  time.Time         `json:"cached_at"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// size approximates the bytes an entry takes in a backend
+func (e *Entry) size() int64 {
+	return int64(len(e.Key) + len(e.Provider) + len(e.Model) + len(e.Content) + 128)
+}
+
+// Backend stores entries, evicting the least recently used ones beyond its
+// limits. Expiry is handled by Cache.
+type Backend interface {
+	Get(key string) (*Entry, bool)
+	Set(entry *Entry) error
+	Delete(key string)
+	Clear() error
+	// Len returns the number of entries and their size in bytes
+	Len() (int, int64)
+}
+
+// Stats tracks cache effectiveness
+type Stats struct {
+	Backend     string  `json:"backend"`
+	Hits        int64   `json:"hits"`
+	Misses      int64   `json:"misses"`
+	Bypassed    int64   `json:"bypassed"`
+	Stores      int64   `json:"stores"`
+	Expired     int64   `json:"expired"`
+	Entries     int     `json:"entries"`
+	Bytes       int64   `json:"bytes"`
+	HitRate     float64 `json:"hit_rate"`
+	SavedTokens int64   `json:"saved_tokens"`
+	SavedCost   float64 `json:"saved_cost_usd"`
+}
+
+// Cache is a response cache with TTL over a Backend. A nil *Cache is valid
+// and caches nothing.
+type Cache struct {
+	config  Config
+	backend Backend
+	now     func() time.Time
+
+	mu    sync.Mutex
+	stats Stats
+}
+
+// New creates a cache with the configured backend. Zero limits take the
+// values of DefaultConfig.
+func New(config Config) (*Cache, error) {
+	defaults := DefaultConfig()
+	if config.TTL <= 0 {
+		config.TTL = defaults.TTL
+	}
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = defaults.MaxEntries
+	}
+	if config.MaxBytes <= 0 {
+		config.MaxBytes = defaults.MaxBytes
+	}
+
+	var backend Backend
+	switch config.Backend {
+	case "memory":
+		backend = NewMemoryBackend(config.MaxEntries, config.MaxBytes)
+	case "disk":
+		if config.Dir == "" {
+			config.Dir = defaults.Dir
+		}
+		disk, err := NewDiskBackend(config.Dir, config.MaxEntries, config.MaxBytes)
+		if err != nil {
+			return nil, err
+		}
+		backend = disk
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q (use memory or disk)", config.Backend)
+	}
+
+	return &Cache{
+		config:  config,
+		backend: backend,
+		now:     time.Now,
+		stats:   Stats{Backend: config.Backend},
+	}, nil
+}
+
+// Open creates the cache configured by the environment. It returns nil when
+// the cache is disabled, or when it cannot be created, after logging why.
+func Open() *Cache {
+	cfg, err := ConfigFromEnv()
+	if err == nil && cfg.Backend == "" {
+		return nil
+	}
+	var c *Cache
+	if err == nil {
+		c, err = New(cfg)
+	}
+	if err != nil {
+		gl.Log("warn", fmt.Sprintf("Response cache disabled: %v", err))
+		return nil
+	}
+	return c
+}
+
+// Cacheable reports whether a request at temperature is deterministic enough
+// to be cached.
+func (c *Cache) Cacheable(temperature float32) bool {
+	return c != nil && temperature <= c.config.MaxTemperature
+}
+
+// Get returns the live entry under key
+func (c *Cache) Get(key string) (*Entry, bool) {
+	if c == nil {
+		return nil, false
+	}
+	entry, ok := c.backend.Get(key)
+	expired := ok && !c.now().Before(entry.ExpiresAt)
+	if expired {
+		c.backend.Delete(key)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch {
+	case expired:
+		c.stats.Expired++
+		c.stats.Misses++
+		return nil, false
+	case !ok:
+		c.stats.Misses++
+		return nil, false
+	}
+	c.stats.Hits++
+	if entry.Usage != nil {
+		c.stats.SavedTokens += int64(entry.Usage.Tokens)
+		c.stats.SavedCost += entry.Usage.CostUSD
+	}
+	return entry, true
+}
+
+// Put stores a response under key for the configured TTL
+func (c *Cache) Put(key, provider, model, content string, usage *interfaces.Usage) error {
+	if c == nil {
+		return nil
+	}
+	now := c.now()
+	entry := &Entry{
+		Key:       key,
+		Provider:  provider,
+		Model:     model,
+		Content:   content,
+		Usage:     usage,
+		CachedAt:  now,
+		ExpiresAt: now.Add(c.config.TTL),
+	}
+	if err := c.backend.Set(entry); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.stats.Stores++
+	c.mu.Unlock()
+	return nil
+}
+
+// Bypassed counts a request that skipped the cache
+func (c *Cache) Bypassed() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.stats.Bypassed++
+	c.mu.Unlock()
+}
+
+// Stats returns a snapshot of the cache statistics
+func (c *Cache) Stats() Stats {
+	if c == nil {
+		return Stats{}
+	}
+	c.mu.Lock()
+	stats := c.stats
+	c.mu.Unlock()
+
+	stats.Entries, stats.Bytes = c.backend.Len()
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
+
+// Clear removes every entry
+func (c *Cache) Clear() error {
+	if c == nil {
+		return nil
+	}
+	return c.backend.Clear()
+}
+
+// Key hashes the parts that identify a request. Callers must leave out
+// anything secret, such as API keys, and anything that does not change the
+// response, such as streaming.
+func Key(parts ...any) string {
+	data, err := json.Marshal(parts)
+	if err != nil {
+		data = []byte(fmt.Sprintf("%#v", parts))
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ChatKey is the key of a chat request. The tools offered are part of it,
+// so a request with tools is never answered by a reply given without them.
+func ChatKey(req interfaces.ChatRequest) string {
+	return Key("chat", req.Provider, req.Model, req.PromptTemplate, req.Messages, req.Temp, req.Meta, req.Tools, req.ToolChoice)
+}
+
+type bypassKey struct{}
+
+// Bypass returns a context whose requests skip the cache, neither reading
+// nor storing entries.
+func Bypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassKey{}, true)
+}
+
+// IsBypassed reports whether ctx was created by Bypass
+func IsBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(bypassKey{}).(bool)
+	return bypass
+}
+
+// NoCache reports whether a Cache-Control header asks to skip the cache
+func NoCache(cacheControl string) bool {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		switch strings.ToLower(strings.TrimSpace(directive)) {
+		case "no-cache", "no-store":
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,218 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+)
+
+// countingProvider streams a fixed reply and counts the calls that reach it
+type countingProvider struct {
+	interfaces.Provider
+	reply string
+	calls int
+}
+
+func (p *countingProvider) Name() string { return "stub" }
+
+func (p *countingProvider) Chat(ctx context.Context, req interfaces.ChatRequest) (<-chan interfaces.ChatChunk, error) {
+	p.calls++
+	ch := make(chan interfaces.ChatChunk, 3)
+	ch <- interfaces.ChatChunk{Content: p.reply[:2]}
+	ch <- interfaces.ChatChunk{Content: p.reply[2:]}
+	ch <- interfaces.ChatChunk{Done: true, Usage: &interfaces.Usage{Prompt: 5, Completion: 2, Tokens: 7, Ms: 120}}
+	close(ch)
+	return ch, nil
+}
+
+func (p *countingProvider) Execute(ctx context.Context, template string, vars map[string]any) (*interfaces.Result, error) {
+	p.calls++
+	return &interfaces.Result{Response: p.reply + " " + template, Provider: p.Name()}, nil
+}
+
+func drain(t *testing.T, ch <-chan interfaces.ChatChunk) (string, bool, *interfaces.Usage) {
+	t.Helper()
+	var content strings.Builder
+	var usage *interfaces.Usage
+	cached := false
+	for chunk := range ch {
+		content.WriteString(chunk.Content)
+		cached = cached || chunk.Cached
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+	}
+	return content.String(), cached, usage
+}
+
+func chat(t *testing.T, p interfaces.Provider, ctx context.Context, req interfaces.ChatRequest) (string, bool, *interfaces.Usage) {
+	t.Helper()
+	ch, err := p.Chat(ctx, req)
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	return drain(t, ch)
+}
+
+func TestWrapReplaysDeterministicChats(t *testing.T) {
+	c, err := New(Config{Backend: "memory", MaxTemperature: 0.3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner := &countingProvider{reply: "hello"}
+	p := Wrap(inner, c)
+	req := interfaces.ChatRequest{Model: "m", Messages: []interfaces.Message{{Role: "user", Content: "hi"}}, Headers: map[string]string{"x-external-api-key": "a"}}
+	ctx := context.Background()
+
+	if got, cached, _ := chat(t, p, ctx, req); got != "hello" || cached {
+		t.Fatalf("first call should reach the provider, got %q cached=%v", got, cached)
+	}
+
+	// Headers carry credentials and do not change the response
+	req.Headers = map[string]string{"x-external-api-key": "b"}
+	got, cached, usage := chat(t, p, ctx, req)
+	if got != "hello" || !cached || inner.calls != 1 {
+		t.Fatalf("second call should be replayed, got %q cached=%v calls=%d", got, cached, inner.calls)
+	}
+	if usage == nil || usage.Tokens != 7 || usage.Ms != 0 {
+		t.Fatalf("expected replayed usage without latency, got %+v", usage)
+	}
+
+	// A different model, tools, a bypass or a creative temperature reach the provider
+	req.Model = "other"
+	chat(t, p, ctx, req)
+	req.Model = "m"
+	req.Tools = []interfaces.Tool{{Name: "lookup"}}
+	chat(t, p, ctx, req)
+	req.ToolChoice = "required"
+	chat(t, p, ctx, req)
+	req.Tools, req.ToolChoice = nil, ""
+	chat(t, p, Bypass(ctx), req)
+	req.Temp = 0.9
+	chat(t, p, ctx, req)
+	if inner.calls != 6 {
+		t.Fatalf("expected 6 provider calls, got %d", inner.calls)
+	}
+
+	stats := c.Stats()
+	if stats.Hits != 1 || stats.Bypassed != 2 || stats.Stores != 4 || stats.SavedTokens != 7 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestWrapExecute(t *testing.T) {
+	c, _ := New(Config{Backend: "memory"})
+	inner := &countingProvider{reply: "done"}
+	p := Wrap(inner, c)
+
+	first, _ := p.Execute(context.Background(), "task", map[string]any{"a": 1})
+	second, _ := p.Execute(context.Background(), "task", map[string]any{"a": 1})
+	if inner.calls != 1 || second.Response != first.Response || second.Metadata["cache"] != "hit" {
+		t.Fatalf("expected the second execute to be a hit, calls=%d result=%+v", inner.calls, second)
+	}
+	if Unwrap(p) != inner || Wrap(inner, nil) != inner {
+		t.Fatalf("Unwrap and a nil cache should return the provider itself")
+	}
+}
+
+// embeddingProvider is a countingProvider that also computes embeddings
+type embeddingProvider struct{ countingProvider }
+
+func (p *embeddingProvider) Embed(ctx context.Context, req interfaces.EmbeddingRequest) (*interfaces.Embeddings, error) {
+	return &interfaces.Embeddings{Vectors: [][]float64{{1}}}, nil
+}
+
+func TestWrapKeepsEmbeddings(t *testing.T) {
+	c, _ := New(Config{Backend: "memory"})
+	if _, ok := interfaces.EmbedderOf(Wrap(&embeddingProvider{}, c)); !ok {
+		t.Fatal("expected the embeddings of a cached provider to be reachable")
+	}
+	if _, ok := interfaces.EmbedderOf(Wrap(&countingProvider{}, c)); ok {
+		t.Fatal("expected a provider without embeddings to have none behind the cache")
+	}
+}
+
+func TestCacheExpires(t *testing.T) {
+	c, _ := New(Config{Backend: "memory", TTL: time.Minute})
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	c.Put("k", "p", "m", "v", nil)
+	if _, ok := c.Get("k"); !ok {
+		t.Fatal("expected a live entry")
+	}
+	now = now.Add(time.Minute)
+	if _, ok := c.Get("k"); ok {
+		t.Fatal("expected the entry to expire")
+	}
+	if stats := c.Stats(); stats.Expired != 1 || stats.Entries != 0 {
+		t.Fatalf("expected the expired entry to be dropped, got %+v", stats)
+	}
+}
+
+func TestBackendsEvictLeastRecentlyUsed(t *testing.T) {
+	disk, err := NewDiskBackend(t.TempDir(), 2, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, b := range map[string]Backend{"memory": NewMemoryBackend(2, 1<<20), "disk": disk} {
+		t.Run(name, func(t *testing.T) {
+			b.Set(&Entry{Key: "a", Content: "1"})
+			time.Sleep(5 * time.Millisecond)
+			b.Set(&Entry{Key: "b", Content: "2"})
+			time.Sleep(5 * time.Millisecond)
+			b.Get("a") // a is now more recent than b
+			time.Sleep(5 * time.Millisecond)
+			b.Set(&Entry{Key: "c", Content: "3"})
+
+			if _, ok := b.Get("b"); ok {
+				t.Fatal("expected b to be evicted")
+			}
+			if _, ok := b.Get("a"); !ok {
+				t.Fatal("expected a to survive")
+			}
+			if n, _ := b.Len(); n != 2 {
+				t.Fatalf("expected 2 entries, got %d", n)
+			}
+		})
+	}
+
+	small := NewMemoryBackend(100, 300)
+	small.Set(&Entry{Key: "a", Content: strings.Repeat("x", 100)})
+	small.Set(&Entry{Key: "b", Content: strings.Repeat("x", 100)})
+	if n, bytes := small.Len(); n != 1 || bytes > 300 {
+		t.Fatalf("expected the size limit to keep one entry, got %d (%d bytes)", n, bytes)
+	}
+}
+
+func TestDiskBackendPersists(t *testing.T) {
+	dir := t.TempDir()
+	c, err := New(Config{Backend: "disk", Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Put(ChatKey(interfaces.ChatRequest{Provider: "p"}), "p", "m", "saved", nil)
+
+	reopened, _ := New(Config{Backend: "disk", Dir: dir})
+	entry, ok := reopened.Get(ChatKey(interfaces.ChatRequest{Provider: "p"}))
+	if !ok || entry.Content != "saved" {
+		t.Fatalf("expected the entry to survive a restart, got %+v", entry)
+	}
+	if err := reopened.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if n := reopened.Stats().Entries; n != 0 {
+		t.Fatalf("expected an empty cache after Clear, got %d entries", n)
+	}
+}
+
+func TestNoCache(t *testing.T) {
+	for header, want := range map[string]bool{"no-cache": true, "max-age=0, No-Store": true, "max-age=60": false, "": false} {
+		if got := NoCache(header); got != want {
+			t.Errorf("NoCache(%q) = %v, want %v", header, got, want)
+		}
+	}
+}
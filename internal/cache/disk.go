@@ -0,0 +1,177 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DiskBackend keeps one JSON file per entry in a directory, so cached
+// responses survive restarts and can be shared between CI runs. Files are
+// touched on access and the least recently used ones are removed beyond the
+// limits.
+type DiskBackend struct {
+	dir        string
+	maxEntries int
+	maxBytes   int64
+
+	mu    sync.Mutex
+	index map[string]diskItem
+	bytes int64
+}
+
+// diskItem is the index record of one file
+type diskItem struct {
+	size int64
+	used time.Time
+}
+
+// NewDiskBackend opens the cache directory, creating it when needed, and
+// indexes the entries already in it.
+func NewDiskBackend(dir string, maxEntries int, maxBytes int64) (*DiskBackend, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	d := &DiskBackend{dir: dir, maxEntries: maxEntries, maxBytes: maxBytes, index: make(map[string]diskItem)}
+	for _, f := range files {
+		key, ok := strings.CutSuffix(f.Name(), ".json")
+		if !ok || f.IsDir() {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		d.index[key] = diskItem{size: info.Size(), used: info.ModTime()}
+		d.bytes += info.Size()
+	}
+	d.evict()
+	return d, nil
+}
+
+func (d *DiskBackend) path(key string) string {
+	return filepath.Join(d.dir, key+".json")
+}
+
+// Get reads the entry under key and marks it as recently used
+func (d *DiskBackend) Get(key string) (*Entry, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	item, ok := d.index[key]
+	if !ok {
+		return nil, false
+	}
+	data, err := os.ReadFile(d.path(key))
+	var entry Entry
+	if err == nil {
+		err = json.Unmarshal(data, &entry)
+	}
+	if err != nil {
+		// Removed or corrupted behind our back
+		d.drop(key)
+		return nil, false
+	}
+
+	item.used = time.Now()
+	d.index[key] = item
+	_ = os.Chtimes(d.path(key), item.used, item.used)
+	return &entry, true
+}
+
+// Set writes entry, evicting the least recently used entries over the limits
+func (d *DiskBackend) Set(entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	tmp := d.path(entry.Key) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp, d.path(entry.Key)); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	if old, ok := d.index[entry.Key]; ok {
+		d.bytes -= old.size
+	}
+	d.index[entry.Key] = diskItem{size: int64(len(data)), used: time.Now()}
+	d.bytes += int64(len(data))
+	d.evict()
+	return nil
+}
+
+// Delete removes the entry under key
+func (d *DiskBackend) Delete(key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.drop(key)
+}
+
+// Clear removes every entry
+func (d *DiskBackend) Clear() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for key := range d.index {
+		if err := os.Remove(d.path(key)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to clear cache: %w", err)
+		}
+		delete(d.index, key)
+	}
+	d.bytes = 0
+	return nil
+}
+
+// Len returns the number of entries and their size on disk
+func (d *DiskBackend) Len() (int, int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.index), d.bytes
+}
+
+// drop removes the file and index record of key; the caller holds d.mu
+func (d *DiskBackend) drop(key string) {
+	item, ok := d.index[key]
+	if !ok {
+		return
+	}
+	_ = os.Remove(d.path(key))
+	delete(d.index, key)
+	d.bytes -= item.size
+}
+
+// evict removes the least recently used entries over the limits; the caller
+// holds d.mu
+func (d *DiskBackend) evict() {
+	if len(d.index) <= d.maxEntries && d.bytes <= d.maxBytes {
+		return
+	}
+	keys := make([]string, 0, len(d.index))
+	for key := range d.index {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return d.index[keys[i]].used.Before(d.index[keys[j]].used) })
+
+	for _, key := range keys {
+		if len(d.index) <= 1 || (len(d.index) <= d.maxEntries && d.bytes <= d.maxBytes) {
+			break
+		}
+		d.drop(key)
+	}
+}
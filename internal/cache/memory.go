@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// MemoryBackend keeps entries in an LRU list bounded by count and size
+type MemoryBackend struct {
+	maxEntries int
+	maxBytes   int64
+
+	mu    sync.Mutex
+	order *list.List // front is the most recently used
+	items map[string]*list.Element
+	bytes int64
+}
+
+// NewMemoryBackend creates an in-memory backend
+func NewMemoryBackend(maxEntries int, maxBytes int64) *MemoryBackend {
+	return &MemoryBackend{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		order:      list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+// Get returns the entry under key and marks it as recently used
+func (m *MemoryBackend) Get(key string) (*Entry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	el, ok := m.items[key]
+	if !ok {
+		return nil, false
+	}
+	m.order.MoveToFront(el)
+	return el.Value.(*Entry), true
+}
+
+// Set stores entry, evicting the least recently used entries over the limits
+func (m *MemoryBackend) Set(entry *Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if el, ok := m.items[entry.Key]; ok {
+		m.remove(el)
+	}
+	m.items[entry.Key] = m.order.PushFront(entry)
+	m.bytes += entry.size()
+
+	for m.order.Len() > 1 && (m.order.Len() > m.maxEntries || m.bytes > m.maxBytes) {
+		m.remove(m.order.Back())
+	}
+	return nil
+}
+
+// Delete removes the entry under key
+func (m *MemoryBackend) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if el, ok := m.items[key]; ok {
+		m.remove(el)
+	}
+}
+
+// Clear removes every entry
+func (m *MemoryBackend) Clear() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.order.Init()
+	m.items = make(map[string]*list.Element)
+	m.bytes = 0
+	return nil
+}
+
+// Len returns the number of entries and their size
+func (m *MemoryBackend) Len() (int, int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.order.Len(), m.bytes
+}
+
+// remove drops el; the caller holds m.mu
+func (m *MemoryBackend) remove(el *list.Element) {
+	entry := m.order.Remove(el).(*Entry)
+	delete(m.items, entry.Key)
+	m.bytes -= entry.size()
+}
@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+)
+
+// cachedProvider answers Chat and Execute from the cache when it can
+type cachedProvider struct {
+	interfaces.Provider
+	cache *Cache
+}
+
+// Wrap puts c in front of p's Chat and Execute. Requests above the cache's
+// MaxTemperature or made with a Bypass context always reach p. A nil cache
+// returns p unchanged.
+func Wrap(p interfaces.Provider, c *Cache) interfaces.Provider {
+	if c == nil || p == nil {
+		return p
+	}
+	if _, ok := p.(*cachedProvider); ok {
+		return p
+	}
+	return &cachedProvider{Provider: p, cache: c}
+}
+
+// Unwrap returns the provider behind a cache wrapper
+func Unwrap(p interfaces.Provider) interfaces.Provider {
+	if cp, ok := p.(*cachedProvider); ok {
+		return cp.Provider
+	}
+	return p
+}
+
+// Unwrap returns the wrapped provider, so capabilities the cache does not
+// intercept (like interfaces.EmbedderOf) can be found behind it
+func (cp *cachedProvider) Unwrap() interfaces.Provider {
+	return cp.Provider
+}
+
+// Chat replays a cached response as a single chunk flagged Cached, or streams
+// the provider's response and stores it once it completes without error.
+func (cp *cachedProvider) Chat(ctx context.Context, req interfaces.ChatRequest) (<-chan interfaces.ChatChunk, error) {
+	if IsBypassed(ctx) || !cp.cache.Cacheable(req.Temp) {
+		cp.cache.Bypassed()
+		return cp.Provider.Chat(ctx, req)
+	}
+
+	// The provider name is part of the key even when the request leaves it out
+	keyed := req
+	keyed.Provider = cp.Name()
+	key := ChatKey(keyed)
+	if entry, ok := cp.cache.Get(key); ok {
+		out := make(chan interfaces.ChatChunk, 1)
+		out <- interfaces.ChatChunk{Content: entry.Content, Done: true, Usage: replayUsage(entry.Usage), Cached: true}
+		close(out)
+		return out, nil
+	}
+
+	stream, err := cp.Provider.Chat(ctx, req)
+	if err != nil || stream == nil {
+		return stream, err
+	}
+
+	out := make(chan interfaces.ChatChunk)
+	go func() {
+		defer close(out)
+		var content strings.Builder
+		var usage *interfaces.Usage
+		failed := false
+		for chunk := range stream {
+			content.WriteString(chunk.Content)
+			if chunk.Usage != nil {
+				usage = chunk.Usage
+			}
+			// Tool calls are not replayable as text, so those replies are not stored
+			failed = failed || chunk.Error != "" || chunk.ToolCall != nil
+			// Stored before the done chunk is forwarded, so a caller that
+			// stops reading there already finds it cached
+			if chunk.Done && !failed {
+				_ = cp.cache.Put(key, cp.Name(), req.Model, content.String(), usage)
+			}
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				// Drain so the provider's goroutine can finish; a cancelled
+				// response is incomplete and is not stored
+				for range stream {
+				}
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// Execute returns a cached result flagged with metadata "cache": "hit", or
+// runs the provider and stores its response.
+func (cp *cachedProvider) Execute(ctx context.Context, template string, vars map[string]any) (*interfaces.Result, error) {
+	if IsBypassed(ctx) {
+		cp.cache.Bypassed()
+		return cp.Provider.Execute(ctx, template, vars)
+	}
+
+	key := Key("execute", cp.Name(), template, vars)
+	if entry, ok := cp.cache.Get(key); ok {
+		return &interfaces.Result{
+			Prompt:    template,
+			Response:  entry.Content,
+			Provider:  cp.Name(),
+			Model:     entry.Model,
+			Usage:     replayUsage(entry.Usage),
+			Variables: vars,
+			Metadata:  map[string]any{"cache": "hit", "cached_at": entry.CachedAt},
+			Timestamp: time.Now(),
+		}, nil
+	}
+
+	result, err := cp.Provider.Execute(ctx, template, vars)
+	if err == nil && result != nil {
+		_ = cp.cache.Put(key, cp.Name(), result.Model, result.Response, result.Usage)
+	}
+	return result, err
+}
+
+// replayUsage copies cached usage with no latency, since nothing was called
+func replayUsage(usage *interfaces.Usage) *interfaces.Usage {
+	if usage == nil {
+		return nil
+	}
+	u := *usage
+	u.Ms = 0
+	return &u
+}
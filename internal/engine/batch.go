@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+)
+
+// defaultBatchConcurrency is used when BatchOptions.Concurrency is not set.
+const defaultBatchConcurrency = 4
+
+// BatchProcess processes prompts with a bounded worker pool. Every prompt gets
+// its own outcome, in input order; a failing prompt does not affect the others.
+// Prompts that have not started when ctx is cancelled fail with ctx.Err().
+func (e *Engine) BatchProcess(ctx context.Context, prompts []string, vars map[string]interface{}, opts interfaces.BatchOptions) []interfaces.BatchOutcome {
+	outcomes := make([]interfaces.BatchOutcome, len(prompts))
+	for i, p := range prompts {
+		outcomes[i] = interfaces.BatchOutcome{Index: i, Prompt: p}
+	}
+	if e == nil {
+		for i := range outcomes {
+			outcomes[i].Err = fmt.Errorf("engine is nil")
+			outcomes[i].Error = outcomes[i].Err.Error()
+		}
+		return outcomes
+	}
+
+	workers := opts.Concurrency
+	if workers <= 0 {
+		workers = defaultBatchConcurrency
+	}
+	if workers > len(prompts) {
+		workers = len(prompts)
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				// Each worker only writes to its own index, so no locking is needed.
+				outcomes[i] = e.processItem(ctx, outcomes[i], vars, opts)
+			}
+		}()
+	}
+
+dispatch:
+	for i := range prompts {
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case jobs <- i:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	// Anything never dispatched was cut off by cancellation.
+	for i := range outcomes {
+		if outcomes[i].Result == nil && outcomes[i].Err == nil {
+			outcomes[i].Err = ctx.Err()
+			if outcomes[i].Err == nil {
+				outcomes[i].Err = fmt.Errorf("prompt %d was not processed", i)
+			}
+			outcomes[i].Error = outcomes[i].Err.Error()
+		}
+	}
+	return outcomes
+}
+
+func (e *Engine) processItem(ctx context.Context, out interfaces.BatchOutcome, vars map[string]interface{}, opts interfaces.BatchOptions) interfaces.BatchOutcome {
+	itemCtx := ctx
+	if opts.ItemTimeout > 0 {
+		var cancel context.CancelFunc
+		itemCtx, cancel = context.WithTimeout(ctx, opts.ItemTimeout)
+		defer cancel()
+	}
+
+	result, err := e.ProcessPrompt(itemCtx, out.Prompt, vars)
+	if err != nil {
+		out.Err = fmt.Errorf("prompt %d: %w", out.Index, err)
+		out.Error = out.Err.Error()
+		return out
+	}
+	out.Result = result
+	return out
+}
@@ -0,0 +1,113 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"github.com/kubex-ecosystem/grompt/internal/providertest"
+)
+
+// slowProvider echoes prompts after a delay and tracks peak concurrency.
+type slowProvider struct {
+	providertest.Provider
+	delay   time.Duration
+	active  atomic.Int32
+	peak    atomic.Int32
+	failOn  string
+	blockOn string
+}
+
+func (s *slowProvider) Chat(ctx context.Context, req interfaces.ChatRequest) (<-chan interfaces.ChatChunk, error) {
+	prompt := req.Messages[0].Content
+	ch := make(chan interfaces.ChatChunk, 1)
+	go func() {
+		defer close(ch)
+		n := s.active.Add(1)
+		defer s.active.Add(-1)
+		for {
+			old := s.peak.Load()
+			if n <= old || s.peak.CompareAndSwap(old, n) {
+				break
+			}
+		}
+
+		delay := s.delay
+		if prompt == s.blockOn {
+			delay = time.Hour
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		if prompt == s.failOn {
+			ch <- interfaces.ChatChunk{Error: "rejected", Done: true}
+			return
+		}
+		ch <- interfaces.ChatChunk{Content: strings.ToUpper(prompt), Done: true}
+	}()
+	return ch, nil
+}
+
+func TestBatchProcessBoundsConcurrencyAndKeepsOrder(t *testing.T) {
+	p := &slowProvider{Provider: providertest.Provider{ProviderName: "slow"}, delay: 20 * time.Millisecond, failOn: "c"}
+	e := newTestEngine(p)
+
+	prompts := []string{"a", "b", "c", "d", "e", "f"}
+	outcomes := e.BatchProcess(context.Background(), prompts, nil, interfaces.BatchOptions{Concurrency: 2})
+
+	if len(outcomes) != len(prompts) {
+		t.Fatalf("expected %d outcomes, got %d", len(prompts), len(outcomes))
+	}
+	for i, out := range outcomes {
+		if out.Index != i || out.Prompt != prompts[i] {
+			t.Fatalf("outcome %d out of order: %+v", i, out)
+		}
+		if prompts[i] == "c" {
+			if out.Succeeded() || out.Error == "" {
+				t.Fatalf("expected prompt c to fail, got %+v", out)
+			}
+			continue
+		}
+		if !out.Succeeded() || out.Result.Response != strings.ToUpper(prompts[i]) {
+			t.Fatalf("unexpected outcome for %q: %+v", prompts[i], out)
+		}
+	}
+	if peak := p.peak.Load(); peak > 2 {
+		t.Fatalf("expected at most 2 concurrent calls, saw %d", peak)
+	}
+}
+
+func TestBatchProcessItemTimeout(t *testing.T) {
+	p := &slowProvider{Provider: providertest.Provider{ProviderName: "slow"}, delay: time.Millisecond, blockOn: "stuck"}
+	e := newTestEngine(p)
+
+	outcomes := e.BatchProcess(context.Background(), []string{"ok", "stuck"}, nil, interfaces.BatchOptions{ItemTimeout: 50 * time.Millisecond})
+
+	if !outcomes[0].Succeeded() {
+		t.Fatalf("expected first prompt to succeed, got %+v", outcomes[0])
+	}
+	if !errors.Is(outcomes[1].Err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded for stuck prompt, got %v", outcomes[1].Err)
+	}
+}
+
+func TestBatchProcessHonoursCancellation(t *testing.T) {
+	p := &slowProvider{Provider: providertest.Provider{ProviderName: "slow"}, delay: time.Millisecond}
+	e := newTestEngine(p)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	outcomes := e.BatchProcess(ctx, []string{"a", "b", "c"}, nil, interfaces.BatchOptions{Concurrency: 1})
+	for _, out := range outcomes {
+		if out.Succeeded() || !errors.Is(out.Err, context.Canceled) {
+			t.Fatalf("expected cancellation for every prompt, got %+v", out)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"github.com/kubex-ecosystem/grompt/internal/tokenizer"
+	"github.com/kubex-ecosystem/grompt/internal/types"
+)
+
+// Compare sends one prompt to every target in parallel and reports each
+// response with its latency, token usage and estimated cost. Unlike
+// ProcessPrompt there is no fallback: a failing target is reported as such.
+// With no targets, every registered provider is compared on its default model.
+// Successful responses are recorded in history with metadata "comparison_id".
+func (e *Engine) Compare(ctx context.Context, prompt string, targets []interfaces.CompareTarget, vars map[string]interface{}) (*interfaces.Comparison, error) {
+	if e == nil {
+		return nil, fmt.Errorf("engine is nil")
+	}
+
+	if len(targets) == 0 {
+		for _, p := range e.providers {
+			targets = append(targets, interfaces.CompareTarget{Provider: p.Name()})
+		}
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no providers available")
+	}
+
+	providers := make([]interfaces.Provider, len(targets))
+	for i, t := range targets {
+		if providers[i] = e.Resolve(t.Provider); providers[i] == nil {
+			return nil, fmt.Errorf("provider %s not found", t.Provider)
+		}
+	}
+
+	processedPrompt, err := e.templates.Process(prompt, vars)
+	if err != nil {
+		return nil, fmt.Errorf("template processing failed: %w", err)
+	}
+
+	cmp := &interfaces.Comparison{
+		ID:      fmt.Sprintf("compare_%d", time.Now().UnixNano()),
+		Prompt:  processedPrompt,
+		Entries: make([]interfaces.CompareEntry, len(targets)),
+	}
+
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cmp.Entries[i] = e.compareOne(ctx, cmp.ID, providers[i], t.Model, processedPrompt, vars)
+		}()
+	}
+	wg.Wait()
+
+	var fastest, cheapest *interfaces.CompareEntry
+	for i := range cmp.Entries {
+		entry := &cmp.Entries[i]
+		if entry.Error != "" {
+			continue
+		}
+		if fastest == nil || entry.LatencyMs < fastest.LatencyMs {
+			fastest = entry
+		}
+		if entry.Pricing != nil && (cheapest == nil || entry.EstimatedCost < cheapest.EstimatedCost) {
+			cheapest = entry
+		}
+	}
+	if fastest != nil {
+		cmp.Fastest = targetLabel(fastest)
+	}
+	if cheapest != nil {
+		cmp.Cheapest = targetLabel(cheapest)
+	}
+
+	return cmp, nil
+}
+
+func (e *Engine) compareOne(ctx context.Context, comparisonID string, provider interfaces.Provider, model, prompt string, vars map[string]interface{}) interfaces.CompareEntry {
+	entry := interfaces.CompareEntry{Provider: provider.Name(), Model: model}
+
+	if _, _, _, err := tokenizer.Fit(provider.Name(), model, prompt, intVar(vars, "max_tokens"), tokenizer.PolicyReject); err != nil {
+		entry.Error = err.Error()
+		return entry
+	}
+
+	started := time.Now()
+	response, usage, err := Complete(ctx, provider, prompt, model)
+	entry.LatencyMs = time.Since(started).Milliseconds()
+	if err != nil {
+		entry.Error = err.Error()
+		return entry
+	}
+
+	if usage == nil {
+		usage = &interfaces.Usage{}
+	}
+	if usage.Prompt == 0 && usage.Completion == 0 {
+		tk := tokenizer.For(provider.Name(), model)
+		usage.Prompt, usage.Completion = tk.Count(prompt), tk.Count(response)
+		entry.UsageEstimated = true
+	}
+	if usage.Tokens == 0 {
+		usage.Tokens = usage.Prompt + usage.Completion
+	}
+	if usage.Ms == 0 {
+		usage.Ms = entry.LatencyMs
+	}
+	if usage.Provider == "" {
+		usage.Provider = provider.Name()
+	}
+	if usage.Model == "" {
+		usage.Model = model
+	}
+
+	// Built-in pricing, by name or else by type: capabilities would list the
+	// provider's models over the network, and fail for some providers
+	pricing := types.PricingForProvider(provider.Name())
+	if pricing == nil {
+		pricing = types.PricingForProvider(provider.Type())
+	}
+	if pricing != nil {
+		entry.Pricing = pricing
+		if usage.CostUSD == 0 {
+			usage.CostUSD = pricing.Cost(usage)
+		}
+	}
+	entry.EstimatedCost = usage.CostUSD
+	entry.Response, entry.Usage, entry.Model = response, usage, usage.Model
+
+	result := interfaces.Result{
+		ID:        generateID(),
+		Prompt:    prompt,
+		Response:  response,
+		Provider:  provider.Name(),
+		Model:     usage.Model,
+		Usage:     usage,
+		Variables: vars,
+		Tags:      tagsFrom(vars),
+		Metadata:  map[string]any{"comparison_id": comparisonID},
+		Timestamp: time.Now(),
+	}
+	e.history.Add(result)
+	entry.HistoryID = result.ID
+
+	return entry
+}
+
+func targetLabel(entry *interfaces.CompareEntry) string {
+	if entry.Model == "" {
+		return entry.Provider
+	}
+	return entry.Provider + ":" + entry.Model
+}
@@ -0,0 +1,66 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"github.com/kubex-ecosystem/grompt/internal/providertest"
+)
+
+// delayedProvider answers after a delay
+type delayedProvider struct {
+	providertest.Provider
+	delay time.Duration
+}
+
+func (p *delayedProvider) Chat(ctx context.Context, req interfaces.ChatRequest) (<-chan interfaces.ChatChunk, error) {
+	time.Sleep(p.delay)
+	return p.Provider.Chat(ctx, req)
+}
+
+func (p *delayedProvider) GetCapabilities(ctx context.Context) *interfaces.Capabilities {
+	panic("compare must price without listing models")
+}
+
+func TestCompareReportsEveryTarget(t *testing.T) {
+	// Priced from the built-in tables: deepseek $0.01/$0.02, openai $0.03/$0.06 per 1K
+	cheap := &delayedProvider{
+		Provider: providertest.Provider{ProviderName: "deepseek", Response: "slow answer", Usage: &interfaces.Usage{Prompt: 1000, Completion: 2000}},
+		delay:    30 * time.Millisecond,
+	}
+	fast := &delayedProvider{Provider: providertest.Provider{ProviderName: "openai", Response: "abcd"}}
+	broken := &providertest.Provider{ProviderName: "broken", Err: errors.New("quota exceeded")}
+	e := newTestEngine(cheap, fast, broken)
+
+	cmp, err := e.Compare(context.Background(), "Summarize {{.topic}}", []interfaces.CompareTarget{
+		{Provider: "deepseek", Model: "small"},
+		{Provider: "openai"},
+		{Provider: "broken"},
+	}, map[string]interface{}{"topic": "Go"})
+	if err != nil {
+		t.Fatalf("Compare returned error: %v", err)
+	}
+
+	if cmp.Prompt != "Summarize Go" || len(cmp.Entries) != 3 {
+		t.Fatalf("unexpected comparison: %+v", cmp)
+	}
+	if got := cmp.Entries[0]; got.Provider != "deepseek" || got.Model != "small" || math.Abs(got.EstimatedCost-0.05) > 1e-9 || got.UsageEstimated {
+		t.Fatalf("unexpected deepseek entry: %+v", got)
+	}
+	if got := cmp.Entries[1]; !got.UsageEstimated || got.Usage.Prompt != 3 || got.Usage.Completion != 1 || math.Abs(got.EstimatedCost-0.00015) > 1e-9 {
+		t.Fatalf("expected estimated usage and cost for openai, got %+v usage=%+v", got, got.Usage)
+	}
+	if got := cmp.Entries[2]; got.Error == "" || broken.Calls != 1 {
+		t.Fatalf("expected the broken target to fail without fallback, got %+v", got)
+	}
+	if cmp.Fastest != "openai" || cmp.Cheapest != "openai" {
+		t.Fatalf("unexpected winners: fastest=%q cheapest=%q", cmp.Fastest, cmp.Cheapest)
+	}
+	if len(e.GetHistory()) != 2 {
+		t.Fatalf("expected successful responses in history, got %d", len(e.GetHistory()))
+	}
+}
@@ -4,32 +4,54 @@ package engine
 import (
 	"context"
 	"fmt"
+	"io"
+	"strings"
 	"time"
 
 	"github.com/kubex-ecosystem/grompt/factory/templates"
+	"github.com/kubex-ecosystem/grompt/internal/cache"
+	"github.com/kubex-ecosystem/grompt/internal/examples"
+	"github.com/kubex-ecosystem/grompt/internal/history"
 	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"github.com/kubex-ecosystem/grompt/internal/providers"
 	"github.com/kubex-ecosystem/grompt/internal/types"
+	"github.com/kubex-ecosystem/grompt/utils"
 )
 
 // Engine represents the core prompt engineering engine
 type Engine struct {
-	providers []interfaces.Provider
-	templates templates.Manager
-	history   interfaces.IHistoryManager
-	config    interfaces.IConfig
+	providers       []interfaces.Provider
+	templates       templates.Manager
+	history         interfaces.IHistoryManager
+	cache           *cache.Cache
+	examples        *examples.Store
+	tools           *Toolbox
+	config          interfaces.IConfig
+	defaultProvider string
 }
 
-// NewEngine creates a new IEngine instance with initialized providers
+// NewEngine creates a new IEngine instance with initialized providers. When
+// GROMPT_CACHE enables the response cache, it is put in front of every
+// provider.
 func NewEngine(config interfaces.IConfig) interfaces.IEngine {
 	engine := &Engine{
 		providers: make([]interfaces.Provider, 0),
-		templates: templates.NewManager("./templates"), // Default templates path
-		history:   interfaces.NewHistoryStore(100),    // Default history limit
+		templates: templates.NewManager(utils.GetEnvOr("GROMPT_TEMPLATES_DIR", "./templates")),
+		history:   history.Open(),
+		cache:     cache.Open(),
+		examples:  examples.Open(),
+		tools:     NewToolbox(),
 		config:    config,
+
+		defaultProvider: utils.GetEnvOr("GROMPT_DEFAULT_PROVIDER", ""),
 	}
 
 	// Initialize concrete providers
 	engine.initializeProviders()
+	for i, p := range engine.providers {
+		engine.providers[i] = cache.Wrap(p, engine.cache)
+	}
+	engine.configureExamples()
 
 	return engine
 }
@@ -38,34 +60,44 @@ func NewEngine(config interfaces.IConfig) interfaces.IEngine {
 func (e *Engine) initializeProviders() {
 	// Initialize OpenAI provider
 	if apiKey := e.config.GetAPIKey("openai"); apiKey != "" {
-		provider := types.NewOpenAIAPI(apiKey)
-		e.providers = append(e.providers, provider)
-	}
-
-	// // Initialize Claude provider
-	// if apiKey := e.config.GetAPIKey("claude"); apiKey != "" {
-	// 	provider := types.NewClaudeAPI(apiKey)
-	// 	e.providers = append(e.providers, provider)
-	// }
-
-	// // Initialize DeepSeek provider
-	// if apiKey := e.config.GetAPIKey("deepseek"); apiKey != "" {
-	// 	provider := types.NewDeepSeekAPI(apiKey)
-	// 	e.providers = append(e.providers, provider)
-	// }
-
-	// // Initialize Ollama provider (local - no API key needed)
-	// provider := types.NewOllamaAPI("")
-	// e.providers = append(e.providers, provider)
-
-	// // Initialize Gemini provider
-	// if apiKey := e.config.GetAPIKey("gemini"); apiKey != "" {
-	// 	provider := types.NewGeminiAPI(apiKey)
-	// 	e.providers = append(e.providers, provider)
-	// }
+		e.providers = append(e.providers, providers.NewOpenAIProvider(apiKey))
+	}
+
+	// Initialize Claude provider
+	if apiKey := e.config.GetAPIKey("claude"); apiKey != "" {
+		e.providers = append(e.providers, providers.NewClaudeProvider(apiKey))
+	}
+
+	// Initialize Gemini provider
+	if apiKey := e.config.GetAPIKey("gemini"); apiKey != "" {
+		e.providers = append(e.providers, providers.NewGeminiProvider(apiKey))
+	}
+
+	// Initialize DeepSeek provider
+	if apiKey := e.config.GetAPIKey("deepseek"); apiKey != "" {
+		e.providers = append(e.providers, providers.NewDeepSeekProvider(apiKey))
+	}
+
+	// Initialize ChatGPT provider
+	if apiKey := e.config.GetAPIKey("chatgpt"); apiKey != "" {
+		e.providers = append(e.providers, providers.NewChatGPTProvider(apiKey))
+	}
+
+	// Ollama is local and keyless, so it is only registered when an endpoint is configured
+	if endpoint := e.config.GetAPIEndpoint("ollama"); endpoint != "" {
+		e.providers = append(e.providers, &types.ProviderImpl{
+			VName: "ollama",
+			VAPI:  types.NewOllamaAPI(endpoint),
+		})
+	}
 }
 
-// ProcessPrompt processes a prompt with variables and returns the result
+// ProcessPrompt processes a prompt with variables and returns the result.
+// The prompt is sent to the preferred provider (vars["provider"] or the engine
+// default) and falls back to the remaining providers in order on failure.
+// Each provider is skipped without a call when the prompt plus vars
+// ["max_tokens"] exceeds its context window, unless vars["context_policy"] is
+// "trim", in which case the middle of the prompt is cut to fit.
 func (e *Engine) ProcessPrompt(ctx context.Context, template string, vars map[string]interface{}) (*interfaces.Result, error) {
 	if e == nil {
 		return nil, fmt.Errorf("engine is nil")
@@ -77,35 +109,76 @@ func (e *Engine) ProcessPrompt(ctx context.Context, template string, vars map[st
 		return nil, fmt.Errorf("template processing failed: %w", err)
 	}
 
-	// Get default provider (first available)
-	if len(e.providers) == 0 {
-		return nil, fmt.Errorf("no providers available")
+	preferred, _ := vars["provider"].(string)
+	return e.run(ctx, preferred, processedPrompt, vars, nil)
+}
+
+// ProcessTemplate renders a stored template by name and processes the result
+// like ProcessPrompt. The library examples relevant to the rendered template
+// are appended to it as demonstrations and listed in metadata "examples".
+func (e *Engine) ProcessTemplate(ctx context.Context, name string, vars map[string]interface{}) (*interfaces.Result, error) {
+	if e == nil {
+		return nil, fmt.Errorf("engine is nil")
 	}
 
-	provider := e.providers[0]
+	content, err := e.templates.Render(name, vars)
+	if err != nil {
+		return nil, fmt.Errorf("template processing failed: %w", err)
+	}
 
-	// // Execute prompt with provider
-	// response, err := provider.Execute(context.Background(), processedPrompt)
-	// if err != nil {
-	// 	return nil, fmt.Errorf("provider execution failed: %w", err)
-	// }
+	metadata := map[string]any{"template": name}
+	content, ids := e.templateExamples(ctx, name, content, vars)
+	if len(ids) > 0 {
+		metadata["examples"] = ids
+	}
 
-	// Create result
-	result := &interfaces.Result{
-		ID:        generateID(),
-		Prompt:    processedPrompt,
-		// Response:  response,
-		Provider:  provider.Name(),
-		Variables: vars,
-		Timestamp: time.Now(),
+	preferred, _ := vars["provider"].(string)
+	return e.run(ctx, preferred, content, vars, metadata)
+}
+
+// run executes a processed prompt through the fallback chain starting at
+// preferred, attaches metadata and records the result in history.
+func (e *Engine) run(ctx context.Context, preferred, prompt string, vars map[string]interface{}, metadata map[string]any) (*interfaces.Result, error) {
+	chain := e.fallbackChain(preferred)
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("no providers available")
 	}
 
+	result, err := e.execute(ctx, chain, prompt, vars)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range metadata {
+		if result.Metadata == nil {
+			result.Metadata = map[string]any{}
+		}
+		result.Metadata[k] = v
+	}
+	result.Tags = tagsFrom(vars)
+
 	// Add to history
 	e.history.Add(*result)
 
 	return result, nil
 }
 
+// GetTemplates returns the template library used by the engine
+func (e *Engine) GetTemplates() interfaces.Manager {
+	if e == nil {
+		return nil
+	}
+	return e.templates
+}
+
+// GetCache returns the response cache in front of the providers, nil when
+// GROMPT_CACHE leaves it disabled
+func (e *Engine) GetCache() *cache.Cache {
+	if e == nil {
+		return nil
+	}
+	return e.cache
+}
+
 // GetProviders returns available providers
 func (e *Engine) GetProviders() []interfaces.Provider {
 	if e == nil {
@@ -123,6 +196,14 @@ func (e *Engine) GetHistory() []interfaces.Result {
 	return e.history.Snapshot()
 }
 
+// QueryHistory returns the history entries matching q, newest first
+func (e *Engine) QueryHistory(q interfaces.HistoryQuery) interfaces.HistoryPage {
+	if e == nil || e.history == nil {
+		return interfaces.HistoryPage{Entries: []interfaces.Result{}}
+	}
+	return e.history.Query(q)
+}
+
 // SaveToHistory saves a prompt/response pair to history
 func (e *Engine) SaveToHistory(ctx context.Context, prompt, response string) error {
 	if e == nil || e.history == nil {
@@ -141,40 +222,6 @@ func (e *Engine) SaveToHistory(ctx context.Context, prompt, response string) err
 	return nil
 }
 
-// BatchProcess processes multiple prompts concurrently
-func (e *Engine) BatchProcess(ctx context.Context, prompts []string, vars map[string]interface{}) ([]interfaces.Result, error) {
-	if e == nil {
-		return nil, fmt.Errorf("engine is nil")
-	}
-
-	results := make([]interfaces.Result, len(prompts))
-	errors := make([]error, len(prompts))
-
-	// Process prompts concurrently
-	for i, prompt := range prompts {
-		go func(index int, p string) {
-			result, err := e.ProcessPrompt(ctx, p, vars)
-			if err != nil {
-				errors[index] = err
-				return
-			}
-			results[index] = *result
-		}(i, prompt)
-	}
-
-	// Wait for all goroutines to complete (simplified version)
-	time.Sleep(time.Second * 2) // In production, use sync.WaitGroup
-
-	// Check for errors
-	for _, err := range errors {
-		if err != nil {
-			return results, fmt.Errorf("batch processing errors occurred: %v", err)
-		}
-	}
-
-	return results, nil
-}
-
 func (e *Engine) GetConfig() interfaces.IConfig {
 	if e == nil {
 		return nil
@@ -198,7 +245,7 @@ func (e *Engine) Resolve(name string) interfaces.Provider {
 		return nil
 	}
 	for _, p := range e.providers {
-		if p.Name() == name {
+		if strings.EqualFold(p.Name(), name) {
 			return p
 		}
 	}
@@ -209,30 +256,29 @@ func (e *Engine) AddProvider(provider interfaces.Provider) error {
 	if e == nil {
 		return fmt.Errorf("engine is nil")
 	}
-	e.providers = append(e.providers, provider)
+	e.providers = append(e.providers, cache.Wrap(provider, e.cache))
 	return nil
 }
 
 // Close releases any resources held by the engine
 func (e *Engine) Close() error {
-	// Currently, no resources to release
+	if e == nil {
+		return nil
+	}
+	if closer, ok := e.history.(io.Closer); ok {
+		return closer.Close()
+	}
 	return nil
 }
 
+// InvokeProvider runs a prompt against the named provider, falling back to the
+// remaining providers in order if it fails.
 func (e *Engine) InvokeProvider(ctx context.Context, providerName, prompt string, vars map[string]interface{}) (*interfaces.Result, error) {
 	if e == nil {
 		return nil, fmt.Errorf("engine is nil")
 	}
 
-	// Find the specified provider
-	var provider interfaces.Provider
-	for _, p := range e.providers {
-		if p.Name() == providerName {
-			provider = p
-			break
-		}
-	}
-	if provider == nil {
+	if e.Resolve(providerName) == nil {
 		return nil, fmt.Errorf("provider %s not found", providerName)
 	}
 
@@ -242,26 +288,45 @@ func (e *Engine) InvokeProvider(ctx context.Context, providerName, prompt string
 		return nil, fmt.Errorf("template processing failed: %w", err)
 	}
 
-	// // Execute prompt with provider
-	// response, err := provider.Execute(ctx, processedPrompt, vars)
-	// if err != nil {
-	// 	return nil, fmt.Errorf("provider execution failed: %w", err)
-	// }
+	return e.run(ctx, providerName, processedPrompt, vars, nil)
+}
 
-	// Create result
-	result := &interfaces.Result{
-		ID:        generateID(),
-		Prompt:    processedPrompt,
-		// Response:  response,
-		Provider:  provider.Name(),
-		Variables: vars,
-		Timestamp: time.Now(),
+// Rerun replays the prompt of a history entry. An empty provider or model
+// keeps the one the entry was produced with; a different provider clears the
+// original model, since models are provider specific. The new result is
+// recorded in history with the original entry ID under metadata "rerun_of".
+func (e *Engine) Rerun(ctx context.Context, id, provider, model string) (*interfaces.Result, error) {
+	if e == nil || e.history == nil {
+		return nil, fmt.Errorf("engine or history is nil")
 	}
 
-	// Add to history
-	e.history.Add(*result)
+	entry, ok := e.history.Get(id)
+	if !ok {
+		return nil, fmt.Errorf("history entry %s not found", id)
+	}
 
-	return result, nil
+	if provider == "" {
+		provider = entry.Provider
+		if model == "" {
+			model = entry.Model
+		}
+	} else if model == "" && strings.EqualFold(provider, entry.Provider) {
+		model = entry.Model
+	}
+	if e.Resolve(provider) == nil {
+		return nil, fmt.Errorf("provider %s not found", provider)
+	}
+
+	vars := map[string]interface{}{"provider": provider}
+	if model != "" {
+		vars["model"] = model
+	}
+	if len(entry.Tags) > 0 {
+		vars["tags"] = entry.Tags
+	}
+
+	// The stored prompt is already rendered, so it is not processed as a template again.
+	return e.run(ctx, provider, entry.Prompt, vars, map[string]any{"rerun_of": entry.ID})
 }
 
 func (e *Engine) GetCapabilities(ctx context.Context) *interfaces.Capabilities {
@@ -277,3 +342,30 @@ func (e *Engine) GetCapabilities(ctx context.Context) *interfaces.Capabilities {
 func generateID() string {
 	return fmt.Sprintf("prompt_%d", time.Now().UnixNano())
 }
+
+// tagsFrom reads history tags from vars["tags"], given either as a list or a
+// comma-separated string.
+func tagsFrom(vars map[string]interface{}) []string {
+	var raw []string
+	switch v := vars["tags"].(type) {
+	case []string:
+		raw = v
+	case []interface{}:
+		for _, item := range v {
+			raw = append(raw, fmt.Sprint(item))
+		}
+	case string:
+		raw = strings.Split(v, ",")
+	}
+
+	tags := make([]string, 0, len(raw))
+	for _, tag := range raw {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	return tags
+}
@@ -0,0 +1,231 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kubex-ecosystem/grompt/factory/templates"
+	"github.com/kubex-ecosystem/grompt/internal/cache"
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"github.com/kubex-ecosystem/grompt/internal/providertest"
+)
+
+func newTestEngine(providers ...interfaces.Provider) *Engine {
+	return &Engine{
+		providers: providers,
+		templates: templates.NewManager("./templates"),
+		history:   interfaces.NewHistoryStore(10),
+	}
+}
+
+func TestProcessPromptFallsBackOnError(t *testing.T) {
+	broken := &providertest.Provider{ProviderName: "broken", Err: errors.New("upstream down")}
+	healthy := &providertest.Provider{ProviderName: "healthy", Response: "hello", Usage: &interfaces.Usage{Prompt: 3, Completion: 1, Tokens: 4}}
+	e := newTestEngine(broken, healthy)
+
+	result, err := e.ProcessPrompt(context.Background(), "say hi", nil)
+	if err != nil {
+		t.Fatalf("ProcessPrompt returned error: %v", err)
+	}
+	if result.Provider != "healthy" || result.Response != "hello" {
+		t.Fatalf("unexpected result: provider=%q response=%q", result.Provider, result.Response)
+	}
+	if result.Usage == nil || result.Usage.Tokens != 4 {
+		t.Fatalf("expected usage to be propagated, got %+v", result.Usage)
+	}
+	if broken.Calls != 1 || healthy.Calls != 1 {
+		t.Fatalf("expected one call per provider, got broken=%d healthy=%d", broken.Calls, healthy.Calls)
+	}
+	if len(e.GetHistory()) != 1 {
+		t.Fatalf("expected result to be recorded in history")
+	}
+}
+
+// executeProvider can stand for an API without chat, driven through Execute
+type executeProvider struct {
+	providertest.Provider
+	chats    bool
+	chatErr  error
+	executes int
+}
+
+func (p *executeProvider) SupportsChat() bool { return p.chats }
+
+func (p *executeProvider) Chat(ctx context.Context, req interfaces.ChatRequest) (<-chan interfaces.ChatChunk, error) {
+	if p.chatErr != nil {
+		p.Calls++
+		return nil, p.chatErr
+	}
+	return p.Provider.Chat(ctx, req)
+}
+
+func (p *executeProvider) Execute(ctx context.Context, template string, vars map[string]any) (*interfaces.Result, error) {
+	p.executes++
+	return &interfaces.Result{Response: p.Response}, nil
+}
+
+func TestProcessPromptExecutesOnlyWithoutChat(t *testing.T) {
+	failing := &executeProvider{Provider: providertest.Provider{ProviderName: "failing"}, chats: true, chatErr: errors.New("502 bad gateway")}
+	legacy := &executeProvider{Provider: providertest.Provider{ProviderName: "legacy", Response: "done"}}
+	responses, err := cache.New(cache.Config{Backend: "memory"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The cache wrapper hides the provider's SupportsChat behind Unwrap
+	e := newTestEngine(failing, cache.Wrap(legacy, responses))
+
+	result, err := e.ProcessPrompt(context.Background(), "x", nil)
+	if err != nil {
+		t.Fatalf("ProcessPrompt returned error: %v", err)
+	}
+	if result.Provider != "legacy" || result.Response != "done" {
+		t.Fatalf("unexpected result: provider=%q response=%q", result.Provider, result.Response)
+	}
+	if failing.Calls != 1 || failing.executes != 0 {
+		t.Fatalf("expected a failed chat not to be retried through Execute, got %d chats and %d executes", failing.Calls, failing.executes)
+	}
+	if legacy.Calls != 0 || legacy.executes != 1 {
+		t.Fatalf("expected the provider without chat to be executed, got %d chats and %d executes", legacy.Calls, legacy.executes)
+	}
+}
+
+func TestProcessPromptPrefersRequestedProvider(t *testing.T) {
+	first := &providertest.Provider{ProviderName: "first", Response: "one"}
+	second := &providertest.Provider{ProviderName: "second", Response: "two"}
+	e := newTestEngine(first, second)
+
+	result, err := e.ProcessPrompt(context.Background(), "pick", map[string]interface{}{"provider": "second"})
+	if err != nil {
+		t.Fatalf("ProcessPrompt returned error: %v", err)
+	}
+	if result.Provider != "second" || first.Calls != 0 {
+		t.Fatalf("expected the requested provider to answer first, got %q (first calls=%d)", result.Provider, first.Calls)
+	}
+}
+
+func TestProcessPromptAllProvidersFail(t *testing.T) {
+	e := newTestEngine(
+		&providertest.Provider{ProviderName: "a", Err: errors.New("boom")},
+		&providertest.Provider{ProviderName: "b", Err: errors.New("bang")},
+	)
+
+	if _, err := e.ProcessPrompt(context.Background(), "x", nil); err == nil {
+		t.Fatal("expected an error when every provider fails")
+	}
+	if len(e.GetHistory()) != 0 {
+		t.Fatal("failed prompts must not be recorded in history")
+	}
+}
+
+func TestRerunReplaysHistoryEntry(t *testing.T) {
+	first := &providertest.Provider{ProviderName: "first", Response: "one"}
+	second := &providertest.Provider{ProviderName: "second", Response: "two"}
+	e := newTestEngine(first, second)
+
+	original, err := e.ProcessPrompt(context.Background(), "say hi", map[string]interface{}{"model": "m1", "tags": "greeting"})
+	if err != nil {
+		t.Fatalf("ProcessPrompt returned error: %v", err)
+	}
+
+	same, err := e.Rerun(context.Background(), original.ID, "", "")
+	if err != nil {
+		t.Fatalf("Rerun returned error: %v", err)
+	}
+	if same.Provider != "first" || first.Model != "m1" || same.Prompt != "say hi" {
+		t.Fatalf("expected replay on first/m1, got provider=%q model=%q", same.Provider, first.Model)
+	}
+	if same.Metadata["rerun_of"] != original.ID || len(same.Tags) != 1 {
+		t.Fatalf("expected rerun metadata and tags, got %+v %v", same.Metadata, same.Tags)
+	}
+
+	other, err := e.Rerun(context.Background(), original.ID, "second", "")
+	if err != nil {
+		t.Fatalf("Rerun returned error: %v", err)
+	}
+	if other.Provider != "second" || second.Model != "" {
+		t.Fatalf("expected replay on second without the original model, got provider=%q model=%q", other.Provider, second.Model)
+	}
+	if len(e.GetHistory()) != 3 {
+		t.Fatalf("expected reruns to be recorded, got %d entries", len(e.GetHistory()))
+	}
+}
+
+func TestProcessPromptContextPreflight(t *testing.T) {
+	huge := strings.Repeat("lorem ipsum dolor sit amet ", 30000) // ~150k tokens
+
+	small := &providertest.Provider{ProviderName: "deepseek", Response: "short window"}
+	large := &providertest.Provider{ProviderName: "gemini", Response: "large window"}
+	e := newTestEngine(small, large)
+
+	result, err := e.ProcessPrompt(context.Background(), huge, nil)
+	if err != nil {
+		t.Fatalf("ProcessPrompt returned error: %v", err)
+	}
+	if result.Provider != "gemini" || small.Calls != 0 {
+		t.Fatalf("oversized prompt should skip deepseek without a call, got %q (calls=%d)", result.Provider, small.Calls)
+	}
+	if errs, _ := result.Metadata["fallback_errors"].([]string); len(errs) != 1 || !strings.Contains(errs[0], "context window") {
+		t.Fatalf("expected a context window error in fallback_errors, got %v", result.Metadata)
+	}
+	if result.Usage.Prompt == 0 || result.Metadata["usage_estimated"] != true {
+		t.Fatalf("expected estimated usage, got %+v", result.Usage)
+	}
+
+	trimmed, err := newTestEngine(small).ProcessPrompt(context.Background(), huge, map[string]interface{}{"context_policy": "trim"})
+	if err != nil {
+		t.Fatalf("trim policy returned error: %v", err)
+	}
+	if small.Calls != 1 || len(trimmed.Prompt) >= len(huge) || trimmed.Metadata["trimmed_tokens"] == nil {
+		t.Fatalf("expected a trimmed prompt to be sent, got metadata %v", trimmed.Metadata)
+	}
+}
+
+func TestProcessPromptReplaysCachedResponses(t *testing.T) {
+	stub := &providertest.Provider{ProviderName: "openai", Response: "cached answer", Usage: &interfaces.Usage{Prompt: 2, Completion: 2, Tokens: 4}}
+	e := newTestEngine()
+	e.cache, _ = cache.New(cache.Config{Backend: "memory"})
+	e.AddProvider(stub)
+
+	first, err := e.ProcessPrompt(context.Background(), "same prompt", nil)
+	if err != nil {
+		t.Fatalf("ProcessPrompt returned error: %v", err)
+	}
+	second, err := e.ProcessPrompt(context.Background(), "same prompt", nil)
+	if err != nil {
+		t.Fatalf("ProcessPrompt returned error: %v", err)
+	}
+	if stub.Calls != 1 || second.Response != first.Response || second.Metadata["cache"] != "hit" || first.Metadata["cache"] != nil {
+		t.Fatalf("expected the second prompt from the cache, calls=%d metadata=%v", stub.Calls, second.Metadata)
+	}
+
+	if _, err := e.ProcessPrompt(cache.Bypass(context.Background()), "same prompt", nil); err != nil || stub.Calls != 2 {
+		t.Fatalf("expected a bypass to reach the provider, calls=%d err=%v", stub.Calls, err)
+	}
+}
+
+func TestProcessPromptRepairsJSONOutput(t *testing.T) {
+	stub := &providertest.Provider{
+		ProviderName: "openai",
+		Replies:      []string{`{"title": 42}`, "```json\n{\"title\": \"Release notes\"}\n```"},
+		Usage:        &interfaces.Usage{Prompt: 10, Completion: 5, Tokens: 15},
+	}
+	e := newTestEngine(stub)
+	schema := `{"type": "object", "required": ["title"], "properties": {"title": {"type": "string"}}}`
+
+	result, err := e.ProcessPrompt(context.Background(), "summarise", map[string]any{"json_schema": schema})
+	if err != nil {
+		t.Fatalf("ProcessPrompt returned error: %v", err)
+	}
+	if stub.Calls != 2 || result.Metadata["json_attempts"] != 2 {
+		t.Fatalf("expected one repair, calls=%d metadata=%v", stub.Calls, result.Metadata)
+	}
+	doc, ok := result.JSON.(map[string]any)
+	if !ok || doc["title"] != "Release notes" || result.Response != `{"title": "Release notes"}` {
+		t.Fatalf("unexpected document: json=%v response=%q", result.JSON, result.Response)
+	}
+	if result.Usage == nil || result.Usage.Tokens != 30 {
+		t.Fatalf("expected usage of both attempts, got %+v", result.Usage)
+	}
+}
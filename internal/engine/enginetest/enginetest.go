@@ -0,0 +1,28 @@
+// Package enginetest builds engines for the tests of packages that run
+// prompts through one.
+package enginetest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/kubex-ecosystem/grompt/internal/engine"
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"github.com/kubex-ecosystem/grompt/internal/types"
+)
+
+// New returns an engine over providers, in fallback order, with its history
+// and templates kept in temporary directories of t
+func New(t testing.TB, providers ...interfaces.Provider) interfaces.IEngine {
+	t.Helper()
+	t.Setenv("GROMPT_HISTORY_FILE", filepath.Join(t.TempDir(), "history.jsonl"))
+	t.Setenv("GROMPT_TEMPLATES_DIR", t.TempDir())
+
+	e := engine.NewEngine(types.NewConfig("", "", "", "", "", "", "", "", nil))
+	for _, p := range providers {
+		if err := e.AddProvider(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return e
+}
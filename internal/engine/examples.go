@@ -0,0 +1,111 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	gl "github.com/kubex-ecosystem/logz/logger"
+
+	"github.com/kubex-ecosystem/grompt/internal/examples"
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"github.com/kubex-ecosystem/grompt/internal/strategy"
+)
+
+// GetExamples returns the few-shot example library, nil when the engine has
+// none
+func (e *Engine) GetExamples() *examples.Store {
+	if e == nil {
+		return nil
+	}
+	return e.examples
+}
+
+// configureExamples switches example retrieval to the embeddings of the
+// provider named by GROMPT_EXAMPLES_EMBEDDER, computed with the model named by
+// GROMPT_EXAMPLES_EMBED_MODEL or the provider's default. Without it, or when the
+// provider cannot compute embeddings, examples are retrieved with BM25.
+func (e *Engine) configureExamples() {
+	name := os.Getenv("GROMPT_EXAMPLES_EMBEDDER")
+	if name == "" || e.examples == nil {
+		return
+	}
+	p := e.Resolve(name)
+	if p == nil {
+		gl.Log("warn", fmt.Sprintf("Example embedder %s is not configured, using BM25", name))
+		return
+	}
+	emb, ok := interfaces.EmbedderOf(p)
+	if !ok {
+		gl.Log("warn", fmt.Sprintf("Provider %s does not compute embeddings, using BM25", name))
+		return
+	}
+	model := os.Getenv("GROMPT_EXAMPLES_EMBED_MODEL")
+	e.examples.SetEmbedder(func(ctx context.Context, texts []string) ([][]float64, error) {
+		res, err := emb.Embed(ctx, interfaces.EmbeddingRequest{Model: model, Input: texts})
+		if err != nil {
+			return nil, err
+		}
+		return res.Vectors, nil
+	})
+}
+
+// retrieveExamples returns up to k library examples of purposeType relevant
+// to text. A negative k disables retrieval and 0 uses examples.DefaultK.
+func (e *Engine) retrieveExamples(ctx context.Context, text, purposeType string, k int) []interfaces.Example {
+	if e.examples == nil || k < 0 {
+		return nil
+	}
+	return e.examples.Retrieve(ctx, interfaces.ExampleQuery{Text: text, Purpose: purposeType, K: k})
+}
+
+// templateExamples appends the library examples relevant to a rendered
+// template as demonstrations. The purpose type is vars["purpose_type"] or the
+// template's category; vars["max_examples"] sets how many are retrieved, 0 for none, and
+// vars["use_examples"] set to false turns retrieval off.
+func (e *Engine) templateExamples(ctx context.Context, name, content string, vars map[string]interface{}) (string, []string) {
+	if off, ok := vars["use_examples"]; ok && !enabled(off) {
+		return content, nil
+	}
+	k := 0
+	if v, ok := vars["max_examples"]; ok {
+		if n, err := strconv.Atoi(strings.TrimSpace(fmt.Sprint(v))); err == nil {
+			if k = n; k == 0 {
+				k = -1
+			}
+		}
+	}
+	purposeType, _ := vars["purpose_type"].(string)
+	if purposeType == "" {
+		if tmpl, err := e.templates.GetTemplate(name); err == nil {
+			purposeType = tmpl.Category
+		}
+	}
+
+	found := e.retrieveExamples(ctx, content, purposeType, k)
+	if len(found) == 0 {
+		return content, nil
+	}
+	lang, _ := vars["lang"].(string)
+	ids := make([]string, len(found))
+	for i, ex := range found {
+		ids[i] = ex.ID
+	}
+	return strings.TrimRight(content, "\n") + "\n\n" + strings.TrimSpace(strategy.Demonstrations(lang, found)), ids
+}
+
+// enabled reads a boolean switch given as a bool or a string
+func enabled(v any) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "false", "off", "no", "0", "none":
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,256 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"github.com/kubex-ecosystem/grompt/internal/structured"
+	"github.com/kubex-ecosystem/grompt/internal/tokenizer"
+)
+
+// fallbackChain returns the providers to try for a request, in order: the
+// preferred provider (or the engine default when none is given), followed by
+// every other registered provider in registration order.
+func (e *Engine) fallbackChain(preferred string) []interfaces.Provider {
+	if preferred == "" {
+		preferred = e.defaultProvider
+	}
+
+	chain := make([]interfaces.Provider, 0, len(e.providers))
+	if head := e.Resolve(preferred); head != nil {
+		chain = append(chain, head)
+	}
+	for _, p := range e.providers {
+		if len(chain) > 0 && p == chain[0] {
+			continue
+		}
+		chain = append(chain, p)
+	}
+	return chain
+}
+
+// execute runs an already processed prompt through the chain and returns the
+// first successful result. When every provider fails, the errors are joined.
+// With vars["json_schema"] the reply must be a JSON document valid against
+// the schema; a provider that cannot produce one within vars["json_retries"]
+// repairs counts as failed.
+func (e *Engine) execute(ctx context.Context, chain []interfaces.Provider, prompt string, vars map[string]interface{}) (*interfaces.Result, error) {
+	model, _ := vars["model"].(string)
+	maxOutput := intVar(vars, "max_tokens")
+	policy := tokenizer.ParsePolicy(fmt.Sprint(vars["context_policy"]))
+	schema, retries, jsonMode, err := structured.FromMeta(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for _, provider := range chain {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+
+		// Oversized prompts are caught here instead of after a round-trip; a
+		// provider with a larger window may still take the request.
+		sent, _, est, err := tokenizer.Fit(provider.Name(), model, prompt, maxOutput, policy)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+			continue
+		}
+
+		started := time.Now()
+		var response string
+		var usage *interfaces.Usage
+		var cached bool
+		var doc *structured.Result
+		if jsonMode {
+			doc, usage, cached, err = completeJSON(ctx, provider, sent, model, schema, retries)
+			if err == nil {
+				response = doc.Raw
+			}
+		} else {
+			response, usage, cached, err = complete(ctx, provider, sent, model, nil)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+			continue
+		}
+
+		metadata := map[string]any{}
+		if cached {
+			metadata["cache"] = "hit"
+		}
+		if doc != nil {
+			metadata["json_attempts"] = doc.Attempts
+			if len(doc.Rejected) > 0 {
+				metadata["json_rejected"] = doc.Rejected
+			}
+		}
+		if usage == nil {
+			usage = &interfaces.Usage{}
+		}
+		if usage.Prompt == 0 && usage.Completion == 0 {
+			tk := tokenizer.For(provider.Name(), model)
+			usage.Prompt, usage.Completion = tk.Count(sent), tk.Count(response)
+			usage.Tokens = usage.Prompt + usage.Completion
+			metadata["usage_estimated"] = true
+		}
+		if est.TrimmedTokens > 0 {
+			metadata["trimmed_tokens"] = est.TrimmedTokens
+		}
+		if usage.Ms == 0 {
+			usage.Ms = time.Since(started).Milliseconds()
+		}
+		if usage.Provider == "" {
+			usage.Provider = provider.Name()
+		}
+		if usage.Model == "" {
+			usage.Model = model
+		}
+
+		if len(errs) > 0 {
+			metadata["fallback_errors"] = errorStrings(errs)
+		}
+
+		result := &interfaces.Result{
+			ID:        generateID(),
+			Prompt:    sent,
+			Response:  response,
+			Provider:  provider.Name(),
+			Model:     usage.Model,
+			Usage:     usage,
+			Variables: vars,
+			Timestamp: time.Now(),
+		}
+		if doc != nil {
+			result.JSON = doc.Data
+		}
+		if len(metadata) > 0 {
+			result.Metadata = metadata
+		}
+		return result, nil
+	}
+
+	return nil, fmt.Errorf("provider execution failed: %w", errors.Join(errs...))
+}
+
+// Complete sends prompt to a single provider, without fallback or history.
+// Chat is preferred because it reports usage; providers whose API does not
+// chat (see interfaces.CanChat) are driven through Execute instead. A failed
+// chat is returned as is rather than sent again through Execute.
+func Complete(ctx context.Context, provider interfaces.Provider, prompt, model string) (string, *interfaces.Usage, error) {
+	response, usage, _, err := complete(ctx, provider, prompt, model, nil)
+	return response, usage, err
+}
+
+// complete is Complete, also reporting whether the response was replayed
+// from the response cache. meta is sent as ChatRequest.Meta, or as the vars
+// of Execute.
+func complete(ctx context.Context, provider interfaces.Provider, prompt, model string, meta map[string]any) (string, *interfaces.Usage, bool, error) {
+	if !interfaces.CanChat(provider) {
+		res, err := provider.Execute(ctx, prompt, meta)
+		if err != nil {
+			return "", nil, false, err
+		}
+		if res == nil {
+			return "", nil, false, fmt.Errorf("empty result")
+		}
+		return res.Response, res.Usage, res.Metadata["cache"] == "hit", nil
+	}
+
+	stream, err := provider.Chat(ctx, interfaces.ChatRequest{
+		Provider: provider.Name(),
+		Model:    model,
+		Messages: []interfaces.Message{{Role: "user", Content: prompt}},
+		Stream:   false,
+		Meta:     meta,
+	})
+	if err != nil {
+		return "", nil, false, err
+	}
+	if stream == nil {
+		return "", nil, false, fmt.Errorf("empty chat stream")
+	}
+
+	var content strings.Builder
+	var usage *interfaces.Usage
+	cached := false
+	for {
+		select {
+		case <-ctx.Done():
+			return "", nil, false, ctx.Err()
+		case chunk, ok := <-stream:
+			if !ok {
+				return content.String(), usage, cached, nil
+			}
+			if chunk.Error != "" {
+				return "", nil, false, errors.New(chunk.Error)
+			}
+			content.WriteString(chunk.Content)
+			if chunk.Usage != nil {
+				usage = chunk.Usage
+			}
+			cached = cached || chunk.Cached
+			if chunk.Done {
+				return content.String(), usage, cached, nil
+			}
+		}
+	}
+}
+
+// completeJSON asks one provider for a document valid against schema,
+// re-prompting with the validation errors up to retries times. The schema is
+// passed along in the request meta so providers with a native JSON mode use
+// it. The usage of every attempt is summed.
+func completeJSON(ctx context.Context, provider interfaces.Provider, prompt, model string, schema map[string]any, retries int) (*structured.Result, *interfaces.Usage, bool, error) {
+	meta := map[string]any{structured.MetaSchema: schema}
+	var total *interfaces.Usage
+	cached := true
+	doc, err := structured.Generate(ctx, func(ctx context.Context, messages []interfaces.Message) (string, error) {
+		reply, usage, hit, err := complete(ctx, provider, structured.Flatten(messages), model, meta)
+		cached = cached && hit
+		if usage != nil {
+			if total == nil {
+				total = &interfaces.Usage{Provider: usage.Provider, Model: usage.Model}
+			}
+			total.Prompt += usage.Prompt
+			total.Completion += usage.Completion
+			total.Tokens += usage.Tokens
+			total.CostUSD += usage.CostUSD
+		}
+		return reply, err
+	}, []interfaces.Message{{Role: "user", Content: prompt}}, schema, retries)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	return doc, total, cached, nil
+}
+
+// intVar reads an integer variable given as a number or a numeric string
+func intVar(vars map[string]interface{}, key string) int {
+	switch v := vars[key].(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	case string:
+		n, _ := strconv.Atoi(strings.TrimSpace(v))
+		return n
+	}
+	return 0
+}
+
+func errorStrings(errs []error) []string {
+	out := make([]string, 0, len(errs))
+	for _, err := range errs {
+		out = append(out, err.Error())
+	}
+	return out
+}
@@ -0,0 +1,83 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"github.com/kubex-ecosystem/grompt/internal/strategy"
+)
+
+// Generate engineers a prompt from raw ideas with the strategy named in the
+// request or, by default, the one registered for its purpose type. Every
+// model call goes to the requested provider, without fallback; their usage is
+// summed. Unless the request brings its own, the library examples relevant
+// to the ideas are injected as demonstrations. The result is recorded in
+// history tagged "generate" and the strategy name, with metadata "strategy",
+// "calls" and the injected "examples".
+func (e *Engine) Generate(ctx context.Context, req interfaces.GenerationRequest) (*interfaces.Generation, error) {
+	if e == nil {
+		return nil, fmt.Errorf("engine is nil")
+	}
+	if len(req.Ideas) == 0 {
+		return nil, fmt.Errorf("at least one idea is required")
+	}
+	s, err := strategy.Select(req.Strategy, req.PurposeType)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := e.pick(req.Provider)
+	if err != nil {
+		return nil, err
+	}
+	if req.Examples == nil {
+		req.Examples = e.retrieveExamples(ctx, strings.Join(append(append([]string{}, req.Ideas...), req.Purpose), "\n"), req.PurposeType, req.MaxExamples)
+	}
+	var meta map[string]any
+	if req.MaxTokens > 0 {
+		meta = map[string]any{"max_tokens": req.MaxTokens}
+	}
+
+	var (
+		mu    sync.Mutex
+		total *interfaces.Usage
+	)
+	started := time.Now()
+	gen, err := strategy.Run(ctx, s, req, func(ctx context.Context, prompt string) (string, error) {
+		response, usage, _, err := complete(ctx, provider, prompt, req.Model, meta)
+		mu.Lock()
+		total = addUsage(total, usage)
+		mu.Unlock()
+		return response, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	gen.Provider, gen.Model = provider.Name(), req.Model
+	gen.Usage = completeUsage(total, provider.Name(), req.Model, started)
+	if gen.Usage.Model != "" {
+		gen.Model = gen.Usage.Model
+	}
+
+	result := interfaces.Result{
+		ID:        generateID(),
+		Prompt:    gen.Request,
+		Response:  gen.Prompt,
+		Provider:  gen.Provider,
+		Model:     gen.Model,
+		Usage:     gen.Usage,
+		Variables: map[string]any{"ideas": req.Ideas, "purpose": req.Purpose, "purpose_type": req.PurposeType, "lang": req.Lang},
+		Tags:      append([]string{"generate", s.Name()}, req.Tags...),
+		Metadata:  map[string]any{"strategy": s.Name(), "calls": gen.Calls},
+		Timestamp: time.Now(),
+	}
+	if len(gen.Examples) > 0 {
+		result.Metadata["examples"] = gen.Examples
+	}
+	e.history.Add(result)
+	gen.HistoryID = result.ID
+	return gen, nil
+}
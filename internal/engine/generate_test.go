@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kubex-ecosystem/grompt/factory/templates"
+	"github.com/kubex-ecosystem/grompt/internal/examples"
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"github.com/kubex-ecosystem/grompt/internal/providertest"
+)
+
+func TestGenerateRunsPurposeStrategy(t *testing.T) {
+	p := &providertest.Provider{ProviderName: "writer", Replies: []string{
+		`{"identity": "You are a support agent.", "capabilities": ["Look up orders"], "boundaries": ["Never share card numbers"], "behaviour": [], "style": "Friendly."}`,
+	}, Usage: &interfaces.Usage{Prompt: 10, Completion: 20}}
+	e := newTestEngine(p)
+
+	gen, err := e.Generate(context.Background(), interfaces.GenerationRequest{Ideas: []string{"orders"}, PurposeType: "Agent", Tags: []string{"team"}})
+	if err != nil {
+		t.Fatalf("Generate returned error: %v", err)
+	}
+	if gen.Strategy != "agent" || gen.Calls != 1 || gen.Provider != "writer" || gen.Usage.Completion != 20 {
+		t.Fatalf("unexpected generation %+v", gen)
+	}
+
+	entries := e.GetHistory()
+	if len(entries) != 1 || entries[0].Response != gen.Prompt || entries[0].Metadata["strategy"] != "agent" {
+		t.Fatalf("expected the generation in history, got %+v", entries)
+	}
+	if tags := entries[0].Tags; len(tags) != 3 || tags[0] != "generate" || tags[1] != "agent" || tags[2] != "team" {
+		t.Fatalf("unexpected history tags %v", tags)
+	}
+
+	if _, err := e.Generate(context.Background(), interfaces.GenerationRequest{Ideas: []string{"x"}, Strategy: "nope"}); err == nil {
+		t.Fatal("expected an unknown strategy to fail")
+	}
+}
+
+func TestGenerateInjectsLibraryExamples(t *testing.T) {
+	p := &providertest.Provider{ProviderName: "writer", Response: "Review the pull request."}
+	e := newTestEngine(p)
+	e.examples = examples.NewMemoryStore()
+	ex, _ := e.examples.Add(interfaces.Example{Input: "Review the retry logic", Output: "Flag unbounded retries", Purpose: "code"})
+	e.examples.Add(interfaces.Example{Input: "Write a haiku", Output: "Autumn moonlight", Purpose: "creative"})
+
+	gen, err := e.Generate(context.Background(), interfaces.GenerationRequest{Ideas: []string{"review", "retry logic"}, PurposeType: "code", Strategy: "meta"})
+	if err != nil {
+		t.Fatalf("Generate returned error: %v", err)
+	}
+	if len(gen.Examples) != 1 || gen.Examples[0] != ex.ID {
+		t.Fatalf("expected the retry example to be injected, got %v", gen.Examples)
+	}
+	if !strings.Contains(gen.Prompt, "## Examples") || !strings.Contains(gen.Prompt, "Flag unbounded retries") {
+		t.Fatalf("expected demonstrations in the prompt, got %q", gen.Prompt)
+	}
+	if ids, _ := e.GetHistory()[0].Metadata["examples"].([]string); len(ids) != 1 {
+		t.Fatalf("expected the injected examples in history metadata, got %+v", e.GetHistory()[0].Metadata)
+	}
+
+	gen, err = e.Generate(context.Background(), interfaces.GenerationRequest{Ideas: []string{"retry logic"}, MaxExamples: -1})
+	if err != nil {
+		t.Fatalf("Generate returned error: %v", err)
+	}
+	if len(gen.Examples) != 0 || strings.Contains(gen.Prompt, "## Examples") {
+		t.Fatalf("expected no examples when disabled, got %q", gen.Prompt)
+	}
+}
+
+func TestProcessTemplateInjectsLibraryExamples(t *testing.T) {
+	p := &providertest.Provider{ProviderName: "writer", Response: "ok"}
+	e := newTestEngine(p)
+	e.templates = templates.NewManager(t.TempDir())
+	if err := e.templates.SaveTemplate("commit", "Write a commit message for {{.change}}"); err != nil {
+		t.Fatalf("SaveTemplate returned error: %v", err)
+	}
+	e.examples = examples.NewMemoryStore()
+	e.examples.Add(interfaces.Example{Input: "commit message for the parser fix", Output: "Fix off-by-one in the parser"})
+
+	result, err := e.ProcessTemplate(context.Background(), "commit", map[string]interface{}{"change": "the parser cache"})
+	if err != nil {
+		t.Fatalf("ProcessTemplate returned error: %v", err)
+	}
+	if !strings.Contains(result.Prompt, "Fix off-by-one in the parser") || result.Metadata["examples"] == nil {
+		t.Fatalf("expected the example in the rendered prompt, got %q", result.Prompt)
+	}
+
+	result, err = e.ProcessTemplate(context.Background(), "commit", map[string]interface{}{"change": "the parser cache", "use_examples": false})
+	if err != nil {
+		t.Fatalf("ProcessTemplate returned error: %v", err)
+	}
+	if strings.Contains(result.Prompt, "## Examples") {
+		t.Fatalf("expected use_examples=false to skip the examples, got %q", result.Prompt)
+	}
+}
@@ -0,0 +1,17 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubex-ecosystem/grompt/internal/pipeline"
+)
+
+// RunPipeline executes a multi-step pipeline on the engine's providers and
+// templates. Each step is recorded in history; see pipeline.Runner.
+func (e *Engine) RunPipeline(ctx context.Context, p *pipeline.Pipeline, inputs map[string]any) (*pipeline.Run, error) {
+	if e == nil {
+		return nil, fmt.Errorf("engine is nil")
+	}
+	return (&pipeline.Runner{Engine: e}).Run(ctx, p, inputs)
+}
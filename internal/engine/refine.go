@@ -0,0 +1,229 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+)
+
+const (
+	// DefaultRefineThreshold is the critic score that ends a refine loop
+	DefaultRefineThreshold = 0.8
+	// DefaultRefineIterations bounds the generations of a refine loop
+	DefaultRefineIterations = 3
+	// MaxRefineIterations is the most iterations a server request may ask
+	// for; each one is a generation and a critique
+	MaxRefineIterations = 10
+)
+
+// Refine generates a prompt from request, usually the meta-prompt of
+// GetBaseGenerationPrompt, and has a critic score it against a rubric. While
+// the score is below the threshold the prompt is regenerated with the
+// critique, up to the maximum number of iterations. Every iteration is
+// returned with its score and recorded in history tagged "refine", with
+// metadata "refine_id", "iteration" and "score".
+//
+// When a later step fails, the iterations so far are returned with the error.
+func (e *Engine) Refine(ctx context.Context, request string, opts interfaces.RefineOptions) (*interfaces.Refinement, error) {
+	if e == nil {
+		return nil, fmt.Errorf("engine is nil")
+	}
+	if strings.TrimSpace(request) == "" {
+		return nil, fmt.Errorf("refine request is empty")
+	}
+
+	generator, err := e.pick(opts.Provider)
+	if err != nil {
+		return nil, err
+	}
+	critic := generator
+	if opts.Critic != "" {
+		if critic, err = e.pick(opts.Critic); err != nil {
+			return nil, err
+		}
+	}
+	criticModel := opts.CriticModel
+	if criticModel == "" && critic == generator {
+		criticModel = opts.Model
+	}
+	rubric := opts.Rubric
+	if len(rubric) == 0 {
+		rubric = interfaces.DefaultRubric
+	}
+	threshold := opts.Threshold
+	if threshold <= 0 {
+		threshold = DefaultRefineThreshold
+	}
+	maxIterations := opts.MaxIterations
+	if maxIterations <= 0 {
+		maxIterations = DefaultRefineIterations
+	}
+	var meta map[string]any
+	if opts.MaxTokens > 0 {
+		meta = map[string]any{"max_tokens": opts.MaxTokens}
+	}
+
+	ref := &interfaces.Refinement{
+		ID:        fmt.Sprintf("refine_%d", time.Now().UnixNano()),
+		Threshold: threshold,
+		Rubric:    rubric,
+	}
+	prompt := request
+	for n := 1; n <= maxIterations; n++ {
+		started := time.Now()
+		response, usage, _, err := complete(ctx, generator, prompt, opts.Model, meta)
+		if err != nil {
+			return refined(ref), fmt.Errorf("iteration %d: %w", n, err)
+		}
+		usage = completeUsage(usage, generator.Name(), opts.Model, started)
+		it := interfaces.RefineIteration{Iteration: n, Prompt: strings.TrimSpace(response), Usage: usage}
+		ref.Usage = addUsage(ref.Usage, usage)
+
+		critique, err := critiquePrompt(ctx, critic, criticModel, request, it.Prompt, rubric)
+		if err != nil {
+			ref.Iterations = append(ref.Iterations, it)
+			return refined(ref), fmt.Errorf("iteration %d: critic: %w", n, err)
+		}
+		it.Critique = critique
+		ref.Usage = addUsage(ref.Usage, critique.Usage)
+
+		result := interfaces.Result{
+			ID:        generateID(),
+			Prompt:    prompt,
+			Response:  it.Prompt,
+			Provider:  generator.Name(),
+			Model:     usage.Model,
+			Usage:     usage,
+			Tags:      append([]string{"refine"}, opts.Tags...),
+			Metadata:  map[string]any{"refine_id": ref.ID, "iteration": n, "score": critique.Score},
+			Timestamp: time.Now(),
+		}
+		e.history.Add(result)
+		it.HistoryID = result.ID
+		ref.Iterations = append(ref.Iterations, it)
+
+		if critique.Score >= threshold {
+			ref.Converged = true
+			break
+		}
+		prompt = improvePrompt(request, it.Prompt, critique, rubric)
+	}
+	return refined(ref), nil
+}
+
+// pick resolves a provider by name, or the default one when name is empty
+func (e *Engine) pick(name string) (interfaces.Provider, error) {
+	if name != "" {
+		if p := e.Resolve(name); p != nil {
+			return p, nil
+		}
+		return nil, fmt.Errorf("provider %s not found", name)
+	}
+	chain := e.fallbackChain("")
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("no providers available")
+	}
+	return chain[0], nil
+}
+
+// refined selects the best scored iteration as the result of ref
+func refined(ref *interfaces.Refinement) *interfaces.Refinement {
+	ref.Prompt, ref.Score, ref.Best = "", 0, 0
+	for _, it := range ref.Iterations {
+		if it.Critique == nil {
+			continue
+		}
+		if ref.Best == 0 || it.Critique.Score > ref.Score {
+			ref.Prompt, ref.Score, ref.Best = it.Prompt, it.Critique.Score, it.Iteration
+		}
+	}
+	return ref
+}
+
+// critiquePrompt asks critic to score candidate on every rubric criterion.
+// The reply is a JSON document validated against a schema, so a malformed
+// verdict is repaired before it is scored.
+func critiquePrompt(ctx context.Context, critic interfaces.Provider, model, request, candidate string, rubric []interfaces.Criterion) (*interfaces.Critique, error) {
+	properties := map[string]any{}
+	required := make([]any, 0, len(rubric))
+	var criteria strings.Builder
+	for _, c := range rubric {
+		properties[c.Name] = map[string]any{"type": "number", "minimum": 0, "maximum": 1}
+		required = append(required, c.Name)
+		fmt.Fprintf(&criteria, "- %s: %s\n", c.Name, c.Description)
+	}
+	schema := map[string]any{
+		"type":     "object",
+		"required": []any{"scores", "feedback"},
+		"properties": map[string]any{
+			"scores":   map[string]any{"type": "object", "required": required, "properties": properties},
+			"feedback": map[string]any{"type": "string"},
+		},
+	}
+
+	prompt := "You are a strict prompt engineering reviewer. Score the candidate prompt below on each criterion, " +
+		"from 0 (fails it) to 1 (excellent), and give concrete, actionable feedback on how to improve it.\n\n" +
+		"Criteria:\n" + criteria.String() +
+		"\nThe prompt was written for this request:\n<request>\n" + request + "\n</request>\n\n" +
+		"Candidate prompt:\n<prompt>\n" + candidate + "\n</prompt>"
+
+	started := time.Now()
+	doc, usage, _, err := completeJSON(ctx, critic, prompt, model, schema, 2)
+	if err != nil {
+		return nil, err
+	}
+	critique := &interfaces.Critique{Scores: map[string]float64{}, Usage: completeUsage(usage, critic.Name(), model, started)}
+	data, _ := doc.Data.(map[string]any)
+	critique.Feedback, _ = data["feedback"].(string)
+	scores, _ := data["scores"].(map[string]any)
+
+	var total, weights float64
+	for _, c := range rubric {
+		score, _ := scores[c.Name].(float64)
+		critique.Scores[c.Name] = score
+		weight := c.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		total += weight * score
+		weights += weight
+	}
+	if weights > 0 {
+		critique.Score = total / weights
+	}
+	return critique, nil
+}
+
+// improvePrompt asks for a new version of candidate addressing its critique
+func improvePrompt(request, candidate string, critique *interfaces.Critique, rubric []interfaces.Criterion) string {
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(request, "\n"))
+	fmt.Fprintf(&b, "\n\nA previous version of the prompt scored %.2f of 1 in review:\n<prompt>\n%s\n</prompt>\n\nScores:\n", critique.Score, candidate)
+	for _, c := range rubric {
+		fmt.Fprintf(&b, "- %s: %.2f\n", c.Name, critique.Scores[c.Name])
+	}
+	b.WriteString("\nReviewer feedback:\n" + critique.Feedback + "\n\n")
+	b.WriteString("Rewrite the prompt so that it addresses the feedback and keeps what already works. " +
+		"Respond ONLY with the improved prompt.")
+	return b.String()
+}
+
+// completeUsage fills the provider, model and latency of a call's usage
+func completeUsage(usage *interfaces.Usage, provider, model string, started time.Time) *interfaces.Usage {
+	if usage == nil {
+		usage = &interfaces.Usage{}
+	}
+	if usage.Provider == "" {
+		usage.Provider = provider
+	}
+	if usage.Model == "" {
+		usage.Model = model
+	}
+	if usage.Ms == 0 {
+		usage.Ms = time.Since(started).Milliseconds()
+	}
+	return usage
+}
@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"github.com/kubex-ecosystem/grompt/internal/providertest"
+)
+
+func verdict(clarity, completeness, format float64, feedback string) string {
+	return fmt.Sprintf(`{"scores": {"clarity": %v, "completeness": %v, "output_format": %v}, "feedback": %q}`, clarity, completeness, format, feedback)
+}
+
+func TestRefineStopsAtThreshold(t *testing.T) {
+	writer := &providertest.Provider{ProviderName: "writer", Replies: []string{"draft one", "draft two", "draft three"}}
+	critic := &providertest.Provider{ProviderName: "critic", Replies: []string{
+		verdict(0.5, 0.6, 0.4, "state the output format"),
+		verdict(0.9, 0.9, 0.9, "good"),
+	}}
+	e := newTestEngine(writer, critic)
+
+	ref, err := e.Refine(context.Background(), "Engineer a prompt about SQL indexes", interfaces.RefineOptions{
+		Provider: "writer", Critic: "critic", Threshold: 0.85, Tags: []string{"generate"},
+	})
+	if err != nil {
+		t.Fatalf("Refine returned error: %v", err)
+	}
+
+	if !ref.Converged || len(ref.Iterations) != 2 || ref.Best != 2 || ref.Prompt != "draft two" {
+		t.Fatalf("expected convergence on the second draft, got %+v", ref)
+	}
+	if got := ref.Iterations[0].Critique.Score; got != 0.5 {
+		t.Fatalf("expected the mean of the rubric scores, got %v", got)
+	}
+	if writer.Calls != 2 || critic.Calls != 2 {
+		t.Fatalf("expected two generations and two critiques, got %d and %d", writer.Calls, critic.Calls)
+	}
+
+	entries := e.GetHistory()
+	if len(entries) != 2 {
+		t.Fatalf("expected every generation in history, got %d", len(entries))
+	}
+	second := entries[len(entries)-1]
+	if !strings.Contains(second.Prompt, "state the output format") || second.Metadata["refine_id"] != ref.ID {
+		t.Fatalf("expected the second generation to carry the critique, got %+v", second)
+	}
+	if len(second.Tags) != 2 || second.Tags[0] != "refine" || second.Tags[1] != "generate" {
+		t.Fatalf("unexpected history tags %v", second.Tags)
+	}
+}
+
+func TestRefineKeepsBestIteration(t *testing.T) {
+	writer := &providertest.Provider{ProviderName: "writer", Replies: []string{"a", "b", "c"}}
+	writer.Response = "d"
+	critic := &providertest.Provider{ProviderName: "critic", Replies: []string{
+		verdict(0.5, 0.5, 0.5, "vague"),
+		verdict(0.7, 0.7, 0.7, "better"),
+		verdict(0.6, 0.6, 0.6, "worse"),
+	}}
+	e := newTestEngine(writer, critic)
+
+	rubric := []interfaces.Criterion{{Name: "clarity", Weight: 3}, {Name: "completeness"}, {Name: "output_format"}}
+	ref, err := e.Refine(context.Background(), "request", interfaces.RefineOptions{Provider: "writer", Critic: "critic", Rubric: rubric})
+	if err != nil {
+		t.Fatalf("Refine returned error: %v", err)
+	}
+	if ref.Converged || len(ref.Iterations) != DefaultRefineIterations || ref.Best != 2 || ref.Prompt != "b" {
+		t.Fatalf("expected the best of three iterations, got %+v", ref)
+	}
+}
+
+func TestRefineUnknownCritic(t *testing.T) {
+	e := newTestEngine(&providertest.Provider{ProviderName: "writer", Response: "x"})
+	if _, err := e.Refine(context.Background(), "request", interfaces.RefineOptions{Critic: "nobody"}); err == nil {
+		t.Fatal("expected an unknown critic to fail")
+	}
+}
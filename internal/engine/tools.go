@@ -0,0 +1,220 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+)
+
+// DefaultMaxToolSteps bounds the model turns of RunTools when none is given
+const DefaultMaxToolSteps = 8
+
+// ErrToolSteps is returned when the model still calls tools after the last
+// allowed step
+var ErrToolSteps = errors.New("tool loop exceeded its step limit")
+
+// ToolHandler runs one tool call. Its result, usually JSON text, is sent back
+// to the model as the content of a "tool" message.
+type ToolHandler func(ctx context.Context, arguments json.RawMessage) (string, error)
+
+// Toolbox holds tool definitions with the Go handlers that run them
+type Toolbox struct {
+	mu       sync.RWMutex
+	tools    []interfaces.Tool
+	handlers map[string]ToolHandler
+}
+
+// NewToolbox creates an empty toolbox
+func NewToolbox() *Toolbox {
+	return &Toolbox{handlers: make(map[string]ToolHandler)}
+}
+
+// Register adds a tool, replacing any tool of the same name
+func (t *Toolbox) Register(tool interfaces.Tool, handler ToolHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.handlers[tool.Name]; ok {
+		for i := range t.tools {
+			if t.tools[i].Name == tool.Name {
+				t.tools[i] = tool
+			}
+		}
+	} else {
+		t.tools = append(t.tools, tool)
+	}
+	t.handlers[tool.Name] = handler
+}
+
+// Definitions returns the registered tools in registration order
+func (t *Toolbox) Definitions() []interfaces.Tool {
+	if t == nil {
+		return nil
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return append([]interfaces.Tool(nil), t.tools...)
+}
+
+// Call runs the handler of call. Unknown tools and handler errors are
+// reported to the model as {"error": ...} so it can recover.
+func (t *Toolbox) Call(ctx context.Context, call interfaces.ToolCall) string {
+	var handler ToolHandler
+	if t != nil {
+		t.mu.RLock()
+		handler = t.handlers[call.Name]
+		t.mu.RUnlock()
+	}
+	if handler == nil {
+		return toolError(fmt.Errorf("unknown tool %q", call.Name))
+	}
+	result, err := handler(ctx, call.Arguments)
+	if err != nil {
+		return toolError(err)
+	}
+	return result
+}
+
+func toolError(err error) string {
+	b, _ := json.Marshal(map[string]string{"error": err.Error()})
+	return string(b)
+}
+
+// ToolRun is the outcome of a tool loop
+type ToolRun struct {
+	Content  string                `json:"content"`
+	Messages []interfaces.Message  `json:"messages"` // the conversation, tool turns included
+	Calls    []interfaces.ToolCall `json:"calls,omitempty"`
+	Steps    int                   `json:"steps"`
+	Usage    *interfaces.Usage     `json:"usage,omitempty"`
+}
+
+// RunTools chats with provider until it answers without calling a tool. Each
+// round of tool calls is run through box and fed back as "tool" messages.
+// The tools of box are offered in addition to any in req.Tools. maxSteps
+// bounds the model turns; past it the partial run is returned with
+// ErrToolSteps.
+func RunTools(ctx context.Context, provider interfaces.Provider, req interfaces.ChatRequest, box *Toolbox, maxSteps int) (*ToolRun, error) {
+	if maxSteps <= 0 {
+		maxSteps = DefaultMaxToolSteps
+	}
+	req.Tools = mergeTools(req.Tools, box.Definitions())
+	req.Messages = append([]interfaces.Message(nil), req.Messages...)
+	req.Stream = false
+
+	run := &ToolRun{}
+	for run.Steps < maxSteps {
+		run.Steps++
+		content, calls, usage, err := chatTurn(ctx, provider, req)
+		if err != nil {
+			return run, err
+		}
+		run.Usage = addUsage(run.Usage, usage)
+
+		req.Messages = append(req.Messages, interfaces.Message{Role: "assistant", Content: content, ToolCalls: calls})
+		run.Messages = req.Messages
+		if len(calls) == 0 {
+			run.Content = content
+			return run, nil
+		}
+
+		for _, call := range calls {
+			run.Calls = append(run.Calls, call)
+			req.Messages = append(req.Messages, interfaces.Message{Role: "tool", ToolCallID: call.ID, Content: box.Call(ctx, call)})
+		}
+		run.Messages = req.Messages
+	}
+	return run, fmt.Errorf("%w (%d steps)", ErrToolSteps, maxSteps)
+}
+
+// RegisterTool makes a tool available to the engine's RunTools
+func (e *Engine) RegisterTool(tool interfaces.Tool, handler ToolHandler) {
+	if e.tools == nil {
+		e.tools = NewToolbox()
+	}
+	e.tools.Register(tool, handler)
+}
+
+// RunTools runs a tool loop with the registered tools on the requested
+// provider, or the default one
+func (e *Engine) RunTools(ctx context.Context, req interfaces.ChatRequest, maxSteps int) (*ToolRun, error) {
+	chain := e.fallbackChain(req.Provider)
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("no providers available")
+	}
+	if req.Provider != "" && !strings.EqualFold(chain[0].Name(), req.Provider) {
+		return nil, fmt.Errorf("provider %q not found", req.Provider)
+	}
+	return RunTools(ctx, chain[0], req, e.tools, maxSteps)
+}
+
+// chatTurn collects one reply of a chat stream
+func chatTurn(ctx context.Context, provider interfaces.Provider, req interfaces.ChatRequest) (string, []interfaces.ToolCall, *interfaces.Usage, error) {
+	stream, err := provider.Chat(ctx, req)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if stream == nil {
+		return "", nil, nil, fmt.Errorf("provider %s returned no chat stream", provider.Name())
+	}
+
+	var content strings.Builder
+	var calls []interfaces.ToolCall
+	var usage *interfaces.Usage
+	for {
+		select {
+		case <-ctx.Done():
+			return "", nil, nil, ctx.Err()
+		case chunk, ok := <-stream:
+			if !ok {
+				return content.String(), calls, usage, nil
+			}
+			if chunk.Error != "" {
+				return "", nil, nil, errors.New(chunk.Error)
+			}
+			content.WriteString(chunk.Content)
+			if chunk.ToolCall != nil {
+				calls = append(calls, *chunk.ToolCall)
+			}
+			if chunk.Usage != nil {
+				usage = chunk.Usage
+			}
+			if chunk.Done {
+				return content.String(), calls, usage, nil
+			}
+		}
+	}
+}
+
+// mergeTools appends the tools of extra that tools does not already name
+func mergeTools(tools, extra []interfaces.Tool) []interfaces.Tool {
+	out := append([]interfaces.Tool(nil), tools...)
+	for _, t := range extra {
+		found := false
+		for _, have := range tools {
+			found = found || have.Name == t.Name
+		}
+		if !found {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func addUsage(total, usage *interfaces.Usage) *interfaces.Usage {
+	if usage == nil {
+		return total
+	}
+	if total == nil {
+		total = &interfaces.Usage{Provider: usage.Provider, Model: usage.Model}
+	}
+	total.Prompt += usage.Prompt
+	total.Completion += usage.Completion
+	total.Tokens += usage.Tokens
+	total.CostUSD += usage.CostUSD
+	return total
+}
@@ -0,0 +1,99 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"github.com/kubex-ecosystem/grompt/internal/providertest"
+)
+
+// toolProvider replies with turns in order, recording the requests it got
+type toolProvider struct {
+	providertest.Provider
+	turns    []interfaces.ChatChunk // one reply per turn; ToolCall set for tool turns
+	requests []interfaces.ChatRequest
+}
+
+func (p *toolProvider) Chat(ctx context.Context, req interfaces.ChatRequest) (<-chan interfaces.ChatChunk, error) {
+	p.requests = append(p.requests, req)
+	turn := p.turns[0]
+	if len(p.turns) > 1 {
+		p.turns = p.turns[1:]
+	}
+	ch := make(chan interfaces.ChatChunk, 2)
+	ch <- turn
+	ch <- interfaces.ChatChunk{Done: true, Usage: &interfaces.Usage{Prompt: 10, Completion: 2, Tokens: 12}}
+	close(ch)
+	return ch, nil
+}
+
+func weatherCall(id string) interfaces.ChatChunk {
+	return interfaces.ChatChunk{ToolCall: &interfaces.ToolCall{ID: id, Name: "weather", Arguments: json.RawMessage(`{"city":"Lisbon"}`)}}
+}
+
+func TestRunToolsFeedsResultsBack(t *testing.T) {
+	p := &toolProvider{Provider: providertest.Provider{ProviderName: "openai"}, turns: []interfaces.ChatChunk{
+		weatherCall("call_1"),
+		{Content: "It is sunny in Lisbon."},
+	}}
+	e := newTestEngine(p)
+	var city string
+	e.RegisterTool(interfaces.Tool{Name: "weather", Description: "Current weather"}, func(ctx context.Context, args json.RawMessage) (string, error) {
+		var in struct{ City string }
+		if err := json.Unmarshal(args, &in); err != nil {
+			return "", err
+		}
+		city = in.City
+		return `{"sky":"clear"}`, nil
+	})
+
+	run, err := e.RunTools(context.Background(), interfaces.ChatRequest{Messages: []interfaces.Message{{Role: "user", Content: "Weather in Lisbon?"}}}, 0)
+	if err != nil {
+		t.Fatalf("RunTools: %v", err)
+	}
+	if run.Content != "It is sunny in Lisbon." || run.Steps != 2 || city != "Lisbon" {
+		t.Fatalf("unexpected run: %+v (city %q)", run, city)
+	}
+	if run.Usage == nil || run.Usage.Tokens != 24 {
+		t.Fatalf("expected usage of both turns, got %+v", run.Usage)
+	}
+
+	// The second turn carries the call and its result, and the tools are offered
+	second := p.requests[1]
+	if len(second.Tools) != 1 || len(second.Messages) != 3 {
+		t.Fatalf("unexpected second request: %+v", second)
+	}
+	if result := second.Messages[2]; result.Role != "tool" || result.ToolCallID != "call_1" || result.Content != `{"sky":"clear"}` {
+		t.Fatalf("unexpected tool result message: %+v", result)
+	}
+}
+
+func TestRunToolsReportsHandlerErrorsToTheModel(t *testing.T) {
+	p := &toolProvider{Provider: providertest.Provider{ProviderName: "openai"}, turns: []interfaces.ChatChunk{weatherCall("a"), {Content: "Sorry."}}}
+	box := NewToolbox()
+	box.Register(interfaces.Tool{Name: "weather"}, func(ctx context.Context, args json.RawMessage) (string, error) {
+		return "", errors.New("service down")
+	})
+
+	if _, err := RunTools(context.Background(), p, interfaces.ChatRequest{}, box, 0); err != nil {
+		t.Fatalf("RunTools: %v", err)
+	}
+	if got := p.requests[1].Messages[1].Content; !strings.Contains(got, "service down") {
+		t.Fatalf("expected the handler error as the tool result, got %q", got)
+	}
+}
+
+func TestRunToolsStopsAtStepLimit(t *testing.T) {
+	p := &toolProvider{Provider: providertest.Provider{ProviderName: "openai"}, turns: []interfaces.ChatChunk{weatherCall("loop")}}
+	run, err := RunTools(context.Background(), p, interfaces.ChatRequest{}, NewToolbox(), 3)
+	if !errors.Is(err, ErrToolSteps) || run.Steps != 3 || len(run.Calls) != 3 {
+		t.Fatalf("expected to stop after 3 steps, got %v (%+v)", err, run)
+	}
+	if !strings.Contains(run.Messages[1].Content, "unknown tool") {
+		t.Fatalf("expected an unknown tool error, got %+v", run.Messages[1])
+	}
+}
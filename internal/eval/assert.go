@@ -0,0 +1,198 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kubex-ecosystem/grompt/internal/jsonschema"
+)
+
+// Assertion types
+const (
+	AssertContains   = "contains"
+	AssertRegex      = "regex"
+	AssertJSONSchema = "json_schema"
+	AssertMaxLength  = "max_length"
+	AssertLLMJudge   = "llm_judge"
+)
+
+// defaultJudgeThreshold is the minimum llm_judge score to pass
+const defaultJudgeThreshold = 0.7
+
+// Assertion checks a single property of a provider output.
+type Assertion struct {
+	Type       string  `json:"type" yaml:"type"`
+	Value      string  `json:"value,omitempty" yaml:"value,omitempty"`             // contains: substring, regex: pattern
+	IgnoreCase bool    `json:"ignore_case,omitempty" yaml:"ignore_case,omitempty"` // contains, regex
+	Schema     any     `json:"schema,omitempty" yaml:"schema,omitempty"`           // json_schema: inline schema or file path
+	Max        int     `json:"max,omitempty" yaml:"max,omitempty"`                 // max_length: characters
+	Rubric     string  `json:"rubric,omitempty" yaml:"rubric,omitempty"`           // llm_judge
+	Threshold  float64 `json:"threshold,omitempty" yaml:"threshold,omitempty"`     // llm_judge: 0..1, default 0.7
+}
+
+// AssertionResult is the outcome of one assertion on one output.
+type AssertionResult struct {
+	Type    string   `json:"type"`
+	Passed  bool     `json:"passed"`
+	Message string   `json:"message,omitempty"`
+	Score   *float64 `json:"score,omitempty"`
+}
+
+// JudgeFunc sends a grading prompt to the judge model and returns its reply.
+type JudgeFunc func(ctx context.Context, prompt string) (string, error)
+
+// ParseAssertion parses the CLI shorthand "type=value", e.g. "contains=TODO",
+// "regex=^##", "max_length=400", "json_schema=schema.json" or
+// "llm_judge=Is the answer polite?". Schema files are read immediately.
+func ParseAssertion(spec string) (Assertion, error) {
+	kind, value, ok := strings.Cut(spec, "=")
+	if !ok {
+		return Assertion{}, fmt.Errorf("invalid assertion %q, expected type=value", spec)
+	}
+	a := Assertion{Type: strings.TrimSpace(kind)}
+	switch a.Type {
+	case AssertContains, AssertRegex:
+		a.Value = value
+	case AssertJSONSchema:
+		schema, err := loadSchema(strings.TrimSpace(value))
+		if err != nil {
+			return a, err
+		}
+		a.Schema = schema
+	case AssertMaxLength:
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return a, fmt.Errorf("invalid max_length %q: %w", value, err)
+		}
+		a.Max = n
+	case AssertLLMJudge:
+		a.Rubric = value
+	default:
+		return a, fmt.Errorf("unknown assertion type %q", a.Type)
+	}
+	return a, a.Validate()
+}
+
+// Validate reports assertions that can never be evaluated.
+func (a Assertion) Validate() error {
+	switch a.Type {
+	case AssertContains:
+		if a.Value == "" {
+			return fmt.Errorf("contains assertion needs a value")
+		}
+	case AssertRegex:
+		if _, err := regexp.Compile(a.Value); err != nil {
+			return fmt.Errorf("invalid regex %q: %w", a.Value, err)
+		}
+	case AssertJSONSchema:
+		if a.Schema == nil {
+			return fmt.Errorf("json_schema assertion needs a schema")
+		}
+	case AssertMaxLength:
+		if a.Max <= 0 {
+			return fmt.Errorf("max_length assertion needs a positive max")
+		}
+	case AssertLLMJudge:
+		if strings.TrimSpace(a.Rubric) == "" {
+			return fmt.Errorf("llm_judge assertion needs a rubric")
+		}
+	default:
+		return fmt.Errorf("unknown assertion type %q", a.Type)
+	}
+	return nil
+}
+
+// Check evaluates the assertion against output. judge is only used by
+// llm_judge assertions and may be nil otherwise.
+func (a Assertion) Check(ctx context.Context, output string, judge JudgeFunc) AssertionResult {
+	res := AssertionResult{Type: a.Type}
+	pass := func(ok bool, format string, args ...any) AssertionResult {
+		res.Passed = ok
+		if !ok {
+			res.Message = fmt.Sprintf(format, args...)
+		}
+		return res
+	}
+
+	switch a.Type {
+	case AssertContains:
+		haystack, needle := output, a.Value
+		if a.IgnoreCase {
+			haystack, needle = strings.ToLower(haystack), strings.ToLower(needle)
+		}
+		return pass(strings.Contains(haystack, needle), "output does not contain %q", a.Value)
+
+	case AssertRegex:
+		pattern := a.Value
+		if a.IgnoreCase {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return pass(false, "invalid regex %q: %v", a.Value, err)
+		}
+		return pass(re.MatchString(output), "output does not match %s", a.Value)
+
+	case AssertJSONSchema:
+		_, err := jsonschema.ValidateJSON(a.Schema, []byte(jsonschema.Extract(output)))
+		return pass(err == nil, "%v", err)
+
+	case AssertMaxLength:
+		n := utf8.RuneCountInString(output)
+		return pass(n <= a.Max, "output has %d characters, max is %d", n, a.Max)
+
+	case AssertLLMJudge:
+		if judge == nil {
+			return pass(false, "no judge provider configured")
+		}
+		score, reason, err := runJudge(ctx, judge, a.Rubric, output)
+		if err != nil {
+			return pass(false, "judge failed: %v", err)
+		}
+		res.Score = &score
+		threshold := a.Threshold
+		if threshold <= 0 {
+			threshold = defaultJudgeThreshold
+		}
+		return pass(score >= threshold, "score %.2f below %.2f: %s", score, threshold, reason)
+
+	default:
+		return pass(false, "unknown assertion type %q", a.Type)
+	}
+}
+
+const judgePrompt = `You are a strict evaluator grading the output of a language model against a rubric.
+
+Rubric:
+%s
+
+Output to grade:
+<<<
+%s
+>>>
+
+Reply with JSON only, no prose: {"score": <number from 0 to 1>, "reason": "<one sentence>"}`
+
+func runJudge(ctx context.Context, judge JudgeFunc, rubric, output string) (float64, string, error) {
+	reply, err := judge(ctx, fmt.Sprintf(judgePrompt, rubric, output))
+	if err != nil {
+		return 0, "", err
+	}
+
+	var verdict struct {
+		Score  float64 `json:"score"`
+		Reason string  `json:"reason"`
+	}
+	if err := json.Unmarshal([]byte(jsonschema.Extract(reply)), &verdict); err != nil {
+		return 0, "", fmt.Errorf("unparseable verdict %q", strings.TrimSpace(reply))
+	}
+	if verdict.Score < 0 || verdict.Score > 1 {
+		return 0, "", fmt.Errorf("score %v out of range", verdict.Score)
+	}
+	return verdict.Score, verdict.Reason, nil
+}
@@ -0,0 +1,196 @@
+// Package eval runs prompt templates against datasets on one or more
+// providers and checks every output with assertions.
+package eval
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Suite is an evaluation: one template, the providers to run it on, and the
+// dataset cases with their assertions.
+type Suite struct {
+	Name       string      `json:"name,omitempty" yaml:"name,omitempty"`
+	Template   string      `json:"template" yaml:"template"`                       // stored template name or inline template text
+	Providers  []string    `json:"providers,omitempty" yaml:"providers,omitempty"` // "provider" or "provider:model"
+	Judge      string      `json:"judge,omitempty" yaml:"judge,omitempty"`         // provider (or provider:model) for llm_judge assertions
+	Assertions []Assertion `json:"assert,omitempty" yaml:"assert,omitempty"`       // applied to every case
+	Cases      []Case      `json:"cases" yaml:"cases"`
+}
+
+// Case is one dataset row: the template variables and case-specific assertions.
+type Case struct {
+	Name       string         `json:"name,omitempty" yaml:"name,omitempty"`
+	Vars       map[string]any `json:"vars" yaml:"vars"`
+	Assertions []Assertion    `json:"assert,omitempty" yaml:"assert,omitempty"`
+}
+
+// LoadSuite reads a dataset file. JSONL files hold one case per line; YAML
+// and JSON files hold either a list of cases or a full Suite document. A case
+// is either a bare object of variables or an object with a "vars" key and
+// optional "name" and "assert" keys.
+//
+// json_schema assertions may reference a schema file by path, relative to
+// the dataset file.
+func LoadSuite(path string) (*Suite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dataset %s: %w", path, err)
+	}
+
+	suite := &Suite{}
+	var rows []map[string]any
+	if strings.EqualFold(filepath.Ext(path), ".jsonl") {
+		rows, err = readJSONL(data)
+	} else {
+		rows, err = readYAML(data, suite)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse dataset %s: %w", path, err)
+	}
+
+	for i, row := range rows {
+		c, err := caseFromRow(row)
+		if err != nil {
+			return nil, fmt.Errorf("dataset %s: case %d: %w", path, i+1, err)
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+	for i := range suite.Cases {
+		if suite.Cases[i].Name == "" {
+			suite.Cases[i].Name = fmt.Sprintf("case-%d", i+1)
+		}
+	}
+	if suite.Name == "" {
+		suite.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	base := filepath.Dir(path)
+	if err := resolveSchemas(suite.Assertions, base); err != nil {
+		return nil, err
+	}
+	for _, c := range suite.Cases {
+		if err := resolveSchemas(c.Assertions, base); err != nil {
+			return nil, fmt.Errorf("case %s: %w", c.Name, err)
+		}
+	}
+	return suite, nil
+}
+
+func readJSONL(data []byte) ([]map[string]any, error) {
+	var rows []map[string]any
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 || text[0] == '#' {
+			continue
+		}
+		var row map[string]any
+		if err := json.Unmarshal(text, &row); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rows = append(rows, row)
+	}
+	return rows, scanner.Err()
+}
+
+// readYAML decodes either a list of rows or a Suite document. In the latter
+// case the suite fields are filled in and its raw cases are returned as rows.
+func readYAML(data []byte, suite *Suite) ([]map[string]any, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	if len(node.Content) == 0 {
+		return nil, nil
+	}
+
+	var rows []map[string]any
+	if node.Content[0].Kind == yaml.SequenceNode {
+		return rows, node.Decode(&rows)
+	}
+
+	// Cases are decoded as raw rows so bare variable objects are accepted.
+	doc := node.Content[0]
+	var cases *yaml.Node
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		if doc.Content[i].Value == "cases" {
+			cases = doc.Content[i+1]
+			doc.Content = append(doc.Content[:i:i], doc.Content[i+2:]...)
+			break
+		}
+	}
+	if err := doc.Decode(suite); err != nil {
+		return nil, err
+	}
+	if cases != nil {
+		if err := cases.Decode(&rows); err != nil {
+			return nil, err
+		}
+	}
+	return rows, nil
+}
+
+func caseFromRow(row map[string]any) (Case, error) {
+	vars, structured := row["vars"].(map[string]any)
+	if !structured {
+		return Case{Vars: row}, nil
+	}
+
+	c := Case{Vars: vars}
+	if name, ok := row["name"]; ok {
+		c.Name = fmt.Sprint(name)
+	}
+	if raw, ok := row["assert"]; ok {
+		data, err := yaml.Marshal(raw)
+		if err != nil {
+			return c, err
+		}
+		if err := yaml.Unmarshal(data, &c.Assertions); err != nil {
+			return c, fmt.Errorf("invalid assertions: %w", err)
+		}
+	}
+	return c, nil
+}
+
+// resolveSchemas loads json_schema assertions whose schema is a file path.
+func resolveSchemas(assertions []Assertion, base string) error {
+	for i := range assertions {
+		a := &assertions[i]
+		path, ok := a.Schema.(string)
+		if a.Type != AssertJSONSchema || !ok {
+			continue
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(base, path)
+		}
+		schema, err := loadSchema(path)
+		if err != nil {
+			return err
+		}
+		a.Schema = schema
+	}
+	return nil
+}
+
+func loadSchema(path string) (any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema %s: %w", path, err)
+	}
+	var schema any
+	if err := yaml.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("failed to parse schema %s: %w", path, err)
+	}
+	return schema, nil
+}
@@ -0,0 +1,115 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kubex-ecosystem/grompt/internal/engine/enginetest"
+	"github.com/kubex-ecosystem/grompt/internal/providertest"
+)
+
+const suiteYAML = `
+name: greetings
+template: "Greet {{.name}} politely."
+providers: [polite, terse]
+assert:
+  - type: contains
+    value: hello
+    ignore_case: true
+cases:
+  - name: ada
+    vars: {name: Ada}
+  - name: json
+    vars: {name: Bob}
+    assert:
+      - type: json_schema
+        schema: schema.yaml
+      - type: llm_judge
+        rubric: Is the greeting polite?
+`
+
+func TestRunSuite(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "suite.yaml"), []byte(suiteYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "schema.yaml"), []byte("type: object\nrequired: [greeting]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	suite, err := LoadSuite(filepath.Join(dir, "suite.yaml"))
+	if err != nil {
+		t.Fatalf("LoadSuite returned error: %v", err)
+	}
+
+	polite := &providertest.Provider{ProviderName: "polite", Reply: func(prompt string) string {
+		if strings.Contains(prompt, "strict evaluator") {
+			return "```json\n{\"score\": 0.9, \"reason\": \"warm\"}\n```"
+		}
+		return `{"greeting": "Hello there"}`
+	}}
+	terse := &providertest.Provider{ProviderName: "terse", Reply: func(string) string { return "hey" }}
+
+	runner := &Runner{Engine: enginetest.New(t, polite, terse), Concurrency: 2}
+	report, err := runner.Run(context.Background(), suite)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	if report.Total != 4 || report.Passed != 2 || report.Failed != 2 || report.Errors != 0 {
+		t.Fatalf("unexpected summary: %+v", report)
+	}
+	got := report.Results[1] // ada on terse
+	if got.Case != "ada" || got.Provider != "terse" || got.Passed || !strings.Contains(got.Failure(), "does not contain") {
+		t.Fatalf("unexpected result order or outcome: %+v", got)
+	}
+	if judged := report.Results[2]; len(judged.Assertions) != 3 || judged.Assertions[2].Score == nil || *judged.Assertions[2].Score != 0.9 {
+		t.Fatalf("expected the judge score to be recorded, got %+v", judged.Assertions)
+	}
+	if report.Results[0].Prompt != "Greet Ada politely." {
+		t.Fatalf("expected the rendered prompt, got %q", report.Results[0].Prompt)
+	}
+
+	var junit bytes.Buffer
+	if err := report.Write(&junit, FormatJUnit); err != nil {
+		t.Fatalf("junit report returned error: %v", err)
+	}
+	var parsed junitSuites
+	if err := xml.Unmarshal(junit.Bytes(), &parsed); err != nil {
+		t.Fatalf("junit report does not parse: %v", err)
+	}
+	if len(parsed.Suites) != 2 || parsed.Suites[1].Failures != 2 || parsed.Tests != 4 {
+		t.Fatalf("unexpected junit structure: %+v", parsed)
+	}
+}
+
+func TestLoadSuiteJSONLRows(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cases.jsonl")
+	data := `{"name": "Ada"}
+# comments and blank lines are skipped
+
+{"name": "row", "vars": {"name": "Bob"}, "assert": [{"type": "max_length", "max": 10}]}
+`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	suite, err := LoadSuite(path)
+	if err != nil {
+		t.Fatalf("LoadSuite returned error: %v", err)
+	}
+	if suite.Name != "cases" || len(suite.Cases) != 2 {
+		t.Fatalf("unexpected suite: %+v", suite)
+	}
+	if suite.Cases[0].Name != "case-1" || suite.Cases[0].Vars["name"] != "Ada" {
+		t.Fatalf("bare rows should become variables, got %+v", suite.Cases[0])
+	}
+	if c := suite.Cases[1]; c.Name != "row" || c.Vars["name"] != "Bob" || len(c.Assertions) != 1 || c.Assertions[0].Max != 10 {
+		t.Fatalf("structured rows should keep name and assertions, got %+v", c)
+	}
+}
@@ -0,0 +1,211 @@
+package eval
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Report formats supported by Report.Write.
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatJUnit = "junit"
+)
+
+// Report is the outcome of a suite run.
+type Report struct {
+	Suite      string       `json:"suite"`
+	Template   string       `json:"template"`
+	StartedAt  time.Time    `json:"started_at"`
+	DurationMs int64        `json:"duration_ms"`
+	Total      int          `json:"total"`
+	Passed     int          `json:"passed"`
+	Failed     int          `json:"failed"` // assertion failures
+	Errors     int          `json:"errors"` // provider or template errors
+	Results    []CaseResult `json:"results"`
+}
+
+// OK reports whether every case passed on every provider.
+func (r *Report) OK() bool { return r.Passed == r.Total }
+
+func (r *Report) summarize() {
+	r.Total, r.Passed, r.Failed, r.Errors = len(r.Results), 0, 0, 0
+	for _, res := range r.Results {
+		switch {
+		case res.Error != "":
+			r.Errors++
+		case res.Passed:
+			r.Passed++
+		default:
+			r.Failed++
+		}
+	}
+}
+
+// Write renders the report as a terminal table, JSON or JUnit XML.
+func (r *Report) Write(w io.Writer, format string) error {
+	switch strings.ToLower(format) {
+	case "", FormatTable:
+		return r.writeTable(w)
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case FormatJUnit, "xml":
+		return r.writeJUnit(w)
+	default:
+		return fmt.Errorf("unsupported report format %q (use table, json or junit)", format)
+	}
+}
+
+func (r *Report) writeTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CASE\tPROVIDER\tMODEL\tRESULT\tLATENCY\tDETAILS")
+	for _, res := range r.Results {
+		status := "PASS"
+		switch {
+		case res.Error != "":
+			status = "ERROR"
+		case !res.Passed:
+			status = "FAIL"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%dms\t%s\n",
+			res.Case, res.Provider, res.Model, status, res.LatencyMs, oneLine(res.Failure(), 80))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\n%s: %d/%d passed, %d failed, %d errors in %s\n",
+		r.Suite, r.Passed, r.Total, r.Failed, r.Errors, time.Duration(r.DurationMs)*time.Millisecond)
+	for _, p := range r.providers() {
+		passed, total := 0, 0
+		for _, res := range r.Results {
+			if res.Provider == p {
+				total++
+				if res.Passed {
+					passed++
+				}
+			}
+		}
+		fmt.Fprintf(w, "  %s: %d/%d\n", p, passed, total)
+	}
+	return nil
+}
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name      string      `xml:"name,attr"`
+	Tests     int         `xml:"tests,attr"`
+	Failures  int         `xml:"failures,attr"`
+	Errors    int         `xml:"errors,attr"`
+	Time      string      `xml:"time,attr"`
+	Timestamp string      `xml:"timestamp,attr"`
+	Cases     []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Body    string `xml:",chardata"`
+}
+
+// writeJUnit emits one testsuite per provider and one testcase per case.
+func (r *Report) writeJUnit(w io.Writer) error {
+	out := junitSuites{
+		Name:     r.Suite,
+		Tests:    r.Total,
+		Failures: r.Failed,
+		Errors:   r.Errors,
+		Time:     seconds(r.DurationMs),
+	}
+
+	for _, p := range r.providers() {
+		suite := junitSuite{Name: r.Suite + "." + p, Timestamp: r.StartedAt.Format("2006-01-02T15:04:05")}
+		var ms int64
+		for _, res := range r.Results {
+			if res.Provider != p {
+				continue
+			}
+			tc := junitCase{Name: res.Case, Classname: suite.Name, Time: seconds(res.LatencyMs), SystemOut: res.Output}
+			switch {
+			case res.Error != "":
+				tc.Error = &junitMessage{Message: res.Error, Type: "error"}
+				suite.Errors++
+			case !res.Passed:
+				var details []string
+				for _, a := range res.Assertions {
+					if !a.Passed {
+						details = append(details, a.Type+": "+a.Message)
+					}
+				}
+				tc.Failure = &junitMessage{Message: res.Failure(), Type: "assertion", Body: strings.Join(details, "\n")}
+				suite.Failures++
+			}
+			suite.Tests++
+			ms += res.LatencyMs
+			suite.Cases = append(suite.Cases, tc)
+		}
+		suite.Time = seconds(ms)
+		out.Suites = append(out.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// providers returns the providers of the report in first-seen order.
+func (r *Report) providers() []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, res := range r.Results {
+		if !seen[res.Provider] {
+			seen[res.Provider] = true
+			out = append(out, res.Provider)
+		}
+	}
+	return out
+}
+
+func seconds(ms int64) string {
+	return fmt.Sprintf("%.3f", float64(ms)/1000)
+}
+
+func oneLine(s string, max int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len([]rune(s)) > max {
+		return string([]rune(s)[:max-3]) + "..."
+	}
+	return s
+}
@@ -0,0 +1,207 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubex-ecosystem/grompt/internal/engine"
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+)
+
+// defaultConcurrency bounds the number of provider calls in flight
+const defaultConcurrency = 4
+
+// Runner executes suites through an engine.
+type Runner struct {
+	Engine      interfaces.IEngine
+	Concurrency int // defaults to 4
+}
+
+// CaseResult is the outcome of one case on one provider.
+type CaseResult struct {
+	Case       string            `json:"case"`
+	Provider   string            `json:"provider"`
+	Model      string            `json:"model,omitempty"`
+	Prompt     string            `json:"prompt,omitempty"`
+	Output     string            `json:"output,omitempty"`
+	LatencyMs  int64             `json:"latency_ms"`
+	Usage      *interfaces.Usage `json:"usage,omitempty"`
+	Assertions []AssertionResult `json:"assertions,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	Passed     bool              `json:"passed"`
+}
+
+// Failure returns the first failure message of the result, or "".
+func (r CaseResult) Failure() string {
+	if r.Error != "" {
+		return r.Error
+	}
+	for _, a := range r.Assertions {
+		if !a.Passed {
+			return a.Type + ": " + a.Message
+		}
+	}
+	return ""
+}
+
+// target is a provider with an optional model, parsed from "provider:model".
+type target struct {
+	provider string
+	model    string
+}
+
+func parseTarget(spec string) target {
+	provider, model, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	return target{provider: provider, model: model}
+}
+
+// Run evaluates every case of the suite on every provider and returns the
+// report. Provider failures are recorded per case; an error is only returned
+// when the suite itself cannot run.
+func (r *Runner) Run(ctx context.Context, suite *Suite) (*Report, error) {
+	if r.Engine == nil {
+		return nil, fmt.Errorf("eval runner has no engine")
+	}
+	if strings.TrimSpace(suite.Template) == "" {
+		return nil, fmt.Errorf("suite %s has no template", suite.Name)
+	}
+	if len(suite.Cases) == 0 {
+		return nil, fmt.Errorf("suite %s has no cases", suite.Name)
+	}
+	if len(suite.Providers) == 0 {
+		return nil, fmt.Errorf("suite %s has no providers", suite.Name)
+	}
+
+	for _, a := range suite.Assertions {
+		if err := a.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	for _, c := range suite.Cases {
+		for _, a := range c.Assertions {
+			if err := a.Validate(); err != nil {
+				return nil, fmt.Errorf("case %s: %w", c.Name, err)
+			}
+		}
+	}
+
+	targets := make([]target, 0, len(suite.Providers))
+	for _, spec := range suite.Providers {
+		t := parseTarget(spec)
+		if r.Engine.Resolve(t.provider) == nil {
+			return nil, fmt.Errorf("provider %s is not available", t.provider)
+		}
+		targets = append(targets, t)
+	}
+
+	needsJudge := hasJudge(suite.Assertions)
+	for _, c := range suite.Cases {
+		needsJudge = needsJudge || hasJudge(c.Assertions)
+	}
+	var judge JudgeFunc
+	if needsJudge {
+		var err error
+		if judge, err = r.judge(suite, targets); err != nil {
+			return nil, err
+		}
+	}
+
+	report := &Report{Suite: suite.Name, Template: suite.Template, StartedAt: time.Now()}
+	report.Results = make([]CaseResult, len(suite.Cases)*len(targets))
+
+	concurrency := r.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for ci, c := range suite.Cases {
+		for ti, t := range targets {
+			idx := ci*len(targets) + ti
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				select {
+				case sem <- struct{}{}:
+					defer func() { <-sem }()
+					report.Results[idx] = r.runCase(ctx, suite, c, t, judge)
+				case <-ctx.Done():
+					report.Results[idx] = CaseResult{Case: c.Name, Provider: t.provider, Model: t.model, Error: ctx.Err().Error()}
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	report.DurationMs = time.Since(report.StartedAt).Milliseconds()
+	report.summarize()
+	return report, nil
+}
+
+func (r *Runner) runCase(ctx context.Context, suite *Suite, c Case, t target, judge JudgeFunc) CaseResult {
+	res := CaseResult{Case: c.Name, Provider: t.provider, Model: t.model}
+
+	vars := make(map[string]interface{}, len(c.Vars)+2)
+	for k, v := range c.Vars {
+		vars[k] = v
+	}
+	if t.model != "" {
+		vars["model"] = t.model
+	}
+	vars["tags"] = []string{"eval", suite.Name}
+
+	started := time.Now()
+	result, err := r.Engine.InvokeProvider(ctx, t.provider, suite.Template, vars)
+	res.LatencyMs = time.Since(started).Milliseconds()
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.Prompt, res.Output, res.Usage = result.Prompt, result.Response, result.Usage
+	if result.Model != "" {
+		res.Model = result.Model
+	}
+	if !strings.EqualFold(result.Provider, t.provider) {
+		// The engine fell back to another provider; that output says nothing about this one.
+		res.Error = fmt.Sprintf("%s failed and the engine fell back to %s", t.provider, result.Provider)
+		return res
+	}
+
+	res.Passed = true
+	for _, a := range append(append([]Assertion(nil), suite.Assertions...), c.Assertions...) {
+		ar := a.Check(ctx, result.Response, judge)
+		res.Passed = res.Passed && ar.Passed
+		res.Assertions = append(res.Assertions, ar)
+	}
+	return res
+}
+
+// judge resolves the llm_judge provider: the suite's judge, or else the first
+// provider under test.
+func (r *Runner) judge(suite *Suite, targets []target) (JudgeFunc, error) {
+	t := targets[0]
+	if suite.Judge != "" {
+		t = parseTarget(suite.Judge)
+	}
+	provider := r.Engine.Resolve(t.provider)
+	if provider == nil {
+		return nil, fmt.Errorf("judge provider %s is not available", t.provider)
+	}
+	return func(ctx context.Context, prompt string) (string, error) {
+		reply, _, err := engine.Complete(ctx, provider, prompt, t.model)
+		return reply, err
+	}, nil
+}
+
+func hasJudge(assertions []Assertion) bool {
+	for _, a := range assertions {
+		if a.Type == AssertLLMJudge {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+)
+
+func TestStorePersistsExamples(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "examples.jsonl")
+	s, err := NewStore(path)
+	if err != nil {
+		t.Fatalf("NewStore returned error: %v", err)
+	}
+
+	ex, err := s.Add(interfaces.Example{Input: " add a retry ", Output: "Wrap the call in retry.Do", Purpose: "Coding", Tags: []string{"Go", "go"}})
+	if err != nil {
+		t.Fatalf("Add returned error: %v", err)
+	}
+	if ex.ID == "" || ex.Input != "add a retry" || ex.Purpose != "code" || len(ex.Tags) != 1 || ex.Tags[0] != "go" {
+		t.Fatalf("unexpected example %+v", ex)
+	}
+	if _, err := s.Add(interfaces.Example{Input: "no output"}); err == nil {
+		t.Fatal("expected an example without output to be rejected")
+	}
+	if _, err := s.Add(interfaces.Example{Input: "write a haiku", Output: "Autumn moonlight", Purpose: "creative"}); err != nil {
+		t.Fatalf("Add returned error: %v", err)
+	}
+
+	reopened, err := NewStore(path)
+	if err != nil {
+		t.Fatalf("NewStore returned error: %v", err)
+	}
+	if all := reopened.List("", nil); len(all) != 2 {
+		t.Fatalf("expected 2 examples after reopening, got %d", len(all))
+	}
+	if code := reopened.List("programming", []string{"GO"}); len(code) != 1 || code[0].ID != ex.ID {
+		t.Fatalf("expected the code example, got %+v", code)
+	}
+
+	if _, err := reopened.Remove(ex.ID); err != nil {
+		t.Fatalf("Remove returned error: %v", err)
+	}
+	if _, ok := s.Get(ex.ID); ok {
+		t.Fatal("expected the removed example to be gone for the other store too")
+	}
+	if _, err := s.Remove(ex.ID); err == nil {
+		t.Fatal("expected removing a missing example to fail")
+	}
+}
+
+func TestSearchRanksWithBM25(t *testing.T) {
+	s := NewMemoryStore()
+	for _, ex := range []interfaces.Example{
+		{Input: "Summarize the quarterly sales report", Output: "Revenue grew 12%", Purpose: "data_analysis"},
+		{Input: "Add retries to the HTTP client", Output: "Use exponential backoff with jitter", Purpose: "code"},
+		{Input: "Handle HTTP timeouts in the client", Output: "Set a context deadline", Purpose: "code"},
+		{Input: "Write release notes", Output: "## Highlights"},
+	} {
+		if _, err := s.Add(ex); err != nil {
+			t.Fatalf("Add returned error: %v", err)
+		}
+	}
+
+	matches := s.Search(context.Background(), interfaces.ExampleQuery{Text: "retries for http requests", Purpose: "code"})
+	if len(matches) != 2 {
+		t.Fatalf("expected the 2 http examples, got %+v", matches)
+	}
+	if matches[0].Input != "Add retries to the HTTP client" || matches[0].Score <= matches[1].Score {
+		t.Fatalf("expected the retry example first, got %+v", matches)
+	}
+
+	if got := s.Search(context.Background(), interfaces.ExampleQuery{Text: "release notes", Purpose: "code"}); len(got) != 1 || got[0].Purpose != "" {
+		t.Fatalf("expected examples without purpose to match every purpose, got %+v", got)
+	}
+	if got := s.Search(context.Background(), interfaces.ExampleQuery{Text: "http client", K: 1}); len(got) != 1 {
+		t.Fatalf("expected K to bound the matches, got %d", len(got))
+	}
+	if got := s.Search(context.Background(), interfaces.ExampleQuery{Text: "kubernetes"}); len(got) != 0 {
+		t.Fatalf("expected unrelated text to match nothing, got %+v", got)
+	}
+}
+
+func TestSearchUsesEmbedder(t *testing.T) {
+	s := NewMemoryStore()
+	cats, _ := s.Add(interfaces.Example{Input: "cats", Output: "meow"})
+	s.Add(interfaces.Example{Input: "dogs", Output: "woof"})
+
+	calls := 0
+	s.SetEmbedder(func(ctx context.Context, texts []string) ([][]float64, error) {
+		calls++
+		out := make([][]float64, len(texts))
+		for i, text := range texts {
+			if text == "kitten" || text[:4] == "cats" {
+				out[i] = []float64{1, 0}
+			} else {
+				out[i] = []float64{0, 1}
+			}
+		}
+		return out, nil
+	})
+	matches := s.Search(context.Background(), interfaces.ExampleQuery{Text: "kitten", K: 1})
+	if len(matches) != 1 || matches[0].ID != cats.ID {
+		t.Fatalf("expected the semantically closest example, got %+v", matches)
+	}
+	s.Search(context.Background(), interfaces.ExampleQuery{Text: "kitten"})
+	if calls != 2 {
+		t.Fatalf("expected example embeddings to be reused, got %d calls", calls)
+	}
+
+	s.SetEmbedder(func(ctx context.Context, texts []string) ([][]float64, error) {
+		return nil, errors.New("no embeddings")
+	})
+	if got := s.Search(context.Background(), interfaces.ExampleQuery{Text: "dogs"}); len(got) != 1 || got[0].Input != "dogs" {
+		t.Fatalf("expected a failing embedder to fall back to BM25, got %+v", got)
+	}
+}
@@ -0,0 +1,127 @@
+package examples
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	gl "github.com/kubex-ecosystem/logz/logger"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"github.com/kubex-ecosystem/grompt/internal/vectorindex"
+)
+
+// DefaultK is the number of examples retrieved when a query leaves K unset
+const DefaultK = 3
+
+// EmbedFunc turns texts into vectors, one per text, in order
+type EmbedFunc func(ctx context.Context, texts []string) ([][]float64, error)
+
+// SetEmbedder switches retrieval to cosine similarity of the embeddings
+// returned by fn. A nil fn restores lexical BM25 retrieval.
+func (s *Store) SetEmbedder(fn EmbedFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.embed, s.vectors = fn, nil
+}
+
+// Search returns up to q.K examples of q.Purpose having every tag of q.Tags,
+// most relevant to q.Text first. Only examples that share something with the
+// text are returned. Relevance is BM25 over the input, output and tags of
+// each example, or cosine similarity when an embedder is set; when the
+// embedder fails the search falls back to BM25.
+func (s *Store) Search(ctx context.Context, q interfaces.ExampleQuery) []interfaces.ExampleMatch {
+	s.mu.Lock()
+	s.refreshLocked()
+	candidates := filter(s.examples, q.Purpose, q.Tags)
+	embed := s.embed
+	s.mu.Unlock()
+
+	k := q.K
+	if k <= 0 {
+		k = DefaultK
+	}
+	if len(candidates) == 0 || strings.TrimSpace(q.Text) == "" {
+		return []interfaces.ExampleMatch{}
+	}
+
+	var scores []float64
+	if embed != nil {
+		var err error
+		if scores, err = s.cosine(ctx, embed, q.Text, candidates); err != nil {
+			gl.Log("warn", fmt.Sprintf("Embedding retrieval failed, using BM25: %v", err))
+			scores = nil
+		}
+	}
+	if scores == nil {
+		docs := make([][]string, len(candidates))
+		for i, ex := range candidates {
+			docs[i] = vectorindex.Tokenize(document(ex))
+		}
+		scores = vectorindex.BM25(vectorindex.Tokenize(q.Text), docs)
+	}
+
+	matches := make([]interfaces.ExampleMatch, 0, len(candidates))
+	for i, ex := range candidates {
+		if scores[i] > 0 {
+			matches = append(matches, interfaces.ExampleMatch{Example: ex, Score: scores[i]})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	if len(matches) > k {
+		matches = matches[:k]
+	}
+	return matches
+}
+
+// Retrieve returns the examples of Search without their scores
+func (s *Store) Retrieve(ctx context.Context, q interfaces.ExampleQuery) []interfaces.Example {
+	matches := s.Search(ctx, q)
+	out := make([]interfaces.Example, len(matches))
+	for i, m := range matches {
+		out[i] = m.Example
+	}
+	return out
+}
+
+func document(ex interfaces.Example) string {
+	return ex.Input + "\n" + ex.Output + "\n" + strings.Join(ex.Tags, " ")
+}
+
+// cosine scores candidates by the cosine similarity of their embedding to
+// the text's. Example embeddings are computed once and kept in memory.
+func (s *Store) cosine(ctx context.Context, embed EmbedFunc, text string, candidates []interfaces.Example) ([]float64, error) {
+	s.mu.Lock()
+	texts := []string{text}
+	var missing []string
+	for _, ex := range candidates {
+		if _, ok := s.vectors[ex.ID]; !ok {
+			texts = append(texts, document(ex))
+			missing = append(missing, ex.ID)
+		}
+	}
+	s.mu.Unlock()
+
+	vectors, err := embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("embedder returned %d vectors for %d texts", len(vectors), len(texts))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.vectors == nil {
+		s.vectors = map[string][]float64{}
+	}
+	for i, id := range missing {
+		s.vectors[id] = vectors[i+1]
+	}
+	scores := make([]float64, len(candidates))
+	for i, ex := range candidates {
+		scores[i] = vectorindex.Cosine(vectors[0], s.vectors[ex.ID])
+	}
+	return scores, nil
+}
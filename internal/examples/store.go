@@ -0,0 +1,322 @@
+// Package examples implements the few-shot example library: input/output
+// pairs tagged by purpose type that are retrieved by relevance and injected
+// into prompts as demonstrations.
+package examples
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	gl "github.com/kubex-ecosystem/logz/logger"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"github.com/kubex-ecosystem/grompt/internal/metaprompt"
+	"github.com/kubex-ecosystem/grompt/internal/module/kbx"
+)
+
+// DefaultPath returns the example library location. GROMPT_EXAMPLES_FILE
+// overrides the default under ~/.kubex/grompt.
+func DefaultPath() string {
+	if path := os.Getenv("GROMPT_EXAMPLES_FILE"); path != "" {
+		return path
+	}
+	return os.ExpandEnv(kbx.DefaultGromptExamplesPath)
+}
+
+// Store keeps the example library as JSON Lines, one interfaces.Example per
+// line. The file is reloaded whenever it changes, so examples added by other
+// grompt processes are visible too. A Store without a path lives in memory.
+type Store struct {
+	path string
+
+	mu       sync.Mutex
+	examples []interfaces.Example
+	modTime  time.Time
+	size     int64
+
+	embed   EmbedFunc
+	vectors map[string][]float64 // example ID -> embedding
+}
+
+// NewStore opens the example library at path, creating its directory when
+// needed. A missing file is an empty library.
+func NewStore(path string) (*Store, error) {
+	if path == "" {
+		path = DefaultPath()
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create examples directory: %w", err)
+	}
+	s := &Store{path: path}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.reloadLocked(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// NewMemoryStore returns an empty library that is not persisted
+func NewMemoryStore() *Store { return &Store{} }
+
+// Open opens the library at DefaultPath. When it cannot be used, a warning is
+// logged and an in-memory library is returned instead.
+func Open() *Store {
+	s, err := NewStore(DefaultPath())
+	if err != nil {
+		gl.Log("warn", fmt.Sprintf("Example library unavailable, keeping it in memory: %v", err))
+		return NewMemoryStore()
+	}
+	return s
+}
+
+// Path returns the library file, empty for an in-memory library
+func (s *Store) Path() string { return s.path }
+
+// Add validates ex, assigns its ID and creation time, normalizes its purpose
+// type and appends it to the library.
+func (s *Store) Add(ex interfaces.Example) (interfaces.Example, error) {
+	ex.Input, ex.Output = strings.TrimSpace(ex.Input), strings.TrimSpace(ex.Output)
+	if ex.Input == "" || ex.Output == "" {
+		return ex, fmt.Errorf("an example needs both an input and an output")
+	}
+	if strings.TrimSpace(ex.Purpose) != "" {
+		ex.Purpose = metaprompt.Purpose(ex.Purpose)
+	} else {
+		ex.Purpose = ""
+	}
+	ex.Tags = normalizeTags(ex.Tags)
+	ex.ID = fmt.Sprintf("ex_%d", time.Now().UnixNano())
+	ex.CreatedAt = time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.reloadLocked(); err != nil {
+		return ex, err
+	}
+	if s.path != "" {
+		line, err := json.Marshal(ex)
+		if err != nil {
+			return ex, err
+		}
+		f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return ex, err
+		}
+		_, err = f.Write(append(line, '\n'))
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return ex, err
+		}
+		s.markLoadedLocked()
+	}
+	s.examples = append(s.examples, ex)
+	return ex, nil
+}
+
+// Remove deletes the example with the given ID or unique ID prefix
+func (s *Store) Remove(id string) (interfaces.Example, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.reloadLocked(); err != nil {
+		return interfaces.Example{}, err
+	}
+	i, ok := s.findLocked(id)
+	if !ok {
+		return interfaces.Example{}, fmt.Errorf("example %s not found (or the prefix is ambiguous)", id)
+	}
+	removed := s.examples[i]
+	kept := append(append([]interfaces.Example{}, s.examples[:i]...), s.examples[i+1:]...)
+	if s.path != "" {
+		if err := s.rewriteLocked(kept); err != nil {
+			return removed, err
+		}
+	}
+	s.examples = kept
+	delete(s.vectors, removed.ID)
+	return removed, nil
+}
+
+// Get returns the example with the given ID or unique ID prefix
+func (s *Store) Get(id string) (*interfaces.Example, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refreshLocked()
+	i, ok := s.findLocked(id)
+	if !ok {
+		return nil, false
+	}
+	ex := s.examples[i]
+	return &ex, true
+}
+
+// List returns the examples of a purpose type having every tag, oldest
+// first. Examples without a purpose type belong to every purpose.
+func (s *Store) List(purpose string, tags []string) []interfaces.Example {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refreshLocked()
+	return filter(s.examples, purpose, tags)
+}
+
+func filter(all []interfaces.Example, purpose string, tags []string) []interfaces.Example {
+	if strings.TrimSpace(purpose) != "" {
+		purpose = metaprompt.Purpose(purpose)
+	}
+	tags = normalizeTags(tags)
+	out := make([]interfaces.Example, 0, len(all))
+	for _, ex := range all {
+		if purpose != "" && ex.Purpose != "" && ex.Purpose != purpose {
+			continue
+		}
+		if !hasTags(ex.Tags, tags) {
+			continue
+		}
+		out = append(out, ex)
+	}
+	return out
+}
+
+func hasTags(have, want []string) bool {
+	for _, t := range want {
+		found := false
+		for _, h := range have {
+			if h == t {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func normalizeTags(tags []string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (s *Store) findLocked(id string) (int, bool) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return 0, false
+	}
+	found := -1
+	for i, ex := range s.examples {
+		if ex.ID == id {
+			return i, true
+		}
+		if strings.HasPrefix(ex.ID, id) {
+			if found >= 0 {
+				return 0, false
+			}
+			found = i
+		}
+	}
+	return found, found >= 0
+}
+
+// refreshLocked reloads the file, logging instead of failing for readers
+func (s *Store) refreshLocked() {
+	if err := s.reloadLocked(); err != nil {
+		gl.Log("warn", fmt.Sprintf("Failed to reload examples: %v", err))
+	}
+}
+
+// reloadLocked reads the whole file again when its size or modification
+// time changed since the last read. Corrupt lines are skipped.
+func (s *Store) reloadLocked() error {
+	if s.path == "" {
+		return nil
+	}
+	info, err := os.Stat(s.path)
+	if os.IsNotExist(err) {
+		s.examples, s.modTime, s.size = nil, time.Time{}, 0
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return nil
+	}
+
+	f, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var loaded []interfaces.Example
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			var ex interfaces.Example
+			if jerr := json.Unmarshal(line, &ex); jerr != nil {
+				gl.Log("warn", fmt.Sprintf("Skipping corrupt example line in %s: %v", s.path, jerr))
+			} else {
+				loaded = append(loaded, ex)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	s.examples, s.modTime, s.size = loaded, info.ModTime(), info.Size()
+	return nil
+}
+
+// rewriteLocked replaces the file with examples through a temporary file
+func (s *Store) rewriteLocked(examples []interfaces.Example) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, ex := range examples {
+		if err := enc.Encode(ex); err != nil {
+			return err
+		}
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	s.markLoadedLocked()
+	return nil
+}
+
+// markLoadedLocked records the current file state after our own write
+func (s *Store) markLoadedLocked() {
+	if info, err := os.Stat(s.path); err == nil {
+		s.modTime, s.size = info.ModTime(), info.Size()
+	}
+}
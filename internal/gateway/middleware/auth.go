@@ -0,0 +1,339 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/kubex-ecosystem/grompt/internal/module/kbx"
+)
+
+// Scopes a client may hold
+const (
+	ScopeChat  = "chat"  // chats, sessions, advice and own budget
+	ScopeState = "state" // /v1/state export and import
+	ScopeAdmin = "admin" // cache control and provider secrets; implies every scope
+)
+
+var knownScopes = []string{ScopeChat, ScopeState, ScopeAdmin}
+
+// Authentication errors
+var (
+	ErrUnauthenticated = errors.New("missing or invalid credentials")
+	ErrForbidden       = errors.New("credentials lack the required scope")
+	ErrLoginDisabled   = errors.New("token login is disabled: jwt.secret_env is not set")
+)
+
+// AuthConfig holds the credentials accepted from gateway clients. API keys
+// are sent as "Authorization: Bearer <key>" or x-api-key, JWTs as
+// "Authorization: Bearer <jwt>".
+type AuthConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Methods lists the accepted credentials, kbx.AuthTypeAPIKey and
+	// kbx.AuthTypeBearer (JWT); both by default
+	Methods []string  `yaml:"methods"`
+	Keys    []APIKey  `yaml:"keys"`
+	JWT     JWTConfig `yaml:"jwt"`
+}
+
+// APIKey is a client key stored by its hash, bound to a tenant and user
+type APIKey struct {
+	Name   string   `yaml:"name"`
+	Hash   string   `yaml:"hash"` // "sha256:<hex>", see HashAPIKey
+	Tenant string   `yaml:"tenant"`
+	User   string   `yaml:"user"`
+	Scopes []string `yaml:"scopes"`
+}
+
+// JWTConfig verifies bearer tokens and signs the ones of /v1/auth/login
+type JWTConfig struct {
+	// SecretEnv names the variable holding the HS256 secret. Without it
+	// /v1/auth/login is disabled.
+	SecretEnv string `yaml:"secret_env"`
+	// PublicKeyFile is an RSA public key (PEM) that verifies RS256 tokens
+	// issued by another service
+	PublicKeyFile string `yaml:"public_key_file"`
+	Issuer        string `yaml:"issuer"`
+	Audience      string `yaml:"audience"`
+	TTLMinutes    int    `yaml:"ttl_minutes"` // lifetime of login tokens
+}
+
+// Claims are the JWT claims read by the gateway: the user is the subject.
+// Scopes may also come as an OAuth space-separated "scope".
+type Claims struct {
+	Tenant string   `json:"tenant,omitempty"`
+	Scopes []string `json:"scopes,omitempty"`
+	Scope  string   `json:"scope,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Principal is the authenticated client of a request
+type Principal struct {
+	Tenant string   `json:"tenant,omitempty"`
+	User   string   `json:"user,omitempty"`
+	Scopes []string `json:"scopes"`
+	Method string   `json:"method"`        // kbx.AuthTypeAPIKey or kbx.AuthTypeBearer
+	Key    string   `json:"key,omitempty"` // name of the API key used
+}
+
+// Can reports whether the principal holds scope
+func (p *Principal) Can(scope string) bool {
+	return p != nil && (slices.Contains(p.Scopes, scope) || slices.Contains(p.Scopes, ScopeAdmin))
+}
+
+// Authenticator checks the credentials of gateway requests
+type Authenticator struct {
+	config    AuthConfig
+	keys      map[string]APIKey // by hex SHA-256 of the key
+	secret    []byte
+	publicKey *rsa.PublicKey
+	now       func() time.Time
+}
+
+// NewAuthenticator validates config and loads the JWT keys it names
+func NewAuthenticator(config AuthConfig) (*Authenticator, error) {
+	if len(config.Methods) == 0 {
+		config.Methods = []string{kbx.AuthTypeAPIKey, kbx.AuthTypeBearer}
+	}
+	if config.JWT.TTLMinutes <= 0 {
+		config.JWT.TTLMinutes = 60
+	}
+	a := &Authenticator{config: config, keys: make(map[string]APIKey), now: time.Now}
+
+	for _, m := range config.Methods {
+		switch m {
+		case kbx.AuthTypeAPIKey, kbx.AuthTypeBearer:
+		case kbx.AuthTypeOIDC:
+			return nil, fmt.Errorf("auth method %s is not supported; verify the identity provider's tokens with jwt.public_key_file", m)
+		default:
+			return nil, fmt.Errorf("unknown auth method %q", m)
+		}
+	}
+
+	for _, k := range config.Keys {
+		digest, ok := strings.CutPrefix(k.Hash, "sha256:")
+		if _, err := hex.DecodeString(digest); !ok || err != nil || len(digest) != sha256.Size*2 {
+			return nil, fmt.Errorf("api key %q: hash must be sha256:<64 hex digits>", k.Name)
+		}
+		if err := checkScopes(k.Scopes); err != nil {
+			return nil, fmt.Errorf("api key %q: %w", k.Name, err)
+		}
+		a.keys[strings.ToLower(digest)] = k
+	}
+
+	if env := config.JWT.SecretEnv; env != "" {
+		if a.secret = []byte(os.Getenv(env)); len(a.secret) == 0 {
+			return nil, fmt.Errorf("jwt secret variable %s is empty", env)
+		}
+	}
+	if path := config.JWT.PublicKeyFile; path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read jwt public key: %w", err)
+		}
+		if a.publicKey, err = jwt.ParseRSAPublicKeyFromPEM(data); err != nil {
+			return nil, fmt.Errorf("failed to parse jwt public key %s: %w", path, err)
+		}
+	}
+
+	// Tokens come from /v1/auth/login, which needs API keys, or from an
+	// external issuer
+	if len(a.keys) == 0 && (a.publicKey == nil || !a.accepts(kbx.AuthTypeBearer)) {
+		return nil, errors.New("auth is enabled but no api keys or external jwt issuer are configured")
+	}
+	if !a.accepts(kbx.AuthTypeAPIKey) && a.secret == nil && a.publicKey == nil {
+		return nil, errors.New("bearer auth needs jwt.secret_env or jwt.public_key_file")
+	}
+	return a, nil
+}
+
+func (a *Authenticator) accepts(method string) bool {
+	return slices.Contains(a.config.Methods, method)
+}
+
+// Authenticate returns the principal of r's credentials
+func (a *Authenticator) Authenticate(r *http.Request) (*Principal, error) {
+	credential := r.Header.Get("x-api-key")
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && credential == "" {
+		credential = strings.TrimSpace(bearer)
+	}
+	if credential == "" {
+		return nil, ErrUnauthenticated
+	}
+	if strings.Count(credential, ".") == 2 && a.accepts(kbx.AuthTypeBearer) {
+		return a.verifyToken(credential)
+	}
+	if a.accepts(kbx.AuthTypeAPIKey) {
+		return a.verifyKey(credential)
+	}
+	return nil, ErrUnauthenticated
+}
+
+func (a *Authenticator) verifyKey(key string) (*Principal, error) {
+	sum := sha256.Sum256([]byte(key))
+	k, ok := a.keys[hex.EncodeToString(sum[:])]
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+	return &Principal{Tenant: k.Tenant, User: k.User, Scopes: k.Scopes, Method: kbx.AuthTypeAPIKey, Key: k.Name}, nil
+}
+
+func (a *Authenticator) verifyToken(token string) (*Principal, error) {
+	opts := []jwt.ParserOption{jwt.WithTimeFunc(a.now), jwt.WithExpirationRequired()}
+	if a.config.JWT.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(a.config.JWT.Issuer))
+	}
+	if a.config.JWT.Audience != "" {
+		opts = append(opts, jwt.WithAudience(a.config.JWT.Audience))
+	}
+	var claims Claims
+	_, err := jwt.ParseWithClaims(token, &claims, func(t *jwt.Token) (any, error) {
+		switch t.Method.(type) {
+		case *jwt.SigningMethodHMAC:
+			if a.secret != nil {
+				return a.secret, nil
+			}
+		case *jwt.SigningMethodRSA:
+			if a.publicKey != nil {
+				return a.publicKey, nil
+			}
+		}
+		return nil, fmt.Errorf("unexpected signing method %s", t.Method.Alg())
+	}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+	scopes := claims.Scopes
+	if len(scopes) == 0 {
+		scopes = strings.Fields(claims.Scope)
+	}
+	return &Principal{Tenant: claims.Tenant, User: claims.Subject, Scopes: scopes, Method: kbx.AuthTypeBearer}, nil
+}
+
+// Login exchanges an API key for a signed JWT carrying its identity and
+// scopes, narrowed to scopes when given
+func (a *Authenticator) Login(key string, scopes []string) (string, time.Time, *Principal, error) {
+	if a.secret == nil {
+		return "", time.Time{}, nil, ErrLoginDisabled
+	}
+	p, err := a.verifyKey(key)
+	if err != nil {
+		return "", time.Time{}, nil, err
+	}
+	if len(scopes) > 0 {
+		for _, s := range scopes {
+			if !p.Can(s) {
+				return "", time.Time{}, nil, fmt.Errorf("%w: %s", ErrForbidden, s)
+			}
+		}
+		p.Scopes = scopes
+	}
+
+	now := a.now()
+	expires := now.Add(time.Duration(a.config.JWT.TTLMinutes) * time.Minute)
+	claims := Claims{
+		Tenant: p.Tenant,
+		Scopes: p.Scopes,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   p.User,
+			Issuer:    a.config.JWT.Issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expires),
+		},
+	}
+	if a.config.JWT.Audience != "" {
+		claims.Audience = jwt.ClaimStrings{a.config.JWT.Audience}
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(a.secret)
+	if err != nil {
+		return "", time.Time{}, nil, err
+	}
+	p.Method = kbx.AuthTypeBearer
+	return token, expires, p, nil
+}
+
+const principalKey = "grompt.principal"
+
+// Require authenticates the request and checks that it holds scope. The
+// tenant and user of the credentials replace the x-tenant-id and x-user-id
+// headers, so every handler down the chain sees the authenticated identity.
+// A nil Authenticator lets every request through with its headers as sent.
+func (a *Authenticator) Require(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if a == nil {
+			c.Next()
+			return
+		}
+		p, err := a.Authenticate(c.Request)
+		if err != nil {
+			c.Header("WWW-Authenticate", `Bearer realm="grompt"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		if !p.Can(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("%s: %s", ErrForbidden, scope)})
+			return
+		}
+		c.Set(principalKey, p)
+		setIdentity(c.Request.Header, "x-tenant-id", p.Tenant)
+		setIdentity(c.Request.Header, "x-user-id", p.User)
+		c.Next()
+	}
+}
+
+func setIdentity(h http.Header, key, value string) {
+	if value == "" {
+		h.Del(key)
+		return
+	}
+	h.Set(key, value)
+}
+
+// PrincipalFrom returns the principal Require bound to c, nil when auth is
+// disabled
+func PrincipalFrom(c *gin.Context) *Principal {
+	if v, ok := c.Get(principalKey); ok {
+		p, _ := v.(*Principal)
+		return p
+	}
+	return nil
+}
+
+// HashAPIKey is the form an API key is configured in
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// GenerateAPIKey returns a new random API key
+func GenerateAPIKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "gk_" + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func checkScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return errors.New("no scopes")
+	}
+	for _, s := range scopes {
+		if !slices.Contains(knownScopes, s) {
+			return fmt.Errorf("unknown scope %q", s)
+		}
+	}
+	return nil
+}
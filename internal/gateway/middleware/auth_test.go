@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kubex-ecosystem/grompt/internal/module/kbx"
+)
+
+func newTestAuth(t *testing.T) *Authenticator {
+	t.Helper()
+	t.Setenv("GROMPT_TEST_JWT_SECRET", "test-secret")
+	a, err := NewAuthenticator(AuthConfig{
+		Enabled: true,
+		Keys: []APIKey{
+			{Name: "ana-laptop", Hash: HashAPIKey("gk_ana"), Tenant: "acme", User: "ana", Scopes: []string{ScopeChat, ScopeState}},
+			{Name: "ops", Hash: HashAPIKey("gk_ops"), Tenant: "acme", Scopes: []string{ScopeAdmin}},
+		},
+		JWT: JWTConfig{SecretEnv: "GROMPT_TEST_JWT_SECRET", Issuer: "grompt"},
+	})
+	if err != nil {
+		t.Fatalf("NewAuthenticator: %v", err)
+	}
+	return a
+}
+
+// serve runs a request through Require(scope) and returns the status and the
+// identity headers the handler saw
+func serve(a *Authenticator, scope string, header http.Header) (int, string) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/", a.Require(scope), func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetHeader("x-tenant-id")+"/"+c.GetHeader("x-user-id"))
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header = header
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec.Code, rec.Body.String()
+}
+
+func TestRequireChecksKeysAndScopes(t *testing.T) {
+	a := newTestAuth(t)
+
+	if code, _ := serve(a, ScopeChat, http.Header{}); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without credentials, got %d", code)
+	}
+	if code, _ := serve(a, ScopeChat, http.Header{"X-Api-Key": {"gk_wrong"}}); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for an unknown key, got %d", code)
+	}
+	if code, _ := serve(a, ScopeAdmin, http.Header{"X-Api-Key": {"gk_ana"}}); code != http.StatusForbidden {
+		t.Fatalf("expected 403 without the admin scope, got %d", code)
+	}
+
+	// The key's identity replaces whatever the client claims
+	code, who := serve(a, ScopeChat, http.Header{"Authorization": {"Bearer gk_ana"}, "X-Tenant-Id": {"other"}, "X-User-Id": {"mallory"}})
+	if code != http.StatusOK || who != "acme/ana" {
+		t.Fatalf("expected acme/ana, got %d %q", code, who)
+	}
+	code, who = serve(a, ScopeState, http.Header{"X-Api-Key": {"gk_ops"}, "X-User-Id": {"mallory"}})
+	if code != http.StatusOK || who != "acme/" {
+		t.Fatalf("expected admin to imply state as acme without a user, got %d %q", code, who)
+	}
+
+	// Without an authenticator the headers are trusted as before
+	if code, who := serve(nil, ScopeAdmin, http.Header{"X-Tenant-Id": {"acme"}}); code != http.StatusOK || who != "acme/" {
+		t.Fatalf("expected a nil authenticator to let requests through, got %d %q", code, who)
+	}
+}
+
+func TestLoginIssuesScopedTokens(t *testing.T) {
+	a := newTestAuth(t)
+	now := time.Date(2025, 3, 31, 12, 0, 0, 0, time.UTC)
+	a.now = func() time.Time { return now }
+
+	token, expires, p, err := a.Login("gk_ana", []string{ScopeChat})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !expires.Equal(now.Add(time.Hour)) || p.Method != kbx.AuthTypeBearer {
+		t.Fatalf("unexpected login: %v %+v", expires, p)
+	}
+	if code, who := serve(a, ScopeChat, http.Header{"Authorization": {"Bearer " + token}}); code != http.StatusOK || who != "acme/ana" {
+		t.Fatalf("expected the token to authenticate acme/ana, got %d %q", code, who)
+	}
+	if code, _ := serve(a, ScopeState, http.Header{"Authorization": {"Bearer " + token}}); code != http.StatusForbidden {
+		t.Fatalf("expected the narrowed token to lack the state scope, got %d", code)
+	}
+
+	if _, _, _, err := a.Login("gk_ana", []string{ScopeAdmin}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected a token wider than the key to be refused, got %v", err)
+	}
+	if _, _, _, err := a.Login("gk_wrong", nil); !errors.Is(err, ErrUnauthenticated) {
+		t.Fatalf("expected an unknown key to be refused, got %v", err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if code, _ := serve(a, ScopeChat, http.Header{"Authorization": {"Bearer " + token}}); code != http.StatusUnauthorized {
+		t.Fatalf("expected an expired token to be refused, got %d", code)
+	}
+}
+
+func TestNewAuthenticatorValidatesConfig(t *testing.T) {
+	for name, cfg := range map[string]AuthConfig{
+		"plain key":     {Keys: []APIKey{{Name: "k", Hash: "gk_plain", Scopes: []string{ScopeChat}}}},
+		"unknown scope": {Keys: []APIKey{{Name: "k", Hash: HashAPIKey("x"), Scopes: []string{"root"}}}},
+		"no scopes":     {Keys: []APIKey{{Name: "k", Hash: HashAPIKey("x")}}},
+		"oidc":          {Methods: []string{kbx.AuthTypeOIDC}},
+		"nothing":       {Enabled: true},
+		"empty secret":  {Keys: []APIKey{{Name: "k", Hash: HashAPIKey("x"), Scopes: []string{ScopeChat}}}, JWT: JWTConfig{SecretEnv: "GROMPT_TEST_UNSET_SECRET"}},
+	} {
+		if _, err := NewAuthenticator(cfg); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if _, err := NewAuthenticator(AuthConfig{Keys: []APIKey{{Name: "k", Hash: strings.ToUpper(HashAPIKey("x")[7:]), Scopes: []string{ScopeChat}}}}); err == nil {
+		t.Error("expected a hash without the sha256: prefix to be rejected")
+	}
+}
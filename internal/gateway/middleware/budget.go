@@ -0,0 +1,449 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"github.com/kubex-ecosystem/grompt/internal/types"
+)
+
+// BudgetLimit caps spend in USD per period. Zero means no cap.
+type BudgetLimit struct {
+	DailyUSD   float64 `yaml:"daily_usd" json:"daily_usd,omitempty"`
+	MonthlyUSD float64 `yaml:"monthly_usd" json:"monthly_usd,omitempty"`
+}
+
+// BudgetConfig holds per-tenant and per-user spend limits. Tenants and users
+// are identified by the x-tenant-id and x-user-id headers; users are keyed
+// "tenant/user" or just "user".
+type BudgetConfig struct {
+	Enabled bool                   `yaml:"enabled"`
+	Tenant  BudgetLimit            `yaml:"tenant"` // default for every tenant
+	User    BudgetLimit            `yaml:"user"`   // default for every user
+	Tenants map[string]BudgetLimit `yaml:"tenants"`
+	Users   map[string]BudgetLimit `yaml:"users"`
+
+	// DowngradeAt is the fraction of a limit from which requests are sent to
+	// the cheaper model in Downgrade (provider -> model) instead; providers
+	// without one keep their model until the limit rejects them.
+	DowngradeAt float64           `yaml:"downgrade_at"`
+	Downgrade   map[string]string `yaml:"downgrade"`
+
+	// Prices override provider pricing, keyed by model or provider name
+	Prices map[string]interfaces.Pricing `yaml:"prices"`
+
+	// WarnAt lists the fractions of a limit that trigger a notification
+	WarnAt []float64 `yaml:"warn_at"`
+	Notify struct {
+		Type      string `yaml:"type"` // notifier to deliver through; empty only logs
+		Recipient string `yaml:"recipient"`
+	} `yaml:"notify"`
+
+	// StatePath persists spend across restarts; empty keeps it in memory
+	StatePath string `yaml:"state_path"`
+}
+
+// BudgetDecision is the outcome of checking a request against the budgets
+type BudgetDecision struct {
+	Allowed    bool   `json:"allowed"`
+	Model      string `json:"model,omitempty"` // model to use, the cheaper one when downgraded
+	Downgraded bool   `json:"downgraded,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	// ReservedUSD is held against the limits until Settle releases it
+	ReservedUSD float64 `json:"reserved_usd,omitempty"`
+}
+
+// BudgetStatus reports the spend of one subject in one period
+type BudgetStatus struct {
+	Subject  string  `json:"subject"` // "tenant:acme" or "user:acme/bob"
+	Period   string  `json:"period"`  // "daily" or "monthly"
+	SpentUSD float64 `json:"spent_usd"`
+	LimitUSD float64 `json:"limit_usd"`
+	// ReservedUSD is held by requests still in flight
+	ReservedUSD float64 `json:"reserved_usd,omitempty"`
+}
+
+// SpendRecord is the running spend of one subject
+type SpendRecord struct {
+	Day        string  `json:"day"`
+	DailyUSD   float64 `json:"daily_usd"`
+	Month      string  `json:"month"`
+	MonthlyUSD float64 `json:"monthly_usd"`
+}
+
+// roll resets the counters that belong to a past day or month
+func (r *SpendRecord) roll(now time.Time) {
+	if day := now.Format("2006-01-02"); r.Day != day {
+		r.Day, r.DailyUSD = day, 0
+	}
+	if month := now.Format("2006-01"); r.Month != month {
+		r.Month, r.MonthlyUSD = month, 0
+	}
+}
+
+// Merge combines two records of the same subject. Per period the later
+// record wins, and for the same period the higher spend, so merging a record
+// twice counts it once.
+func (r SpendRecord) Merge(other SpendRecord) SpendRecord {
+	if other.Day > r.Day || (other.Day == r.Day && other.DailyUSD > r.DailyUSD) {
+		r.Day, r.DailyUSD = other.Day, other.DailyUSD
+	}
+	if other.Month > r.Month || (other.Month == r.Month && other.MonthlyUSD > r.MonthlyUSD) {
+		r.Month, r.MonthlyUSD = other.Month, other.MonthlyUSD
+	}
+	return r
+}
+
+// BudgetManager tracks spend per tenant and user and enforces the limits
+type BudgetManager struct {
+	config   BudgetConfig
+	notifier interfaces.Notifier
+	now      func() time.Time
+
+	mu       sync.Mutex
+	spend    map[string]*SpendRecord
+	reserved map[string]float64 // held by requests in flight, by subject
+}
+
+// NewBudgetManager creates a budget manager. Warnings are delivered through
+// notifier, which may be nil to only log them. Saved spend is loaded from
+// config.StatePath when it exists.
+func NewBudgetManager(config BudgetConfig, notifier interfaces.Notifier) (*BudgetManager, error) {
+	if len(config.WarnAt) == 0 {
+		config.WarnAt = []float64{0.5, 0.8, 1}
+	}
+	sort.Float64s(config.WarnAt)
+
+	bm := &BudgetManager{
+		config:   config,
+		notifier: notifier,
+		now:      time.Now,
+		spend:    make(map[string]*SpendRecord),
+		reserved: make(map[string]float64),
+	}
+	if config.StatePath != "" {
+		data, err := os.ReadFile(config.StatePath)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read budget state: %w", err)
+		}
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &bm.spend); err != nil {
+				return nil, fmt.Errorf("failed to parse budget state %s: %w", config.StatePath, err)
+			}
+		}
+	}
+	return bm, nil
+}
+
+// subject is a tenant or user with its limit
+type subject struct {
+	key   string
+	limit BudgetLimit
+}
+
+func (bm *BudgetManager) subjects(tenant, user string) []subject {
+	if tenant == "" {
+		tenant = "default"
+	}
+	limit, ok := bm.config.Tenants[tenant]
+	if !ok {
+		limit = bm.config.Tenant
+	}
+	out := []subject{{key: "tenant:" + tenant, limit: limit}}
+
+	if user != "" {
+		limit, ok := bm.config.Users[tenant+"/"+user]
+		if !ok {
+			limit, ok = bm.config.Users[user]
+		}
+		if !ok {
+			limit = bm.config.User
+		}
+		out = append(out, subject{key: "user:" + tenant + "/" + user, limit: limit})
+	}
+	return out
+}
+
+// used returns the highest fraction of a limit the subject would reach after
+// spending extra on top of its spend and reservations, with the period and
+// limit it refers to.
+func (bm *BudgetManager) used(s subject, extra float64) (float64, string, float64) {
+	rec := bm.spend[s.key]
+	if rec == nil {
+		rec = &SpendRecord{}
+	}
+	rec.roll(bm.now())
+	extra += bm.reserved[s.key]
+
+	frac, period, limit := 0.0, "", 0.0
+	if s.limit.DailyUSD > 0 {
+		if f := (rec.DailyUSD + extra) / s.limit.DailyUSD; f > frac {
+			frac, period, limit = f, "daily", s.limit.DailyUSD
+		}
+	}
+	if s.limit.MonthlyUSD > 0 {
+		if f := (rec.MonthlyUSD + extra) / s.limit.MonthlyUSD; f > frac {
+			frac, period, limit = f, "monthly", s.limit.MonthlyUSD
+		}
+	}
+	return frac, period, limit
+}
+
+// Check decides whether a request estimated to cost at most estimateUSD may
+// go to provider/model. Requests that would cross a limit, counting the
+// reservations of requests in flight, are rejected; requests past
+// DowngradeAt are moved to the provider's downgrade model. An allowed
+// request reserves estimateUSD until it is passed to Settle, so concurrent
+// requests cannot overshoot a limit together.
+func (bm *BudgetManager) Check(tenant, user, provider, model string, estimateUSD float64) BudgetDecision {
+	decision := BudgetDecision{Allowed: true, Model: model}
+	if bm == nil {
+		return decision
+	}
+
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+
+	subjects := bm.subjects(tenant, user)
+	for _, s := range subjects {
+		frac, period, limit := bm.used(s, estimateUSD)
+		if frac >= 1 {
+			return BudgetDecision{
+				Model:  model,
+				Reason: fmt.Sprintf("%s %s budget of $%.2f is exhausted", s.key, period, limit),
+			}
+		}
+		cheaper := bm.config.Downgrade[provider]
+		if bm.config.DowngradeAt > 0 && frac >= bm.config.DowngradeAt && cheaper != "" && cheaper != model {
+			decision.Model, decision.Downgraded = cheaper, true
+			decision.Reason = fmt.Sprintf("%s has used %.0f%% of its %s budget", s.key, frac*100, period)
+		}
+	}
+	if estimateUSD > 0 {
+		for _, s := range subjects {
+			bm.reserved[s.key] += estimateUSD
+		}
+		decision.ReservedUSD = estimateUSD
+	}
+	return decision
+}
+
+// Cost prices usage on provider/model, preferring configured prices for the
+// model, then the provider, then the provider's built-in pricing.
+func (bm *BudgetManager) Cost(provider, model string, usage *interfaces.Usage) float64 {
+	if bm == nil || usage == nil {
+		return 0
+	}
+	if usage.CostUSD > 0 {
+		return usage.CostUSD
+	}
+	if p, ok := bm.config.Prices[model]; ok && model != "" {
+		return p.Cost(usage)
+	}
+	if p, ok := bm.config.Prices[provider]; ok {
+		return p.Cost(usage)
+	}
+	return types.PricingForProvider(provider).Cost(usage)
+}
+
+// Record adds spend to the tenant and user and sends a warning for every
+// threshold in WarnAt the spend crossed.
+func (bm *BudgetManager) Record(ctx context.Context, tenant, user string, usd float64) error {
+	return bm.Settle(ctx, tenant, user, 0, usd)
+}
+
+// Settle ends a request checked by Check: it releases the reservedUSD of its
+// decision and records the usd it actually cost, which is zero for requests
+// that failed or were answered from a cache.
+func (bm *BudgetManager) Settle(ctx context.Context, tenant, user string, reservedUSD, usd float64) error {
+	if bm == nil || (usd <= 0 && reservedUSD <= 0) {
+		return nil
+	}
+
+	type crossing struct {
+		subject   string
+		period    string
+		threshold float64
+		spent     float64
+		limit     float64
+	}
+	var warnings []crossing
+
+	bm.mu.Lock()
+	now := bm.now()
+	for _, s := range bm.subjects(tenant, user) {
+		if reservedUSD > 0 {
+			// Rounding must not leave a residue that blocks the subject
+			if bm.reserved[s.key] -= reservedUSD; bm.reserved[s.key] < 1e-9 {
+				delete(bm.reserved, s.key)
+			}
+		}
+		if usd <= 0 {
+			continue
+		}
+		rec := bm.spend[s.key]
+		if rec == nil {
+			rec = &SpendRecord{}
+			bm.spend[s.key] = rec
+		}
+		rec.roll(now)
+
+		for _, p := range []struct {
+			name  string
+			spent *float64
+			limit float64
+		}{{"daily", &rec.DailyUSD, s.limit.DailyUSD}, {"monthly", &rec.MonthlyUSD, s.limit.MonthlyUSD}} {
+			before := *p.spent
+			*p.spent += usd
+			if p.limit <= 0 {
+				continue
+			}
+			// Only the highest threshold crossed by this request is reported
+			for i := len(bm.config.WarnAt) - 1; i >= 0; i-- {
+				t := bm.config.WarnAt[i]
+				if before < t*p.limit && *p.spent >= t*p.limit {
+					warnings = append(warnings, crossing{s.key, p.name, t, *p.spent, p.limit})
+					break
+				}
+			}
+		}
+	}
+	var err error
+	if usd > 0 {
+		err = bm.save()
+	}
+	bm.mu.Unlock()
+
+	for _, w := range warnings {
+		priority := "medium"
+		if w.threshold >= 1 {
+			priority = "critical"
+		}
+		event := interfaces.NotificationEvent{
+			Type:      bm.config.Notify.Type,
+			Recipient: bm.config.Notify.Recipient,
+			Subject:   fmt.Sprintf("Budget alert: %s at %.0f%% of its %s limit", w.subject, w.threshold*100, w.period),
+			Content:   fmt.Sprintf("%s has spent $%.4f of its $%.2f %s budget.", w.subject, w.spent, w.limit, w.period),
+			Priority:  priority,
+			Metadata: map[string]interface{}{
+				"subject":   w.subject,
+				"period":    w.period,
+				"threshold": w.threshold,
+				"spent_usd": w.spent,
+				"limit_usd": w.limit,
+			},
+			CreatedAt: now,
+		}
+		log.Printf("[Budget] %s", event.Subject)
+		if bm.notifier != nil && event.Type != "" {
+			if nerr := bm.notifier.Notify(ctx, event); nerr != nil {
+				log.Printf("[Budget] failed to deliver alert: %v", nerr)
+			}
+		}
+	}
+	return err
+}
+
+// Status reports the spend of a tenant and user against their limits
+func (bm *BudgetManager) Status(tenant, user string) []BudgetStatus {
+	if bm == nil {
+		return nil
+	}
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+
+	var out []BudgetStatus
+	for _, s := range bm.subjects(tenant, user) {
+		rec := SpendRecord{}
+		if r := bm.spend[s.key]; r != nil {
+			rec = *r
+		}
+		rec.roll(bm.now())
+		reserved := bm.reserved[s.key]
+		out = append(out,
+			BudgetStatus{Subject: s.key, Period: "daily", SpentUSD: rec.DailyUSD, LimitUSD: s.limit.DailyUSD, ReservedUSD: reserved},
+			BudgetStatus{Subject: s.key, Period: "monthly", SpentUSD: rec.MonthlyUSD, LimitUSD: s.limit.MonthlyUSD, ReservedUSD: reserved},
+		)
+	}
+	return out
+}
+
+// Ledger returns the recorded spend of a tenant keyed by user, with the
+// tenant's own record under ""
+func (bm *BudgetManager) Ledger(tenant string) map[string]SpendRecord {
+	if bm == nil {
+		return nil
+	}
+	if tenant == "" {
+		tenant = "default"
+	}
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+
+	out := map[string]SpendRecord{}
+	for key, rec := range bm.spend {
+		if key == "tenant:"+tenant {
+			out[""] = *rec
+		} else if user, ok := strings.CutPrefix(key, "user:"+tenant+"/"); ok {
+			out[user] = *rec
+		}
+	}
+	return out
+}
+
+// RestoreLedger merges spend recorded elsewhere into the record of a tenant
+// or, with user set, one of its users, and reports whether it changed
+
+func (bm *BudgetManager) RestoreLedger(tenant, user string, rec SpendRecord) (bool, error) {
+	if bm == nil {
+		return false, nil
+	}
+	if tenant == "" {
+		tenant = "default"
+	}
+	key := "tenant:" + tenant
+	if user != "" {
+		key = "user:" + tenant + "/" + user
+	}
+
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+	cur := bm.spend[key]
+	if cur == nil {
+		cur = &SpendRecord{}
+	}
+	merged := cur.Merge(rec)
+	if merged == *cur && bm.spend[key] != nil {
+		return false, nil
+	}
+	bm.spend[key] = &merged
+	return true, bm.save()
+}
+
+// save writes the spend to StatePath; the caller holds bm.mu
+func (bm *BudgetManager) save() error {
+	if bm.config.StatePath == "" {
+		return nil
+	}
+	data, err := json.Marshal(bm.spend)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(bm.config.StatePath), 0o755); err != nil {
+		return fmt.Errorf("failed to save budget state: %w", err)
+	}
+	tmp := bm.config.StatePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to save budget state: %w", err)
+	}
+	return os.Rename(tmp, bm.config.StatePath)
+}
@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+)
+
+type recordingNotifier struct {
+	events []interfaces.NotificationEvent
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, event interfaces.NotificationEvent) error {
+	n.events = append(n.events, event)
+	return nil
+}
+
+func newTestBudget(t *testing.T, cfg BudgetConfig, notifier interfaces.Notifier) (*BudgetManager, *time.Time) {
+	t.Helper()
+	bm, err := NewBudgetManager(cfg, notifier)
+	if err != nil {
+		t.Fatalf("NewBudgetManager: %v", err)
+	}
+	now := time.Date(2025, 3, 31, 12, 0, 0, 0, time.UTC)
+	bm.now = func() time.Time { return now }
+	return bm, &now
+}
+
+func TestBudgetRejectsOverLimit(t *testing.T) {
+	cfg := BudgetConfig{Enabled: true, Tenant: BudgetLimit{DailyUSD: 1}, Users: map[string]BudgetLimit{"acme/bob": {DailyUSD: 0.5}}}
+	bm, _ := newTestBudget(t, cfg, nil)
+	ctx := context.Background()
+
+	if d := bm.Check("acme", "bob", "openai", "gpt-4o", 0.1); !d.Allowed {
+		t.Fatalf("fresh budget should allow: %+v", d)
+	}
+	bm.Record(ctx, "acme", "bob", 0.45)
+
+	// bob is capped at $0.50 even though the tenant has room
+	if d := bm.Check("acme", "bob", "openai", "gpt-4o", 0.1); d.Allowed || d.Reason == "" {
+		t.Fatalf("user limit should reject: %+v", d)
+	}
+	if d := bm.Check("acme", "alice", "openai", "gpt-4o", 0.1); !d.Allowed {
+		t.Fatalf("other users share only the tenant limit: %+v", d)
+	}
+
+	bm.Record(ctx, "acme", "alice", 0.6)
+	if d := bm.Check("acme", "alice", "openai", "gpt-4o", 0); d.Allowed {
+		t.Fatalf("tenant limit should reject once spent: %+v", d)
+	}
+}
+
+func TestBudgetReservesInFlightRequests(t *testing.T) {
+	bm, _ := newTestBudget(t, BudgetConfig{Tenant: BudgetLimit{DailyUSD: 1}}, nil)
+	ctx := context.Background()
+
+	// Three concurrent requests of up to $0.40: the third would overshoot
+	first := bm.Check("acme", "", "openai", "gpt-4o", 0.4)
+	second := bm.Check("acme", "", "openai", "gpt-4o", 0.4)
+	if !first.Allowed || !second.Allowed || first.ReservedUSD != 0.4 {
+		t.Fatalf("expected the first two requests to reserve, got %+v %+v", first, second)
+	}
+	if d := bm.Check("acme", "", "openai", "gpt-4o", 0.4); d.Allowed || d.ReservedUSD != 0 {
+		t.Fatalf("expected the reservations to reject a third request, got %+v", d)
+	}
+	if got := bm.Status("acme", "")[0]; got.ReservedUSD != 0.8 || got.SpentUSD != 0 {
+		t.Fatalf("unexpected status %+v", got)
+	}
+
+	// Settling records the actual cost and frees the rest of the reservation
+	bm.Settle(ctx, "acme", "", first.ReservedUSD, 0.1)
+	bm.Settle(ctx, "acme", "", second.ReservedUSD, 0)
+	if got := bm.Status("acme", "")[0]; got.ReservedUSD != 0 || got.SpentUSD != 0.1 {
+		t.Fatalf("expected the reservations to be settled, got %+v", got)
+	}
+	if d := bm.Check("acme", "", "openai", "gpt-4o", 0.85); !d.Allowed {
+		t.Fatalf("expected released budget to be usable, got %+v", d)
+	}
+}
+
+func TestBudgetDowngradesNearLimit(t *testing.T) {
+	cfg := BudgetConfig{
+		Tenant:      BudgetLimit{MonthlyUSD: 10},
+		DowngradeAt: 0.8,
+		Downgrade:   map[string]string{"openai": "gpt-4o-mini"},
+	}
+	bm, _ := newTestBudget(t, cfg, nil)
+	bm.Record(context.Background(), "acme", "", 8.5)
+
+	d := bm.Check("acme", "", "openai", "gpt-4o", 0.01)
+	if !d.Allowed || !d.Downgraded || d.Model != "gpt-4o-mini" {
+		t.Fatalf("expected a downgrade, got %+v", d)
+	}
+	// Providers without a cheaper model keep theirs until the limit
+	if d := bm.Check("acme", "", "claude", "claude-3-opus", 0.01); !d.Allowed || d.Downgraded {
+		t.Fatalf("expected claude to pass unchanged, got %+v", d)
+	}
+}
+
+func TestBudgetRollsOverAndWarns(t *testing.T) {
+	notifier := &recordingNotifier{}
+	cfg := BudgetConfig{Tenant: BudgetLimit{DailyUSD: 1, MonthlyUSD: 100}, WarnAt: []float64{0.5, 1}}
+	cfg.Notify.Type = "discord"
+	bm, now := newTestBudget(t, cfg, notifier)
+	ctx := context.Background()
+
+	bm.Record(ctx, "acme", "", 0.3)
+	if len(notifier.events) != 0 {
+		t.Fatalf("no threshold crossed yet, got %v", notifier.events)
+	}
+	bm.Record(ctx, "acme", "", 0.9) // crosses 50% and 100% at once: only 100% is reported
+	if len(notifier.events) != 1 || notifier.events[0].Priority != "critical" || notifier.events[0].Metadata["period"] != "daily" {
+		t.Fatalf("expected one critical daily alert, got %+v", notifier.events)
+	}
+
+	// A new day resets the daily spend, a new month the monthly one
+	*now = now.Add(24 * time.Hour)
+	status := bm.Status("acme", "")
+	if status[0].SpentUSD != 0 || status[1].SpentUSD != 0 {
+		t.Fatalf("expected spend to roll over into April, got %+v", status)
+	}
+	if d := bm.Check("acme", "", "openai", "", 0.1); !d.Allowed {
+		t.Fatalf("expected a fresh day to allow requests: %+v", d)
+	}
+}
+
+func TestBudgetPersistsSpend(t *testing.T) {
+	cfg := BudgetConfig{Tenant: BudgetLimit{DailyUSD: 1}, StatePath: filepath.Join(t.TempDir(), "budget.json")}
+	bm, _ := newTestBudget(t, cfg, nil)
+	if err := bm.Record(context.Background(), "acme", "", 0.75); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	reloaded, _ := newTestBudget(t, cfg, nil)
+	if got := reloaded.Status("acme", "")[0].SpentUSD; got != 0.75 {
+		t.Fatalf("expected persisted spend of 0.75, got %v", got)
+	}
+}
+
+func TestBudgetCost(t *testing.T) {
+	cfg := BudgetConfig{Prices: map[string]interfaces.Pricing{"gpt-4o-mini": {InputCostPer1K: 0.001, OutputCostPer1K: 0.002}}}
+	bm, _ := newTestBudget(t, cfg, nil)
+	usage := &interfaces.Usage{Prompt: 1000, Completion: 500}
+
+	if got := bm.Cost("openai", "gpt-4o-mini", usage); got != 0.002 {
+		t.Fatalf("expected configured model price, got %v", got)
+	}
+	if got := bm.Cost("openai", "gpt-4o", usage); got != 0.06 {
+		t.Fatalf("expected built-in provider price, got %v", got)
+	}
+	if got := bm.Cost("unknown", "", usage); got != 0 {
+		t.Fatalf("unknown providers cost nothing, got %v", got)
+	}
+}
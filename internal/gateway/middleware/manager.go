@@ -3,7 +3,13 @@ package middleware
 import (
 	"context"
 	"fmt"
+	"os"
 	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/kubex-ecosystem/grompt/internal/cache"
+	"github.com/kubex-ecosystem/grompt/internal/gateway/session"
 )
 
 // ProductionConfig holds all production middleware configuration
@@ -47,6 +53,17 @@ type ProductionConfig struct {
 		MaxDelayMs  int     `yaml:"max_delay_ms"`
 		Multiplier  float64 `yaml:"multiplier"`
 	} `yaml:"retry"`
+
+	Budget BudgetConfig `yaml:"budget"`
+
+	// Cache answers repeated low-temperature chats without a provider call
+	Cache cache.Config `yaml:"cache"`
+
+	// Sessions keep chat history server-side for /v1/session and /v1/chat
+	Sessions session.Config `yaml:"sessions"`
+
+	// Auth requires API keys or JWTs from clients and binds their identity
+	Auth AuthConfig `yaml:"auth"`
 }
 
 // DefaultProductionConfig returns a sensible default configuration
@@ -76,9 +93,33 @@ func DefaultProductionConfig() ProductionConfig {
 	config.Retry.MaxDelayMs = 5000
 	config.Retry.Multiplier = 2.0
 
+	// Budgets are opt-in; warn at half, 80% and the full limit
+	config.Budget.WarnAt = []float64{0.5, 0.8, 1}
+	config.Budget.DowngradeAt = 0.8
+
+	// The response cache is opt-in too: set cache.backend to memory or disk
+	config.Cache = cache.DefaultConfig()
+
+	// Sessions live in memory unless sessions.state_path is set
+	config.Sessions = session.DefaultConfig()
+
 	return config
 }
 
+// LoadProductionConfig reads a YAML file over the defaults, so it only needs
+// the settings that differ.
+func LoadProductionConfig(path string) (ProductionConfig, error) {
+	config := DefaultProductionConfig()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, fmt.Errorf("failed to read middleware config %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("failed to parse middleware config %s: %w", path, err)
+	}
+	return config, nil
+}
+
 // ProductionMiddleware wraps all production middleware functionality
 type ProductionMiddleware struct {
 	config         ProductionConfig
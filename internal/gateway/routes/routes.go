@@ -3,7 +3,6 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
-	"github.com/kubex-ecosystem/grompt/internal/gateway/registry"
 	"github.com/kubex-ecosystem/grompt/internal/gateway/transport"
 )
 
@@ -11,15 +10,16 @@ import (
 // A ideia é manter um ponto único para evoluir, versionar ou adicionar
 // novos grupos de rotas sem precisar tocar diretamente no servidor.
 type GatewayRoutes struct {
-	registry *registry.Registry
+	deps transport.Deps
 }
 
-// NewGatewayRoutes cria um registrador de rotas para o gateway.
-func NewGatewayRoutes(reg *registry.Registry) *GatewayRoutes {
-	return &GatewayRoutes{registry: reg}
+// NewGatewayRoutes cria um registrador de rotas para o gateway a partir dos
+// serviços em deps; veja transport.Deps para os que podem ser nil.
+func NewGatewayRoutes(deps transport.Deps) *GatewayRoutes {
+	return &GatewayRoutes{deps: deps}
 }
 
 // Register injeta todas as rotas conhecidas no router informado.
 func (gr *GatewayRoutes) Register(router gin.IRouter) {
-	transport.WireHTTPSSE(router, gr.registry)
+	transport.WireHTTPSSE(router, gr.deps)
 }
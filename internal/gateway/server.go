@@ -9,9 +9,17 @@ import (
 	"syscall"
 
 	"github.com/gin-gonic/gin"
+	"github.com/kubex-ecosystem/grompt/internal/cache"
 	"github.com/kubex-ecosystem/grompt/internal/gateway/middleware"
 	"github.com/kubex-ecosystem/grompt/internal/gateway/registry"
 	"github.com/kubex-ecosystem/grompt/internal/gateway/routes"
+	"github.com/kubex-ecosystem/grompt/internal/gateway/session"
+	"github.com/kubex-ecosystem/grompt/internal/gateway/state"
+	"github.com/kubex-ecosystem/grompt/internal/gateway/transport"
+	"github.com/kubex-ecosystem/grompt/internal/history"
+	"github.com/kubex-ecosystem/grompt/internal/templates"
+	"github.com/kubex-ecosystem/grompt/internal/vectorindex"
+	"github.com/kubex-ecosystem/grompt/utils"
 )
 
 // ServerConfig holds configuration for the gateway server
@@ -20,6 +28,9 @@ type ServerConfig struct {
 	ProvidersConfig string
 	Debug           bool
 	EnableCORS      bool
+	// MiddlewareConfig is an optional YAML file with rate limit, circuit
+	// breaker, retry, budget and cache settings; defaults apply when empty
+	MiddlewareConfig string
 }
 
 // Server represents the gateway server
@@ -47,8 +58,58 @@ func NewServer(config *ServerConfig) (*Server, error) {
 
 	// Initialize production middleware
 	prodConfig := middleware.DefaultProductionConfig()
+	if config.MiddlewareConfig != "" {
+		if prodConfig, err = middleware.LoadProductionConfig(config.MiddlewareConfig); err != nil {
+			return nil, err
+		}
+	}
 	prodMiddleware := middleware.NewProductionMiddleware(prodConfig)
 
+	// Spend limits per tenant/user; alerts go out through the providers' notifiers
+	var budget *middleware.BudgetManager
+	if prodConfig.Budget.Enabled {
+		if budget, err = middleware.NewBudgetManager(prodConfig.Budget, reg); err != nil {
+			return nil, err
+		}
+	}
+
+	// Response cache in front of the providers' Chat
+	var responses *cache.Cache
+	if prodConfig.Cache.Backend != "" {
+		if responses, err = cache.New(prodConfig.Cache); err != nil {
+			return nil, err
+		}
+	}
+
+	// Conversation history for /v1/session and session chats
+	sessions, err := session.NewStore(prodConfig.Sessions)
+	if err != nil {
+		return nil, err
+	}
+
+	// Archives of sessions, history, templates, providers and spend for /v1/state
+	archiver := state.New(state.Sources{
+		Sessions:  sessions,
+		History:   history.Open(),
+		Templates: templates.NewManager(utils.GetEnvOr("GROMPT_TEMPLATES_DIR", "./templates")),
+		Registry:  reg,
+		Budget:    budget,
+	})
+
+	// Client credentials; without them anyone reaching the port spends the
+	// providers' keys
+	var auth *middleware.Authenticator
+	if prodConfig.Auth.Enabled {
+		if auth, err = middleware.NewAuthenticator(prodConfig.Auth); err != nil {
+			return nil, err
+		}
+	} else {
+		log.Println("⚠️  Gateway auth is disabled: every client is trusted")
+	}
+
+	// Local vector indexes for /v1/index and /v1/search
+	indexes := vectorindex.NewCatalog("")
+
 	// Register all providers with production middleware
 	for _, providerName := range reg.ListProviders() {
 		prodMiddleware.RegisterProvider(providerName)
@@ -59,7 +120,15 @@ func NewServer(config *ServerConfig) (*Server, error) {
 		registry:   reg,
 		middleware: prodMiddleware,
 		router:     router,
-		routes:     routes.NewGatewayRoutes(reg),
+		routes: routes.NewGatewayRoutes(transport.Deps{
+			Registry: reg,
+			Budget:   budget,
+			Cache:    responses,
+			Sessions: sessions,
+			Archiver: archiver,
+			Auth:     auth,
+			Indexes:  indexes,
+		}),
 	}, nil
 }
 
@@ -89,7 +158,7 @@ func (s *Server) Start() error {
 func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Headers", "content-type, authorization, x-external-api-key, x-tenant-id, x-user-id")
+		c.Header("Access-Control-Allow-Headers", "content-type, authorization, cache-control, x-api-key, x-external-api-key, x-tenant-id, x-user-id")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
 		if c.Request.Method == http.MethodOptions {
 			c.AbortWithStatus(http.StatusNoContent)
@@ -0,0 +1,282 @@
+// Package session keeps server-side conversation history for the gateway, so
+// clients send only the new turn of a chat.
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"github.com/kubex-ecosystem/grompt/internal/tokenizer"
+)
+
+// ErrNotFound is returned for sessions that do not exist or belong to another
+// tenant
+var ErrNotFound = errors.New("session not found")
+
+// ErrTaken is returned by Restore for an ID held by another tenant's session
+var ErrTaken = errors.New("session id belongs to another tenant")
+
+// Config holds the session store settings
+type Config struct {
+	// StatePath persists sessions across restarts; empty keeps them in memory
+	StatePath string `yaml:"state_path"`
+	// MaxPerTenant caps the sessions of a tenant; the least recently used
+	// one is dropped to make room. Zero means no cap.
+	MaxPerTenant int `yaml:"max_per_tenant"`
+	// ReplyTokens is the room kept free for the reply when history is
+	// trimmed to the context window
+	ReplyTokens int `yaml:"reply_tokens"`
+}
+
+// DefaultConfig keeps sessions in memory with room for 4096 reply tokens
+func DefaultConfig() Config {
+	return Config{ReplyTokens: 4096}
+}
+
+// Session is one conversation of a tenant
+type Session struct {
+	ID       string               `json:"id"`
+	Tenant   string               `json:"tenant,omitempty"`
+	User     string               `json:"user,omitempty"`
+	Title    string               `json:"title,omitempty"`
+	Provider string               `json:"provider,omitempty"`
+	Model    string               `json:"model,omitempty"`
+	Messages []interfaces.Message `json:"messages"`
+	// Trimmed counts the messages dropped to fit the context window
+	Trimmed   int       `json:"trimmed,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Summary describes a session without its messages
+type Summary struct {
+	ID        string    `json:"id"`
+	User      string    `json:"user,omitempty"`
+	Title     string    `json:"title,omitempty"`
+	Provider  string    `json:"provider,omitempty"`
+	Model     string    `json:"model,omitempty"`
+	Messages  int       `json:"messages"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Store holds sessions keyed by ID. Every operation is scoped to a tenant.
+type Store struct {
+	mu       sync.Mutex
+	config   Config
+	sessions map[string]*Session
+	now      func() time.Time
+}
+
+// NewStore creates a session store, loading config.StatePath when it exists
+func NewStore(config Config) (*Store, error) {
+	if config.ReplyTokens <= 0 {
+		config.ReplyTokens = DefaultConfig().ReplyTokens
+	}
+	s := &Store{config: config, sessions: make(map[string]*Session), now: time.Now}
+	if config.StatePath != "" {
+		data, err := os.ReadFile(config.StatePath)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			return nil, fmt.Errorf("failed to read sessions %s: %w", config.StatePath, err)
+		default:
+			if err := json.Unmarshal(data, &s.sessions); err != nil {
+				return nil, fmt.Errorf("failed to parse sessions %s: %w", config.StatePath, err)
+			}
+		}
+	}
+	return s, nil
+}
+
+// Create starts a session, optionally seeded with messages such as a system
+// prompt
+func (s *Store) Create(tenant, user, title, provider, model string, messages []interfaces.Message) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	sess := &Session{
+		ID:        uuid.NewString(),
+		Tenant:    tenant,
+		User:      user,
+		Title:     title,
+		Provider:  provider,
+		Model:     model,
+		Messages:  append([]interfaces.Message{}, messages...),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.trim(sess)
+	s.sessions[sess.ID] = sess
+	s.evict(tenant)
+	return sess.copy(), s.save()
+}
+
+// Get returns a copy of a session of tenant
+func (s *Store) Get(tenant, id string) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[id]
+	if !ok || sess.Tenant != tenant {
+		return nil, ErrNotFound
+	}
+	return sess.copy(), nil
+}
+
+// List returns the sessions of tenant, most recently used first
+func (s *Store) List(tenant string) []Summary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := []Summary{}
+	for _, sess := range s.sessions {
+		if sess.Tenant != tenant {
+			continue
+		}
+		out = append(out, Summary{
+			ID:        sess.ID,
+			User:      sess.User,
+			Title:     sess.Title,
+			Provider:  sess.Provider,
+			Model:     sess.Model,
+			Messages:  len(sess.Messages),
+			CreatedAt: sess.CreatedAt,
+			UpdatedAt: sess.UpdatedAt,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].UpdatedAt.After(out[j].UpdatedAt) })
+	return out
+}
+
+// Delete removes a session of tenant
+func (s *Store) Delete(tenant, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sess, ok := s.sessions[id]; !ok || sess.Tenant != tenant {
+		return ErrNotFound
+	}
+	delete(s.sessions, id)
+	return s.save()
+}
+
+// Append adds messages to a session of tenant and trims its history to the
+// context window of the session's model
+func (s *Store) Append(tenant, id string, messages ...interfaces.Message) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[id]
+	if !ok || sess.Tenant != tenant {
+		return nil, ErrNotFound
+	}
+	sess.Messages = append(sess.Messages, messages...)
+	sess.UpdatedAt = s.now()
+	s.trim(sess)
+	return sess.copy(), s.save()
+}
+
+// All returns full copies of the sessions of tenant, oldest first
+func (s *Store) All(tenant string) []*Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := []*Session{}
+	for _, sess := range s.sessions {
+		if sess.Tenant == tenant {
+			out = append(out, sess.copy())
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out
+}
+
+// Restore stores sess as given, replacing the session of the same tenant and
+// ID. Used to import sessions saved elsewhere.
+func (s *Store) Restore(sess *Session) error {
+	if sess == nil || sess.ID == "" {
+		return errors.New("session without id")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cur, ok := s.sessions[sess.ID]; ok && cur.Tenant != sess.Tenant {
+		return ErrTaken
+	}
+	s.sessions[sess.ID] = sess.copy()
+	s.evict(sess.Tenant)
+	return s.save()
+}
+
+// Conversation is the history of sess followed by turn, trimmed to the
+// context window of provider/model with room for the reply
+func (s *Store) Conversation(sess *Session, turn []interfaces.Message, provider, model string) ([]interfaces.Message, int) {
+	messages := append(append([]interfaces.Message{}, sess.Messages...), turn...)
+	return tokenizer.TrimMessages(tokenizer.For(provider, model), messages, s.budget(provider, model))
+}
+
+// budget is the prompt room of a model's window, 0 when it is unknown
+func (s *Store) budget(provider, model string) int {
+	window := tokenizer.ContextWindow(provider, model)
+	if window == 0 {
+		return 0
+	}
+	return window - min(s.config.ReplyTokens, window/2)
+}
+
+// trim drops the oldest turns that no longer fit; the caller holds s.mu
+func (s *Store) trim(sess *Session) {
+	kept, dropped := tokenizer.TrimMessages(tokenizer.For(sess.Provider, sess.Model), sess.Messages, s.budget(sess.Provider, sess.Model))
+	sess.Messages = kept
+	sess.Trimmed += dropped
+}
+
+// evict drops the least recently used sessions of tenant above
+// MaxPerTenant; the caller holds s.mu
+func (s *Store) evict(tenant string) {
+	if s.config.MaxPerTenant <= 0 {
+		return
+	}
+	var owned []*Session
+	for _, sess := range s.sessions {
+		if sess.Tenant == tenant {
+			owned = append(owned, sess)
+		}
+	}
+	if len(owned) <= s.config.MaxPerTenant {
+		return
+	}
+	sort.Slice(owned, func(i, j int) bool { return owned[i].UpdatedAt.Before(owned[j].UpdatedAt) })
+	for _, sess := range owned[:len(owned)-s.config.MaxPerTenant] {
+		delete(s.sessions, sess.ID)
+	}
+}
+
+// save writes the sessions to StatePath; the caller holds s.mu
+func (s *Store) save() error {
+	if s.config.StatePath == "" {
+		return nil
+	}
+	data, err := json.Marshal(s.sessions)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.config.StatePath), 0o755); err != nil {
+		return err
+	}
+	tmp := s.config.StatePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.config.StatePath)
+}
+
+func (sess *Session) copy() *Session {
+	c := *sess
+	c.Messages = append([]interfaces.Message{}, sess.Messages...)
+	return &c
+}
@@ -0,0 +1,89 @@
+package session
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+)
+
+func TestStoreScopesSessionsToTenants(t *testing.T) {
+	s, err := NewStore(DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sess, err := s.Create("acme", "ana", "Release notes", "openai", "gpt-4o", []interfaces.Message{{Role: "system", Content: "Be brief."}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Get("other", sess.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected another tenant not to see the session, got %v", err)
+	}
+	if err := s.Delete("other", sess.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected another tenant not to delete the session, got %v", err)
+	}
+
+	updated, err := s.Append("acme", sess.ID, interfaces.Message{Role: "user", Content: "Hi"}, interfaces.Message{Role: "assistant", Content: "Hello"})
+	if err != nil || len(updated.Messages) != 3 {
+		t.Fatalf("expected 3 messages after append, got %+v (%v)", updated, err)
+	}
+	if list := s.List("acme"); len(list) != 1 || list[0].Messages != 3 || list[0].Title != "Release notes" {
+		t.Fatalf("unexpected list: %+v", list)
+	}
+	if list := s.List("other"); len(list) != 0 {
+		t.Fatalf("expected no sessions for another tenant, got %+v", list)
+	}
+
+	if err := s.Delete("acme", sess.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get("acme", sess.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected the session to be gone, got %v", err)
+	}
+}
+
+func TestStoreTrimsToContextWindow(t *testing.T) {
+	// gpt-4 has an 8192-token window; keep 4096 for the reply
+	s, _ := NewStore(DefaultConfig())
+	sess, _ := s.Create("", "", "", "openai", "gpt-4", []interfaces.Message{{Role: "system", Content: "Be brief."}})
+
+	long := strings.Repeat("lorem ipsum ", 1000) // ~2000 tokens
+	for i := 0; i < 4; i++ {
+		sess, _ = s.Append("", sess.ID, interfaces.Message{Role: "user", Content: long}, interfaces.Message{Role: "assistant", Content: "ok"})
+	}
+	if sess.Trimmed == 0 || sess.Messages[0].Role != "system" || len(sess.Messages) >= 9 {
+		t.Fatalf("expected old turns to be trimmed, kept %d (trimmed %d)", len(sess.Messages), sess.Trimmed)
+	}
+
+	conversation, trimmed := s.Conversation(sess, []interfaces.Message{{Role: "user", Content: long}}, "openai", "gpt-4")
+	if trimmed == 0 || conversation[len(conversation)-1].Content != long {
+		t.Fatalf("expected the new turn to be kept and history trimmed, got %d messages (trimmed %d)", len(conversation), trimmed)
+	}
+}
+
+func TestStorePersistsAndEvicts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.json")
+	s, _ := NewStore(Config{StatePath: path, MaxPerTenant: 2})
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { now = now.Add(time.Minute); return now }
+
+	first, _ := s.Create("acme", "", "first", "", "", nil)
+	s.Create("acme", "", "second", "", "", nil)
+	s.Create("acme", "", "third", "", "", nil)
+
+	reopened, err := NewStore(Config{StatePath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := reopened.List("acme")
+	if len(list) != 2 || list[0].Title != "third" {
+		t.Fatalf("expected the two newest sessions to survive a restart, got %+v", list)
+	}
+	if _, err := reopened.Get("acme", first.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected the oldest session to be evicted, got %v", err)
+	}
+}
@@ -0,0 +1,508 @@
+// Package state moves the gateway's state between installations as a
+// versioned archive: sessions, history, templates, provider config and usage
+// ledgers.
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kubex-ecosystem/grompt/internal/gateway/middleware"
+	"github.com/kubex-ecosystem/grompt/internal/gateway/registry"
+	"github.com/kubex-ecosystem/grompt/internal/gateway/session"
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+)
+
+// Format identifies grompt state archives
+const Format = "grompt-state"
+
+// Version is the archive version written by Export. Import reads archives of
+// this version and older.
+const Version = 1
+
+// Archive sections
+const (
+	SectionSessions  = "sessions"
+	SectionHistory   = "history"
+	SectionTemplates = "templates"
+	SectionProviders = "providers"
+	SectionLedger    = "ledger"
+)
+
+// Sections lists every archive section in import order
+var Sections = []string{SectionSessions, SectionHistory, SectionTemplates, SectionProviders, SectionLedger}
+
+// SharedSections hold records of every tenant; they are only moved when
+// ExportOptions.Shared or ImportOptions.Shared is set
+var SharedSections = []string{SectionHistory, SectionTemplates}
+
+// ErrInvalid wraps the validation errors of an archive
+var ErrInvalid = errors.New("invalid state archive")
+
+// Archive is a portable snapshot of a tenant's gateway state. Sessions and
+// ledger entries carry no tenant, so they land in the importing tenant.
+type Archive struct {
+	Format    string                `json:"format"`
+	Version   int                   `json:"version"`
+	CreatedAt time.Time             `json:"created_at"`
+	Sessions  []*session.Session    `json:"sessions,omitempty"`
+	History   []interfaces.Result   `json:"history,omitempty"`
+	Templates []interfaces.Template `json:"templates,omitempty"`
+	Providers []Provider            `json:"providers,omitempty"`
+	Ledger    []LedgerEntry         `json:"ledger,omitempty"`
+}
+
+// Provider is the configuration of one provider. APIKey is only exported
+// when secrets are requested.
+type Provider struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	KeyEnv string `json:"key_env,omitempty"`
+	APIKey string `json:"api_key,omitempty"`
+}
+
+// LedgerEntry is the spend of the tenant, or of one of its users
+type LedgerEntry struct {
+	User string `json:"user,omitempty"`
+	middleware.SpendRecord
+}
+
+// Sources are the stores an archive is read from and merged into. Nil
+// sources are left out of exports and skipped on import.
+type Sources struct {
+	Sessions  *session.Store
+	History   interfaces.IHistoryManager
+	Templates interfaces.Manager
+	Registry  *registry.Registry
+	Budget    *middleware.BudgetManager
+}
+
+// Archiver exports and imports the state held by its sources
+type Archiver struct {
+	src Sources
+	now func() time.Time
+}
+
+// New creates an archiver over src
+func New(src Sources) *Archiver {
+	return &Archiver{src: src, now: time.Now}
+}
+
+// ExportOptions selects what Export writes
+type ExportOptions struct {
+	Tenant string
+	// Sections to export; empty exports all of them
+	Sections []string
+	// Secrets adds the providers' API keys to the archive
+	Secrets bool
+	// Shared adds the SharedSections, which hold every tenant's records
+	Shared bool
+}
+
+// Export snapshots the state of opts.Tenant. Without opts.Shared the history
+// and templates are left out, and asking for them is an error.
+func (a *Archiver) Export(opts ExportOptions) (*Archive, error) {
+	for _, s := range opts.Sections {
+		if !slices.Contains(Sections, s) {
+			return nil, fmt.Errorf("unknown section %q", s)
+		}
+		if !opts.Shared && slices.Contains(SharedSections, s) {
+			return nil, fmt.Errorf("section %q is shared by every tenant and cannot be exported for one", s)
+		}
+	}
+	want := func(s string) bool {
+		if !opts.Shared && slices.Contains(SharedSections, s) {
+			return false
+		}
+		return len(opts.Sections) == 0 || slices.Contains(opts.Sections, s)
+	}
+
+	out := &Archive{Format: Format, Version: Version, CreatedAt: a.now().UTC()}
+	if want(SectionSessions) && a.src.Sessions != nil {
+		for _, sess := range a.src.Sessions.All(opts.Tenant) {
+			sess.Tenant = ""
+			out.Sessions = append(out.Sessions, sess)
+		}
+	}
+	if want(SectionHistory) && a.src.History != nil {
+		out.History = a.src.History.Snapshot()
+	}
+	if want(SectionTemplates) && a.src.Templates != nil {
+		for _, name := range a.src.Templates.ListTemplates() {
+			tmpl, err := a.src.Templates.GetTemplate(name)
+			if err != nil {
+				return nil, err
+			}
+			out.Templates = append(out.Templates, *tmpl)
+		}
+	}
+	if want(SectionProviders) && a.src.Registry != nil {
+		for name, pc := range a.src.Registry.Config().Providers {
+			p := Provider{Name: name, Type: pc.Type(), KeyEnv: pc.KeyEnv()}
+			if opts.Secrets && p.KeyEnv != "" {
+				p.APIKey = os.Getenv(p.KeyEnv)
+			}
+			out.Providers = append(out.Providers, p)
+		}
+		sort.Slice(out.Providers, func(i, j int) bool { return out.Providers[i].Name < out.Providers[j].Name })
+	}
+	if want(SectionLedger) && a.src.Budget != nil {
+		for user, rec := range a.src.Budget.Ledger(opts.Tenant) {
+			out.Ledger = append(out.Ledger, LedgerEntry{User: user, SpendRecord: rec})
+		}
+		sort.Slice(out.Ledger, func(i, j int) bool { return out.Ledger[i].User < out.Ledger[j].User })
+	}
+	return out, nil
+}
+
+// Validate checks that archive is a state archive this version can read and
+// that its records are well formed
+func Validate(archive *Archive) error {
+	var problems []string
+	fail := func(format string, args ...any) { problems = append(problems, fmt.Sprintf(format, args...)) }
+
+	if archive.Format != Format {
+		return fmt.Errorf("%w: format %q is not %q", ErrInvalid, archive.Format, Format)
+	}
+	if archive.Version < 1 || archive.Version > Version {
+		return fmt.Errorf("%w: version %d is not supported (1 to %d)", ErrInvalid, archive.Version, Version)
+	}
+	for i, sess := range archive.Sessions {
+		if sess == nil || sess.ID == "" {
+			fail("sessions[%d]: missing id", i)
+		}
+	}
+	for i, r := range archive.History {
+		if r.ID == "" {
+			fail("history[%d]: missing id", i)
+		}
+	}
+	for i, t := range archive.Templates {
+		if t.Name == "" {
+			fail("templates[%d]: missing name", i)
+		} else if _, err := template.New(t.Name).Parse(t.Content); err != nil {
+			fail("templates[%d]: %v", i, err)
+		}
+	}
+	for i, p := range archive.Providers {
+		if p.Name == "" || p.Type == "" {
+			fail("providers[%d]: missing name or type", i)
+		}
+	}
+	for i, e := range archive.Ledger {
+		if e.DailyUSD < 0 || e.MonthlyUSD < 0 {
+			fail("ledger[%d]: negative spend", i)
+		}
+	}
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// ValidationError lists every malformed record of an archive
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %d malformed records, first: %s", ErrInvalid, len(e.Problems), e.Problems[0])
+}
+
+func (e *ValidationError) Unwrap() error { return ErrInvalid }
+
+// ImportOptions controls how Import merges an archive
+type ImportOptions struct {
+	Tenant string
+	// DryRun reports what would change without writing anything
+	DryRun bool
+	// Overwrite replaces local sessions and templates that conflict with the
+	// archive; by default the local copy is kept
+	Overwrite bool
+	// Shared imports the SharedSections, which every tenant sees; without it
+	// they are skipped
+	Shared bool
+}
+
+// Report describes the outcome of an import, per section
+type Report struct {
+	DryRun   bool                      `json:"dry_run"`
+	Sections map[string]*SectionReport `json:"sections"`
+}
+
+// SectionReport counts the records of one section by outcome
+type SectionReport struct {
+	Added     int        `json:"added"`
+	Updated   int        `json:"updated"`
+	Unchanged int        `json:"unchanged"`
+	Conflicts []Conflict `json:"conflicts,omitempty"`
+	// Skipped explains why the section was not imported
+	Skipped string `json:"skipped,omitempty"`
+}
+
+// Conflict is a record that differs between the archive and this gateway
+type Conflict struct {
+	Key        string `json:"key"`
+	Reason     string `json:"reason"`
+	Resolution string `json:"resolution"` // "kept local", "replaced" or "not applied"
+}
+
+// Import validates archive and merges it into the state of opts.Tenant.
+// Records missing locally are added and identical ones left alone. Differing
+// sessions and templates are conflicts resolved by opts.Overwrite; history
+// entries are append-only, so a differing entry keeps the local copy.
+// Provider config is only compared: providers are managed in the gateway's
+// providers file. Ledger records merge per period, keeping the later and
+// higher spend.
+func (a *Archiver) Import(archive *Archive, opts ImportOptions) (*Report, error) {
+	if err := Validate(archive); err != nil {
+		return nil, err
+	}
+	report := &Report{DryRun: opts.DryRun, Sections: map[string]*SectionReport{}}
+	for _, section := range Sections {
+		sr := &SectionReport{}
+		report.Sections[section] = sr
+		if !opts.Shared && slices.Contains(SharedSections, section) {
+			if archiveHas(archive, section) {
+				sr.Skipped = section + " are shared by every tenant; importing them needs the admin scope"
+			}
+			continue
+		}
+		var err error
+		switch section {
+		case SectionSessions:
+			err = a.importSessions(archive.Sessions, opts, sr)
+		case SectionHistory:
+			err = a.importHistory(archive.History, opts, sr)
+		case SectionTemplates:
+			err = a.importTemplates(archive.Templates, opts, sr)
+		case SectionProviders:
+			a.compareProviders(archive.Providers, sr)
+		case SectionLedger:
+			err = a.importLedger(archive.Ledger, opts, sr)
+		}
+		if err != nil {
+			return report, fmt.Errorf("failed to import %s: %w", section, err)
+		}
+	}
+	return report, nil
+}
+
+func (a *Archiver) importSessions(sessions []*session.Session, opts ImportOptions, sr *SectionReport) error {
+	if len(sessions) == 0 {
+		return nil
+	}
+	if a.src.Sessions == nil {
+		sr.Skipped = "sessions are disabled"
+		return nil
+	}
+	for _, in := range sessions {
+		sess := *in
+		sess.Tenant = opts.Tenant
+		cur, err := a.src.Sessions.Get(opts.Tenant, sess.ID)
+		switch {
+		case err == nil && sameSession(cur, &sess):
+			sr.Unchanged++
+			continue
+		case err == nil:
+			conflict := Conflict{Key: sess.ID, Reason: "session differs", Resolution: "kept local"}
+			if opts.Overwrite {
+				conflict.Resolution = "replaced"
+				sr.Updated++
+			}
+			sr.Conflicts = append(sr.Conflicts, conflict)
+			if !opts.Overwrite {
+				continue
+			}
+		default:
+			sr.Added++
+		}
+		if opts.DryRun {
+			continue
+		}
+		err = a.src.Sessions.Restore(&sess)
+		if errors.Is(err, session.ErrTaken) {
+			// Another tenant holds the ID, e.g. the same archive imported twice
+			sess.ID = uuid.NewString()
+			err = a.src.Sessions.Restore(&sess)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *Archiver) importHistory(entries []interfaces.Result, opts ImportOptions, sr *SectionReport) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	if a.src.History == nil {
+		sr.Skipped = "history is not available"
+		return nil
+	}
+	local := map[string]interfaces.Result{}
+	for _, r := range a.src.History.Snapshot() {
+		local[r.ID] = r
+	}
+	for _, r := range entries {
+		if cur, ok := local[r.ID]; ok {
+			if sameResult(cur, r) {
+				sr.Unchanged++
+			} else {
+				sr.Conflicts = append(sr.Conflicts, Conflict{Key: r.ID, Reason: "history entry differs", Resolution: "kept local"})
+			}
+			continue
+		}
+		sr.Added++
+		local[r.ID] = r
+		if !opts.DryRun {
+			a.src.History.Add(r)
+		}
+	}
+	return nil
+}
+
+func (a *Archiver) importTemplates(tmpls []interfaces.Template, opts ImportOptions, sr *SectionReport) error {
+	if len(tmpls) == 0 {
+		return nil
+	}
+	if a.src.Templates == nil {
+		sr.Skipped = "templates are not available"
+		return nil
+	}
+	for _, t := range tmpls {
+		if cur, err := a.src.Templates.GetTemplate(t.Name); err == nil {
+			if sameJSON(cur, t) {
+				sr.Unchanged++
+				continue
+			}
+			conflict := Conflict{Key: t.Name, Reason: "template differs", Resolution: "kept local"}
+			if opts.Overwrite {
+				conflict.Resolution = "replaced"
+				sr.Updated++
+			}
+			sr.Conflicts = append(sr.Conflicts, conflict)
+			if !opts.Overwrite {
+				continue
+			}
+		} else {
+			sr.Added++
+		}
+		if opts.DryRun {
+			continue
+		}
+		if err := a.src.Templates.PutTemplate(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compareProviders reports the providers of the archive that are missing or
+// configured differently here; none of them is applied
+func (a *Archiver) compareProviders(providers []Provider, sr *SectionReport) {
+	if len(providers) == 0 {
+		return
+	}
+	if a.src.Registry == nil {
+		sr.Skipped = "no provider registry"
+		return
+	}
+	local := a.src.Registry.Config().Providers
+	for _, p := range providers {
+		pc, ok := local[p.Name]
+		switch {
+		case !ok:
+			sr.Conflicts = append(sr.Conflicts, Conflict{Key: p.Name, Reason: "provider is not configured here", Resolution: "not applied"})
+		case pc.Type() != p.Type || pc.KeyEnv() != p.KeyEnv:
+			sr.Conflicts = append(sr.Conflicts, Conflict{
+				Key:        p.Name,
+				Reason:     fmt.Sprintf("configured here as type %s with key in %s", pc.Type(), pc.KeyEnv()),
+				Resolution: "not applied",
+			})
+		case p.APIKey != "" && os.Getenv(p.KeyEnv) != p.APIKey:
+			sr.Conflicts = append(sr.Conflicts, Conflict{Key: p.Name, Reason: "API key differs from " + p.KeyEnv, Resolution: "not applied"})
+		default:
+			sr.Unchanged++
+		}
+	}
+}
+
+func (a *Archiver) importLedger(entries []LedgerEntry, opts ImportOptions, sr *SectionReport) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	if a.src.Budget == nil {
+		sr.Skipped = "budgets are disabled"
+		return nil
+	}
+	local := a.src.Budget.Ledger(opts.Tenant)
+	for _, e := range entries {
+		cur, ok := local[e.User]
+		if opts.DryRun {
+			switch {
+			case !ok:
+				sr.Added++
+			case cur.Merge(e.SpendRecord) != cur:
+				sr.Updated++
+			default:
+				sr.Unchanged++
+			}
+			continue
+		}
+		changed, err := a.src.Budget.RestoreLedger(opts.Tenant, e.User, e.SpendRecord)
+		if err != nil {
+			return err
+		}
+		switch {
+		case !ok:
+			sr.Added++
+		case changed:
+			sr.Updated++
+		default:
+			sr.Unchanged++
+		}
+	}
+	return nil
+}
+
+// archiveHas reports whether archive holds records of a shared section
+func archiveHas(archive *Archive, section string) bool {
+	switch section {
+	case SectionHistory:
+		return len(archive.History) > 0
+	case SectionTemplates:
+		return len(archive.Templates) > 0
+	}
+	return false
+}
+
+// sameSession compares sessions by content, ignoring time zones
+func sameSession(a, b *session.Session) bool {
+	return a.UpdatedAt.Equal(b.UpdatedAt) && a.Title == b.Title && sameJSON(a.Messages, b.Messages)
+}
+
+// sameResult compares history entries by content, ignoring time zones
+func sameResult(a, b interfaces.Result) bool {
+	if !a.Timestamp.Equal(b.Timestamp) {
+		return false
+	}
+	a.Timestamp, b.Timestamp = time.Time{}, time.Time{}
+	return sameJSON(a, b)
+}
+
+// sameJSON reports whether a and b encode to the same JSON
+func sameJSON(a, b any) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
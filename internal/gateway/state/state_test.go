@@ -0,0 +1,210 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kubex-ecosystem/grompt/internal/gateway/middleware"
+	"github.com/kubex-ecosystem/grompt/internal/gateway/registry"
+	"github.com/kubex-ecosystem/grompt/internal/gateway/session"
+	"github.com/kubex-ecosystem/grompt/internal/history"
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"github.com/kubex-ecosystem/grompt/internal/templates"
+	"github.com/kubex-ecosystem/grompt/internal/types"
+)
+
+func newSources(t *testing.T) Sources {
+	t.Helper()
+	dir := t.TempDir()
+	sessions, err := session.NewStore(session.Config{StatePath: filepath.Join(dir, "sessions.json")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hist, err := history.NewFileStore(filepath.Join(dir, "history.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	budget, err := middleware.NewBudgetManager(middleware.BudgetConfig{Enabled: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg, err := registry.FromConfig(&types.Config{Providers: map[string]interfaces.Provider{
+		"openai": &types.ProviderImpl{VName: "openai", VType: "openai", VKeyEnv: "GROMPT_STATE_TEST_KEY"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Sources{
+		Sessions:  sessions,
+		History:   hist,
+		Templates: templates.NewManager(filepath.Join(dir, "templates")),
+		Registry:  reg,
+		Budget:    budget,
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	laptop := newSources(t)
+	if _, err := laptop.Sessions.Create("", "ana", "Draft", "openai", "gpt-4o", []interfaces.Message{{Role: "user", Content: "Hi"}}); err != nil {
+		t.Fatal(err)
+	}
+	laptop.History.Add(interfaces.Result{ID: "h1", Prompt: "p", Response: "r", Provider: "openai", Timestamp: time.Now()})
+	if err := laptop.Templates.PutTemplate(interfaces.Template{Name: "review/go", Content: "Review {{.code}}"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := laptop.Budget.Record(context.Background(), "", "ana", 0.25); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GROMPT_STATE_TEST_KEY", "sk-secret")
+
+	archive, err := New(laptop).Export(ExportOptions{Shared: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if archive.Format != Format || archive.Version != Version {
+		t.Fatalf("unexpected header: %s v%d", archive.Format, archive.Version)
+	}
+	if len(archive.Providers) != 1 || archive.Providers[0].APIKey != "" {
+		t.Fatalf("expected the provider without its key, got %+v", archive.Providers)
+	}
+	if len(archive.Sessions) != 1 || archive.Sessions[0].Tenant != "" || len(archive.Ledger) != 2 {
+		t.Fatalf("unexpected archive: %d sessions, %d ledger entries", len(archive.Sessions), len(archive.Ledger))
+	}
+
+	gateway := newSources(t)
+	report, err := New(gateway).Import(archive, ImportOptions{Tenant: "acme", Shared: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, section := range []string{SectionSessions, SectionHistory, SectionTemplates} {
+		if report.Sections[section].Added != 1 {
+			t.Fatalf("expected one %s added, got %+v", section, report.Sections[section])
+		}
+	}
+	if report.Sections[SectionLedger].Added != 2 || report.Sections[SectionProviders].Unchanged != 1 {
+		t.Fatalf("unexpected report: ledger %+v, providers %+v", report.Sections[SectionLedger], report.Sections[SectionProviders])
+	}
+	if got := gateway.Sessions.List("acme"); len(got) != 1 || got[0].Title != "Draft" {
+		t.Fatalf("expected the session in the importing tenant, got %+v", got)
+	}
+	if status := gateway.Budget.Status("acme", "ana"); status[2].SpentUSD != 0.25 {
+		t.Fatalf("expected the user's spend to move along, got %+v", status)
+	}
+
+	// Importing again changes nothing, spend included
+	again, err := New(gateway).Import(archive, ImportOptions{Tenant: "acme", Shared: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, section := range Sections {
+		if sr := again.Sections[section]; sr.Added+sr.Updated != 0 || len(sr.Conflicts) != 0 {
+			t.Fatalf("expected %s to be unchanged on a second import, got %+v", section, sr)
+		}
+	}
+	if status := gateway.Budget.Status("acme", ""); status[0].SpentUSD != 0.25 {
+		t.Fatalf("expected spend to be counted once, got %+v", status)
+	}
+}
+
+func TestImportDryRunReportsConflicts(t *testing.T) {
+	src := newSources(t)
+	src.Templates.PutTemplate(interfaces.Template{Name: "greet", Content: "Hello {{.name}}"})
+	archive, _ := New(src).Export(ExportOptions{Sections: []string{SectionTemplates}, Shared: true})
+
+	dst := newSources(t)
+	dst.Templates.PutTemplate(interfaces.Template{Name: "greet", Content: "Hi {{.name}}"})
+	archive.Templates = append(archive.Templates, interfaces.Template{Name: "bye", Content: "Bye"})
+
+	report, err := New(dst).Import(archive, ImportOptions{DryRun: true, Overwrite: true, Shared: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sr := report.Sections[SectionTemplates]
+	if !report.DryRun || sr.Added != 1 || sr.Updated != 1 || len(sr.Conflicts) != 1 || sr.Conflicts[0].Key != "greet" {
+		t.Fatalf("unexpected dry-run report: %+v", sr)
+	}
+	if names := dst.Templates.ListTemplates(); len(names) != 1 {
+		t.Fatalf("dry run must not write, got templates %v", names)
+	}
+	if content, _ := dst.Templates.LoadTemplate("greet"); content != "Hi {{.name}}" {
+		t.Fatalf("dry run must not overwrite, got %q", content)
+	}
+
+	report, _ = New(dst).Import(archive, ImportOptions{Shared: true})
+	if sr := report.Sections[SectionTemplates]; sr.Conflicts[0].Resolution != "kept local" {
+		t.Fatalf("expected the local template to win without overwrite, got %+v", sr)
+	}
+	if content, _ := dst.Templates.LoadTemplate("greet"); content != "Hi {{.name}}" {
+		t.Fatalf("expected the local template to be kept, got %q", content)
+	}
+}
+
+func TestValidateRejectsBadArchives(t *testing.T) {
+	if err := Validate(&Archive{Format: "other", Version: 1}); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected a foreign format to be rejected, got %v", err)
+	}
+	if err := Validate(&Archive{Format: Format, Version: Version + 1}); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected a newer version to be rejected, got %v", err)
+	}
+
+	err := Validate(&Archive{
+		Format:    Format,
+		Version:   Version,
+		History:   []interfaces.Result{{Prompt: "no id"}},
+		Templates: []interfaces.Template{{Name: "broken", Content: "{{.x"}},
+	})
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) || len(invalid.Problems) != 2 {
+		t.Fatalf("expected two problems, got %v", err)
+	}
+}
+
+func TestExportSecretsOnRequest(t *testing.T) {
+	src := newSources(t)
+	t.Setenv("GROMPT_STATE_TEST_KEY", "sk-secret")
+	archive, err := New(src).Export(ExportOptions{Sections: []string{SectionProviders}, Secrets: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(archive.Providers) != 1 || archive.Providers[0].APIKey != "sk-secret" || archive.Sessions != nil {
+		t.Fatalf("unexpected archive: %+v", archive)
+	}
+	if _, err := New(src).Export(ExportOptions{Sections: []string{"passwords"}}); err == nil {
+		t.Fatal("expected an unknown section to be rejected")
+	}
+}
+
+func TestSharedSectionsNeedShared(t *testing.T) {
+	src := newSources(t)
+	src.History.Add(interfaces.Result{ID: "h1", Prompt: "p", Response: "r", Timestamp: time.Now()})
+	src.Templates.PutTemplate(interfaces.Template{Name: "greet", Content: "Hello {{.name}}"})
+
+	archive, err := New(src).Export(ExportOptions{Tenant: "acme"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if archive.History != nil || archive.Templates != nil {
+		t.Fatalf("expected a tenant export without shared sections, got %+v", archive)
+	}
+	if _, err := New(src).Export(ExportOptions{Tenant: "acme", Sections: []string{SectionHistory}}); err == nil {
+		t.Fatal("expected asking a tenant export for the history to be rejected")
+	}
+
+	full, _ := New(src).Export(ExportOptions{Shared: true})
+	full.Templates[0].Content = "Pwned {{.name}}"
+	dst := newSources(t)
+	dst.Templates.PutTemplate(interfaces.Template{Name: "greet", Content: "Hi {{.name}}"})
+	report, err := New(dst).Import(full, ImportOptions{Tenant: "acme", Overwrite: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Sections[SectionHistory].Skipped == "" || report.Sections[SectionTemplates].Skipped == "" {
+		t.Fatalf("expected the shared sections to be skipped, got %+v", report.Sections)
+	}
+	if content, _ := dst.Templates.LoadTemplate("greet"); content != "Hi {{.name}}" || len(dst.History.Snapshot()) != 0 {
+		t.Fatalf("expected shared state to be untouched, got %q and %d history entries", content, len(dst.History.Snapshot()))
+	}
+}
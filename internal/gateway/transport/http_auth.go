@@ -0,0 +1,57 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kubex-ecosystem/grompt/internal/gateway/middleware"
+)
+
+type loginReq struct {
+	APIKey string   `json:"api_key"`
+	Scopes []string `json:"scopes"` // narrows the token to these scopes
+}
+
+// POST /v1/auth/login — exchanges an API key, in the body or x-api-key, for
+// a short-lived JWT with the key's identity and scopes
+func (h *httpHandlersSSE) authLogin(c *gin.Context) {
+	if h.auth == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "authentication is disabled"})
+		return
+	}
+	var in loginReq
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&in); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if in.APIKey == "" {
+		in.APIKey = c.GetHeader("x-api-key")
+	}
+
+	token, expires, p, err := h.auth.Login(in.APIKey, in.Scopes)
+	switch {
+	case errors.Is(err, middleware.ErrUnauthenticated):
+		c.Header("WWW-Authenticate", `Bearer realm="grompt"`)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, middleware.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, middleware.ErrLoginDisabled):
+		c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, gin.H{
+			"access_token": token,
+			"token_type":   "Bearer",
+			"expires_at":   expires,
+			"expires_in":   int(time.Until(expires).Seconds()),
+			"tenant":       p.Tenant,
+			"user":         p.User,
+			"scopes":       p.Scopes,
+		})
+	}
+}
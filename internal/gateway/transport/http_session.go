@@ -0,0 +1,126 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kubex-ecosystem/grompt/internal/gateway/session"
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+	gl "github.com/kubex-ecosystem/logz/logger"
+)
+
+type sessionReq struct {
+	Title    string               `json:"title"`
+	Provider string               `json:"provider"`
+	Model    string               `json:"model"`
+	Messages []interfaces.Message `json:"messages"`
+}
+
+// POST /v1/session — starts a conversation, optionally seeded with messages
+func (h *httpHandlersSSE) sessionCreate(c *gin.Context) {
+	if !h.sessionsEnabled(c) {
+		return
+	}
+	var in sessionReq
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	sess, err := h.sessions.Create(c.GetHeader("x-tenant-id"), c.GetHeader("x-user-id"), in.Title, in.Provider, in.Model, in.Messages)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, sess)
+}
+
+// GET /v1/session — the calling tenant's sessions, most recent first
+func (h *httpHandlersSSE) sessionList(c *gin.Context) {
+	if !h.sessionsEnabled(c) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"sessions": h.sessions.List(c.GetHeader("x-tenant-id"))})
+}
+
+// GET /v1/session/:id
+func (h *httpHandlersSSE) sessionGet(c *gin.Context) {
+	if !h.sessionsEnabled(c) {
+		return
+	}
+	if sess := h.loadSession(c, c.GetHeader("x-tenant-id"), c.Param("id")); sess != nil {
+		c.JSON(http.StatusOK, sess)
+	}
+}
+
+// DELETE /v1/session/:id
+func (h *httpHandlersSSE) sessionDelete(c *gin.Context) {
+	if !h.sessionsEnabled(c) {
+		return
+	}
+	if err := h.sessions.Delete(c.GetHeader("x-tenant-id"), c.Param("id")); err != nil {
+		sessionError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// POST /v1/session/:id/messages — appends messages without calling a model
+func (h *httpHandlersSSE) sessionAppend(c *gin.Context) {
+	if !h.sessionsEnabled(c) {
+		return
+	}
+	var in struct {
+		Messages []interfaces.Message `json:"messages" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	sess, err := h.sessions.Append(c.GetHeader("x-tenant-id"), c.Param("id"), in.Messages...)
+	if err != nil {
+		sessionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sess)
+}
+
+// loadSession returns a session of tenant, or answers the request and
+// returns nil when there is none
+func (h *httpHandlersSSE) loadSession(c *gin.Context, tenant, id string) *session.Session {
+	if !h.sessionsEnabled(c) {
+		return nil
+	}
+	sess, err := h.sessions.Get(tenant, id)
+	if err != nil {
+		sessionError(c, err)
+		return nil
+	}
+	return sess
+}
+
+// remember stores a completed chat turn and its reply in sess, if any
+func (h *httpHandlersSSE) remember(sess *session.Session, tenant string, turn []interfaces.Message, reply interfaces.Message) {
+	if sess == nil {
+		return
+	}
+	if _, err := h.sessions.Append(tenant, sess.ID, append(turn, reply)...); err != nil {
+		gl.Log("warn", "failed to store session turn: "+err.Error())
+	}
+}
+
+func (h *httpHandlersSSE) sessionsEnabled(c *gin.Context) bool {
+	if h.sessions == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "sessions are disabled"})
+		return false
+	}
+	return true
+}
+
+func sessionError(c *gin.Context, err error) {
+	if errors.Is(err, session.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -2,33 +2,99 @@
 package transport
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/kubex-ecosystem/grompt/internal/advise"
+	"github.com/kubex-ecosystem/grompt/internal/cache"
+	"github.com/kubex-ecosystem/grompt/internal/gateway/middleware"
 	"github.com/kubex-ecosystem/grompt/internal/gateway/registry"
+	"github.com/kubex-ecosystem/grompt/internal/gateway/session"
+	"github.com/kubex-ecosystem/grompt/internal/gateway/state"
 	"github.com/kubex-ecosystem/grompt/internal/interfaces"
 	"github.com/kubex-ecosystem/grompt/internal/scorecard"
+	"github.com/kubex-ecosystem/grompt/internal/structured"
+	"github.com/kubex-ecosystem/grompt/internal/tokenizer"
+	"github.com/kubex-ecosystem/grompt/internal/types"
+	"github.com/kubex-ecosystem/grompt/internal/vectorindex"
+	gl "github.com/kubex-ecosystem/logz/logger"
 )
 
 type httpHandlersSSE struct {
 	reg    *registry.Registry
 	engine *scorecard.Engine // Add scorecard engine
+	budget *middleware.BudgetManager
+	cache  *cache.Cache
+	// sessions holds server-side chat history; nil disables /v1/session
+	sessions *session.Store
+	// archiver backs /v1/state; nil disables it
+	archiver *state.Archiver
+	// auth checks client credentials; nil when auth is disabled
+	auth *middleware.Authenticator
+	// indexes backs /v1/index and /v1/search
+	indexes *vectorindex.Catalog
 }
 
-func WireHTTPSSE(router gin.IRouter, reg *registry.Registry) {
-	hh := &httpHandlersSSE{reg: reg, engine: nil} // TODO: Initialize engine when ready
+// Deps are the services the /v1 routes are served from. Only Registry is
+// required.
+type Deps struct {
+	Registry *registry.Registry
+	// Budget enforces spend limits; nil disables them
+	Budget *middleware.BudgetManager
+	// Cache stores chat responses; nil disables caching
+	Cache *cache.Cache
+	// Sessions holds server-side chat history; nil disables /v1/session
+	Sessions *session.Store
+	// Archiver backs /v1/state; nil disables it
+	Archiver *state.Archiver
+	// Auth checks client credentials; nil serves every client and trusts the
+	// x-tenant-id and x-user-id headers
+	Auth *middleware.Authenticator
+	// Indexes backs /v1/index and /v1/search; nil uses the catalog in
+	// vectorindex.DefaultDir
+	Indexes *vectorindex.Catalog
+}
+
+// WireHTTPSSE registers the /v1 routes served from deps.
+func WireHTTPSSE(router gin.IRouter, deps Deps) {
+	if deps.Indexes == nil {
+		deps.Indexes = vectorindex.NewCatalog("")
+	}
+	auth := deps.Auth
+	hh := &httpHandlersSSE{reg: deps.Registry, engine: nil, budget: deps.Budget, cache: deps.Cache, sessions: deps.Sessions, archiver: deps.Archiver, auth: auth, indexes: deps.Indexes} // TODO: Initialize engine when ready
 	router.GET("/healthz", func(c *gin.Context) { c.Status(http.StatusNoContent) })
 
 	v1 := router.Group("/v1")
-	v1.Any("/chat", hh.chatSSE)
-	v1.Any("/session", hh.session)
-	v1.Any("/providers", hh.providers) // status simples
-	v1.Any("/auth/login", hh.authLoginPassthrough)
-	v1.Any("/state/export", hh.stateExport)
-	v1.Any("/state/import", hh.stateImport)
-	v1.Any("/advise", gin.WrapH(advise.New(reg)))
+	v1.POST("/auth/login", hh.authLogin)
+
+	chat := v1.Group("", auth.Require(middleware.ScopeChat))
+	chat.Any("/chat", hh.chatSSE)
+	chat.POST("/session", hh.sessionCreate)
+	chat.GET("/session", hh.sessionList)
+	chat.GET("/session/:id", hh.sessionGet)
+	chat.DELETE("/session/:id", hh.sessionDelete)
+	chat.POST("/session/:id/messages", hh.sessionAppend)
+	chat.Any("/providers", hh.providers) // status simples
+	chat.Any("/advise", gin.WrapH(advise.New(deps.Registry, deps.Budget)))
+	chat.GET("/budget", hh.budgetStatus)
+	chat.POST("/embeddings", hh.embeddings)
+	chat.GET("/index", hh.indexList)
+	chat.POST("/index", hh.indexUpsert)
+	chat.POST("/search", hh.search)
+
+	st := v1.Group("/state", auth.Require(middleware.ScopeState))
+	st.GET("/export", hh.stateExport)
+	st.POST("/import", hh.stateImport)
+
+	admin := v1.Group("", auth.Require(middleware.ScopeAdmin))
+	admin.GET("/cache", hh.cacheStats)
+	admin.DELETE("/cache", hh.cacheStats)
 
 	// Repository Intelligence APIs (to be implemented)
 	// v1.Any("/scorecard", hh.handleScorecard)
@@ -44,6 +110,13 @@ type chatReq struct {
 	Temp     float32              `json:"temperature"`
 	Stream   bool                 `json:"stream"`
 	Meta     map[string]any       `json:"meta"`
+	// Tools the model may call; calls are streamed as "toolCall" events and
+	// answered by the client with "tool" messages in a follow-up request
+	Tools      []interfaces.Tool `json:"tools"`
+	ToolChoice string            `json:"tool_choice"`
+	// SessionID continues a /v1/session conversation: Messages is only the
+	// new turn, and the turn and the reply are stored in the session
+	SessionID string `json:"session_id"`
 }
 
 func (h *httpHandlersSSE) chatSSE(c *gin.Context) {
@@ -52,27 +125,111 @@ func (h *httpHandlersSSE) chatSSE(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	tenant, user := c.GetHeader("x-tenant-id"), c.GetHeader("x-user-id")
+
+	var sess *session.Session
+	if in.SessionID != "" {
+		if sess = h.loadSession(c, tenant, in.SessionID); sess == nil {
+			return
+		}
+		if in.Provider == "" {
+			in.Provider = sess.Provider
+		}
+		if in.Model == "" {
+			in.Model = sess.Model
+		}
+	}
 	p := h.reg.Resolve(in.Provider)
 	if p == nil {
 		c.String(http.StatusBadRequest, "bad provider")
 		return
 	}
+	turn := in.Messages
+	if sess != nil {
+		// The stored history is trimmed to the model's window, oldest turns first
+		var trimmed int
+		if in.Messages, trimmed = h.sessions.Conversation(sess, turn, p.Name(), in.Model); trimmed > 0 {
+			c.Header("X-Grompt-Session-Trimmed", strconv.Itoa(trimmed))
+		}
+	}
+	tk := tokenizer.For(p.Name(), in.Model)
+	promptTokens := tokenizer.CountMessages(tk, in.Messages)
+
+	// JSON output mode validates the whole reply, so it is answered in one event
+	schema, retries, jsonMode, err := structured.FromMeta(in.Meta)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Enforce spend limits. The request reserves its worst case, the prompt
+	// and a full completion for every attempt, until it is charged.
+	var reserved float64
+	defer func() { h.charge(c, tenant, user, &reserved, p.Name(), in.Model, nil) }()
+	if h.budget != nil {
+		attempts := 1
+		if jsonMode {
+			attempts += max(retries, 0)
+		}
+		worst := &interfaces.Usage{Prompt: promptTokens, Completion: types.ChatMaxTokens(interfaces.ChatRequest{Meta: in.Meta})}
+		estimate := h.budget.Cost(p.Name(), in.Model, worst) * float64(attempts)
+		decision := h.budget.Check(tenant, user, p.Name(), in.Model, estimate)
+		if !decision.Allowed {
+			c.JSON(http.StatusPaymentRequired, gin.H{"error": decision.Reason})
+			return
+		}
+		reserved = decision.ReservedUSD
+		if decision.Downgraded {
+			c.Header("X-Grompt-Budget", "downgraded; "+decision.Reason)
+			in.Model = decision.Model
+			tk = tokenizer.For(p.Name(), in.Model)
+			promptTokens = tokenizer.CountMessages(tk, in.Messages)
+		}
+	}
+
+	// Reject requests that cannot fit the model's context window before the round-trip
+	if window := tokenizer.ContextWindow(p.Name(), in.Model); window > 0 && promptTokens >= window {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error":          fmt.Sprintf("messages of ~%d tokens exceed the %d-token context window", promptTokens, window),
+			"prompt_tokens":  promptTokens,
+			"context_window": window,
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if cache.NoCache(c.GetHeader("Cache-Control")) {
+		ctx = cache.Bypass(ctx)
+	}
+	p = cache.Wrap(p, h.cache)
+
 	headers := map[string]string{
 		"x-external-api-key": c.GetHeader("x-external-api-key"),
-		"x-tenant-id":        c.GetHeader("x-tenant-id"),
-		"x-user-id":          c.GetHeader("x-user-id"),
-	}
-	ch, err := p.Chat(c.Request.Context(), interfaces.ChatRequest{
-		Provider: in.Provider,
-		Model:    in.Model,
-		Messages: in.Messages,
-		Temp:     in.Temp,
-		Stream:   in.Stream,
-		Meta:     in.Meta,
-		Headers:  headers,
-	})
+		"x-tenant-id":        tenant,
+		"x-user-id":          user,
+	}
+	req := interfaces.ChatRequest{
+		Provider:   in.Provider,
+		Model:      in.Model,
+		Messages:   in.Messages,
+		Temp:       in.Temp,
+		Stream:     in.Stream,
+		Meta:       in.Meta,
+		Headers:    headers,
+		Tools:      in.Tools,
+		ToolChoice: in.ToolChoice,
+	}
+
+	if jsonMode {
+		if doc := h.chatJSON(ctx, c, p, req, schema, retries, tenant, user, &reserved); doc != nil {
+			h.remember(sess, tenant, turn, interfaces.Message{Role: "assistant", Content: doc.Raw})
+		}
+		return
+	}
+
+	ch, err := p.Chat(ctx, req)
 	if err != nil {
-		c.String(http.StatusBadGateway, err.Error())
+		chatError(c, err)
 		return
 	}
 
@@ -83,18 +240,34 @@ func (h *httpHandlersSSE) chatSSE(c *gin.Context) {
 	fl, _ := w.(http.Flusher)
 
 	enc := func(v any) []byte { b, _ := json.Marshal(v); return b }
+	var completion strings.Builder
+	var usage *interfaces.Usage
+	var calls []interfaces.ToolCall
+	cached, failed := false, false
 	for c := range ch {
 		payload := map[string]any{}
 		if c.Content != "" {
 			payload["content"] = c.Content
+			completion.WriteString(c.Content)
+		}
+		failed = failed || c.Error != ""
+		if c.Cached {
+			payload["cached"] = true
+			cached = true
+		}
+		if c.ToolCall != nil {
+			payload["toolCall"] = c.ToolCall
+			calls = append(calls, *c.ToolCall)
 		}
-		// if c.ToolCall != nil {
-		// 	payload["toolCall"] = c.ToolCall
-		// }
 		if c.Done {
 			payload["done"] = true
-			if c.Usage != nil {
-				payload["usage"] = c.Usage
+			if usage = c.Usage; usage != nil {
+				payload["usage"] = usage
+			} else {
+				usage = &interfaces.Usage{Prompt: promptTokens, Completion: tk.Count(completion.String()), Provider: p.Name(), Model: in.Model}
+				usage.Tokens = usage.Prompt + usage.Completion
+				payload["usage"] = usage
+				payload["usage_estimated"] = true
 			}
 		}
 
@@ -106,36 +279,158 @@ func (h *httpHandlersSSE) chatSSE(c *gin.Context) {
 		w.Write([]byte("\n\n"))
 		fl.Flush()
 	}
+
+	// Replayed responses cost nothing; their reservation is released
+	if !cached {
+		if usage == nil {
+			// The stream ended without a done chunk; charge what was produced
+			usage = &interfaces.Usage{Prompt: promptTokens, Completion: tk.Count(completion.String())}
+		}
+		h.charge(c, tenant, user, &reserved, p.Name(), in.Model, usage)
+	}
+	if !failed {
+		h.remember(sess, tenant, turn, interfaces.Message{Role: "assistant", Content: completion.String(), ToolCalls: calls})
+	}
 }
 
-// /v1/providers — lista nomes e tipos carregados (pra pintar “verde” no dropdown)
+// chatJSON answers a chat in JSON output mode. The reply is validated
+// against schema and repaired up to retries times, then sent as a single
+// event carrying the parsed document under "json". Every attempt is charged
+// against the budget reserved for the request.
+// The document is returned, or nil once the error has been answered.
+func (h *httpHandlersSSE) chatJSON(ctx context.Context, c *gin.Context, p interfaces.Provider, req interfaces.ChatRequest, schema map[string]any, retries int, tenant, user string, reserved *float64) *structured.Result {
+	tk := tokenizer.For(p.Name(), req.Model)
+	total := &interfaces.Usage{Provider: p.Name(), Model: req.Model}
+	cached, estimated := true, false
 
-func (h *httpHandlersSSE) providers(c *gin.Context) {
-	cfg := h.reg.Config() // adicione um getter simples no registry
-	type item struct{ Name, Type string }
-	out := []item{}
-	for name, pc := range cfg.Providers {
-		out = append(out, item{Name: name, Type: pc.Type()})
+	doc, err := structured.Generate(ctx, func(ctx context.Context, messages []interfaces.Message) (string, error) {
+		attempt := req
+		attempt.Messages, attempt.Stream = messages, false
+		ch, err := p.Chat(ctx, attempt)
+		if err != nil {
+			return "", err
+		}
+		var reply strings.Builder
+		var usage *interfaces.Usage
+		var failure string
+		hit := false
+		for chunk := range ch {
+			reply.WriteString(chunk.Content)
+			hit = hit || chunk.Cached
+			if chunk.Usage != nil {
+				usage = chunk.Usage
+			}
+			if chunk.Error != "" && failure == "" {
+				failure = chunk.Error
+			}
+		}
+		if failure != "" {
+			return "", errors.New(failure)
+		}
+		if usage == nil {
+			usage = &interfaces.Usage{Prompt: tokenizer.CountMessages(tk, messages), Completion: tk.Count(reply.String())}
+			estimated = true
+		}
+		if cached = cached && hit; !hit {
+			total.Prompt += usage.Prompt
+			total.Completion += usage.Completion
+			total.CostUSD += usage.CostUSD
+		}
+		return reply.String(), nil
+	}, req.Messages, schema, retries)
+	total.Tokens = total.Prompt + total.Completion
+	if !cached {
+		h.charge(c, tenant, user, reserved, p.Name(), req.Model, total)
 	}
-	c.JSON(http.StatusOK, gin.H{"providers": out})
+
+	if err != nil {
+		var invalid *structured.Error
+		if errors.As(err, &invalid) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "attempts": invalid.Attempts, "last_reply": invalid.LastReply})
+			return nil
+		}
+		chatError(c, err)
+		return nil
+	}
+
+	payload := gin.H{"content": doc.Raw, "json": doc.Data, "json_attempts": doc.Attempts, "done": true, "usage": total}
+	if estimated {
+		payload["usage_estimated"] = true
+	}
+	if cached {
+		payload["cached"] = true
+	}
+	data, _ := json.Marshal(payload)
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Status(http.StatusOK)
+	c.Writer.Write([]byte("data: "))
+	c.Writer.Write(data)
+	c.Writer.Write([]byte("\n\n"))
+	c.Writer.Flush()
+	return doc
 }
 
-func (h *httpHandlersSSE) session(c *gin.Context) {
-	// Implementar lógica para gerenciar sessões
-	c.AbortWithStatus(http.StatusNotImplemented)
+// chatError answers a failed chat: inputs the provider cannot take are the
+// client's fault, anything else the upstream's
+func chatError(c *gin.Context, err error) {
+	var unsupported *interfaces.UnsupportedInputError
+	if errors.As(err, &unsupported) || errors.Is(err, interfaces.ErrInvalidInput) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.String(http.StatusBadGateway, err.Error())
 }
 
-func (h *httpHandlersSSE) authLoginPassthrough(c *gin.Context) {
-	// Implementar lógica para login via passthrough
-	c.AbortWithStatus(http.StatusNotImplemented)
+// charge records the spend of a request against the tenant and user budgets
+// and releases what Check reserved for it, zeroing *reserved so a deferred
+// charge of a nil usage only releases a reservation still held
+func (h *httpHandlersSSE) charge(c *gin.Context, tenant, user string, reserved *float64, provider, model string, usage *interfaces.Usage) {
+	if h.budget == nil {
+		return
+	}
+	held := *reserved
+	*reserved = 0
+	if err := h.budget.Settle(context.WithoutCancel(c.Request.Context()), tenant, user, held, h.budget.Cost(provider, model, usage)); err != nil {
+		gl.Log("warn", fmt.Sprintf("failed to record spend: %v", err))
+	}
 }
 
-func (h *httpHandlersSSE) stateExport(c *gin.Context) {
-	// Implementar lógica para exportar estado
-	c.AbortWithStatus(http.StatusNotImplemented)
+// /v1/budget — spend of the calling tenant/user against their limits
+func (h *httpHandlersSSE) budgetStatus(c *gin.Context) {
+	if h.budget == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"enabled": true,
+		"budgets": h.budget.Status(c.GetHeader("x-tenant-id"), c.GetHeader("x-user-id")),
+	})
+}
+
+// /v1/cache — response cache statistics; DELETE clears it
+func (h *httpHandlersSSE) cacheStats(c *gin.Context) {
+	if h.cache == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+	if c.Request.Method == http.MethodDelete {
+		if err := h.cache.Clear(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"enabled": true, "stats": h.cache.Stats()})
 }
 
-func (h *httpHandlersSSE) stateImport(c *gin.Context) {
-	// Implementar lógica para importar estado
-	c.AbortWithStatus(http.StatusNotImplemented)
+// /v1/providers — lista nomes e tipos carregados (pra pintar “verde” no dropdown)
+
+func (h *httpHandlersSSE) providers(c *gin.Context) {
+	cfg := h.reg.Config() // adicione um getter simples no registry
+	type item struct{ Name, Type string }
+	out := []item{}
+	for name, pc := range cfg.Providers {
+		out = append(out, item{Name: name, Type: pc.Type()})
+	}
+	c.JSON(http.StatusOK, gin.H{"providers": out})
 }
@@ -0,0 +1,86 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kubex-ecosystem/grompt/internal/gateway/middleware"
+	"github.com/kubex-ecosystem/grompt/internal/gateway/state"
+)
+
+// maxArchiveBytes bounds the body of /v1/state/import
+const maxArchiveBytes = 64 << 20
+
+// GET /v1/state/export — the calling tenant's state as a versioned archive.
+// ?sections=sessions,ledger limits the sections. The history and templates,
+// shared by every tenant, are only included for admins, and provider API
+// keys only with ?secrets=true from an admin; both need auth to be enabled.
+func (h *httpHandlersSSE) stateExport(c *gin.Context) {
+	if !h.stateEnabled(c) {
+		return
+	}
+	opts := state.ExportOptions{Tenant: c.GetHeader("x-tenant-id"), Secrets: c.Query("secrets") == "true", Shared: h.isAdmin(c)}
+	if opts.Secrets && !opts.Shared {
+		c.JSON(http.StatusForbidden, gin.H{"error": "exporting provider secrets needs auth enabled and the admin scope"})
+		return
+	}
+	if sections := c.Query("sections"); sections != "" {
+		opts.Sections = strings.Split(sections, ",")
+	}
+	archive, err := h.archiver.Export(opts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="grompt-state-`+archive.CreatedAt.Format("20060102-150405")+`.json"`)
+	c.JSON(http.StatusOK, archive)
+}
+
+// POST /v1/state/import — merges an archive into the calling tenant's state.
+// ?dry_run=true only reports what would change and the conflicts;
+// ?overwrite=true lets the archive win conflicts. The shared history and
+// templates are only imported for admins.
+func (h *httpHandlersSSE) stateImport(c *gin.Context) {
+	if !h.stateEnabled(c) {
+		return
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxArchiveBytes)
+	var archive state.Archive
+	if err := c.ShouldBindJSON(&archive); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	report, err := h.archiver.Import(&archive, state.ImportOptions{
+		Tenant:    c.GetHeader("x-tenant-id"),
+		DryRun:    c.Query("dry_run") == "true",
+		Overwrite: c.Query("overwrite") == "true",
+		Shared:    h.isAdmin(c),
+	})
+	var invalid *state.ValidationError
+	switch {
+	case errors.As(err, &invalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": state.ErrInvalid.Error(), "problems": invalid.Problems})
+	case errors.Is(err, state.ErrInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "report": report})
+	default:
+		c.JSON(http.StatusOK, report)
+	}
+}
+
+func (h *httpHandlersSSE) stateEnabled(c *gin.Context) bool {
+	if h.archiver == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "state export is disabled"})
+		return false
+	}
+	return true
+}
+
+// isAdmin reports whether the caller authenticated with the admin scope;
+// without auth nobody is
+func (h *httpHandlersSSE) isAdmin(c *gin.Context) bool {
+	return h.auth != nil && middleware.PrincipalFrom(c).Can(middleware.ScopeAdmin)
+}
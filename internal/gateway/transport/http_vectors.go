@@ -0,0 +1,260 @@
+package transport
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"github.com/kubex-ecosystem/grompt/internal/tokenizer"
+	"github.com/kubex-ecosystem/grompt/internal/vectorindex"
+)
+
+// defaultSearchK is the number of hits of a /v1/search without k
+const defaultSearchK = 5
+
+// texts is a JSON string or array of strings
+type texts []string
+
+func (t *texts) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*t = texts{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return fmt.Errorf("input must be a string or an array of strings")
+	}
+	*t = many
+	return nil
+}
+
+type embeddingsReq struct {
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+	Input    texts  `json:"input"`
+}
+
+type indexDoc struct {
+	ID       string            `json:"id"`
+	Text     string            `json:"text"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+type indexReq struct {
+	Index     string     `json:"index"`
+	Provider  string     `json:"provider"`
+	Model     string     `json:"model"`
+	Documents []indexDoc `json:"documents"`
+}
+
+type searchReq struct {
+	Index    string             `json:"index"`
+	Provider string             `json:"provider"`
+	Model    string             `json:"model"`
+	Query    string             `json:"query"`
+	Vector   []float64          `json:"vector"`
+	K        int                `json:"k"`
+	Filter   vectorindex.Filter `json:"filter"`
+}
+
+// POST /v1/embeddings — one vector per input, in an OpenAI-like envelope
+func (h *httpHandlersSSE) embeddings(c *gin.Context) {
+	var in embeddingsReq
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(in.Input) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "input is required"})
+		return
+	}
+	res, ok := h.embed(c, in.Provider, in.Model, in.Input)
+	if !ok {
+		return
+	}
+
+	data := make([]gin.H, len(res.Vectors))
+	for i, v := range res.Vectors {
+		data[i] = gin.H{"object": "embedding", "index": i, "embedding": v}
+	}
+	c.JSON(http.StatusOK, gin.H{"object": "list", "data": data, "model": res.Model, "usage": res.Usage})
+}
+
+// GET /v1/index — the calling tenant's vector indexes and where their
+// vectors come from
+func (h *httpHandlersSSE) indexList(c *gin.Context) {
+	infos, err := h.indexes.List(c.GetHeader("x-tenant-id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"indexes": infos})
+}
+
+// POST /v1/index — embeds documents and upserts them into an index of the
+// calling tenant. A
+// document without an id is keyed by the hash of its text. The first write
+// binds the index to its provider and model; later writes default to them.
+func (h *httpHandlersSSE) indexUpsert(c *gin.Context) {
+	var in indexReq
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(in.Documents) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "documents are required"})
+		return
+	}
+	idx, provider, model, ok := h.openIndex(c, in.Index, in.Provider, in.Model)
+	if !ok {
+		return
+	}
+
+	input := make([]string, len(in.Documents))
+	for i, doc := range in.Documents {
+		if strings.TrimSpace(doc.Text) == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("document %d has no text", i)})
+			return
+		}
+		input[i] = doc.Text
+	}
+	res, ok := h.embed(c, provider, model, input)
+	if !ok {
+		return
+	}
+	if err := idx.Bind(provider, res.Model); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	records := make([]vectorindex.Record, len(in.Documents))
+	ids := make([]string, len(in.Documents))
+	for i, doc := range in.Documents {
+		if ids[i] = doc.ID; ids[i] == "" {
+			sum := sha256.Sum256([]byte(doc.Text))
+			ids[i] = "doc_" + hex.EncodeToString(sum[:8])
+		}
+		records[i] = vectorindex.Record{ID: ids[i], Vector: res.Vectors[i], Text: doc.Text, Metadata: doc.Metadata}
+	}
+	if err := idx.Upsert(records...); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"index": idx.Info(), "ids": ids, "usage": res.Usage})
+}
+
+// POST /v1/search — the documents of a tenant's index nearest to a query text, or
+// to a vector given as is, restricted by metadata filters. The query is
+// embedded with the provider and model the index is bound to.
+func (h *httpHandlersSSE) search(c *gin.Context) {
+	var in searchReq
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if strings.TrimSpace(in.Query) == "" && len(in.Vector) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "query or vector is required"})
+		return
+	}
+	if in.K <= 0 {
+		in.K = defaultSearchK
+	}
+	idx, provider, model, ok := h.openIndex(c, in.Index, in.Provider, in.Model)
+	if !ok {
+		return
+	}
+
+	var usage *interfaces.Usage
+	vector := in.Vector
+	if len(vector) == 0 {
+		res, ok := h.embed(c, provider, model, []string{in.Query})
+		if !ok {
+			return
+		}
+		vector, usage = res.Vectors[0], res.Usage
+	}
+	hits, err := idx.Search(vector, in.K, in.Filter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"index": idx.Info().Name, "hits": hits, "usage": usage})
+}
+
+// openIndex opens the named index of the calling tenant and settles the
+// provider and model of its vectors: those of the request, else those the
+// index is bound to. A model other than the bound one is refused, since its
+// vectors are not comparable. When it returns false the request has been
+// answered.
+func (h *httpHandlersSSE) openIndex(c *gin.Context, name, provider, model string) (*vectorindex.Index, string, string, bool) {
+	idx, err := h.indexes.Open(c.GetHeader("x-tenant-id"), name)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, "", "", false
+	}
+	info := idx.Info()
+	if info.Count > 0 && ((provider != "" && provider != info.Provider) || (model != "" && model != info.Model)) {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("index %s holds %s/%s vectors", info.Name, info.Provider, info.Model)})
+		return nil, "", "", false
+	}
+	if provider == "" {
+		provider = info.Provider
+	}
+	if model == "" {
+		model = info.Model
+	}
+	return idx, provider, model, true
+}
+
+// embed computes embeddings with the named provider, reserving the caller's
+// budget first and charging it after. When it returns false the request has
+// been answered.
+func (h *httpHandlersSSE) embed(c *gin.Context, provider, model string, input []string) (*interfaces.Embeddings, bool) {
+	p := h.reg.Resolve(provider)
+	if p == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("bad provider %q", provider)})
+		return nil, false
+	}
+	embedder, ok := interfaces.EmbedderOf(p)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s: %v", provider, interfaces.ErrEmbeddingsUnsupported)})
+		return nil, false
+	}
+	tenant, user := c.GetHeader("x-tenant-id"), c.GetHeader("x-user-id")
+
+	// Embeddings have no completion, so the input prices the whole request
+	var reserved float64
+	defer func() { h.charge(c, tenant, user, &reserved, p.Name(), model, nil) }()
+	if h.budget != nil {
+		tk := tokenizer.For(p.Name(), model)
+		tokens := 0
+		for _, text := range input {
+			tokens += tk.Count(text)
+		}
+		estimate := h.budget.Cost(p.Name(), model, &interfaces.Usage{Prompt: tokens})
+		decision := h.budget.Check(tenant, user, p.Name(), model, estimate)
+		if !decision.Allowed {
+			c.JSON(http.StatusPaymentRequired, gin.H{"error": decision.Reason})
+			return nil, false
+		}
+		reserved = decision.ReservedUSD
+	}
+
+	res, err := embedder.Embed(c.Request.Context(), interfaces.EmbeddingRequest{Model: model, Input: input})
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if len(res.Vectors) != len(input) {
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("%s returned %d vectors for %d inputs", provider, len(res.Vectors), len(input))})
+		return nil, false
+	}
+	h.charge(c, tenant, user, &reserved, p.Name(), res.Model, res.Usage)
+	return res, true
+}
@@ -0,0 +1,96 @@
+package history
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+)
+
+// Export formats supported by Export.
+const (
+	FormatMarkdown = "markdown"
+	FormatJSON     = "json"
+	FormatCSV      = "csv"
+)
+
+// Export writes entries to w as Markdown, JSON or CSV.
+func Export(w io.Writer, entries []interfaces.Result, format string) error {
+	switch strings.ToLower(format) {
+	case FormatMarkdown, "md":
+		return exportMarkdown(w, entries)
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	case FormatCSV:
+		return exportCSV(w, entries)
+	default:
+		return fmt.Errorf("unsupported export format %q (use markdown, json or csv)", format)
+	}
+}
+
+func exportMarkdown(w io.Writer, entries []interfaces.Result) error {
+	var b strings.Builder
+	b.WriteString("# Grompt History\n")
+	for _, e := range entries {
+		fmt.Fprintf(&b, "\n## %s\n\n", e.ID)
+		fmt.Fprintf(&b, "- **Date:** %s\n", e.Timestamp.Format(time.RFC3339))
+		fmt.Fprintf(&b, "- **Provider:** %s\n", e.Provider)
+		if e.Model != "" {
+			fmt.Fprintf(&b, "- **Model:** %s\n", e.Model)
+		}
+		if len(e.Tags) > 0 {
+			fmt.Fprintf(&b, "- **Tags:** %s\n", strings.Join(e.Tags, ", "))
+		}
+		if e.Usage != nil && e.Usage.Tokens > 0 {
+			fmt.Fprintf(&b, "- **Tokens:** %d\n", e.Usage.Tokens)
+		}
+		fmt.Fprintf(&b, "\n### Prompt\n\n%s\n", fence(e.Prompt))
+		fmt.Fprintf(&b, "\n### Response\n\n%s\n", strings.TrimSpace(e.Response))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func exportCSV(w io.Writer, entries []interfaces.Result) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"id", "timestamp", "provider", "model", "tags", "tokens", "prompt", "response"}); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		tokens := ""
+		if e.Usage != nil {
+			tokens = strconv.Itoa(e.Usage.Tokens)
+		}
+		record := []string{
+			e.ID,
+			e.Timestamp.Format(time.RFC3339),
+			e.Provider,
+			e.Model,
+			strings.Join(e.Tags, ";"),
+			tokens,
+			e.Prompt,
+			e.Response,
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// fence wraps text in a code fence long enough not to clash with fences inside it.
+func fence(text string) string {
+	marker := "```"
+	for strings.Contains(text, marker) {
+		marker += "`"
+	}
+	return marker + "\n" + strings.TrimRight(text, "\n") + "\n" + marker
+}
@@ -0,0 +1,47 @@
+package history
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+)
+
+func TestExportFormats(t *testing.T) {
+	entries := []interfaces.Result{{
+		ID:        "prompt_1",
+		Prompt:    "Show a fence:\n```go\nfmt.Println()\n```",
+		Response:  "Done, with \"quotes\"",
+		Provider:  "openai",
+		Model:     "gpt-4o",
+		Tags:      []string{"go", "docs"},
+		Timestamp: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+	}}
+
+	var md bytes.Buffer
+	if err := Export(&md, entries, "md"); err != nil {
+		t.Fatalf("markdown export returned error: %v", err)
+	}
+	if !strings.Contains(md.String(), "````\nShow a fence:") {
+		t.Fatalf("prompt fence should outgrow the fence inside it:\n%s", md.String())
+	}
+
+	var buf bytes.Buffer
+	if err := Export(&buf, entries, FormatCSV); err != nil {
+		t.Fatalf("csv export returned error: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("csv output does not parse: %v", err)
+	}
+	if len(records) != 2 || records[1][4] != "go;docs" || records[1][6] != entries[0].Prompt {
+		t.Fatalf("unexpected csv records: %q", records)
+	}
+
+	if err := Export(&buf, entries, "xml"); err == nil {
+		t.Fatal("expected an error for an unsupported format")
+	}
+}
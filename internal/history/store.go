@@ -0,0 +1,209 @@
+// Package history implements the persistent prompt history store.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	gl "github.com/kubex-ecosystem/logz/logger"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"github.com/kubex-ecosystem/grompt/internal/module/kbx"
+)
+
+// DefaultPath returns the history file location. GROMPT_HISTORY_FILE
+// overrides the default under ~/.kubex/grompt.
+func DefaultPath() string {
+	if path := os.Getenv("GROMPT_HISTORY_FILE"); path != "" {
+		return path
+	}
+	return os.ExpandEnv(kbx.DefaultGromptHistoryPath)
+}
+
+// FileStore keeps history as JSON Lines, one interfaces.Result per line.
+// Entries are appended on Add and read back incrementally, so results
+// written by other grompt processes sharing the file become visible too.
+type FileStore struct {
+	path string
+
+	mu      sync.Mutex
+	entries []interfaces.Result
+	offset  int64 // bytes of the file already indexed
+}
+
+// NewFileStore opens the history file at path, creating its directory when
+// needed. A missing file is treated as an empty history.
+func NewFileStore(path string) (*FileStore, error) {
+	if path == "" {
+		path = DefaultPath()
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+	s := &FileStore{path: path}
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Open opens the history file at DefaultPath. When it cannot be used, a
+// warning is logged and an in-memory store is returned instead, so callers
+// always get a working history.
+func Open() interfaces.IHistoryManager {
+	store, err := NewFileStore(DefaultPath())
+	if err != nil {
+		gl.Log("warn", fmt.Sprintf("Persistent history unavailable, keeping it in memory: %v", err))
+		return interfaces.NewHistoryStore(100)
+	}
+	return store
+}
+
+// Path returns the history file location
+func (s *FileStore) Path() string { return s.path }
+
+// Add appends a result to the history file
+func (s *FileStore) Add(result interfaces.Result) {
+	if err := s.append(result); err != nil {
+		gl.Log("error", fmt.Sprintf("Failed to persist history entry %s: %v", result.ID, err))
+	}
+}
+
+// Snapshot returns every entry in insertion order
+func (s *FileStore) Snapshot() []interfaces.Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.refreshLocked(); err != nil {
+		gl.Log("warn", fmt.Sprintf("Failed to reload history: %v", err))
+	}
+	out := make([]interfaces.Result, len(s.entries))
+	copy(out, s.entries)
+	return out
+}
+
+// Query returns the entries matching q, newest first
+func (s *FileStore) Query(q interfaces.HistoryQuery) interfaces.HistoryPage {
+	return interfaces.ApplyHistoryQuery(s.Snapshot(), q)
+}
+
+// Get returns the entry with the given ID or unique ID prefix
+func (s *FileStore) Get(id string) (*interfaces.Result, bool) {
+	return interfaces.FindHistoryEntry(s.Snapshot(), id)
+}
+
+// Close releases the store. Writes are not buffered, so there is nothing to flush.
+func (s *FileStore) Close() error { return nil }
+
+func (s *FileStore) append(result interfaces.Result) error {
+	line, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Catch up first so the offset stays aligned with what we have indexed.
+	if err := s.refreshLocked(); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(line); err != nil {
+		return err
+	}
+	s.entries = append(s.entries, result)
+	s.offset += int64(len(line))
+	return nil
+}
+
+func (s *FileStore) refresh() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.refreshLocked()
+}
+
+// refreshLocked indexes lines appended since the last read. The file is
+// reloaded from the start when it shrank, e.g. after being truncated.
+func (s *FileStore) refreshLocked() error {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		s.entries, s.offset = nil, 0
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() < s.offset {
+		s.entries, s.offset = nil, 0
+	}
+	if info.Size() == s.offset {
+		return nil
+	}
+	if _, err := f.Seek(s.offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// A partial trailing line is still being written; read it next time.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		s.offset += int64(len(line))
+
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		var entry interfaces.Result
+		if err := json.Unmarshal(line, &entry); err != nil {
+			gl.Log("warn", fmt.Sprintf("Skipping corrupt history line in %s: %v", s.path, err))
+			continue
+		}
+		s.entries = append(s.entries, entry)
+	}
+}
+
+// ParseTime parses a query bound given as RFC 3339 or as a YYYY-MM-DD date in
+// local time. A plain date used as an upper bound covers the whole day.
+func ParseTime(v string, endOfDay bool) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation(time.DateOnly, v, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected RFC 3339 or YYYY-MM-DD, got %q", v)
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
@@ -0,0 +1,111 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+)
+
+func seed(t *testing.T, s *FileStore) time.Time {
+	t.Helper()
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	entries := []interfaces.Result{
+		{ID: "prompt_1", Prompt: "Explain goroutines", Response: "Lightweight threads", Provider: "openai", Model: "gpt-4o-mini", Tags: []string{"go"}},
+		{ID: "prompt_2", Prompt: "Write a haiku", Response: "Autumn moonlight", Provider: "claude", Model: "claude-3-haiku", Tags: []string{"poetry"}},
+		{ID: "prompt_3", Prompt: "Explain channels in Go", Response: "Typed conduits", Provider: "openai", Model: "gpt-4o", Tags: []string{"go", "concurrency"}},
+	}
+	for i, e := range entries {
+		e.Timestamp = base.Add(time.Duration(i) * time.Hour)
+		s.Add(e)
+	}
+	return base
+}
+
+func TestFileStoreQuery(t *testing.T) {
+	s, err := NewFileStore(filepath.Join(t.TempDir(), "history.jsonl"))
+	if err != nil {
+		t.Fatalf("NewFileStore returned error: %v", err)
+	}
+	base := seed(t, s)
+
+	cases := []struct {
+		name  string
+		query interfaces.HistoryQuery
+		want  []string
+	}{
+		{"all newest first", interfaces.HistoryQuery{}, []string{"prompt_3", "prompt_2", "prompt_1"}},
+		{"provider", interfaces.HistoryQuery{Provider: "OpenAI"}, []string{"prompt_3", "prompt_1"}},
+		{"model", interfaces.HistoryQuery{Model: "gpt-4o"}, []string{"prompt_3"}},
+		{"tag", interfaces.HistoryQuery{Tag: "concurrency"}, []string{"prompt_3"}},
+		{"text terms", interfaces.HistoryQuery{Text: "explain GO conduits"}, []string{"prompt_3"}},
+		{"time range", interfaces.HistoryQuery{Since: base.Add(30 * time.Minute), Until: base.Add(90 * time.Minute)}, []string{"prompt_2"}},
+		{"page", interfaces.HistoryQuery{Offset: 1, Limit: 1}, []string{"prompt_2"}},
+		{"past the end", interfaces.HistoryQuery{Offset: 5}, []string{}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			page := s.Query(tc.query)
+			got := make([]string, 0, len(page.Entries))
+			for _, e := range page.Entries {
+				got = append(got, e.ID)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Fatalf("got %v, want %v", got, tc.want)
+				}
+			}
+		})
+	}
+
+	if page := s.Query(interfaces.HistoryQuery{Limit: 1}); page.Total != 3 {
+		t.Fatalf("Total = %d, want 3", page.Total)
+	}
+}
+
+func TestFileStorePersistsAndSharesEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "history.jsonl")
+	writer, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore returned error: %v", err)
+	}
+	reader, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore returned error: %v", err)
+	}
+
+	seed(t, writer)
+	if got := len(reader.Snapshot()); got != 3 {
+		t.Fatalf("reader sees %d entries, want 3", got)
+	}
+
+	entry, ok := reader.Get("prompt_2")
+	if !ok || entry.Provider != "claude" {
+		t.Fatalf("Get(prompt_2) = %+v, %v", entry, ok)
+	}
+	if _, ok := reader.Get("prompt_"); ok {
+		t.Fatal("an ambiguous ID prefix should not match")
+	}
+
+	// Corrupt lines are skipped rather than failing the whole history.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("{not json\n")
+	f.Close()
+	writer.Add(interfaces.Result{ID: "prompt_4", Timestamp: time.Now()})
+
+	reopened, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore returned error: %v", err)
+	}
+	if got := len(reopened.Snapshot()); got != 4 {
+		t.Fatalf("reopened store has %d entries, want 4", got)
+	}
+}
@@ -1,6 +1,8 @@
 // Package interfaces defines the contracts for various components in the application.
 package interfaces
 
+import "context"
+
 // LegacyAPIConfig defines the contract for legacy API configurations
 type LegacyAPIConfig interface {
 	IsAvailable() bool
@@ -19,3 +21,33 @@ type IAPIConfig interface {
 	GetCommonModels() []string
 	Complete(prompt string, maxTokens int, model string) (string, error)
 }
+
+// JSONCompleter is implemented by APIs with a native JSON output mode. The
+// API constrains its reply to schema where it can, or to any JSON document
+// when schema is nil or its JSON mode takes no schema.
+type JSONCompleter interface {
+	CompleteJSON(prompt string, maxTokens int, model string, schema map[string]any) (string, error)
+}
+
+// Chatter is implemented by APIs that hold a multi-turn chat, including tool
+// definitions and tool results, over their wire protocol.
+type Chatter interface {
+	Chat(ctx context.Context, req ChatRequest) (<-chan ChatChunk, error)
+}
+
+// CanChat reports whether p holds chats over its API. Providers backed by an
+// API without Chatter report false from SupportsChat and are driven through
+// Execute; wrappers are followed through Unwrap, and any other provider chats.
+func CanChat(p Provider) bool {
+	for p != nil {
+		if s, ok := p.(interface{ SupportsChat() bool }); ok {
+			return s.SupportsChat()
+		}
+		u, ok := p.(interface{ Unwrap() Provider })
+		if !ok {
+			break
+		}
+		p = u.Unwrap()
+	}
+	return p != nil
+}
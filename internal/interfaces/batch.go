@@ -0,0 +1,24 @@
+package interfaces
+
+import "time"
+
+// BatchOptions controls how a batch of prompts is processed
+type BatchOptions struct {
+	// Concurrency is the maximum number of prompts processed at once (default 4)
+	Concurrency int `json:"concurrency,omitempty"`
+	// ItemTimeout bounds the processing of each prompt; zero means no per-item limit
+	ItemTimeout time.Duration `json:"item_timeout,omitempty"`
+}
+
+// BatchOutcome is the outcome of a single prompt in a batch. Exactly one of
+// Result and Err is set.
+type BatchOutcome struct {
+	Index  int     `json:"index"`
+	Prompt string  `json:"prompt"`
+	Result *Result `json:"result,omitempty"`
+	Err    error   `json:"-"`
+	Error  string  `json:"error,omitempty"`
+}
+
+// Succeeded reports whether the prompt was processed successfully
+func (o BatchOutcome) Succeeded() bool { return o.Err == nil && o.Result != nil }
@@ -0,0 +1,43 @@
+package interfaces
+
+// CompareTarget selects a provider, and optionally a model, for a comparison
+type CompareTarget struct {
+	Provider string `json:"provider"`
+	Model    string `json:"model,omitempty"`
+}
+
+// CompareEntry is the response of one target in a comparison. Exactly one of
+// Response and Error is meaningful.
+type CompareEntry struct {
+	Provider  string   `json:"provider"`
+	Model     string   `json:"model,omitempty"`
+	Response  string   `json:"response,omitempty"`
+	LatencyMs int64    `json:"latency_ms"`
+	Usage     *Usage   `json:"usage,omitempty"`
+	Pricing   *Pricing `json:"pricing,omitempty"`
+	// EstimatedCost is the cost of the call in Pricing.Currency
+	EstimatedCost float64 `json:"estimated_cost"`
+	// UsageEstimated is set when the provider did not report token counts and
+	// they were estimated from the prompt and response length
+	UsageEstimated bool   `json:"usage_estimated,omitempty"`
+	HistoryID      string `json:"history_id,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+// Comparison is the outcome of sending one prompt to several targets
+type Comparison struct {
+	ID      string         `json:"id"`
+	Prompt  string         `json:"prompt"`
+	Entries []CompareEntry `json:"entries"`
+	// Fastest and Cheapest name the winning successful targets as provider[:model]
+	Fastest  string `json:"fastest,omitempty"`
+	Cheapest string `json:"cheapest,omitempty"`
+}
+
+// Cost returns the cost of usage under this pricing
+func (p *Pricing) Cost(u *Usage) float64 {
+	if p == nil || u == nil {
+		return 0
+	}
+	return float64(u.Prompt)/1000*p.InputCostPer1K + float64(u.Completion)/1000*p.OutputCostPer1K
+}
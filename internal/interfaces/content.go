@@ -0,0 +1,134 @@
+package interfaces
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Content part types of a multimodal Message
+const (
+	PartText     = "text"
+	PartImageURL = "image_url" // an image fetched by the provider from URL
+	PartImage    = "image"     // an inline base64 image in Data
+	PartFile     = "file"      // a document, inline in Data or uploaded as FileID
+)
+
+// ContentPart is one piece of a multimodal message. Which fields are used
+// depends on Type: Text for text, URL for image_url, Data (base64) and
+// MimeType for image, and FileID or Data with MimeType for file.
+type ContentPart struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	URL      string `json:"url,omitempty"`
+	Data     string `json:"data,omitempty"`
+	MimeType string `json:"mime_type,omitempty"`
+	FileID   string `json:"file_id,omitempty"`
+	Name     string `json:"name,omitempty"`
+}
+
+// IsImage reports whether the part is an image
+func (p ContentPart) IsImage() bool {
+	return p.Type == PartImageURL || p.Type == PartImage
+}
+
+// PartFrom builds a content part from an http(s) image URL or a local file.
+// Local images become inline image parts and any other file an inline file
+// part, with the MIME type taken from the extension or the content.
+func PartFrom(ref string) (ContentPart, error) {
+	if strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://") {
+		return ContentPart{Type: PartImageURL, URL: ref}, nil
+	}
+	data, err := os.ReadFile(ref)
+	if err != nil {
+		return ContentPart{}, fmt.Errorf("failed to read %s: %w", ref, err)
+	}
+	mimeType := mime.TypeByExtension(filepath.Ext(ref))
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	mimeType, _, _ = strings.Cut(mimeType, ";")
+	part := ContentPart{Type: PartFile, Data: base64.StdEncoding.EncodeToString(data), MimeType: mimeType, Name: filepath.Base(ref)}
+	if strings.HasPrefix(mimeType, "image/") {
+		part.Type = PartImage
+	}
+	return part, nil
+}
+
+// Text returns the text of the message: Content followed by its text parts
+func (m Message) Text() string {
+	if len(m.Parts) == 0 {
+		return m.Content
+	}
+	texts := make([]string, 0, len(m.Parts)+1)
+	if m.Content != "" {
+		texts = append(texts, m.Content)
+	}
+	for _, p := range m.Parts {
+		if p.Type == PartText && p.Text != "" {
+			texts = append(texts, p.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// ErrInvalidInput wraps the errors of malformed content parts
+var ErrInvalidInput = errors.New("invalid content part")
+
+// UnsupportedInputError is returned when a request carries a kind of input
+// the provider cannot take
+type UnsupportedInputError struct {
+	Provider string
+	Input    string // "image" or "file"
+}
+
+func (e *UnsupportedInputError) Error() string {
+	return fmt.Sprintf("provider %s does not accept %s input", e.Provider, e.Input)
+}
+
+// InputAccepter is implemented by chat APIs that take content parts beyond
+// text. AcceptedInputs reports them through SupportsImages and
+// SupportsFiles; APIs without it take text only.
+type InputAccepter interface {
+	AcceptedInputs() *Capabilities
+}
+
+// CheckInputs validates the content parts of messages against caps and
+// returns an *UnsupportedInputError for the first part caps does not allow.
+// Malformed parts are reported wrapping ErrInvalidInput.
+func CheckInputs(provider string, caps *Capabilities, messages []Message) error {
+	for i, m := range messages {
+		for _, p := range m.Parts {
+			switch p.Type {
+			case PartText:
+				continue
+			case PartImageURL:
+				if p.URL == "" {
+					return fmt.Errorf("%w: message %d: image_url part without url", ErrInvalidInput, i)
+				}
+			case PartImage:
+				if p.Data == "" || p.MimeType == "" {
+					return fmt.Errorf("%w: message %d: image part needs data and mime_type", ErrInvalidInput, i)
+				}
+			case PartFile:
+				if p.FileID == "" && (p.Data == "" || p.MimeType == "") {
+					return fmt.Errorf("%w: message %d: file part needs file_id, or data and mime_type", ErrInvalidInput, i)
+				}
+			default:
+				return fmt.Errorf("%w: message %d: unknown type %q", ErrInvalidInput, i, p.Type)
+			}
+			if p.IsImage() && (caps == nil || !caps.SupportsImages) {
+				return &UnsupportedInputError{Provider: provider, Input: "image"}
+			}
+			if p.Type == PartFile && (caps == nil || !caps.SupportsFiles) {
+				return &UnsupportedInputError{Provider: provider, Input: "file"}
+			}
+		}
+	}
+	return nil
+}
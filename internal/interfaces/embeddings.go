@@ -0,0 +1,48 @@
+package interfaces
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrEmbeddingsUnsupported is returned by providers without an embedding API
+var ErrEmbeddingsUnsupported = errors.New("provider does not support embeddings")
+
+// EmbeddingRequest asks for one vector per input text
+type EmbeddingRequest struct {
+	Model string   `json:"model,omitempty"` // empty uses the API's default embedding model
+	Input []string `json:"input"`
+}
+
+// Embeddings are the vectors of an EmbeddingRequest, in input order
+type Embeddings struct {
+	Model   string      `json:"model"`
+	Vectors [][]float64 `json:"vectors"`
+	Usage   *Usage      `json:"usage,omitempty"`
+}
+
+// Embedder is the optional capability of providers and APIs that turn text
+// into embedding vectors
+type Embedder interface {
+	Embed(ctx context.Context, req EmbeddingRequest) (*Embeddings, error)
+}
+
+// EmbedderOf returns the embedding capability of p. Wrappers with an
+// Unwrap method are looked through, and a provider that reports
+// SupportsEmbeddings false has none even when it has an Embed method.
+func EmbedderOf(p Provider) (Embedder, bool) {
+	for p != nil {
+		if e, ok := p.(Embedder); ok {
+			if s, ok := p.(interface{ SupportsEmbeddings() bool }); ok && !s.SupportsEmbeddings() {
+				return nil, false
+			}
+			return e, true
+		}
+		u, ok := p.(interface{ Unwrap() Provider })
+		if !ok {
+			break
+		}
+		p = u.Unwrap()
+	}
+	return nil, false
+}
@@ -6,17 +6,38 @@ type IEngine interface {
 	// ProcessPrompt processes a prompt with variables and returns the result
 	ProcessPrompt(ctx context.Context, template string, vars map[string]interface{}) (*Result, error)
 
+	// ProcessTemplate renders a stored template by name and processes it like ProcessPrompt
+	ProcessTemplate(ctx context.Context, name string, vars map[string]interface{}) (*Result, error)
+
+	// GetTemplates returns the template library used by the engine
+	GetTemplates() Manager
+
 	// GetProviders returns available providers
 	GetProviders() []Provider
 
 	// GetHistory returns the prompt history
 	GetHistory() []Result
 
+	// QueryHistory returns the history entries matching q, newest first
+	QueryHistory(q HistoryQuery) HistoryPage
+
+	// Rerun replays a history entry, optionally against another provider or model
+	Rerun(ctx context.Context, id, provider, model string) (*Result, error)
+
 	// SaveToHistory saves a prompt/response pair to history
 	SaveToHistory(ctx context.Context, prompt, response string) error
 
-	// BatchProcess processes multiple prompts concurrently
-	BatchProcess(ctx context.Context, prompts []string, vars map[string]interface{}) ([]Result, error)
+	// BatchProcess processes multiple prompts concurrently and returns one outcome per prompt, in input order
+	BatchProcess(ctx context.Context, prompts []string, vars map[string]interface{}, opts BatchOptions) []BatchOutcome
+
+	// Compare sends one prompt to several providers/models in parallel and reports latency, usage and estimated cost
+	Compare(ctx context.Context, prompt string, targets []CompareTarget, vars map[string]interface{}) (*Comparison, error)
+
+	// Generate engineers a prompt from raw ideas with the strategy of the request's purpose type
+	Generate(ctx context.Context, req GenerationRequest) (*Generation, error)
+
+	// Refine generates a prompt from a request and regenerates it with a critic's rubric scores until it passes a threshold
+	Refine(ctx context.Context, request string, opts RefineOptions) (*Refinement, error)
 
 	// InvokeProvider invokes a specific provider with a prompt and variables
 	InvokeProvider(ctx context.Context, providerName, prompt string, vars map[string]interface{}) (*Result, error)
@@ -0,0 +1,29 @@
+package interfaces
+
+import "time"
+
+// Example is an input/output pair of the few-shot example library. It
+// demonstrates the expected behaviour for prompts of its purpose type.
+type Example struct {
+	ID     string `json:"id"`
+	Input  string `json:"input"`
+	Output string `json:"output"`
+	// Purpose is a normalized purpose type; empty matches every purpose
+	Purpose   string    `json:"purpose,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ExampleQuery selects the examples most relevant to a text
+type ExampleQuery struct {
+	Text    string   `json:"text"`
+	Purpose string   `json:"purpose,omitempty"`
+	Tags    []string `json:"tags,omitempty"` // every tag must match
+	K       int      `json:"k,omitempty"`
+}
+
+// ExampleMatch is a retrieved example and its relevance score
+type ExampleMatch struct {
+	Example
+	Score float64 `json:"score"`
+}
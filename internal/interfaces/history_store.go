@@ -1,6 +1,11 @@
 package interfaces
 
-import "sync"
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
 
 // ---------- History store ----------
 
@@ -36,10 +41,137 @@ func (h *historyStore) Snapshot() []Result {
 	return out
 }
 
+func (h *historyStore) Query(q HistoryQuery) HistoryPage {
+	return ApplyHistoryQuery(h.Snapshot(), q)
+}
+
+func (h *historyStore) Get(id string) (*Result, bool) {
+	return FindHistoryEntry(h.Snapshot(), id)
+}
+
 type IHistoryManager interface {
 	// Add adds a new result to the history
 	Add(result Result)
 
 	// Snapshot returns a copy of the current history entries
 	Snapshot() []Result
+
+	// Query returns the entries matching q, newest first
+	Query(q HistoryQuery) HistoryPage
+
+	// Get returns the entry with the given ID, or the only entry whose ID starts with it
+	Get(id string) (*Result, bool)
+}
+
+// ---------- History queries ----------
+
+// HistoryQuery filters history entries. Zero-valued fields do not filter.
+type HistoryQuery struct {
+	ID       string    `json:"id,omitempty"` // ID or unique ID prefix
+	Provider string    `json:"provider,omitempty"`
+	Model    string    `json:"model,omitempty"`
+	Tag      string    `json:"tag,omitempty"`
+	Text     string    `json:"text,omitempty"` // every term must appear in the prompt or response
+	Since    time.Time `json:"since,omitempty"`
+	Until    time.Time `json:"until,omitempty"`
+	Offset   int       `json:"offset,omitempty"`
+	Limit    int       `json:"limit,omitempty"` // zero returns every match
+}
+
+// HistoryPage is one page of query results
+type HistoryPage struct {
+	Entries []Result `json:"entries"`
+	Total   int      `json:"total"`
+	Offset  int      `json:"offset"`
+	Limit   int      `json:"limit"`
+}
+
+// Matches reports whether r satisfies every filter of q
+func (q HistoryQuery) Matches(r Result) bool {
+	if q.ID != "" && !strings.HasPrefix(r.ID, q.ID) {
+		return false
+	}
+	if q.Provider != "" && !strings.EqualFold(r.Provider, q.Provider) {
+		return false
+	}
+	if q.Model != "" && !strings.EqualFold(r.Model, q.Model) {
+		return false
+	}
+	if !q.Since.IsZero() && r.Timestamp.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && r.Timestamp.After(q.Until) {
+		return false
+	}
+	if q.Tag != "" {
+		found := false
+		for _, tag := range r.Tags {
+			if strings.EqualFold(tag, q.Tag) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if text := strings.TrimSpace(q.Text); text != "" {
+		haystack := strings.ToLower(r.Prompt + "\n" + r.Response)
+		for _, term := range strings.Fields(strings.ToLower(text)) {
+			if !strings.Contains(haystack, term) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ApplyHistoryQuery filters entries with q and returns the requested page, newest first
+func ApplyHistoryQuery(entries []Result, q HistoryQuery) HistoryPage {
+	matched := make([]Result, 0)
+	for _, r := range entries {
+		if q.Matches(r) {
+			matched = append(matched, r)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].Timestamp.After(matched[j].Timestamp)
+	})
+
+	page := HistoryPage{Total: len(matched), Offset: q.Offset, Limit: q.Limit}
+	if q.Offset < 0 {
+		page.Offset = 0
+	}
+	if page.Offset >= len(matched) {
+		page.Entries = []Result{}
+		return page
+	}
+	end := len(matched)
+	if q.Limit > 0 && page.Offset+q.Limit < end {
+		end = page.Offset + q.Limit
+	}
+	page.Entries = matched[page.Offset:end]
+	return page
+}
+
+// FindHistoryEntry looks an entry up by exact ID, falling back to a unique ID prefix
+func FindHistoryEntry(entries []Result, id string) (*Result, bool) {
+	if id == "" {
+		return nil, false
+	}
+	var match *Result
+	for i := range entries {
+		if entries[i].ID == id {
+			r := entries[i]
+			return &r, true
+		}
+		if strings.HasPrefix(entries[i].ID, id) {
+			if match != nil {
+				return nil, false // ambiguous prefix
+			}
+			r := entries[i]
+			match = &r
+		}
+	}
+	return match, match != nil
 }
@@ -1,6 +1,9 @@
 package interfaces
 
-import "context"
+import (
+	"context"
+	"encoding/json"
+)
 
 // Provider interface defines the contract for AI providers
 type Provider interface {
@@ -25,12 +28,37 @@ type ChatRequest struct {
 	Temp     float32           `json:"temperature"`
 	Stream   bool              `json:"stream"`
 	Meta     map[string]any    `json:"meta"`
+	Tools      []Tool `json:"tools,omitempty"`
+	// ToolChoice is "auto" (the default), "none", "required" or the name of
+	// the one tool the model must call
+	ToolChoice string `json:"tool_choice,omitempty"`
 }
 
 // Message represents a single chat message
 type Message struct {
 	Role    string `json:"role"`
 	Content string `json:"content"`
+	// Parts carry images and files alongside Content; see ContentPart
+	Parts []ContentPart `json:"parts,omitempty"`
+	// ToolCalls are the calls requested by an assistant message
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	// ToolCallID is the call a "tool" message answers
+	ToolCallID string `json:"tool_call_id,omitempty"`
+}
+
+// Tool is a provider-neutral function the model may call. Parameters is the
+// JSON Schema of its arguments.
+type Tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  map[string]any `json:"parameters,omitempty"`
+}
+
+// ToolCall is a model's request to run a tool. Arguments is a JSON object.
+type ToolCall struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
 }
 
 // Usage represents token usage and cost information
@@ -50,7 +78,8 @@ type ChatChunk struct {
 	Done     bool      `json:"done"`
 	Usage    *Usage    `json:"usage,omitempty"`
 	Error    string    `json:"error,omitempty"`
-	// ToolCall *ToolCall `json:"toolCall,omitempty"`
+	Cached   bool      `json:"cached,omitempty"` // replayed from the response cache
+	ToolCall *ToolCall `json:"toolCall,omitempty"`
 }
 
 // Capabilities describes what a provider can do
@@ -58,13 +87,16 @@ type Capabilities struct {
 	MaxTokens         int      `json:"max_tokens"`
 	SupportsBatch     bool     `json:"supports_batch"`
 	SupportsStreaming bool     `json:"supports_streaming"`
+	SupportsImages    bool     `json:"supports_images"`
+	SupportsFiles     bool     `json:"supports_files"`
+	SupportsEmbeddings bool    `json:"supports_embeddings"`
 	Models            map[string]any `json:"models"`
 	Pricing           *Pricing `json:"pricing,omitempty"`
 }
 
 // Pricing information for the provider
 type Pricing struct {
-	InputCostPer1K  float64 `json:"input_cost_per_1k"`
-	OutputCostPer1K float64 `json:"output_cost_per_1k"`
-	Currency        string  `json:"currency"`
+	InputCostPer1K  float64 `json:"input_cost_per_1k" yaml:"input_cost_per_1k"`
+	OutputCostPer1K float64 `json:"output_cost_per_1k" yaml:"output_cost_per_1k"`
+	Currency        string  `json:"currency" yaml:"currency"`
 }
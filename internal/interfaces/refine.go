@@ -0,0 +1,65 @@
+package interfaces
+
+// Criterion is one dimension of the rubric a critic scores a prompt on
+type Criterion struct {
+	Name        string  `json:"name" yaml:"name"`
+	Description string  `json:"description,omitempty" yaml:"description,omitempty"`
+	Weight      float64 `json:"weight,omitempty" yaml:"weight,omitempty"` // default 1
+}
+
+// DefaultRubric is used when RefineOptions.Rubric is empty
+var DefaultRubric = []Criterion{
+	{Name: "clarity", Description: "Instructions are unambiguous, specific and easy to follow"},
+	{Name: "completeness", Description: "Every idea and constraint of the request is covered, with the context and persona the model needs"},
+	{Name: "output_format", Description: "The expected output format and structure are stated explicitly"},
+}
+
+// RefineOptions controls the generate, critique and improve loop
+type RefineOptions struct {
+	// Provider and Model generate the prompt; empty uses the default provider
+	Provider string `json:"provider,omitempty"`
+	Model    string `json:"model,omitempty"`
+	// Critic and CriticModel score each iteration; empty uses the generator
+	Critic      string      `json:"critic,omitempty"`
+	CriticModel string      `json:"critic_model,omitempty"`
+	Rubric      []Criterion `json:"rubric,omitempty"`
+	// Threshold is the weighted score, 0..1, that ends the loop (default 0.8)
+	Threshold float64 `json:"threshold,omitempty"`
+	// MaxIterations bounds the number of generations (default 3)
+	MaxIterations int `json:"max_iterations,omitempty"`
+	MaxTokens     int `json:"max_tokens,omitempty"`
+	// Tags are added to the history entries of every generation
+	Tags []string `json:"tags,omitempty"`
+}
+
+// Critique is the critic's verdict on one generated prompt
+type Critique struct {
+	// Score is the weighted mean of Scores, 0..1
+	Score    float64            `json:"score"`
+	Scores   map[string]float64 `json:"scores"`
+	Feedback string             `json:"feedback"`
+	Usage    *Usage             `json:"usage,omitempty"`
+}
+
+// RefineIteration is one generated prompt and its critique
+type RefineIteration struct {
+	Iteration int       `json:"iteration"` // 1-based
+	Prompt    string    `json:"prompt"`
+	Critique  *Critique `json:"critique,omitempty"`
+	Usage     *Usage    `json:"usage,omitempty"`
+	HistoryID string    `json:"history_id,omitempty"`
+}
+
+// Refinement is the outcome of a refine loop. Prompt and Score are those of
+// the best iteration, which is not always the last one.
+type Refinement struct {
+	ID         string            `json:"id"`
+	Prompt     string            `json:"prompt"`
+	Score      float64           `json:"score"`
+	Best       int               `json:"best"` // iteration number of Prompt
+	Converged  bool              `json:"converged"`
+	Threshold  float64           `json:"threshold"`
+	Rubric     []Criterion       `json:"rubric"`
+	Iterations []RefineIteration `json:"iterations"`
+	Usage      *Usage            `json:"usage,omitempty"`
+}
@@ -7,7 +7,11 @@ type Result struct {
 	ID        string         `json:"id"`
 	Prompt    string         `json:"prompt"`
 	Response  string         `json:"response"`
+	JSON      any            `json:"json,omitempty"` // parsed Response, for JSON output mode
 	Provider  string         `json:"provider"`
+	Model     string         `json:"model,omitempty"`
+	Usage     *Usage         `json:"usage,omitempty"`
+	Tags      []string       `json:"tags,omitempty"`
 	Variables map[string]any `json:"variables,omitempty"`
 	Metadata  map[string]any `json:"metadata,omitempty"`
 	Timestamp time.Time      `json:"timestamp"`
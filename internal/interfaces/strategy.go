@@ -0,0 +1,52 @@
+package interfaces
+
+import "context"
+
+// GenerationRequest describes the prompt to engineer from raw ideas
+type GenerationRequest struct {
+	Ideas       []string `json:"ideas"`
+	Purpose     string   `json:"purpose,omitempty"`
+	PurposeType string   `json:"purpose_type,omitempty"`
+	Lang        string   `json:"lang,omitempty"`
+	MaxLength   int      `json:"max_length,omitempty"` // characters
+	// Strategy names the strategy to use; empty selects it by PurposeType
+	Strategy string `json:"strategy,omitempty"`
+	// Provider, Model, MaxTokens and Tags apply when the engine runs the
+	// strategy; empty Provider uses the default one
+	Provider  string   `json:"provider,omitempty"`
+	Model     string   `json:"model,omitempty"`
+	MaxTokens int      `json:"max_tokens,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+	// Examples are injected into the prompt as few-shot demonstrations.
+	// When the engine runs the strategy it retrieves them from the example
+	// library: up to MaxExamples of them (0 for the default, negative for
+	// none) unless Examples is already set.
+	Examples    []Example `json:"examples,omitempty"`
+	MaxExamples int       `json:"max_examples,omitempty"`
+}
+
+// LLM sends one prompt to the model a strategy runs on and returns its reply
+type LLM func(ctx context.Context, prompt string) (string, error)
+
+// Strategy turns ideas into a prompt in its own way. It may call the model
+// several times and post-process the replies.
+type Strategy interface {
+	Name() string
+	Description() string
+	Generate(ctx context.Context, req GenerationRequest, llm LLM) (*Generation, error)
+}
+
+// Generation is a prompt produced by a strategy
+type Generation struct {
+	Prompt   string `json:"prompt"`
+	Strategy string `json:"strategy"`
+	// Request is the first prompt the strategy sent to the model
+	Request string `json:"request,omitempty"`
+	Calls   int    `json:"calls"`
+	// Examples are the IDs of the library examples injected
+	Examples  []string `json:"examples,omitempty"`
+	Provider  string   `json:"provider,omitempty"`
+	Model     string   `json:"model,omitempty"`
+	Usage     *Usage   `json:"usage,omitempty"`
+	HistoryID string   `json:"history_id,omitempty"`
+}
@@ -1,5 +1,103 @@
 package interfaces
 
+import (
+	"encoding/json"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Variable types supported by template schemas
+const (
+	VarTypeString = "string"
+	VarTypeInt    = "int"
+	VarTypeEnum   = "enum"
+	VarTypeList   = "list"
+)
+
+// Variable declares a typed template variable. A variable declared as a plain
+// string in YAML/JSON is a required variable of any type.
+type Variable struct {
+	Name        string   `json:"name" yaml:"name"`
+	Type        string   `json:"type,omitempty" yaml:"type,omitempty"`
+	Required    bool     `json:"required,omitempty" yaml:"required,omitempty"`
+	Default     any      `json:"default,omitempty" yaml:"default,omitempty"`
+	Enum        []string `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Pattern     string   `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	MinLength   int      `json:"min_length,omitempty" yaml:"min_length,omitempty"`
+	MaxLength   int      `json:"max_length,omitempty" yaml:"max_length,omitempty"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+type variableFields Variable
+
+// isShorthand reports whether v can be written as a bare variable name.
+func (v Variable) isShorthand() bool {
+	return v.Required && v.Type == "" && v.Default == nil && len(v.Enum) == 0 &&
+		v.Pattern == "" && v.MinLength == 0 && v.MaxLength == 0 && v.Description == ""
+}
+
+// UnmarshalYAML accepts either a bare variable name or a full declaration
+func (v *Variable) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*v = Variable{Name: node.Value, Required: true}
+		return nil
+	}
+	var fields variableFields
+	if err := node.Decode(&fields); err != nil {
+		return err
+	}
+	*v = Variable(fields)
+	return nil
+}
+
+// MarshalYAML writes simple required variables as bare names
+func (v Variable) MarshalYAML() (interface{}, error) {
+	if v.isShorthand() {
+		return v.Name, nil
+	}
+	return variableFields(v), nil
+}
+
+// UnmarshalJSON accepts either a bare variable name or a full declaration
+func (v *Variable) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*v = Variable{Name: name, Required: true}
+		return nil
+	}
+	var fields variableFields
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	*v = Variable(fields)
+	return nil
+}
+
+// MarshalJSON writes simple required variables as bare names
+func (v Variable) MarshalJSON() ([]byte, error) {
+	if v.isShorthand() {
+		return json.Marshal(v.Name)
+	}
+	return json.Marshal(variableFields(v))
+}
+
+// Template represents a prompt template stored in the template library
+type Template struct {
+	Name        string                 `json:"name" yaml:"name"`
+	Content     string                 `json:"content" yaml:"content"`
+	Variables   []Variable             `json:"variables" yaml:"variables"`
+	Description string                 `json:"description" yaml:"description"`
+	Category    string                 `json:"category" yaml:"category"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+}
+
+// VariableReport describes how a set of variables matches a template
+type VariableReport struct {
+	Required []string `json:"required"`
+	Missing  []string `json:"missing,omitempty"`
+	Extra    []string `json:"extra,omitempty"`
+}
+
 // Manager handles prompt templates
 type Manager interface {
 	// Process processes a template with variables
@@ -16,4 +114,16 @@ type Manager interface {
 
 	// DeleteTemplate removes a template
 	DeleteTemplate(name string) error
+
+	// GetTemplate loads the full template record by name
+	GetTemplate(name string) (*Template, error)
+
+	// PutTemplate stores a full template record, replacing any existing one
+	PutTemplate(tmpl Template) error
+
+	// Render loads a template by name and processes it with variables
+	Render(name string, vars map[string]interface{}) (string, error)
+
+	// Check reports required, missing and extra variables for a template text
+	Check(template string, vars map[string]interface{}) (*VariableReport, error)
 }
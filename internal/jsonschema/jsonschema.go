@@ -0,0 +1,296 @@
+// Package jsonschema validates decoded JSON documents against a practical
+// subset of JSON Schema: type, enum, const, properties, required,
+// additionalProperties, items, min/maxItems, min/maxLength, pattern,
+// minimum/maximum and the anyOf/oneOf/allOf combinators.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Error lists every place where a document does not satisfy its schema.
+type Error struct {
+	Violations []string `json:"violations"`
+}
+
+func (e *Error) Error() string {
+	return "schema validation failed: " + strings.Join(e.Violations, "; ")
+}
+
+// Validate checks doc, a value decoded with encoding/json or yaml, against
+// schema. It returns an *Error listing every violation, or nil.
+func Validate(schema any, doc any) error {
+	s, err := normalize(schema)
+	if err != nil {
+		return err
+	}
+	doc, err = normalize(doc)
+	if err != nil {
+		return err
+	}
+
+	var violations []string
+	validate(s, doc, "$", &violations)
+	if len(violations) > 0 {
+		return &Error{Violations: violations}
+	}
+	return nil
+}
+
+// ValidateJSON decodes data and validates it against schema, returning the
+// decoded document.
+func ValidateJSON(schema any, data []byte) (any, error) {
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	return doc, Validate(schema, doc)
+}
+
+// Extract strips a Markdown code fence or surrounding prose from a model
+// reply, returning the outermost JSON object or array it contains.
+func Extract(s string) string {
+	s = strings.TrimSpace(s)
+	start := strings.IndexAny(s, "{[")
+	if start < 0 {
+		return s
+	}
+	closing := byte('}')
+	if s[start] == '[' {
+		closing = ']'
+	}
+	end := strings.LastIndexByte(s, closing)
+	if end < start {
+		return s
+	}
+	return s[start : end+1]
+}
+
+// normalize round-trips v through encoding/json so nested maps, slices and
+// numbers have the shapes validate expects, whatever decoder produced them
+// (yaml decodes integers as int, for instance).
+func normalize(v any) (any, error) {
+	switch v.(type) {
+	case nil, bool, string, float64:
+		return v, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported value: %w", err)
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func validate(schema any, doc any, path string, violations *[]string) {
+	fail := func(format string, args ...any) {
+		*violations = append(*violations, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	s, ok := schema.(map[string]any)
+	if !ok {
+		if b, isBool := schema.(bool); isBool && !b {
+			fail("no value is allowed here")
+		}
+		return
+	}
+
+	if t, ok := s["type"]; ok && !matchesType(t, doc) {
+		fail("expected %s, got %s", typeNames(t), typeOf(doc))
+		return
+	}
+	if enum, ok := s["enum"].([]any); ok && !containsValue(enum, doc) {
+		fail("value %v is not one of %v", doc, enum)
+	}
+	if c, ok := s["const"]; ok && !equal(c, doc) {
+		fail("value %v must equal %v", doc, c)
+	}
+
+	switch v := doc.(type) {
+	case map[string]any:
+		validateObject(s, v, path, violations, fail)
+	case []any:
+		if n, ok := number(s["minItems"]); ok && float64(len(v)) < n {
+			fail("expected at least %v items, got %d", n, len(v))
+		}
+		if n, ok := number(s["maxItems"]); ok && float64(len(v)) > n {
+			fail("expected at most %v items, got %d", n, len(v))
+		}
+		if items, ok := s["items"]; ok {
+			for i, item := range v {
+				validate(items, item, fmt.Sprintf("%s[%d]", path, i), violations)
+			}
+		}
+	case string:
+		length := float64(utf8.RuneCountInString(v))
+		if n, ok := number(s["minLength"]); ok && length < n {
+			fail("expected at least %v characters, got %v", n, length)
+		}
+		if n, ok := number(s["maxLength"]); ok && length > n {
+			fail("expected at most %v characters, got %v", n, length)
+		}
+		if p, ok := s["pattern"].(string); ok {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				fail("invalid pattern %q: %v", p, err)
+			} else if !re.MatchString(v) {
+				fail("%q does not match %s", v, p)
+			}
+		}
+	case float64:
+		if n, ok := number(s["minimum"]); ok && v < n {
+			fail("%v is less than the minimum %v", v, n)
+		}
+		if n, ok := number(s["maximum"]); ok && v > n {
+			fail("%v is greater than the maximum %v", v, n)
+		}
+	}
+
+	if all, ok := s["allOf"].([]any); ok {
+		for _, sub := range all {
+			validate(sub, doc, path, violations)
+		}
+	}
+	if anyOf, ok := s["anyOf"].([]any); ok && countMatches(anyOf, doc, path) == 0 {
+		fail("does not match any of the anyOf schemas")
+	}
+	if oneOf, ok := s["oneOf"].([]any); ok {
+		if n := countMatches(oneOf, doc, path); n != 1 {
+			fail("must match exactly one of the oneOf schemas, matched %d", n)
+		}
+	}
+}
+
+func validateObject(s map[string]any, v map[string]any, path string, violations *[]string, fail func(string, ...any)) {
+	if required, ok := s["required"].([]any); ok {
+		for _, r := range required {
+			name, _ := r.(string)
+			if _, present := v[name]; !present {
+				fail("missing required property %q", name)
+			}
+		}
+	}
+
+	props, _ := s["properties"].(map[string]any)
+	keys := make([]string, 0, len(v))
+	for k := range v {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		child := path + "." + k
+		if sub, ok := props[k]; ok {
+			validate(sub, v[k], child, violations)
+			continue
+		}
+		switch extra := s["additionalProperties"].(type) {
+		case bool:
+			if !extra {
+				fail("unexpected property %q", k)
+			}
+		case map[string]any:
+			validate(extra, v[k], child, violations)
+		}
+	}
+}
+
+func countMatches(schemas []any, doc any, path string) int {
+	n := 0
+	for _, sub := range schemas {
+		var v []string
+		validate(sub, doc, path, &v)
+		if len(v) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func matchesType(t any, doc any) bool {
+	switch t := t.(type) {
+	case string:
+		return isType(t, doc)
+	case []any:
+		for _, name := range t {
+			if s, ok := name.(string); ok && isType(s, doc) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+func isType(name string, doc any) bool {
+	switch name {
+	case "integer":
+		f, ok := doc.(float64)
+		return ok && f == math.Trunc(f)
+	case "number":
+		_, ok := doc.(float64)
+		return ok
+	default:
+		return typeOf(doc) == name
+	}
+}
+
+func typeOf(doc any) string {
+	switch doc.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", doc)
+	}
+}
+
+func typeNames(t any) string {
+	if list, ok := t.([]any); ok {
+		names := make([]string, 0, len(list))
+		for _, n := range list {
+			names = append(names, fmt.Sprint(n))
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(t)
+}
+
+func number(v any) (float64, bool) {
+	f, ok := v.(float64)
+	return f, ok
+}
+
+func containsValue(list []any, v any) bool {
+	for _, item := range list {
+		if equal(item, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func equal(a, b any) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(ja) == string(jb)
+}
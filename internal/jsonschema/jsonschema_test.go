@@ -0,0 +1,62 @@
+package jsonschema
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const reviewSchema = `
+type: object
+required: [verdict, issues]
+additionalProperties: false
+properties:
+  verdict:
+    enum: [approve, request_changes]
+  score:
+    type: integer
+    minimum: 0
+    maximum: 10
+  issues:
+    type: array
+    maxItems: 2
+    items:
+      type: object
+      required: [line]
+      properties:
+        line: {type: integer}
+        note: {type: string, minLength: 3}
+`
+
+func TestValidateJSON(t *testing.T) {
+	var schema any
+	if err := yaml.Unmarshal([]byte(reviewSchema), &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ValidateJSON(schema, []byte(`{"verdict":"approve","score":7,"issues":[{"line":3,"note":"nil check"}]}`)); err != nil {
+		t.Fatalf("expected a valid document, got %v", err)
+	}
+
+	_, err := ValidateJSON(schema, []byte(`{"verdict":"maybe","score":7.5,"issues":[{"note":"x"}],"extra":1}`))
+	var verr *Error
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *Error, got %v", err)
+	}
+	want := []string{
+		`$: unexpected property "extra"`,
+		`$.issues[0]: missing required property "line"`,
+		`$.issues[0].note: expected at least 3 characters, got 1`,
+		`$.score: expected integer, got number`,
+		`$.verdict: value maybe is not one of [approve request_changes]`,
+	}
+	if !reflect.DeepEqual(verr.Violations, want) {
+		t.Fatalf("got violations %q, want %q", verr.Violations, want)
+	}
+
+	if _, err := ValidateJSON(schema, []byte(`not json`)); err == nil {
+		t.Fatal("expected an error for invalid JSON")
+	}
+}
@@ -0,0 +1,203 @@
+// Package lint statically analyses prompt text for common authoring mistakes
+// without calling an LLM.
+package lint
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Severity of a finding
+type Severity string
+
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+func (s Severity) rank() int {
+	switch s {
+	case SeverityError:
+		return 2
+	case SeverityWarning:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// AtLeast reports whether s is as severe as other
+func (s Severity) AtLeast(other Severity) bool { return s.rank() >= other.rank() }
+
+// Position locates a finding in the prompt. Line and Column are 1-based;
+// Column counts runes. Offset is the byte offset.
+type Position struct {
+	Offset int `json:"offset"`
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Finding is a single lint result.
+type Finding struct {
+	Rule     string   `json:"rule"` // rule ID, e.g. GP001
+	Name     string   `json:"name"` // rule name, e.g. missing-output-format
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	Position Position `json:"position"`
+	Excerpt  string   `json:"excerpt,omitempty"`
+}
+
+// Options tunes the linter. The zero value uses the defaults.
+type Options struct {
+	// MaxTokens is the estimated token count above which a prompt is too long (default 3000)
+	MaxTokens int `json:"max_tokens,omitempty"`
+	// Vars are variables that will be supplied at render time; placeholders for them are not reported
+	Vars map[string]any `json:"vars,omitempty"`
+	// Disable lists rule IDs or names to skip
+	Disable []string `json:"disable,omitempty"`
+}
+
+// Report is the outcome of linting one prompt.
+type Report struct {
+	Findings []Finding `json:"findings"`
+	Tokens   int       `json:"estimated_tokens"`
+	Errors   int       `json:"errors"`
+	Warnings int       `json:"warnings"`
+}
+
+// Has reports whether any finding is at least as severe as min
+func (r Report) Has(min Severity) bool {
+	for _, f := range r.Findings {
+		if f.Severity.AtLeast(min) {
+			return true
+		}
+	}
+	return false
+}
+
+// Rule is a single lint check.
+type Rule struct {
+	ID          string
+	Name        string
+	Severity    Severity
+	Description string
+	check       func(d *document, opts Options) []Finding
+}
+
+// Rules returns the built-in rules in ID order.
+func Rules() []Rule {
+	return []Rule{
+		{ID: "GP001", Name: "missing-output-format", Severity: SeverityWarning, Description: "The prompt does not say what shape the answer should take.", check: checkOutputFormat},
+		{ID: "GP002", Name: "missing-role", Severity: SeverityWarning, Description: "The prompt does not give the model a role or persona.", check: checkRole},
+		{ID: "GP003", Name: "ambiguous-pronoun", Severity: SeverityInfo, Description: "A paragraph opens with a pronoun that has no antecedent.", check: checkPronouns},
+		{ID: "GP004", Name: "conflicting-instructions", Severity: SeverityWarning, Description: "Two instructions contradict each other.", check: checkConflicts},
+		{ID: "GP005", Name: "unresolved-placeholder", Severity: SeverityError, Description: "A {{.var}} placeholder was left in the text.", check: checkPlaceholders},
+		{ID: "GP006", Name: "excessive-length", Severity: SeverityWarning, Description: "The prompt is longer than the configured token budget.", check: checkLength},
+	}
+}
+
+// Lint runs every enabled rule over text.
+func Lint(text string, opts Options) Report {
+	if opts.MaxTokens <= 0 {
+		opts.MaxTokens = defaultMaxTokens
+	}
+	disabled := make(map[string]bool, len(opts.Disable))
+	for _, d := range opts.Disable {
+		disabled[strings.ToLower(strings.TrimSpace(d))] = true
+	}
+
+	d := newDocument(text)
+	report := Report{Findings: []Finding{}, Tokens: d.tokens()}
+	for _, rule := range Rules() {
+		if disabled[strings.ToLower(rule.ID)] || disabled[rule.Name] {
+			continue
+		}
+		for _, f := range rule.check(d, opts) {
+			f.Rule, f.Name = rule.ID, rule.Name
+			if f.Severity == "" {
+				f.Severity = rule.Severity
+			}
+			report.Findings = append(report.Findings, f)
+		}
+	}
+
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		return report.Findings[i].Position.Offset < report.Findings[j].Position.Offset
+	})
+	for _, f := range report.Findings {
+		switch f.Severity {
+		case SeverityError:
+			report.Errors++
+		case SeverityWarning:
+			report.Warnings++
+		}
+	}
+	return report
+}
+
+// document is the prompt text with a line index for position lookups.
+type document struct {
+	text       string
+	lower      string
+	lineStarts []int
+}
+
+func newDocument(text string) *document {
+	d := &document{text: text, lower: foldCase(text), lineStarts: []int{0}}
+	for i, c := range text {
+		if c == '\n' {
+			d.lineStarts = append(d.lineStarts, i+1)
+		}
+	}
+	return d
+}
+
+// foldCase lower-cases text rune by rune, keeping any rune whose lower-case
+// form has a different encoded length so that byte offsets into the result
+// are valid in text.
+func foldCase(text string) string {
+	return strings.Map(func(r rune) rune {
+		if l := unicode.ToLower(r); utf8.RuneLen(l) == utf8.RuneLen(r) {
+			return l
+		}
+		return r
+	}, text)
+}
+
+// position converts a byte offset into a Position.
+func (d *document) position(offset int) Position {
+	line := sort.Search(len(d.lineStarts), func(i int) bool { return d.lineStarts[i] > offset }) - 1
+	if line < 0 {
+		line = 0
+	}
+	col := utf8.RuneCountInString(d.text[d.lineStarts[line]:offset]) + 1
+	return Position{Offset: offset, Line: line + 1, Column: col}
+}
+
+// excerpt returns the trimmed line containing offset, shortened to 80 runes.
+func (d *document) excerpt(offset int) string {
+	start := strings.LastIndexByte(d.text[:offset], '\n') + 1
+	end := strings.IndexByte(d.text[offset:], '\n')
+	if end < 0 {
+		end = len(d.text)
+	} else {
+		end += offset
+	}
+	line := strings.TrimSpace(d.text[start:end])
+	if r := []rune(line); len(r) > 80 {
+		line = string(r[:77]) + "..."
+	}
+	return line
+}
+
+func (d *document) finding(offset int, message string) Finding {
+	return Finding{Message: message, Position: d.position(offset), Excerpt: d.excerpt(offset)}
+}
+
+// tokens estimates the token count at four characters per token.
+func (d *document) tokens() int {
+	return (utf8.RuneCountInString(d.text) + 3) / 4
+}
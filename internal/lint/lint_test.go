@@ -0,0 +1,128 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+)
+
+const goodPrompt = `You are a senior Go reviewer.
+
+Review the diff below and list every bug you find.
+
+## Output
+Respond with a markdown list, one bullet per issue.
+`
+
+func rulesOf(r Report) []string {
+	ids := make([]string, 0, len(r.Findings))
+	for _, f := range r.Findings {
+		ids = append(ids, f.Rule)
+	}
+	return ids
+}
+
+func find(r Report, rule string) *Finding {
+	for i := range r.Findings {
+		if r.Findings[i].Rule == rule {
+			return &r.Findings[i]
+		}
+	}
+	return nil
+}
+
+func TestLintCleanPrompt(t *testing.T) {
+	r := Lint(goodPrompt, Options{})
+	if len(r.Findings) != 0 {
+		t.Fatalf("expected no findings, got %v: %+v", rulesOf(r), r.Findings)
+	}
+	if r.Tokens == 0 {
+		t.Fatal("expected a token estimate")
+	}
+}
+
+func TestLintMissingRoleAndFormat(t *testing.T) {
+	r := Lint("Summarise the attached article.", Options{})
+	if find(r, "GP001") == nil || find(r, "GP002") == nil {
+		t.Fatalf("expected GP001 and GP002, got %v", rulesOf(r))
+	}
+	if r.Warnings != 2 || r.Errors != 0 {
+		t.Fatalf("unexpected counts: %d warnings, %d errors", r.Warnings, r.Errors)
+	}
+
+	// Portuguese prompts produced by GetBaseGenerationPrompt are recognised
+	r = Lint("Você é um redator técnico. Responda em formato de lista.", Options{})
+	if len(r.Findings) != 0 {
+		t.Fatalf("expected no findings for Portuguese prompt, got %v", rulesOf(r))
+	}
+}
+
+func TestLintAmbiguousPronoun(t *testing.T) {
+	text := goodPrompt + "\n## Notes\nIt should not be changed.\n"
+	r := Lint(text, Options{})
+	f := find(r, "GP003")
+	if f == nil {
+		t.Fatalf("expected GP003, got %v", rulesOf(r))
+	}
+	if f.Severity != SeverityInfo || f.Position.Line != 9 || f.Position.Column != 1 {
+		t.Fatalf("unexpected finding: %+v", f)
+	}
+
+	// A determiner is not a dangling pronoun
+	if r := Lint(goodPrompt+"\n## Notes\nThis function is hot.\n", Options{}); find(r, "GP003") != nil {
+		t.Fatalf("determiner flagged: %+v", r.Findings)
+	}
+}
+
+func TestLintConflictingInstructions(t *testing.T) {
+	text := goodPrompt + "Be concise.\nExplain everything in detail.\nAlways use tabs.\nNever use tabs.\n"
+	r := Lint(text, Options{})
+	var conflicts []Finding
+	for _, f := range r.Findings {
+		if f.Rule == "GP004" {
+			conflicts = append(conflicts, f)
+		}
+	}
+	if len(conflicts) != 2 {
+		t.Fatalf("expected 2 conflicts, got %+v", conflicts)
+	}
+	if conflicts[0].Position.Line != 8 || !strings.Contains(conflicts[0].Message, "line 7") {
+		t.Fatalf("unexpected length conflict: %+v", conflicts[0])
+	}
+	if conflicts[1].Position.Line != 10 || conflicts[1].Excerpt != "Never use tabs." {
+		t.Fatalf("unexpected always/never conflict: %+v", conflicts[1])
+	}
+}
+
+func TestLintUnresolvedPlaceholders(t *testing.T) {
+	text := goodPrompt + "Focus on {{.topic}} in {{ $.lang }}. {{/* note */}}\n"
+	r := Lint(text, Options{Vars: map[string]any{"lang": "Go"}})
+	f := find(r, "GP005")
+	if f == nil || f.Severity != SeverityError {
+		t.Fatalf("expected GP005 error, got %+v", r.Findings)
+	}
+	if f.Message != "unresolved placeholder {{.topic}}" || f.Position.Column != 10 {
+		t.Fatalf("unexpected finding: %+v", f)
+	}
+	if r.Errors != 1 || !r.Has(SeverityError) {
+		t.Fatalf("expected exactly one error, got %d", r.Errors)
+	}
+}
+
+func TestLintExcessiveLength(t *testing.T) {
+	text := goodPrompt + strings.Repeat("word ", 100)
+	r := Lint(text, Options{MaxTokens: 50})
+	f := find(r, "GP006")
+	if f == nil {
+		t.Fatalf("expected GP006, got %v", rulesOf(r))
+	}
+	if f.Position.Offset != 200 {
+		t.Fatalf("expected the finding where the budget runs out, got %+v", f.Position)
+	}
+}
+
+func TestLintDisable(t *testing.T) {
+	r := Lint("Summarise the attached article.", Options{Disable: []string{"GP001", "missing-role"}})
+	if len(r.Findings) != 0 {
+		t.Fatalf("expected disabled rules to be skipped, got %v", rulesOf(r))
+	}
+}
@@ -0,0 +1,186 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// defaultMaxTokens is the estimated prompt size above which GP006 fires
+const defaultMaxTokens = 3000
+
+// Keywords are matched against the lower-cased prompt. Portuguese variants are
+// included because GetBaseGenerationPrompt can write its meta-prompt in Portuguese.
+var (
+	outputFormatPattern = regexp.MustCompile(`(?m)\b(output format|response format|format:|formatted as|respond (with|in|using|only)|answer (with|in)|reply (with|in)|return (a|an|only|the result|json|yaml|markdown)|as (json|yaml|csv|a table|a list|bullet points)|in (json|yaml|csv|markdown)|json (object|array|schema)|bullet(ed)? (list|points)|numbered list|markdown table|^#+ *(output|format|response)\b|formato|responda|retorne|saída)`)
+
+	rolePattern = regexp.MustCompile(`\b(you are|you're|act as|acting as|take the role|assume the role|your role|role:|persona:|as an? (expert|senior|experienced|professional|specialist)|você é|aja como|atue como|seu papel|papel:)`)
+
+	// pronounPattern matches a pronoun at the start of a paragraph. this/that
+	// only count when used on their own, not as a determiner ("This function").
+	pronounPattern = regexp.MustCompile(`^(?:(it|they|them|isso|isto|ele|ela|eles|elas)\b|(this|that|these|those)(?:\s+(?:is|was|are|were|should|must|needs?|will|can|could|does|do|has|have)\b|\s*[,.:;!?]|\s*$))`)
+
+	placeholderPattern = regexp.MustCompile(`\{\{-?\s*(.*?)\s*-?\}\}`)
+	placeholderVar     = regexp.MustCompile(`^\$?\.?([A-Za-z_][A-Za-z0-9_]*)`)
+
+	alwaysPattern = regexp.MustCompile(`\b(always|sempre) ([a-zà-ú]+(?: [a-zà-ú]+)?)`)
+	neverPattern  = regexp.MustCompile(`\b(never|do not|don't|nunca|não) ([a-zà-ú]+(?: [a-zà-ú]+)?)`)
+)
+
+// conflicts are pairs of instructions that cannot both be followed.
+var conflicts = []struct {
+	topic string
+	a, b  *regexp.Regexp
+}{
+	{
+		topic: "length",
+		a:     regexp.MustCompile(`\b(be (concise|brief|succinct)|keep it (short|brief)|short answers?|in one sentence|seja (conciso|breve|sucinto))\b`),
+		b:     regexp.MustCompile(`\b(be (detailed|thorough|comprehensive|exhaustive)|in (great |full )?detail|explain (everything|thoroughly)|seja (detalhado|minucioso)|em detalhes)\b`),
+	},
+	{
+		topic: "tone",
+		a:     regexp.MustCompile(`\b(formal (tone|language|style)|be formal|tom formal)\b`),
+		b:     regexp.MustCompile(`\b(informal|casual|conversational|tom descontraído)\b`),
+	},
+	{
+		topic: "output format",
+		a:     regexp.MustCompile(`\b((respond|answer|reply|output|return)( only)? (in|with|as) json|only json|apenas json)\b`),
+		b:     regexp.MustCompile(`\b((respond|answer|reply|output|return)( only)? (in|with|as|using) markdown|only markdown|apenas markdown)\b`),
+	},
+	{
+		topic: "code",
+		a:     regexp.MustCompile(`\b(include (a )?code|code (examples?|samples?|snippets?)|inclua código)\b`),
+		b:     regexp.MustCompile(`\b(no code|without code|(do not|don't) include (any )?code|sem código)\b`),
+	},
+}
+
+func checkOutputFormat(d *document, _ Options) []Finding {
+	if strings.TrimSpace(d.text) == "" || outputFormatPattern.MatchString(d.lower) {
+		return nil
+	}
+	return []Finding{{
+		Message:  "no output format: say how the answer should be structured (e.g. JSON, a markdown list, a table)",
+		Position: d.position(0),
+	}}
+}
+
+func checkRole(d *document, _ Options) []Finding {
+	if strings.TrimSpace(d.text) == "" || rolePattern.MatchString(d.lower) {
+		return nil
+	}
+	return []Finding{{
+		Message:  `no role or persona: start with who the model should be (e.g. "You are a senior Go reviewer")`,
+		Position: d.position(0),
+	}}
+}
+
+// checkPronouns flags the first paragraph of the prompt and the first
+// paragraph of each markdown section when they open with a pronoun: nothing
+// before them can be its antecedent.
+func checkPronouns(d *document, _ Options) []Finding {
+	var out []Finding
+	fresh := true // at the start of the prompt or right after a heading
+	for i, start := range d.lineStarts {
+		end := len(d.text)
+		if i+1 < len(d.lineStarts) {
+			end = d.lineStarts[i+1]
+		}
+		line := d.lower[start:end]
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "#") {
+			fresh = true
+			continue
+		}
+		if !fresh {
+			continue
+		}
+		fresh = false
+
+		lead := len(line) - len(strings.TrimLeft(line, " \t-*>0123456789.)"))
+		if m := pronounPattern.FindStringSubmatch(line[lead:]); m != nil {
+			word := strings.TrimSpace(strings.SplitN(m[0], " ", 2)[0])
+			out = append(out, d.finding(start+lead, fmt.Sprintf("%q has nothing to refer to here; name the thing explicitly", word)))
+		}
+	}
+	return out
+}
+
+func checkConflicts(d *document, _ Options) []Finding {
+	var out []Finding
+	for _, c := range conflicts {
+		a := c.a.FindStringIndex(d.lower)
+		b := c.b.FindStringIndex(d.lower)
+		if a == nil || b == nil {
+			continue
+		}
+		first, second := a, b
+		if b[0] < a[0] {
+			first, second = b, a
+		}
+		out = append(out, d.finding(second[0], fmt.Sprintf("%s instruction %q conflicts with %q on line %d",
+			c.topic, d.text[second[0]:second[1]], d.text[first[0]:first[1]], d.position(first[0]).Line)))
+	}
+
+	// "Always X" against "Never X" / "Do not X"
+	always := map[string][]int{}
+	for _, m := range alwaysPattern.FindAllStringSubmatchIndex(d.lower, -1) {
+		phrase := d.lower[m[4]:m[5]]
+		if _, ok := always[phrase]; !ok {
+			always[phrase] = m
+		}
+	}
+	for _, m := range neverPattern.FindAllStringSubmatchIndex(d.lower, -1) {
+		phrase := d.lower[m[4]:m[5]]
+		a, ok := always[phrase]
+		if !ok {
+			continue
+		}
+		first, second := a, m
+		if m[0] < a[0] {
+			first, second = m, a
+		}
+		out = append(out, d.finding(second[0], fmt.Sprintf("%q conflicts with %q on line %d",
+			d.text[second[0]:second[1]], d.text[first[0]:first[1]], d.position(first[0]).Line)))
+	}
+	return out
+}
+
+func checkPlaceholders(d *document, opts Options) []Finding {
+	var out []Finding
+	for _, m := range placeholderPattern.FindAllStringSubmatchIndex(d.text, -1) {
+		action := d.text[m[2]:m[3]]
+		if strings.HasPrefix(action, "/*") {
+			continue
+		}
+		if v := placeholderVar.FindStringSubmatch(action); v != nil {
+			if _, ok := opts.Vars[v[1]]; ok {
+				continue
+			}
+		}
+		out = append(out, d.finding(m[0], fmt.Sprintf("unresolved placeholder %s", d.text[m[0]:m[1]])))
+	}
+	return out
+}
+
+func checkLength(d *document, opts Options) []Finding {
+	tokens := d.tokens()
+	if tokens <= opts.MaxTokens {
+		return nil
+	}
+	// Point at the place where the budget runs out.
+	offset, runes := 0, 0
+	for i := range d.text {
+		if runes == opts.MaxTokens*4 {
+			offset = i
+			break
+		}
+		runes++
+	}
+	return []Finding{{
+		Message:  fmt.Sprintf("prompt is ~%d tokens, over the limit of %d", tokens, opts.MaxTokens),
+		Position: d.position(offset),
+	}}
+}
@@ -0,0 +1,258 @@
+// Package metaprompt holds the meta-prompts that turn raw ideas into a
+// structured prompt, keyed by instruction language and purpose type. The
+// catalog is embedded and can be overridden file by file from a user
+// directory laid out the same way: <dir>/<lang>/<purpose>.tmpl.
+package metaprompt
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+
+	gl "github.com/kubex-ecosystem/logz/logger"
+
+	"github.com/kubex-ecosystem/grompt/internal/module/kbx"
+)
+
+//go:embed catalog
+var embedded embed.FS
+
+// Purpose types with a meta-prompt of their own. Anything else is rendered
+// with PurposeGeneral.
+const (
+	PurposeGeneral      = "general"
+	PurposeCode         = "code"
+	PurposeCreative     = "creative"
+	PurposeDataAnalysis = "data_analysis"
+	PurposeTutorial     = "tutorial"
+	PurposeAgent        = "agent"
+)
+
+// Purposes lists the built-in purpose types
+var Purposes = []string{PurposeGeneral, PurposeCode, PurposeCreative, PurposeDataAnalysis, PurposeTutorial, PurposeAgent}
+
+// FallbackLang is the catalog language used when the requested one has no
+// meta-prompt. The generated prompt is still asked for in the requested one.
+const FallbackLang = "en"
+
+// Defaults applied to empty arguments
+const (
+	DefaultLang      = "english"
+	DefaultMaxLength = 2000
+)
+
+var purposeAliases = map[string]string{
+	"code": PurposeCode, "coding": PurposeCode, "programming": PurposeCode, "software": PurposeCode, "development": PurposeCode,
+	"creative": PurposeCreative, "creative_writing": PurposeCreative, "writing": PurposeCreative, "storytelling": PurposeCreative,
+	"data_analysis": PurposeDataAnalysis, "data": PurposeDataAnalysis, "analysis": PurposeDataAnalysis, "analytics": PurposeDataAnalysis, "data_science": PurposeDataAnalysis,
+	"tutorial": PurposeTutorial, "educational": PurposeTutorial, "education": PurposeTutorial, "teaching": PurposeTutorial, "course": PurposeTutorial,
+	"agent": PurposeAgent, "agent_system_prompt": PurposeAgent, "system_prompt": PurposeAgent, "system": PurposeAgent, "assistant": PurposeAgent, "chatbot": PurposeAgent,
+	"general": PurposeGeneral, "generic": PurposeGeneral,
+}
+
+var langAliases = map[string]string{
+	"english": "en", "inglês": "en", "ingles": "en",
+	"portuguese": "pt", "português": "pt", "portugues": "pt",
+	"spanish": "es", "español": "es", "espanol": "es", "espanhol": "es",
+	"french": "fr", "français": "fr", "francês": "fr",
+	"german": "de", "deutsch": "de", "alemão": "de",
+	"italian": "it", "italiano": "it",
+}
+
+// Purpose normalizes a purpose type: "Data Analysis" and "analytics" both
+// become PurposeDataAnalysis. Unknown types are returned lower-cased, so a
+// user directory can add meta-prompts for them.
+func Purpose(purposeType string) string {
+	key := strings.Join(strings.FieldsFunc(strings.ToLower(purposeType), func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_' || r == '/'
+	}), "_")
+	if key == "" {
+		return PurposeGeneral
+	}
+	if p, ok := purposeAliases[key]; ok {
+		return p
+	}
+	return key
+}
+
+// Language normalizes a language name or tag to the catalog key: "English",
+// "en-US" and "inglês" all become "en".
+func Language(lang string) string {
+	key := strings.ToLower(strings.TrimSpace(lang))
+	if code, ok := langAliases[key]; ok {
+		return code
+	}
+	if code, _, ok := strings.Cut(strings.ReplaceAll(key, "_", "-"), "-"); ok && len(code) == 2 {
+		return code
+	}
+	if key == "" {
+		return FallbackLang
+	}
+	return key
+}
+
+// Data is what a meta-prompt template is rendered with
+type Data struct {
+	Ideas       []string
+	Purpose     string // free-form purpose description
+	PurposeType string // as requested
+	Lang        string // language the prompt is written in, as requested
+	MaxLength   int    // in characters
+}
+
+// Catalog is a set of parsed meta-prompts keyed by "<lang>/<purpose>"
+type Catalog struct {
+	templates map[string]*template.Template
+}
+
+var funcs = template.FuncMap{
+	"add": func(a, b int) int { return a + b },
+}
+
+// New loads the embedded catalog and overlays the meta-prompts found in dir,
+// which may be empty or missing.
+func New(dir string) (*Catalog, error) {
+	c := &Catalog{templates: map[string]*template.Template{}}
+	sub, err := fs.Sub(embedded, "catalog")
+	if err != nil {
+		return nil, err
+	}
+	if err := c.load(sub); err != nil {
+		return nil, err
+	}
+	if dir == "" {
+		return c, nil
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return c, nil
+	}
+	if err := c.load(os.DirFS(dir)); err != nil {
+		return nil, fmt.Errorf("meta-prompts in %s: %w", dir, err)
+	}
+	return c, nil
+}
+
+func (c *Catalog) load(fsys fs.FS) error {
+	return fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || path.Ext(name) != ".tmpl" {
+			return nil
+		}
+		lang, file := path.Split(name)
+		lang = strings.Trim(lang, "/")
+		if lang == "" || strings.Contains(lang, "/") {
+			return nil
+		}
+		text, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		key := Language(lang) + "/" + Purpose(strings.TrimSuffix(file, ".tmpl"))
+		tpl, err := template.New(key).Funcs(funcs).Option("missingkey=error").Parse(string(text))
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		c.templates[key] = tpl
+		return nil
+	})
+}
+
+// Keys lists the loaded meta-prompts as "<lang>/<purpose>"
+func (c *Catalog) Keys() []string {
+	keys := make([]string, 0, len(c.templates))
+	for k := range c.templates {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Lookup returns the meta-prompt for lang and purposeType, falling back to
+// the general one of the language, then to FallbackLang, and its key.
+func (c *Catalog) Lookup(lang, purposeType string) (*template.Template, string) {
+	l, p := Language(lang), Purpose(purposeType)
+	for _, key := range []string{l + "/" + p, l + "/" + PurposeGeneral, FallbackLang + "/" + p, FallbackLang + "/" + PurposeGeneral} {
+		if tpl, ok := c.templates[key]; ok {
+			return tpl, key
+		}
+	}
+	return nil, ""
+}
+
+// Render builds the meta-prompt for the given ideas. Empty lang and
+// maxLength take DefaultLang and DefaultMaxLength; an empty purpose type
+// selects PurposeGeneral.
+func (c *Catalog) Render(ideas []string, purpose, purposeType, lang string, maxLength int) (string, error) {
+	if lang == "" {
+		lang = DefaultLang
+	}
+	if maxLength <= 0 {
+		maxLength = DefaultMaxLength
+	}
+	if purposeType == "" {
+		purposeType = PurposeGeneral
+	}
+	tpl, key := c.Lookup(lang, purposeType)
+	if tpl == nil {
+		return "", fmt.Errorf("no meta-prompt for %s/%s", Language(lang), Purpose(purposeType))
+	}
+	var buf bytes.Buffer
+	data := Data{Ideas: ideas, Purpose: purpose, PurposeType: purposeType, Lang: lang, MaxLength: maxLength}
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("meta-prompt %s: %w", key, err)
+	}
+	return buf.String(), nil
+}
+
+// DefaultDir returns the user meta-prompt directory. GROMPT_METAPROMPTS_DIR
+// overrides the default under ~/.kubex/grompt.
+func DefaultDir() string {
+	if dir := os.Getenv("GROMPT_METAPROMPTS_DIR"); dir != "" {
+		return dir
+	}
+	return os.ExpandEnv(kbx.DefaultGromptMetaPrompts)
+}
+
+var (
+	defaultOnce    sync.Once
+	defaultCatalog *Catalog
+	builtinCatalog *Catalog
+)
+
+// Default returns the catalog of DefaultDir, loaded once per process. When
+// the user directory cannot be loaded a warning is logged and the embedded
+// catalog is used.
+func Default() *Catalog {
+	defaultOnce.Do(func() {
+		var err error
+		if builtinCatalog, err = New(""); err != nil {
+			panic(fmt.Sprintf("embedded meta-prompt catalog: %v", err))
+		}
+		if defaultCatalog, err = New(DefaultDir()); err != nil {
+			gl.Log("warn", fmt.Sprintf("Ignoring user meta-prompts: %v", err))
+			defaultCatalog = builtinCatalog
+		}
+	})
+	return defaultCatalog
+}
+
+// Build renders the meta-prompt from the Default catalog. A user meta-prompt
+// that fails to render is reported and replaced by the embedded one.
+func Build(ideas []string, purpose, purposeType, lang string, maxLength int) string {
+	out, err := Default().Render(ideas, purpose, purposeType, lang, maxLength)
+	if err == nil {
+		return out
+	}
+	gl.Log("warn", fmt.Sprintf("Falling back to the embedded meta-prompt: %v", err))
+	out, _ = builtinCatalog.Render(ideas, purpose, purposeType, lang, maxLength)
+	return out
+}
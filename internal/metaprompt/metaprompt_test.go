@@ -0,0 +1,90 @@
+package metaprompt
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderFollowsLanguageAndPurpose(t *testing.T) {
+	c, err := New("")
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	out, err := c.Render([]string{"sql", "indexes"}, "", "Coding", "English", 1500)
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	if !strings.Contains(out, "senior software engineer") || !strings.Contains(out, `2. "indexes"`) || !strings.Contains(out, "1500 characters") {
+		t.Fatalf("expected the English code meta-prompt, got:\n%s", out)
+	}
+
+	out, err = c.Render([]string{"bot"}, "support", "agent system prompt", "pt-BR", 0)
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	if !strings.Contains(out, "prompts de sistema") || !strings.Contains(out, "(support)") || !strings.Contains(out, "2000 caracteres") {
+		t.Fatalf("expected the Portuguese agent meta-prompt, got:\n%s", out)
+	}
+
+	// No Spanish catalog: English instructions, Spanish output
+	out, err = c.Render([]string{"x"}, "", "poetry", "spanish", 0)
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	if !strings.Contains(out, "expert prompt engineer") || !strings.Contains(out, "written in spanish") {
+		t.Fatalf("expected the English general meta-prompt asking for Spanish, got:\n%s", out)
+	}
+}
+
+func TestUserDirectoryOverrides(t *testing.T) {
+	dir := t.TempDir()
+	for name, text := range map[string]string{
+		"en/code.tmpl":    "house style for {{len .Ideas}} ideas",
+		"de/general.tmpl": "Du bist ein Prompt-Ingenieur. {{.Lang}}",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c, err := New(dir)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	if out, _ := c.Render([]string{"a", "b"}, "", "code", "en", 0); out != "house style for 2 ideas" {
+		t.Fatalf("expected the user meta-prompt to override, got %q", out)
+	}
+	if out, _ := c.Render(nil, "", "code", "German", 0); out != "Du bist ein Prompt-Ingenieur. German" {
+		t.Fatalf("expected a new language from the user directory, got %q", out)
+	}
+	if _, key := c.Lookup("en", "tutorial"); key != "en/tutorial" {
+		t.Fatalf("expected embedded entries to remain, got %q", key)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "en", "broken.tmpl"), []byte("{{.Ideas"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(dir); err == nil {
+		t.Fatal("expected a malformed user meta-prompt to be reported")
+	}
+}
+
+func TestNormalization(t *testing.T) {
+	for in, want := range map[string]string{"Data Analysis": PurposeDataAnalysis, "educational": PurposeTutorial, "": PurposeGeneral, "Legal-Review": "legal_review"} {
+		if got := Purpose(in); got != want {
+			t.Errorf("Purpose(%q) = %q, want %q", in, got, want)
+		}
+	}
+	for in, want := range map[string]string{"Português": "pt", "en_US": "en", "": FallbackLang, "klingon": "klingon"} {
+		if got := Language(in); got != want {
+			t.Errorf("Language(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -10,6 +10,13 @@ const (
 	DefaultGoBECAPath     = "$HOME/.kubex/gobe/ca-cert.pem"
 	DefaultGoBEConfigPath = "$HOME/.kubex/gobe/config/config.json"
 
+	DefaultGromptDir          = "$HOME/.kubex/grompt"
+	DefaultGromptHistoryPath  = "$HOME/.kubex/grompt/history.jsonl"
+	DefaultGromptCacheDir     = "$HOME/.kubex/grompt/cache"
+	DefaultGromptMetaPrompts  = "$HOME/.kubex/grompt/metaprompts"
+	DefaultGromptExamplesPath = "$HOME/.kubex/grompt/examples.jsonl"
+	DefaultGromptVectorsDir   = "$HOME/.kubex/grompt/vectors"
+
 	DefaultConfigDir        = "$HOME/.kubex/gdbase/config"
 	DefaultConfigFile       = "$HOME/.kubex/gdbase/config.json"
 	DefaultGDBaseConfigPath = "$HOME/.kubex/gdbase/config/config.json"
@@ -65,6 +65,11 @@ func (m *Grompt) Command() *cobra.Command {
 	rtCmd.AddCommand(vs.CliCommand())
 	rtCmd.AddCommand(cc.NewDaemonCommand())
 	rtCmd.AddCommand(cc.GatewayCmd())
+	rtCmd.AddCommand(cc.HistoryCmd())
+	rtCmd.AddCommand(cc.EvalCmd())
+	rtCmd.AddCommand(cc.LintCmd())
+	rtCmd.AddCommand(cc.RunCmd())
+	rtCmd.AddCommand(cc.ExamplesCmd())
 
 
 	// Set usage definitions for the command and its subcommands
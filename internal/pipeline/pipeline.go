@@ -0,0 +1,182 @@
+// Package pipeline runs multi-step prompt flows declared in YAML: each step
+// renders a template and sends it to a provider, with inputs mapped from the
+// outputs of earlier steps, optional conditions and parallel fan-out.
+package pipeline
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Pipeline is a named sequence of steps.
+//
+//	name: article
+//	provider: openai                 # default for every step
+//	inputs: {topic: Go generics}     # defaults, overridden at run time
+//	steps:
+//	  - id: ideas
+//	    prompt: "List five angles on {{.topic}}"
+//	  - id: draft
+//	    template: writing/draft      # stored template
+//	    provider: claude
+//	    inputs: {ideas: "{{.steps.ideas.output}}"}
+//	  - id: reviews
+//	    parallel:
+//	      - {id: style, prompt: "Review the style: {{.draft}}", inputs: {draft: "{{.steps.draft.output}}"}}
+//	      - {id: facts, prompt: "Check the facts: {{.draft}}", inputs: {draft: "{{.steps.draft.output}}"}}
+//	  - id: final
+//	    if: '{{contains .steps.reviews.output "REVISE"}}'
+//	    prompt: "Revise: {{.draft}} following {{.notes}}"
+//	    inputs: {draft: "{{.steps.draft.output}}", notes: "{{.steps.reviews.output}}"}
+type Pipeline struct {
+	Name        string         `json:"name" yaml:"name"`
+	Description string         `json:"description,omitempty" yaml:"description,omitempty"`
+	Provider    string         `json:"provider,omitempty" yaml:"provider,omitempty"`
+	Model       string         `json:"model,omitempty" yaml:"model,omitempty"`
+	Inputs      map[string]any `json:"inputs,omitempty" yaml:"inputs,omitempty"`
+	Steps       []Step         `json:"steps" yaml:"steps"`
+	// Output selects the pipeline result, e.g. "{{.steps.final.output}}";
+	// by default it is the output of the last step that ran
+	Output string `json:"output,omitempty" yaml:"output,omitempty"`
+}
+
+// Step is one provider call, or a group of calls run in parallel.
+//
+// Prompt and template see the pipeline inputs and the step's own inputs.
+// Input values, the if condition and the pipeline output are templates over
+// the pipeline inputs plus .steps.<id>.output (and .json for JSON output
+// mode), so later steps consume earlier results through their inputs.
+type Step struct {
+	ID       string `json:"id" yaml:"id"`
+	Prompt   string `json:"prompt,omitempty" yaml:"prompt,omitempty"`     // inline template
+	Template string `json:"template,omitempty" yaml:"template,omitempty"` // stored template name
+	Provider string `json:"provider,omitempty" yaml:"provider,omitempty"`
+	Model    string `json:"model,omitempty" yaml:"model,omitempty"`
+	// Inputs are the step's variables; string values are rendered first.
+	// Engine variables such as max_tokens or json_schema go here too.
+	Inputs map[string]any `json:"inputs,omitempty" yaml:"inputs,omitempty"`
+	// If skips the step unless it renders to something other than "",
+	// "false", "0" or "no"
+	If string `json:"if,omitempty" yaml:"if,omitempty"`
+	// Parallel runs its steps concurrently. Each is addressable by its own
+	// id; the group's output is theirs joined by blank lines.
+	Parallel []Step `json:"parallel,omitempty" yaml:"parallel,omitempty"`
+}
+
+// Load reads a pipeline file and validates it. The name defaults to the file
+// name.
+func Load(path string) (*Pipeline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pipeline %s: %w", path, err)
+	}
+	var p Pipeline
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse pipeline %s: %w", path, err)
+	}
+	if p.Name == "" {
+		p.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("pipeline %s: %w", path, err)
+	}
+	return &p, nil
+}
+
+// Validate checks step ids and shapes and that every expression parses
+func (p *Pipeline) Validate() error {
+	if len(p.Steps) == 0 {
+		return fmt.Errorf("no steps")
+	}
+	seen := map[string]bool{}
+	var check func(s Step, nested bool) error
+	check = func(s Step, nested bool) error {
+		switch {
+		case s.ID == "":
+			return fmt.Errorf("step without id")
+		case strings.ContainsAny(s.ID, ". "):
+			return fmt.Errorf("step %s: id cannot contain dots or spaces", s.ID)
+		case seen[s.ID]:
+			return fmt.Errorf("step %s: duplicate id", s.ID)
+		}
+		seen[s.ID] = true
+
+		if len(s.Parallel) > 0 {
+			if nested {
+				return fmt.Errorf("step %s: parallel groups cannot be nested", s.ID)
+			}
+			if s.Prompt != "" || s.Template != "" {
+				return fmt.Errorf("step %s: a parallel group has no prompt or template of its own", s.ID)
+			}
+			for _, child := range s.Parallel {
+				if err := check(child, true); err != nil {
+					return err
+				}
+			}
+		} else if (s.Prompt == "") == (s.Template == "") {
+			return fmt.Errorf("step %s: needs exactly one of prompt or template", s.ID)
+		}
+
+		exprs := []string{s.If}
+		for _, v := range s.Inputs {
+			if text, ok := v.(string); ok {
+				exprs = append(exprs, text)
+			}
+		}
+		for _, e := range exprs {
+			if _, err := parse(e); err != nil {
+				return fmt.Errorf("step %s: %w", s.ID, err)
+			}
+		}
+		return nil
+	}
+	for _, s := range p.Steps {
+		if err := check(s, false); err != nil {
+			return err
+		}
+	}
+	if _, err := parse(p.Output); err != nil {
+		return fmt.Errorf("output: %w", err)
+	}
+	return nil
+}
+
+// funcs are available to input, if and output expressions
+var funcs = template.FuncMap{
+	"contains": strings.Contains,
+	"lower":    strings.ToLower,
+	"upper":    strings.ToUpper,
+	"trim":     strings.TrimSpace,
+}
+
+func parse(text string) (*template.Template, error) {
+	return template.New("pipeline").Funcs(funcs).Option("missingkey=error").Parse(text)
+}
+
+// render executes an expression against the run's data
+func render(text string, data map[string]any) (string, error) {
+	tpl, err := parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// truthy reports whether a rendered condition holds
+func truthy(s string) bool {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "false", "0", "no", "<no value>":
+		return false
+	}
+	return true
+}
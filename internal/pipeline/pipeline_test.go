@@ -0,0 +1,134 @@
+package pipeline_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kubex-ecosystem/grompt/internal/engine/enginetest"
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"github.com/kubex-ecosystem/grompt/internal/pipeline"
+	"github.com/kubex-ecosystem/grompt/internal/providertest"
+)
+
+const articleYAML = `
+provider: upper
+inputs: {topic: go}
+steps:
+  - id: ideas
+    prompt: "ideas about {{.topic}}"
+  - id: reviews
+    parallel:
+      - {id: style, prompt: "style of {{.d}}", inputs: {d: "{{.steps.ideas.output}}"}}
+      - {id: facts, prompt: "facts of {{.d}}", provider: lower, inputs: {d: "{{.steps.ideas.output}}"}}
+  - id: revise
+    if: '{{contains .steps.reviews.output "REVISE"}}'
+    prompt: "revise"
+  - id: final
+    prompt: "final {{.notes}}"
+    inputs: {notes: "{{.steps.reviews.output}}"}
+output: "{{.steps.final.output}}!"
+`
+
+func writePipeline(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunPipeline(t *testing.T) {
+	p, err := pipeline.Load(writePipeline(t, "article.yml", articleYAML))
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if p.Name != "article" {
+		t.Fatalf("expected the name to default to the file name, got %q", p.Name)
+	}
+
+	upper := &providertest.Provider{ProviderName: "upper", Reply: strings.ToUpper}
+	lower := &providertest.Provider{ProviderName: "lower", Reply: strings.ToLower}
+	eng := enginetest.New(t, upper, lower)
+
+	run, err := (&pipeline.Runner{Engine: eng}).Run(context.Background(), p, map[string]any{"topic": "generics"})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	if len(run.Steps) != 5 {
+		t.Fatalf("expected 5 step traces, got %+v", run.Steps)
+	}
+	if s := run.Steps[0]; s.Output != "IDEAS ABOUT GENERICS" {
+		t.Fatalf("expected the run input to override the default, got %q", s.Output)
+	}
+	if s := run.Steps[1]; s.ID != "style" || s.Group != "reviews" || s.Output != "STYLE OF IDEAS ABOUT GENERICS" {
+		t.Fatalf("unexpected parallel trace %+v", s)
+	}
+	if s := run.Steps[2]; s.Provider != "lower" || s.Output != "facts of ideas about generics" {
+		t.Fatalf("expected the step provider to win, got %+v", s)
+	}
+	if s := run.Steps[3]; s.ID != "revise" || !s.Skipped {
+		t.Fatalf("expected revise to be skipped, got %+v", s)
+	}
+	want := "FINAL STYLE OF IDEAS ABOUT GENERICS\n\nFACTS OF IDEAS ABOUT GENERICS!"
+	if run.Output != want {
+		t.Fatalf("expected output %q, got %q", want, run.Output)
+	}
+
+	page := eng.QueryHistory(interfaces.HistoryQuery{Tag: "article"})
+	if page.Total != 4 {
+		t.Fatalf("expected 4 history entries tagged with the pipeline, got %d", page.Total)
+	}
+	for _, entry := range page.Entries {
+		if entry.Variables["pipeline_run"] != run.ID {
+			t.Fatalf("expected history to carry the run id, got %v", entry.Variables)
+		}
+	}
+}
+
+func TestRunStopsAtFailingStep(t *testing.T) {
+	p := &pipeline.Pipeline{Name: "broken", Steps: []pipeline.Step{
+		{ID: "first", Prompt: "hello"},
+		{ID: "second", Prompt: "{{.x}}", Inputs: map[string]any{"x": "{{.steps.missing.output}}"}},
+		{ID: "third", Prompt: "never"},
+	}}
+	eng := enginetest.New(t, &providertest.Provider{ProviderName: "echo", Reply: func(s string) string { return s }})
+
+	run, err := (&pipeline.Runner{Engine: eng}).Run(context.Background(), p, nil)
+	if err == nil || !strings.Contains(err.Error(), "step second") {
+		t.Fatalf("expected the second step to fail, got %v", err)
+	}
+	if run == nil || len(run.Steps) != 2 || run.Steps[1].Error == "" {
+		t.Fatalf("expected a partial trace, got %+v", run)
+	}
+}
+
+func TestRunChecksProviders(t *testing.T) {
+	p := &pipeline.Pipeline{Name: "typo", Provider: "opnai", Steps: []pipeline.Step{{ID: "a", Prompt: "hi"}}}
+	eng := enginetest.New(t, &providertest.Provider{ProviderName: "echo", Reply: func(s string) string { return s }})
+
+	if _, err := (&pipeline.Runner{Engine: eng}).Run(context.Background(), p, nil); err == nil || !strings.Contains(err.Error(), "opnai") {
+		t.Fatalf("expected an unknown provider error, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cases := map[string]pipeline.Pipeline{
+		"no steps":     {},
+		"duplicate id": {Steps: []pipeline.Step{{ID: "a", Prompt: "x"}, {ID: "a", Prompt: "y"}}},
+		"dotted id":    {Steps: []pipeline.Step{{ID: "a.b", Prompt: "x"}}},
+		"both":         {Steps: []pipeline.Step{{ID: "a", Prompt: "x", Template: "t"}}},
+		"neither":      {Steps: []pipeline.Step{{ID: "a"}}},
+		"nested":       {Steps: []pipeline.Step{{ID: "g", Parallel: []pipeline.Step{{ID: "h", Parallel: []pipeline.Step{{ID: "i", Prompt: "x"}}}}}}},
+		"bad if":       {Steps: []pipeline.Step{{ID: "a", Prompt: "x", If: "{{.broken"}}},
+	}
+	for name, p := range cases {
+		if err := p.Validate(); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+)
+
+// Runner executes pipelines through an engine. Every step is recorded in the
+// engine's history tagged "pipeline" and the pipeline name, with the run and
+// step ids in the variables pipeline_run and pipeline_step.
+type Runner struct {
+	Engine interfaces.IEngine
+}
+
+// Run is the trace of one pipeline execution
+type Run struct {
+	ID         string            `json:"id"`
+	Pipeline   string            `json:"pipeline"`
+	Inputs     map[string]any    `json:"inputs,omitempty"`
+	Steps      []StepTrace       `json:"steps"`
+	Output     string            `json:"output"`
+	Usage      *interfaces.Usage `json:"usage,omitempty"`
+	StartedAt  time.Time         `json:"started_at"`
+	DurationMs int64             `json:"duration_ms"`
+}
+
+// StepTrace is the outcome of one step
+type StepTrace struct {
+	ID        string            `json:"id"`
+	Group     string            `json:"group,omitempty"` // parallel group the step ran in
+	Provider  string            `json:"provider,omitempty"`
+	Model     string            `json:"model,omitempty"`
+	Prompt    string            `json:"prompt,omitempty"`
+	Output    string            `json:"output,omitempty"`
+	Skipped   bool              `json:"skipped,omitempty"`
+	Error     string            `json:"error,omitempty"`
+	LatencyMs int64             `json:"latency_ms"`
+	Usage     *interfaces.Usage `json:"usage,omitempty"`
+	HistoryID string            `json:"history_id,omitempty"`
+}
+
+// Run executes p with inputs over its default inputs. Steps run in order and
+// the first failing step stops the pipeline; the partial run is returned with
+// the error.
+func (r *Runner) Run(ctx context.Context, p *Pipeline, inputs map[string]any) (*Run, error) {
+	if r.Engine == nil {
+		return nil, fmt.Errorf("pipeline runner has no engine")
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	if err := r.check(p, p.Steps); err != nil {
+		return nil, err
+	}
+
+	run := &Run{ID: fmt.Sprintf("pipeline_%d", time.Now().UnixNano()), Pipeline: p.Name, Inputs: map[string]any{}, StartedAt: time.Now()}
+	for k, v := range p.Inputs {
+		run.Inputs[k] = v
+	}
+	for k, v := range inputs {
+		run.Inputs[k] = v
+	}
+	steps := map[string]any{}
+	data := map[string]any{}
+	for k, v := range run.Inputs {
+		data[k] = v
+	}
+	data["steps"] = steps
+
+	last := ""
+	for _, s := range p.Steps {
+		traces, output, ran, err := r.runStep(ctx, p, run, s, data)
+		run.Steps = append(run.Steps, traces...)
+		for _, t := range traces {
+			run.Usage = addUsage(run.Usage, t.Usage)
+		}
+		if err != nil {
+			run.DurationMs = time.Since(run.StartedAt).Milliseconds()
+			return run, err
+		}
+		if ran {
+			last = output
+		}
+	}
+
+	run.Output = last
+	if p.Output != "" {
+		out, err := render(p.Output, data)
+		if err != nil {
+			return run, fmt.Errorf("output: %w", err)
+		}
+		run.Output = out
+	}
+	run.DurationMs = time.Since(run.StartedAt).Milliseconds()
+	return run, nil
+}
+
+// check resolves the providers and stored templates of the steps up front, so
+// a typo fails the run before any provider is paid for
+func (r *Runner) check(p *Pipeline, steps []Step) error {
+	for _, s := range steps {
+		if len(s.Parallel) > 0 {
+			if err := r.check(p, s.Parallel); err != nil {
+				return err
+			}
+			continue
+		}
+		if provider := firstOf(s.Provider, p.Provider); provider != "" && r.Engine.Resolve(provider) == nil {
+			return fmt.Errorf("step %s: provider %s is not available", s.ID, provider)
+		}
+		if s.Template != "" {
+			if _, err := r.Engine.GetTemplates().GetTemplate(s.Template); err != nil {
+				return fmt.Errorf("step %s: %w", s.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// runStep runs a step or parallel group and stores its outputs in data. It
+// returns the traces, the output and whether the step ran at all.
+func (r *Runner) runStep(ctx context.Context, p *Pipeline, run *Run, s Step, data map[string]any) ([]StepTrace, string, bool, error) {
+	steps := data["steps"].(map[string]any)
+	ok, err := condition(s, data)
+	if err != nil {
+		return []StepTrace{{ID: s.ID, Error: err.Error()}}, "", false, fmt.Errorf("step %s: %w", s.ID, err)
+	}
+	if !ok {
+		traces := []StepTrace{{ID: s.ID, Skipped: true}}
+		steps[s.ID] = map[string]any{"output": "", "skipped": true}
+		for _, child := range s.Parallel {
+			traces = append(traces, StepTrace{ID: child.ID, Group: s.ID, Skipped: true})
+			steps[child.ID] = map[string]any{"output": "", "skipped": true}
+		}
+		return traces, "", false, nil
+	}
+
+	if len(s.Parallel) == 0 {
+		trace, result := r.call(ctx, p, run, s, data)
+		steps[s.ID] = stepData(trace, result)
+		if trace.Error != "" {
+			return []StepTrace{trace}, "", false, fmt.Errorf("step %s: %s", s.ID, trace.Error)
+		}
+		return []StepTrace{trace}, trace.Output, true, nil
+	}
+
+	// Children only read data while they run; their results are stored after
+	// the whole group is done
+	traces := make([]StepTrace, len(s.Parallel))
+	results := make([]*interfaces.Result, len(s.Parallel))
+	var wg sync.WaitGroup
+	for i, child := range s.Parallel {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if ok, err := condition(child, data); err != nil || !ok {
+				traces[i] = StepTrace{ID: child.ID, Skipped: err == nil}
+				if err != nil {
+					traces[i].Error = err.Error()
+				}
+				return
+			}
+			traces[i], results[i] = r.call(ctx, p, run, child, data)
+		}()
+	}
+	wg.Wait()
+
+	var outputs []string
+	var failed []string
+	for i, t := range traces {
+		traces[i].Group = s.ID
+		steps[t.ID] = stepData(t, results[i])
+		switch {
+		case t.Error != "":
+			failed = append(failed, t.ID+": "+t.Error)
+		case !t.Skipped:
+			outputs = append(outputs, t.Output)
+		}
+	}
+	output := strings.Join(outputs, "\n\n")
+	steps[s.ID] = map[string]any{"output": output}
+	if len(failed) > 0 {
+		return traces, "", false, fmt.Errorf("step %s: %s", s.ID, strings.Join(failed, "; "))
+	}
+	return traces, output, true, nil
+}
+
+// call renders the inputs of a single step and sends it through the engine
+func (r *Runner) call(ctx context.Context, p *Pipeline, run *Run, s Step, data map[string]any) (StepTrace, *interfaces.Result) {
+	trace := StepTrace{ID: s.ID, Provider: firstOf(s.Provider, p.Provider), Model: firstOf(s.Model, p.Model)}
+
+	vars := make(map[string]interface{}, len(run.Inputs)+len(s.Inputs)+5)
+	for k, v := range run.Inputs {
+		vars[k] = v
+	}
+	for k, v := range s.Inputs {
+		if text, ok := v.(string); ok {
+			rendered, err := render(text, data)
+			if err != nil {
+				trace.Error = fmt.Sprintf("input %s: %v", k, err)
+				return trace, nil
+			}
+			v = rendered
+		}
+		vars[k] = v
+	}
+	if trace.Provider != "" {
+		vars["provider"] = trace.Provider
+	}
+	if trace.Model != "" {
+		vars["model"] = trace.Model
+	}
+	vars["tags"] = []string{"pipeline", p.Name}
+	vars["pipeline_run"] = run.ID
+	vars["pipeline_step"] = s.ID
+
+	started := time.Now()
+	var result *interfaces.Result
+	var err error
+	switch {
+	case s.Template != "":
+		result, err = r.Engine.ProcessTemplate(ctx, s.Template, vars)
+	case trace.Provider != "":
+		result, err = r.Engine.InvokeProvider(ctx, trace.Provider, s.Prompt, vars)
+	default:
+		result, err = r.Engine.ProcessPrompt(ctx, s.Prompt, vars)
+	}
+	trace.LatencyMs = time.Since(started).Milliseconds()
+	if err != nil {
+		trace.Error = err.Error()
+		return trace, nil
+	}
+	trace.Provider, trace.Prompt, trace.Output = result.Provider, result.Prompt, result.Response
+	trace.Usage, trace.HistoryID = result.Usage, result.ID
+	if result.Model != "" {
+		trace.Model = result.Model
+	}
+	return trace, result
+}
+
+// condition evaluates the if of s; steps without one always run
+func condition(s Step, data map[string]any) (bool, error) {
+	if s.If == "" {
+		return true, nil
+	}
+	out, err := render(s.If, data)
+	if err != nil {
+		return false, fmt.Errorf("if: %w", err)
+	}
+	return truthy(out), nil
+}
+
+// stepData is what later expressions see of a step as .steps.<id>
+func stepData(t StepTrace, result *interfaces.Result) map[string]any {
+	d := map[string]any{"output": t.Output}
+	if t.Skipped {
+		d["skipped"] = true
+	}
+	if result != nil && result.JSON != nil {
+		d["json"] = result.JSON
+	}
+	return d
+}
+
+func firstOf(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func addUsage(total, usage *interfaces.Usage) *interfaces.Usage {
+	if usage == nil {
+		return total
+	}
+	if total == nil {
+		total = &interfaces.Usage{}
+	}
+	total.Prompt += usage.Prompt
+	total.Completion += usage.Completion
+	total.Tokens += usage.Tokens
+	total.CostUSD += usage.CostUSD
+	total.Ms += usage.Ms
+	return total
+}
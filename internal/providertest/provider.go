@@ -0,0 +1,65 @@
+// Package providertest provides a scripted interfaces.Provider for tests of
+// the packages that drive providers.
+package providertest
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+)
+
+// Provider answers chats from a script. Each chat consumes the next of
+// Replies; once they run out it answers Reply applied to the last message
+// when Reply is set, and Response otherwise. With Err set every chat fails.
+type Provider struct {
+	ProviderName string
+	Response     string
+	Replies      []string
+	Reply        func(prompt string) string
+	Usage        *interfaces.Usage // reported on the done chunk
+	Err          error
+
+	// Calls counts the chats and Model is the model of the last one
+	Calls int
+	Model string
+}
+
+func (p *Provider) Name() string      { return p.ProviderName }
+func (p *Provider) KeyEnv() string    { return "" }
+func (p *Provider) Type() string      { return "stub" }
+func (p *Provider) IsAvailable() bool { return p.Err == nil }
+func (p *Provider) Version() string   { return "test" }
+
+func (p *Provider) Chat(ctx context.Context, req interfaces.ChatRequest) (<-chan interfaces.ChatChunk, error) {
+	p.Calls++
+	p.Model = req.Model
+	ch := make(chan interfaces.ChatChunk, 2)
+	if p.Err != nil {
+		ch <- interfaces.ChatChunk{Error: p.Err.Error(), Done: true}
+	} else {
+		response := p.Response
+		switch {
+		case len(p.Replies) > 0:
+			response, p.Replies = p.Replies[0], p.Replies[1:]
+		case p.Reply != nil && len(req.Messages) > 0:
+			response = p.Reply(req.Messages[len(req.Messages)-1].Content)
+		}
+		ch <- interfaces.ChatChunk{Content: response}
+		ch <- interfaces.ChatChunk{Done: true, Usage: p.Usage}
+	}
+	close(ch)
+	return ch, nil
+}
+
+func (p *Provider) Execute(ctx context.Context, template string, vars map[string]any) (*interfaces.Result, error) {
+	return nil, errors.New("not used")
+}
+
+func (p *Provider) Notify(ctx context.Context, event interfaces.NotificationEvent) error {
+	return nil
+}
+
+func (p *Provider) GetCapabilities(ctx context.Context) *interfaces.Capabilities {
+	return &interfaces.Capabilities{}
+}
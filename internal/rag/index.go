@@ -0,0 +1,294 @@
+// Package rag answers questions about a code base from its lookatni
+// extraction. Fragments are ranked against the question with BM25, fused
+// with embedding similarity when an embedder is configured, and the best ones
+// are assembled into a prompt that cites them by file and line range while
+// staying within the context window of the target model.
+package rag
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	gl "github.com/kubex-ecosystem/logz/logger"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"github.com/kubex-ecosystem/grompt/internal/services/lookatni"
+	"github.com/kubex-ecosystem/grompt/internal/vectorindex"
+)
+
+// DefaultK is the number of fragments retrieved when Options leaves K unset
+const DefaultK = 8
+
+// rrfK dampens the ranks fused by reciprocal rank fusion
+const rrfK = 60
+
+// embedBatch is the number of fragments embedded per request
+const embedBatch = 64
+
+// Match is a fragment ranked against a question
+type Match struct {
+	lookatni.CodeFragment
+	Score float64 `json:"score"`
+}
+
+// Index ranks the fragments of an extracted project
+type Index struct {
+	project   string
+	fragments []lookatni.CodeFragment
+	keys      []string   // content key of each fragment
+	terms     [][]string // BM25 terms of each fragment
+
+	vectors  *vectorindex.Index
+	embedder interfaces.Embedder
+	model    string
+}
+
+// NewIndex indexes the fragments of project. Files lookatni did not
+// fragment are indexed whole, and fragments repeated by range are kept once.
+func NewIndex(project *lookatni.ExtractedProject) *Index {
+	x := &Index{project: project.ProjectName}
+	seen := map[string]bool{}
+	add := func(f lookatni.CodeFragment) {
+		if strings.TrimSpace(f.Content) == "" {
+			return
+		}
+		key := fragmentKey(f)
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		x.fragments = append(x.fragments, f)
+		x.keys = append(x.keys, key)
+		x.terms = append(x.terms, terms(f.FilePath+"\n"+f.Name+"\n"+f.Content))
+	}
+
+	for _, f := range project.Fragments {
+		add(f)
+	}
+	for _, file := range project.Files {
+		if len(file.Fragments) == 0 {
+			lines := file.LineCount
+			if lines == 0 {
+				lines = strings.Count(strings.TrimRight(file.Content, "\n"), "\n") + 1
+			}
+			add(lookatni.CodeFragment{
+				ID:        file.ID,
+				Type:      "file",
+				Name:      file.Name,
+				FilePath:  file.Path,
+				StartLine: 1,
+				EndLine:   lines,
+				Content:   file.Content,
+				Language:  file.Language,
+			})
+			continue
+		}
+		for _, f := range file.Fragments {
+			if f.FilePath == "" {
+				f.FilePath = file.Path
+			}
+			add(f)
+		}
+	}
+	return x
+}
+
+// Project returns the name of the indexed project
+func (x *Index) Project() string { return x.project }
+
+// Len returns the number of indexed fragments
+func (x *Index) Len() int { return len(x.fragments) }
+
+// UseEmbeddings adds semantic retrieval with the embeddings of provider,
+// kept in store. Only fragments whose content changed since the last call
+// are embedded, and vectors of fragments that no longer exist are removed.
+// An empty model uses the one store is bound to, or the provider's default.
+func (x *Index) UseEmbeddings(ctx context.Context, store *vectorindex.Index, provider string, embedder interfaces.Embedder, model string) error {
+	info := store.Info()
+	if info.Count > 0 && (info.Provider != provider || (model != "" && model != info.Model)) {
+		return fmt.Errorf("index %s holds %s/%s vectors; use another index for %s", info.Name, info.Provider, info.Model, provider)
+	}
+	if model == "" {
+		model = info.Model
+	}
+
+	current := map[string]bool{}
+	var missing []int
+	for i, key := range x.keys {
+		current[key] = true
+		if _, ok := store.Get(key); !ok {
+			missing = append(missing, i)
+		}
+	}
+	for start := 0; start < len(missing); start += embedBatch {
+		batch := missing[start:min(start+embedBatch, len(missing))]
+		input := make([]string, len(batch))
+		for n, i := range batch {
+			input[n] = document(x.fragments[i])
+		}
+		res, err := embedder.Embed(ctx, interfaces.EmbeddingRequest{Model: model, Input: input})
+		if err != nil {
+			return err
+		}
+		if len(res.Vectors) != len(batch) {
+			return fmt.Errorf("%s returned %d vectors for %d fragments", provider, len(res.Vectors), len(batch))
+		}
+		if model == "" {
+			model = res.Model
+		}
+		if err := store.Bind(provider, model); err != nil {
+			return err
+		}
+		records := make([]vectorindex.Record, len(batch))
+		for n, i := range batch {
+			f := x.fragments[i]
+			records[n] = vectorindex.Record{ID: x.keys[i], Vector: res.Vectors[n], Metadata: map[string]string{
+				"path": f.FilePath,
+				"name": f.Name,
+				"type": f.Type,
+			}}
+		}
+		if err := store.Upsert(records...); err != nil {
+			return err
+		}
+	}
+
+	var stale []string
+	for _, id := range store.IDs() {
+		if !current[id] {
+			stale = append(stale, id)
+		}
+	}
+	if _, err := store.Delete(stale...); err != nil {
+		return err
+	}
+
+	x.vectors, x.embedder, x.model = store, embedder, model
+	return nil
+}
+
+// Retrieve returns up to k fragments relevant to question, most relevant
+// first. Without embeddings, fragments that share no term with the question
+// are left out; with them, BM25 and embedding ranks are fused and a failing
+// embedder falls back to BM25.
+func (x *Index) Retrieve(ctx context.Context, question string, k int) []Match {
+	if k <= 0 {
+		k = DefaultK
+	}
+	if len(x.fragments) == 0 || strings.TrimSpace(question) == "" {
+		return []Match{}
+	}
+
+	scores := vectorindex.BM25(terms(question), x.terms)
+	lexical := make([]int, 0, len(scores))
+	for i, s := range scores {
+		if s > 0 {
+			lexical = append(lexical, i)
+		}
+	}
+	sort.SliceStable(lexical, func(a, b int) bool { return scores[lexical[a]] > scores[lexical[b]] })
+
+	if semantic, ok := x.semantic(ctx, question, k*4); ok {
+		fused := map[int]float64{}
+		for rank, i := range lexical[:min(len(lexical), k*4)] {
+			fused[i] += 1.0 / float64(rrfK+rank+1)
+		}
+		for rank, i := range semantic {
+			fused[i] += 1.0 / float64(rrfK+rank+1)
+		}
+		order := make([]int, 0, len(fused))
+		for i := range fused {
+			order = append(order, i)
+		}
+		sort.Slice(order, func(a, b int) bool {
+			if fused[order[a]] != fused[order[b]] {
+				return fused[order[a]] > fused[order[b]]
+			}
+			return order[a] < order[b]
+		})
+		return x.matches(order, k, func(i int) float64 { return fused[i] })
+	}
+	return x.matches(lexical, k, func(i int) float64 { return scores[i] })
+}
+
+// semantic ranks fragments by the similarity of their embedding to the
+// question's
+func (x *Index) semantic(ctx context.Context, question string, n int) ([]int, bool) {
+	if x.vectors == nil || x.embedder == nil {
+		return nil, false
+	}
+	res, err := x.embedder.Embed(ctx, interfaces.EmbeddingRequest{Model: x.model, Input: []string{question}})
+	if err == nil && len(res.Vectors) != 1 {
+		err = fmt.Errorf("got %d vectors for the question", len(res.Vectors))
+	}
+	var hits []vectorindex.Hit
+	if err == nil {
+		hits, err = x.vectors.Search(res.Vectors[0], n, nil)
+	}
+	if err != nil {
+		gl.Log("warn", fmt.Sprintf("Embedding retrieval failed, using BM25: %v", err))
+		return nil, false
+	}
+
+	positions := make(map[string]int, len(x.keys))
+	for i, key := range x.keys {
+		positions[key] = i
+	}
+	order := make([]int, 0, len(hits))
+	for _, h := range hits {
+		if i, ok := positions[h.ID]; ok {
+			order = append(order, i)
+		}
+	}
+	return order, true
+}
+
+func (x *Index) matches(order []int, k int, score func(int) float64) []Match {
+	out := make([]Match, 0, min(k, len(order)))
+	for _, i := range order[:min(k, len(order))] {
+		out = append(out, Match{CodeFragment: x.fragments[i], Score: score(i)})
+	}
+	return out
+}
+
+// document is the text embedded for a fragment
+func document(f lookatni.CodeFragment) string {
+	return f.FilePath + " " + f.Name + "\n" + f.Content
+}
+
+// fragmentKey identifies a fragment by its location and content, so a
+// changed fragment gets a new embedding
+func fragmentKey(f lookatni.CodeFragment) string {
+	sum := sha256.Sum256([]byte(f.FilePath + ":" + strconv.Itoa(f.StartLine) + "-" + strconv.Itoa(f.EndLine) + "\n" + f.Content))
+	return hex.EncodeToString(sum[:12])
+}
+
+// terms tokenizes code for BM25: identifiers count whole and by their
+// camelCase parts, so "ExtractProject" matches "extract project"
+func terms(text string) []string {
+	return vectorindex.Tokenize(text + "\n" + splitCamel(text))
+}
+
+// splitCamel puts a space at every camelCase boundary of text
+func splitCamel(text string) string {
+	runes := []rune(text)
+	var b strings.Builder
+	b.Grow(len(text) + len(text)/8)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,211 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kubex-ecosystem/grompt/internal/tokenizer"
+)
+
+// Budget defaults, in tokens
+const (
+	// DefaultWindow sizes the prompt for models of unknown context window
+	DefaultWindow = 8192
+	// DefaultReserve is kept free for the answer
+	DefaultReserve = 1024
+	// minExcerpt is the smallest budget worth cutting a fragment down to
+	minExcerpt = 64
+)
+
+// Options size and shape an assembled prompt
+type Options struct {
+	// Provider and Model are the target of the prompt; they choose the
+	// tokenizer and the context window
+	Provider string `json:"provider,omitempty"`
+	Model    string `json:"model,omitempty"`
+	// K is the number of fragments retrieved; 0 is DefaultK
+	K int `json:"k,omitempty"`
+	// MaxContextTokens caps the tokens of the cited sources; 0 lets them
+	// fill the window less ReserveTokens
+	MaxContextTokens int `json:"max_context_tokens,omitempty"`
+	// ReserveTokens is kept free for the answer; 0 is DefaultReserve
+	ReserveTokens int `json:"reserve_tokens,omitempty"`
+}
+
+// Citation is a source of an assembled prompt
+type Citation struct {
+	N         int     `json:"n"`
+	Path      string  `json:"path"`
+	StartLine int     `json:"start_line"`
+	EndLine   int     `json:"end_line"`
+	Name      string  `json:"name,omitempty"`
+	Type      string  `json:"type,omitempty"`
+	Score     float64 `json:"score"`
+	// Truncated is set when only the first lines of the fragment fit
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// String formats the citation as path:start-end
+func (c Citation) String() string {
+	if c.StartLine <= 0 {
+		return c.Path
+	}
+	if c.EndLine <= c.StartLine {
+		return fmt.Sprintf("%s:%d", c.Path, c.StartLine)
+	}
+	return fmt.Sprintf("%s:%d-%d", c.Path, c.StartLine, c.EndLine)
+}
+
+// Prompt is a question with the code that answers it
+type Prompt struct {
+	System    string     `json:"system"`
+	User      string     `json:"user"`
+	Citations []Citation `json:"citations"`
+	// Tokens is the estimated size of System and User
+	Tokens int `json:"tokens"`
+	// Budget is how many tokens the sources could take
+	Budget int `json:"budget"`
+	// Omitted counts retrieved fragments left out for the budget
+	Omitted int `json:"omitted,omitempty"`
+}
+
+// Text joins System and User for providers that take a single prompt
+func (p *Prompt) Text() string {
+	return p.System + "\n\n" + p.User
+}
+
+// Prompt retrieves the fragments relevant to question and assembles them
+// into a prompt
+func (x *Index) Prompt(ctx context.Context, question string, opts Options) (*Prompt, error) {
+	return Assemble(x.project, question, x.Retrieve(ctx, question, opts.K), opts)
+}
+
+// Assemble builds the prompt answering question from matches, in order,
+// numbering each source for citation. A match that does not fit the budget
+// is cut down to its first lines when enough room is left, and skipped
+// otherwise; a match within a range already cited is skipped.
+func Assemble(project, question string, matches []Match, opts Options) (*Prompt, error) {
+	tk := tokenizer.For(opts.Provider, opts.Model)
+	window := tokenizer.ContextWindow(opts.Provider, opts.Model)
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	reserve := opts.ReserveTokens
+	if reserve <= 0 {
+		reserve = DefaultReserve
+	}
+
+	p := &Prompt{System: instructions(project)}
+	budget := window - reserve - tk.Count(p.System) - tk.Count(user("", question))
+	if opts.MaxContextTokens > 0 && opts.MaxContextTokens < budget {
+		budget = opts.MaxContextTokens
+	}
+	if budget <= 0 {
+		return nil, fmt.Errorf("the question leaves no room for sources in the %d-token window of %s", window, orDefault(opts.Model, opts.Provider))
+	}
+	p.Budget = budget
+
+	var sources strings.Builder
+	remaining := budget
+	for _, m := range matches {
+		if covered(p.Citations, m) {
+			continue
+		}
+		c := Citation{N: len(p.Citations) + 1, Path: m.FilePath, StartLine: m.StartLine, EndLine: m.EndLine, Name: m.Name, Type: m.Type, Score: m.Score}
+		block := source(c, m.Language, m.Content)
+		cost := tk.Count(block)
+		if cost > remaining {
+			if remaining < minExcerpt {
+				p.Omitted++
+				continue
+			}
+			var ok bool
+			if block, cost, ok = excerpt(tk, &c, m.Language, m.Content, remaining); !ok {
+				p.Omitted++
+				continue
+			}
+		}
+		sources.WriteString(block)
+		remaining -= cost
+		p.Citations = append(p.Citations, c)
+	}
+
+	p.User = user(sources.String(), question)
+	p.Tokens = tk.Count(p.System) + tk.Count(p.User)
+	return p, nil
+}
+
+// excerpt cuts a fragment down to the first lines that fit in budget,
+// adjusting the cited line range
+func excerpt(tk tokenizer.Tokenizer, c *Citation, language, content string, budget int) (string, int, bool) {
+	lines := strings.SplitAfter(strings.TrimRight(content, "\n"), "\n")
+	lo, hi := 0, len(lines)-1 // lo lines always fit; find the most that do
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if tk.Count(source(truncated(*c, mid), language, strings.Join(lines[:mid], ""))) <= budget {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	if lo == 0 {
+		return "", 0, false
+	}
+	*c = truncated(*c, lo)
+	block := source(*c, language, strings.Join(lines[:lo], ""))
+	return block, tk.Count(block), true
+}
+
+func truncated(c Citation, lines int) Citation {
+	if c.StartLine > 0 {
+		c.EndLine = c.StartLine + lines - 1
+	}
+	c.Truncated = true
+	return c
+}
+
+// covered reports whether m lies within a range already cited
+func covered(cited []Citation, m Match) bool {
+	for _, c := range cited {
+		if c.Path == m.FilePath && !c.Truncated && c.StartLine <= m.StartLine && m.EndLine <= c.EndLine {
+			return true
+		}
+	}
+	return false
+}
+
+func source(c Citation, language, content string) string {
+	header := fmt.Sprintf("[%d] %s", c.N, c.String())
+	if c.Name != "" {
+		header += " (" + strings.TrimSpace(c.Type+" "+c.Name) + ")"
+	}
+	if c.Truncated {
+		header += " [truncated]"
+	}
+	return header + "\n```" + language + "\n" + strings.TrimRight(content, "\n") + "\n```\n\n"
+}
+
+func instructions(project string) string {
+	subject := "a code base"
+	if project != "" {
+		subject = "the code base " + project
+	}
+	return "You answer questions about " + subject + " using the numbered sources, excerpts of its files. " +
+		"Support each statement with the sources it comes from, cited as [n] together with the file and line range " +
+		"(path:start-end). If the sources do not contain the answer, say so instead of guessing."
+}
+
+func user(sources, question string) string {
+	if sources == "" {
+		sources = "(no relevant sources were found)\n\n"
+	}
+	return "## Sources\n\n" + sources + "## Question\n\n" + strings.TrimSpace(question)
+}
+
+func orDefault(v, fallback string) string {
+	if v != "" {
+		return v
+	}
+	return fallback
+}
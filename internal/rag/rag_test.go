@@ -0,0 +1,125 @@
+package rag
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"github.com/kubex-ecosystem/grompt/internal/services/lookatni"
+	"github.com/kubex-ecosystem/grompt/internal/tokenizer"
+	"github.com/kubex-ecosystem/grompt/internal/vectorindex"
+)
+
+func testProject() *lookatni.ExtractedProject {
+	return &lookatni.ExtractedProject{
+		ProjectName: "demo",
+		Files: []lookatni.ExtractedFile{
+			{Path: "internal/retry/retry.go", Name: "retry.go", Language: "go", Fragments: []lookatni.CodeFragment{
+				{Type: "function", Name: "DoWithBackoff", StartLine: 10, EndLine: 14, Language: "go",
+					Content: "func DoWithBackoff(fn func() error) error {\n\tfor attempt := 0; attempt < 5; attempt++ {\n\t\tsleep(attempt)\n\t}\n}"},
+				{Type: "function", Name: "sleep", StartLine: 16, EndLine: 18, Language: "go",
+					Content: "func sleep(attempt int) {\n\ttime.Sleep(jitter(attempt))\n}"},
+			}},
+			{Path: "README.md", Name: "README.md", Language: "markdown", LineCount: 2, Content: "# Demo\nA tiny HTTP server.\n"},
+		},
+	}
+}
+
+func TestRetrieveRanksCodeFragments(t *testing.T) {
+	idx := NewIndex(testProject())
+	if idx.Len() != 3 {
+		t.Fatalf("expected 2 fragments and the unfragmented README, got %d", idx.Len())
+	}
+
+	matches := idx.Retrieve(context.Background(), "how does the backoff work?", 0)
+	if len(matches) == 0 || matches[0].Name != "DoWithBackoff" || matches[0].FilePath != "internal/retry/retry.go" {
+		t.Fatalf("expected the camelCase function to match its parts, got %+v", matches)
+	}
+	if got := idx.Retrieve(context.Background(), "kubernetes operators", 0); len(got) != 0 {
+		t.Fatalf("expected unrelated questions to match nothing, got %+v", got)
+	}
+}
+
+func TestAssembleCitesWithinBudget(t *testing.T) {
+	idx := NewIndex(testProject())
+	p, err := idx.Prompt(context.Background(), "backoff attempt sleep", Options{Provider: "openai", Model: "gpt-4o"})
+	if err != nil {
+		t.Fatalf("Prompt returned error: %v", err)
+	}
+	if len(p.Citations) != 2 || p.Citations[0].String() != "internal/retry/retry.go:10-14" {
+		t.Fatalf("unexpected citations %+v", p.Citations)
+	}
+	if !strings.Contains(p.User, "[1] internal/retry/retry.go:10-14 (function DoWithBackoff)") || !strings.HasSuffix(p.User, "backoff attempt sleep") {
+		t.Fatalf("unexpected prompt:\n%s", p.User)
+	}
+	if !strings.Contains(p.System, "demo") || p.Tokens == 0 {
+		t.Fatalf("unexpected system prompt or size: %+v", p)
+	}
+
+	long := Match{CodeFragment: lookatni.CodeFragment{FilePath: "big.go", Name: "Big", StartLine: 100, EndLine: 399, Content: strings.Repeat("x := compute(value, other)\n", 300)}}
+	p, err = Assemble("demo", "what is big?", []Match{long, long}, Options{Provider: "openai", Model: "gpt-4o", MaxContextTokens: 200})
+	if err != nil {
+		t.Fatalf("Assemble returned error: %v", err)
+	}
+	if len(p.Citations) != 1 || !p.Citations[0].Truncated || p.Citations[0].EndLine >= 399 || p.Citations[0].StartLine != 100 {
+		t.Fatalf("expected one truncated citation, got %+v", p.Citations)
+	}
+	tk := tokenizer.For("openai", "gpt-4o")
+	if sources := p.Tokens - tk.Count(p.System) - tk.Count(user("", "what is big?")); p.Budget != 200 || sources > 200 || p.Omitted != 1 {
+		t.Fatalf("expected the sources to stay within the budget, got %d tokens for %+v", sources, p)
+	}
+
+	if _, err := Assemble("demo", strings.Repeat("why ", 10000), nil, Options{Provider: "ollama"}); err == nil {
+		t.Fatal("expected a question larger than the window to be an error")
+	}
+}
+
+// fakeEmbedder maps texts mentioning "server" to one direction and the rest
+// to another
+type fakeEmbedder struct{ texts int }
+
+func (f *fakeEmbedder) Embed(ctx context.Context, req interfaces.EmbeddingRequest) (*interfaces.Embeddings, error) {
+	f.texts += len(req.Input)
+	out := make([][]float64, len(req.Input))
+	for i, text := range req.Input {
+		if strings.Contains(strings.ToLower(text), "server") || strings.Contains(text, "serve") {
+			out[i] = []float64{1, 0}
+		} else {
+			out[i] = []float64{0, 1}
+		}
+	}
+	return &interfaces.Embeddings{Model: "fake-embed", Vectors: out}, nil
+}
+
+func TestUseEmbeddingsReusesVectors(t *testing.T) {
+	store := vectorindex.New()
+	emb := &fakeEmbedder{}
+	idx := NewIndex(testProject())
+	if err := idx.UseEmbeddings(context.Background(), store, "fake", emb, ""); err != nil {
+		t.Fatalf("UseEmbeddings returned error: %v", err)
+	}
+	if store.Len() != 3 || store.Info().Model != "fake-embed" {
+		t.Fatalf("unexpected store %+v", store.Info())
+	}
+
+	matches := idx.Retrieve(context.Background(), "what does it serve", 1)
+	if len(matches) != 1 || matches[0].FilePath != "README.md" {
+		t.Fatalf("expected the semantically closest fragment, got %+v", matches)
+	}
+
+	// A changed project only embeds what changed and drops what is gone
+	project := testProject()
+	project.Files[1].Content = "# Demo\nA tiny gRPC server.\n"
+	project.Files[0].Fragments = project.Files[0].Fragments[:1]
+	emb.texts = 0
+	if err := NewIndex(project).UseEmbeddings(context.Background(), store, "fake", emb, ""); err != nil {
+		t.Fatalf("UseEmbeddings returned error: %v", err)
+	}
+	if emb.texts != 1 || store.Len() != 2 {
+		t.Fatalf("expected 1 new embedding and 2 vectors, got %d and %d", emb.texts, store.Len())
+	}
+	if err := NewIndex(project).UseEmbeddings(context.Background(), store, "other", emb, ""); err == nil {
+		t.Fatal("expected vectors of another provider to be refused")
+	}
+}
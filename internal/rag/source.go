@@ -0,0 +1,69 @@
+package rag
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/kubex-ecosystem/grompt/internal/module/kbx"
+	"github.com/kubex-ecosystem/grompt/internal/services/lookatni"
+)
+
+// Load returns the lookatni extraction of repo: a local directory, a git
+// URL, or a JSON file holding an extraction lookatni already made.
+// Directories and URLs are extracted with lookatni when it is installed in
+// the lookatni work directory, and with its Go extractor otherwise.
+func Load(ctx context.Context, repo string) (*lookatni.ExtractedProject, error) {
+	if isURL(repo) {
+		return lookatni.NewLookAtniService(WorkDir()).Extract(ctx, lookatni.ProjectExtractionRequest{RepoURL: repo})
+	}
+	info, err := os.Stat(repo)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return lookatni.NewLookAtniService(WorkDir()).Extract(ctx, lookatni.ProjectExtractionRequest{LocalPath: repo})
+	}
+
+	data, err := os.ReadFile(repo)
+	if err != nil {
+		return nil, err
+	}
+	var project lookatni.ExtractedProject
+	if err := json.Unmarshal(data, &project); err != nil {
+		return nil, fmt.Errorf("%s is not a lookatni extraction: %w", repo, err)
+	}
+	return &project, nil
+}
+
+// WorkDir returns the lookatni work directory under ~/.kubex/grompt, where
+// its Node.js module is looked up and repositories are cloned
+func WorkDir() string {
+	return filepath.Join(os.ExpandEnv(kbx.DefaultGromptDir), "lookatni")
+}
+
+// IndexName names the vector index of a repository's fragment embeddings
+// from a provider, stable across runs
+func IndexName(repo, provider string) string {
+	if !isURL(repo) {
+		if abs, err := filepath.Abs(repo); err == nil {
+			repo = abs
+		}
+	}
+	sum := sha256.Sum256([]byte(repo))
+	base := nonName.ReplaceAllString(strings.TrimSuffix(filepath.Base(repo), ".git"), "-")
+	return strings.Trim(fmt.Sprintf("repo-%.24s-%s-%s", base, hex.EncodeToString(sum[:4]), provider), "-")
+}
+
+var nonName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func isURL(repo string) bool {
+	return strings.HasPrefix(repo, "https://") || strings.HasPrefix(repo, "http://") ||
+		strings.HasPrefix(repo, "git@") || strings.HasPrefix(repo, "ssh://")
+}
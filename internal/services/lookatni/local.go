@@ -0,0 +1,360 @@
+package lookatni
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Fragment sizes of the local extractor, in lines
+const (
+	windowLines      = 60  // fragments of files that are not parsed
+	maxFragmentLines = 150 // longer declarations are split into windows
+)
+
+// defaultExcludePatterns are skipped by the local extractor when a request
+// has no exclude patterns
+var defaultExcludePatterns = []string{
+	".git/**", "node_modules/**", "vendor/**", "dist/**", "build/**", "target/**",
+	"*.min.js", "*.bundle.js", "*.lock", "*.sum", "*.log", "*.tmp", "*.cache",
+}
+
+// languages maps file extensions to the language names of lookatni
+var languages = map[string]string{
+	".go": "go", ".js": "javascript", ".jsx": "javascript", ".mjs": "javascript", ".ts": "typescript", ".tsx": "typescript",
+	".py": "python", ".java": "java", ".kt": "kotlin", ".scala": "scala", ".rs": "rust", ".rb": "ruby", ".php": "php",
+	".c": "c", ".h": "c", ".cpp": "cpp", ".cc": "cpp", ".hpp": "cpp", ".cs": "csharp", ".swift": "swift", ".dart": "dart",
+	".lua": "lua", ".sh": "shell", ".bash": "shell", ".ps1": "powershell", ".sql": "sql",
+	".html": "html", ".css": "css", ".scss": "scss", ".json": "json", ".xml": "xml",
+	".yaml": "yaml", ".yml": "yaml", ".toml": "toml", ".md": "markdown", ".txt": "text", ".rst": "text",
+}
+
+// Extract extracts a project with lookatni when its Node.js module is
+// installed in the work directory, and with ExtractLocal otherwise
+func (s *LookAtniService) Extract(ctx context.Context, req ProjectExtractionRequest) (*ExtractedProject, error) {
+	if s.lookatniAvailable() {
+		return s.ExtractProject(ctx, req)
+	}
+	return s.ExtractLocal(ctx, req)
+}
+
+func (s *LookAtniService) lookatniAvailable() bool {
+	if _, err := exec.LookPath("node"); err != nil {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(s.nodeModules, "lookatni"))
+	return err == nil
+}
+
+// ExtractLocal extracts a project in Go, without Node.js. It produces the
+// same ExtractedProject as lookatni: Go files are fragmented by top-level
+// declaration, other text files by windows of lines, and with FragmentBy
+// "file" every file is a single fragment. Binary files, hidden files (unless
+// IncludeHidden) and files above MaxFileSize are skipped.
+func (s *LookAtniService) ExtractLocal(ctx context.Context, req ProjectExtractionRequest) (*ExtractedProject, error) {
+	startTime := time.Now()
+
+	root := req.LocalPath
+	if root == "" && req.RepoURL != "" {
+		tempDir := filepath.Join(s.workDir, "temp", fmt.Sprintf("extract_%d", time.Now().UnixNano()))
+		if err := os.MkdirAll(tempDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create temp directory: %w", err)
+		}
+		defer os.RemoveAll(tempDir)
+		clonedPath, err := s.cloneRepository(ctx, req.RepoURL, tempDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to clone repository: %w", err)
+		}
+		root = clonedPath
+	}
+	if root == "" {
+		return nil, fmt.Errorf("a local path or a repository URL is required")
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", root)
+	}
+
+	exclude := req.ExcludePatterns
+	if len(exclude) == 0 {
+		exclude = defaultExcludePatterns
+	}
+	name := filepath.Base(root)
+	if req.RepoURL != "" {
+		name = strings.TrimSuffix(filepath.Base(req.RepoURL), ".git")
+	}
+	extracted := &ExtractedProject{
+		ProjectName: name,
+		Structure:   ProjectStructure{Root: root},
+		Metadata:    ProjectMetadata{Languages: map[string]int{}},
+	}
+
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, path)
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			return nil
+		}
+		hidden := strings.HasPrefix(d.Name(), ".")
+		if d.IsDir() {
+			if (hidden && !req.IncludeHidden) || matchAny(exclude, rel+"/") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || (hidden && !req.IncludeHidden) || matchAny(exclude, rel) {
+			return nil
+		}
+		if len(req.IncludePatterns) > 0 && !matchAny(req.IncludePatterns, rel) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if req.MaxFileSize > 0 && info.Size() > req.MaxFileSize {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil || isBinary(content) {
+			return nil
+		}
+
+		file := ExtractedFile{
+			Name:      d.Name(),
+			Path:      rel,
+			Content:   string(content),
+			Language:  languageOf(rel),
+			Size:      info.Size(),
+			LineCount: lineCount(content),
+			Metadata:  map[string]string{},
+		}
+		file.Fragments = fragmentFile(file, req.FragmentBy)
+		extracted.Files = append(extracted.Files, file)
+		extracted.Structure.TotalSize += file.Size
+		extracted.Metadata.Languages[file.Language]++
+		extracted.Metadata.TotalLines += file.LineCount
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", root, err)
+	}
+	extracted.Structure.TotalFiles = len(extracted.Files)
+	extracted.Metadata.TotalFiles = len(extracted.Files)
+
+	enhanced, err := s.enhanceExtraction(extracted, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enhance extraction: %w", err)
+	}
+	enhanced.ExtractedAt = time.Now()
+	enhanced.Metadata.ExtractionTime = time.Since(startTime)
+	return enhanced, nil
+}
+
+// fragmentFile splits a file into code fragments
+func fragmentFile(file ExtractedFile, by string) []CodeFragment {
+	lines := strings.SplitAfter(file.Content, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	fragment := func(typ, name string, start, end int) CodeFragment {
+		return CodeFragment{
+			Type:      typ,
+			Name:      name,
+			FilePath:  file.Path,
+			StartLine: start,
+			EndLine:   end,
+			Content:   strings.Join(lines[start-1:end], ""),
+			Language:  file.Language,
+			Metadata:  map[string]string{},
+		}
+	}
+	if by == "file" {
+		return []CodeFragment{fragment("file", file.Name, 1, len(lines))}
+	}
+
+	var out []CodeFragment
+	windows := func(typ, name string, start, end, size int) {
+		for from := start; from <= end; from += size {
+			to := min(from+size-1, end)
+			out = append(out, fragment(typ, name, from, to))
+		}
+	}
+	if file.Language == "go" {
+		if decls, ok := goDeclarations(file.Content); ok {
+			for _, d := range decls {
+				d.end = min(d.end, len(lines))
+				if d.start > d.end {
+					continue
+				}
+				if d.end-d.start+1 > maxFragmentLines {
+					windows(d.typ, d.name, d.start, d.end, maxFragmentLines)
+				} else {
+					out = append(out, fragment(d.typ, d.name, d.start, d.end))
+				}
+			}
+			if len(out) > 0 {
+				return out
+			}
+		}
+	}
+	windows("block", file.Name, 1, len(lines), windowLines)
+	return out
+}
+
+type declaration struct {
+	typ, name  string
+	start, end int
+}
+
+// goDeclarations lists the top-level declarations of a Go file with their
+// doc comments, without imports
+func goDeclarations(src string) ([]declaration, bool) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return nil, false
+	}
+	var out []declaration
+	for _, decl := range f.Decls {
+		start := decl.Pos()
+		var d declaration
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			d.typ, d.name = "function", decl.Name.Name
+			if decl.Recv != nil && len(decl.Recv.List) > 0 {
+				d.typ, d.name = "method", receiverName(decl.Recv.List[0].Type)+"."+decl.Name.Name
+			}
+			if decl.Doc != nil {
+				start = decl.Doc.Pos()
+			}
+		case *ast.GenDecl:
+			if decl.Tok == token.IMPORT || len(decl.Specs) == 0 {
+				continue
+			}
+			d.typ, d.name = genDeclaration(decl)
+			if decl.Doc != nil {
+				start = decl.Doc.Pos()
+			}
+		default:
+			continue
+		}
+		d.start, d.end = fset.Position(start).Line, fset.Position(decl.End()).Line
+		out = append(out, d)
+	}
+	return out, true
+}
+
+func genDeclaration(decl *ast.GenDecl) (string, string) {
+	var names []string
+	typ := strings.ToLower(decl.Tok.String()) // const, var, type
+	for _, spec := range decl.Specs {
+		switch spec := spec.(type) {
+		case *ast.TypeSpec:
+			names = append(names, spec.Name.Name)
+			if len(decl.Specs) == 1 {
+				switch spec.Type.(type) {
+				case *ast.StructType:
+					typ = "struct"
+				case *ast.InterfaceType:
+					typ = "interface"
+				}
+			}
+		case *ast.ValueSpec:
+			for _, n := range spec.Names {
+				names = append(names, n.Name)
+			}
+		}
+	}
+	if len(names) > 3 {
+		names = append(names[:3], "...")
+	}
+	return typ, strings.Join(names, ", ")
+}
+
+func receiverName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(e.X)
+	case *ast.IndexExpr:
+		return receiverName(e.X)
+	case *ast.IndexListExpr:
+		return receiverName(e.X)
+	case *ast.Ident:
+		return e.Name
+	}
+	return ""
+}
+
+// matchAny reports whether a slash-separated relative path matches one of
+// the patterns. "dir/**" matches everything under a directory named dir at
+// any depth, a pattern with a slash matches the whole path, and any other
+// pattern matches the file name. Directory paths end in a slash.
+func matchAny(patterns []string, rel string) bool {
+	dir := strings.HasSuffix(rel, "/")
+	rel = strings.TrimSuffix(rel, "/")
+	base := rel[strings.LastIndex(rel, "/")+1:]
+	for _, p := range patterns {
+		if prefix, ok := strings.CutSuffix(p, "/**"); ok {
+			if strings.Contains("/"+rel+"/", "/"+prefix+"/") {
+				return true
+			}
+			continue
+		}
+		if dir {
+			continue
+		}
+		target := base
+		if strings.Contains(p, "/") {
+			target = rel
+		}
+		if ok, _ := filepath.Match(p, target); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func languageOf(path string) string {
+	if lang, ok := languages[strings.ToLower(filepath.Ext(path))]; ok {
+		return lang
+	}
+	return "text"
+}
+
+// isBinary reports whether content looks binary: a NUL byte in its first 8KB
+func isBinary(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), 8<<10)], 0) >= 0
+}
+
+func lineCount(content []byte) int {
+	if len(content) == 0 {
+		return 0
+	}
+	n := bytes.Count(content, []byte("\n"))
+	if content[len(content)-1] != '\n' {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,73 @@
+package lookatni
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const goSource = `package demo
+
+import "fmt"
+
+// Greeter says hello
+type Greeter struct{ name string }
+
+// Greet prints the greeting
+func (g *Greeter) Greet() {
+	fmt.Println("hello", g.name)
+}
+
+func helper() {}
+`
+
+func TestExtractLocalFragmentsFiles(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("demo.go", goSource)
+	write("docs/notes.md", "# Notes\nsome notes\n")
+	write("node_modules/pkg/index.js", "module.exports = {}\n")
+	write(".env", "SECRET=1\n")
+	write("logo.png", "\x89PNG\x00\x00")
+
+	s := NewLookAtniService(t.TempDir())
+	project, err := s.ExtractLocal(context.Background(), ProjectExtractionRequest{LocalPath: root})
+	if err != nil {
+		t.Fatalf("ExtractLocal returned error: %v", err)
+	}
+	if len(project.Files) != 2 || project.Metadata.Languages["go"] != 1 {
+		t.Fatalf("expected the go and markdown files only, got %+v", project.Files)
+	}
+
+	byName := map[string]CodeFragment{}
+	for _, f := range project.Fragments {
+		byName[f.Name] = f
+	}
+	greet, ok := byName["Greeter.Greet"]
+	if !ok || greet.Type != "method" || greet.StartLine != 8 || greet.EndLine != 11 || greet.FilePath != "demo.go" {
+		t.Fatalf("unexpected method fragment %+v", greet)
+	}
+	if typ := byName["Greeter"]; typ.Type != "struct" || typ.StartLine != 5 {
+		t.Fatalf("unexpected struct fragment %+v", typ)
+	}
+	if notes := byName["notes.md"]; notes.Type != "block" || notes.EndLine != 2 || notes.ID == "" {
+		t.Fatalf("unexpected markdown fragment %+v", notes)
+	}
+
+	project, err = s.ExtractLocal(context.Background(), ProjectExtractionRequest{LocalPath: root, IncludePatterns: []string{"*.go"}, FragmentBy: "file"})
+	if err != nil {
+		t.Fatalf("ExtractLocal returned error: %v", err)
+	}
+	if len(project.Fragments) != 1 || project.Fragments[0].Type != "file" || project.Fragments[0].EndLine != 13 {
+		t.Fatalf("expected a single file fragment, got %+v", project.Fragments)
+	}
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
 	"net/http"
@@ -9,7 +10,17 @@ import (
 	"strings"
 	"time"
 
+	"github.com/kubex-ecosystem/grompt/internal/cache"
+	"github.com/kubex-ecosystem/grompt/internal/engine"
+	"github.com/kubex-ecosystem/grompt/internal/examples"
+	"github.com/kubex-ecosystem/grompt/internal/gateway/middleware"
+	"github.com/kubex-ecosystem/grompt/internal/history"
 	ii "github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"github.com/kubex-ecosystem/grompt/internal/lint"
+	"github.com/kubex-ecosystem/grompt/internal/rag"
+	"github.com/kubex-ecosystem/grompt/internal/services/lookatni"
+	"github.com/kubex-ecosystem/grompt/internal/strategy"
+	"github.com/kubex-ecosystem/grompt/internal/structured"
 	it "github.com/kubex-ecosystem/grompt/internal/types"
 )
 
@@ -21,6 +32,11 @@ type Handlers struct {
 	chatGPTAPI  ii.IAPIConfig
 	geminiAPI   ii.IAPIConfig
 	ollamaAPI   ii.IAPIConfig
+	history     ii.IHistoryManager
+	engine      ii.IEngine
+	cache       *cache.Cache
+	examples    *examples.Store
+	auth        *middleware.Authenticator // nil: nobody is an admin
 	// agentStore  *agents.Store
 }
 
@@ -31,7 +47,7 @@ type Handlers struct {
 //   - Direct prompt: {"prompt": "Write a story about cats", "max_tokens": 500}
 //   - Prompt engineering: {"ideas": ["cats", "adventure", "friendship"], "purpose": "Creative writing", "max_tokens": 500}
 type UnifiedRequest struct {
-	Lang        string   `json:"lang,omitempty"`         // Response language (default: "português")
+	Lang        string   `json:"lang,omitempty"`         // Response language (default: "english")
 	Purpose     string   `json:"purpose,omitempty"`      // Specific purpose description
 	PurposeType string   `json:"purpose_type,omitempty"` // Type category (e.g., "Tutorial", "Creative writing")
 	Ideas       []string `json:"ideas,omitempty"`        // Raw ideas for prompt engineering (alternative to prompt)
@@ -39,14 +55,44 @@ type UnifiedRequest struct {
 	MaxTokens   int      `json:"max_tokens,omitempty"`   // Maximum response tokens
 	Model       string   `json:"model,omitempty"`        // AI model to use
 	Provider    string   `json:"provider,omitempty"`     // AI provider (for unified endpoint)
+	Lint        bool     `json:"lint,omitempty"`         // Lint the generated prompt (ideas mode only)
+	Strategy    string   `json:"strategy,omitempty"`     // Prompt engineering strategy (ideas mode only; default: by purpose_type)
+	// MaxExamples is the number of library examples appended to an
+	// engineered prompt as demonstrations: 0 for the default, negative for
+	// none (ideas mode only, not with refine)
+	MaxExamples int `json:"max_examples,omitempty"`
+	// ContextPolicy decides what happens to a prompt larger than the model's
+	// context window: "reject" (default, 413) or "trim"
+	ContextPolicy string `json:"context_policy,omitempty"`
+	// JSONSchema switches to JSON output: the response must be a document
+	// valid against it, re-prompting with the errors up to JSONRetries times
+	JSONSchema  json.RawMessage `json:"json_schema,omitempty"`
+	JSONRetries int             `json:"json_retries,omitempty"`
+	// Refine (ideas mode only) regenerates the prompt until a critic scores
+	// it above a threshold; the provider, model and max_tokens of the request
+	// are the defaults of the options
+	Refine *ii.RefineOptions `json:"refine,omitempty"`
 }
 
 type UnifiedResponse struct {
-	Response string     `json:"response"`
-	Provider string     `json:"provider"`
-	Model    string     `json:"model"`
-	Mode     string     `json:"mode,omitempty"` // "byok", "server", or "demo"
-	Usage    *UsageInfo `json:"usage,omitempty"`
+	Response string       `json:"response"`
+	Provider string       `json:"provider"`
+	Model    string       `json:"model"`
+	Mode     string       `json:"mode,omitempty"` // "byok", "server", or "demo"
+	Usage    *UsageInfo   `json:"usage,omitempty"`
+	Lint     *lint.Report `json:"lint,omitempty"`
+	Cached   bool         `json:"cached,omitempty"` // answered from the response cache
+	// JSON is the parsed response in JSON output mode
+	JSON         any `json:"json,omitempty"`
+	JSONAttempts int `json:"json_attempts,omitempty"`
+	// Strategy engineered the prompt, in ideas mode
+	Strategy string `json:"strategy,omitempty"`
+	// Examples are the IDs of the library examples injected, in ideas mode
+	Examples []string `json:"examples,omitempty"`
+	// Refinement holds every iteration of a refine request with its scores
+	Refinement *ii.Refinement `json:"refinement,omitempty"`
+	// Citations are the code fragments an ask about a repository was answered from
+	Citations []rag.Citation `json:"citations,omitempty"`
 }
 
 type UsageInfo struct {
@@ -54,6 +100,7 @@ type UsageInfo struct {
 	CompletionTokens int     `json:"completion_tokens,omitempty"`
 	TotalTokens      int     `json:"total_tokens,omitempty"`
 	EstimatedCost    float64 `json:"estimated_cost,omitempty"`
+	Estimated        bool    `json:"estimated,omitempty"` // counted locally, not reported by the provider
 }
 
 var llmKeyMap map[string]string
@@ -200,6 +247,18 @@ func NewHandlers(cfg ii.IConfig) *Handlers {
 	hndr.deepseekAPI = it.NewDeepSeekAPI(llmKeyMap["deepseek"])
 	hndr.ollamaAPI = it.NewOllamaAPI(llmKeyMap["ollama"])
 	hndr.geminiAPI = it.NewGeminiAPI(llmKeyMap["gemini"])
+	hndr.history = history.Open()
+	hndr.engine = engine.NewEngine(cfg)
+	// Completions share the engine's response cache
+	if eng, ok := hndr.engine.(interface{ GetCache() *cache.Cache }); ok {
+		hndr.cache = eng.GetCache()
+	}
+	// and its few-shot example library
+	if eng, ok := hndr.engine.(interface{ GetExamples() *examples.Store }); ok {
+		hndr.examples = eng.GetExamples()
+	}
+	// Admin routes check the gateway's client credentials
+	hndr.auth = loadAuth()
 	// hndr.agentStore = agents.NewStore("agents.json")
 
 	return hndr
@@ -856,6 +915,23 @@ func (h *Handlers) HandleUnified(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Refine != nil && req.Prompt == "" {
+		h.handleRefine(w, r, req, prompt)
+		return
+	}
+
+	// Without a model the provider's default window is assumed
+	var ok bool
+	if prompt, req.MaxTokens, ok = h.preflight(w, req.Provider, req.Model, prompt, req.MaxTokens, req.ContextPolicy); !ok {
+		return
+	}
+
+	call, schemaErr := newCompletion(req.JSONSchema, req.JSONRetries)
+	if schemaErr != nil {
+		http.Error(w, schemaErr.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// BYOK Support: Check for external API key in headers
 	// Supports both generic X-API-Key and provider-specific X-{PROVIDER}-Key headers
 	externalKey := r.Header.Get("X-API-Key")
@@ -879,130 +955,190 @@ func (h *Handlers) HandleUnified(w http.ResponseWriter, r *http.Request) {
 		mode = "server"
 	}
 
-	switch req.Provider {
-	case "claude":
-		if finalAPIKey == "" {
-			// Demo mode fallback
-			mode = "demo"
-			if model == "" {
-				model = "claude-3-5-sonnet-20241022"
-			}
-			response = h.generateDemoResponse(prompt, req.Purpose)
-		} else {
-			// Real API call
-			api := h.claudeAPI
-			if mode == "byok" {
-				api = it.NewClaudeAPI(finalAPIKey)
-			}
-			if model == "" {
-				model = "claude-3-5-sonnet-20241022"
-			}
-			response, err = api.Complete(prompt, maxTokens, model)
+	// Ideas are engineered by the strategy of the purpose type. The default
+	// single-call strategy continues below, with the cache and demo mode.
+	if req.Prompt == "" && req.Refine == nil {
+		s, err := strategy.Select(req.Strategy, req.PurposeType)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		}
-
-	case "openai":
-		if finalAPIKey == "" {
-			// Demo mode fallback
-			mode = "demo"
-			if model == "" {
-				model = "gpt-4o-mini"
-			}
-			response = h.generateDemoResponse(prompt, req.Purpose)
-		} else {
-			// Real API call
-			api := h.openaiAPI
-			if mode == "byok" {
-				api = it.NewOpenAIAPI(finalAPIKey)
-			}
-			if model == "" {
-				model = "gpt-4o-mini"
-			}
-			response, err = api.Complete(prompt, req.MaxTokens, model)
+		if s.Name() != strategy.Default && (finalAPIKey != "" || req.Provider == "ollama") {
+			h.handleStrategy(w, r, req, s, finalAPIKey, mode)
+			return
 		}
+	}
 
-	case "deepseek":
-		if finalAPIKey == "" {
-			// Demo mode fallback
-			mode = "demo"
-			if model == "" {
-				model = "deepseek-chat"
-			}
-			response = h.generateDemoResponse(prompt, req.Purpose)
-		} else {
-			// Real API call
-			api := h.deepseekAPI
-			if mode == "byok" {
-				api = it.NewDeepSeekAPI(finalAPIKey)
+	// Real calls are answered from the response cache when possible; demo
+	// responses are never stored
+	var hit *cache.Entry
+	var cacheKey string
+	if finalAPIKey != "" || req.Provider == "ollama" {
+		hit, cacheKey = h.cacheLookup(w, r, "completion", req.Provider, model, maxTokens, prompt, call.schema)
+	}
+
+	if hit != nil {
+		response, model = hit.Content, hit.Model
+		call.parse(response)
+	} else {
+		switch req.Provider {
+		case "claude":
+			if finalAPIKey == "" {
+				// Demo mode fallback
+				mode = "demo"
+				if model == "" {
+					model = "claude-3-5-sonnet-20241022"
+				}
+				response = h.generateDemoResponse(prompt, req.Purpose)
+			} else {
+				// Real API call
+				api := h.claudeAPI
+				if mode == "byok" {
+					api = it.NewClaudeAPI(finalAPIKey)
+				}
+				if model == "" {
+					model = "claude-3-5-sonnet-20241022"
+				}
+				response, err = call.run(r.Context(), api, prompt, maxTokens, model)
 			}
-			if model == "" {
-				model = "deepseek-chat"
+
+		case "openai":
+			if finalAPIKey == "" {
+				// Demo mode fallback
+				mode = "demo"
+				if model == "" {
+					model = "gpt-4o-mini"
+				}
+				response = h.generateDemoResponse(prompt, req.Purpose)
+			} else {
+				// Real API call
+				api := h.openaiAPI
+				if mode == "byok" {
+					api = it.NewOpenAIAPI(finalAPIKey)
+				}
+				if model == "" {
+					model = "gpt-4o-mini"
+				}
+				response, err = call.run(r.Context(), api, prompt, maxTokens, model)
 			}
-			response, err = api.Complete(prompt, req.MaxTokens, model)
-		}
 
-	case "gemini":
-		if finalAPIKey == "" {
-			// Demo mode fallback
-			mode = "demo"
-			if model == "" {
-				model = "gemini-2.0-flash-exp"
+		case "deepseek":
+			if finalAPIKey == "" {
+				// Demo mode fallback
+				mode = "demo"
+				if model == "" {
+					model = "deepseek-chat"
+				}
+				response = h.generateDemoResponse(prompt, req.Purpose)
+			} else {
+				// Real API call
+				api := h.deepseekAPI
+				if mode == "byok" {
+					api = it.NewDeepSeekAPI(finalAPIKey)
+				}
+				if model == "" {
+					model = "deepseek-chat"
+				}
+				response, err = call.run(r.Context(), api, prompt, maxTokens, model)
 			}
-			response = h.generateDemoResponse(prompt, req.Purpose)
-		} else {
-			// Real API call
-			api := h.geminiAPI
-			if mode == "byok" {
-				api = it.NewGeminiAPI(finalAPIKey)
+
+		case "gemini":
+			if finalAPIKey == "" {
+				// Demo mode fallback
+				mode = "demo"
+				if model == "" {
+					model = "gemini-2.0-flash-exp"
+				}
+				response = h.generateDemoResponse(prompt, req.Purpose)
+			} else {
+				// Real API call
+				api := h.geminiAPI
+				if mode == "byok" {
+					api = it.NewGeminiAPI(finalAPIKey)
+				}
+				if model == "" {
+					model = "gemini-2.0-flash-exp"
+				}
+				response, err = call.run(r.Context(), api, prompt, maxTokens, model)
 			}
-			if model == "" {
-				model = "gemini-2.0-flash-exp"
+
+		case "chatgpt":
+			if finalAPIKey == "" {
+				// Demo mode fallback
+				mode = "demo"
+				if model == "" {
+					model = "gpt-4o-mini"
+				}
+				response = h.generateDemoResponse(prompt, req.Purpose)
+			} else {
+				// Real API call
+				api := h.chatGPTAPI
+				if mode == "byok" {
+					api = it.NewChatGPTAPI(finalAPIKey)
+				}
+				if model == "" {
+					model = "gpt-4o-mini"
+				}
+				response, err = call.run(r.Context(), api, prompt, maxTokens, model)
 			}
-			response, err = api.Complete(prompt, req.MaxTokens, model)
-		}
 
-	case "chatgpt":
-		if finalAPIKey == "" {
-			// Demo mode fallback
-			mode = "demo"
+		case "ollama":
+			// Ollama doesn't require API key (local instance)
 			if model == "" {
-				model = "gpt-4o-mini"
+				model = "llama3.2"
 			}
-			response = h.generateDemoResponse(prompt, req.Purpose)
-		} else {
-			// Real API call
-			api := h.chatGPTAPI
-			if mode == "byok" {
-				api = it.NewChatGPTAPI(finalAPIKey)
-			}
-			if model == "" {
-				model = "gpt-4o-mini"
-			}
-			response, err = api.Complete(prompt, req.MaxTokens, model)
-		}
+			response, err = call.run(r.Context(), h.ollamaAPI, prompt, maxTokens, model)
 
-	case "ollama":
-		// Ollama doesn't require API key (local instance)
-		if model == "" {
-			model = "llama3.2"
+		default:
+			http.Error(w, "Unsupported provider: "+req.Provider, http.StatusBadRequest)
+			return
 		}
-		response, err = h.ollamaAPI.Complete(model, maxTokens, prompt)
-
-	default:
-		http.Error(w, "Unsupported provider: "+req.Provider, http.StatusBadRequest)
-		return
 	}
 
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Error in %s API: %v", req.Provider, err), http.StatusInternalServerError)
+		status := http.StatusInternalServerError
+		var invalid *structured.Error
+		if errors.As(err, &invalid) {
+			status = http.StatusUnprocessableEntity
+		}
+		http.Error(w, fmt.Sprintf("Error in %s API: %v", req.Provider, err), status)
 		return
 	}
+	if hit == nil {
+		h.cacheStore(cacheKey, req.Provider, model, response)
+	}
+
+	// Library examples are appended to engineered prompts as demonstrations
+	var exampleIDs []string
+	if req.Prompt == "" && call.doc == nil {
+		var demos []ii.Example
+		if demos, exampleIDs = h.requestExamples(r, req); len(demos) > 0 {
+			response = strings.TrimSpace(response) + "\n\n" + strings.TrimSpace(strategy.Demonstrations(req.Lang, demos))
+		}
+	}
 
 	result := UnifiedResponse{
 		Response: response,
 		Provider: req.Provider,
 		Model:    model,
 		Mode:     mode, // Include mode in response
+		Usage:    estimatedUsage(req.Provider, model, prompt, response),
+		Cached:   hit != nil,
+	}
+	if req.Prompt == "" {
+		result.Strategy = strategy.Default
+		if len(exampleIDs) > 0 {
+			result.Examples = exampleIDs
+		}
 	}
+	if call.doc != nil {
+		result.JSON, result.JSONAttempts = call.doc.Data, call.doc.Attempts
+	}
+	if req.Lint && req.Prompt == "" {
+		report := lint.Lint(response, lint.Options{})
+		result.Lint = &report
+	}
+	h.recordHistory(prompt, result)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(result)
@@ -1015,10 +1151,19 @@ func (h *Handlers) HandleUnified(w http.ResponseWriter, r *http.Request) {
 //	  "question": "...",            // required
 //	  "provider": "openai|claude|...", // optional (auto-pick if omitted)
 //	  "model": "gpt-4o-mini",       // optional
-//	  "max_tokens": 1000             // optional
+//	  "max_tokens": 1000,            // optional
+//	  "repo": "services/billing",   // optional, answer from a code base
+//	  "project": { ... },           // optional, a lookatni extraction instead of repo
+//	  "max_fragments": 8,           // optional, fragments retrieved
+//	  "max_context_tokens": 4000    // optional, cap on the tokens of the sources
 //	}
 //
-// Response JSON matches UnifiedResponse: { response, provider, model }
+// With repo or project the question is answered from the fragments of the
+// code base most relevant to it, cited by file and line range. repo is a
+// directory or extraction file under GROMPT_ASK_REPOS_ROOT, and is refused
+// when that is unset.
+//
+// Response JSON matches UnifiedResponse: { response, provider, model, citations }
 func (h *Handlers) HandleAsk(w http.ResponseWriter, r *http.Request) {
 	h.setCORSHeaders(w)
 
@@ -1036,6 +1181,13 @@ func (h *Handlers) HandleAsk(w http.ResponseWriter, r *http.Request) {
 		Model     string `json:"model,omitempty"`
 		MaxTokens int    `json:"max_tokens,omitempty"`
 		Lang      string `json:"lang,omitempty"`
+		// ContextPolicy is "reject" (default) or "trim", as in UnifiedRequest
+		ContextPolicy string `json:"context_policy,omitempty"`
+		// Repo or Project is the code base the question is answered from
+		Repo             string                     `json:"repo,omitempty"`
+		Project          *lookatni.ExtractedProject `json:"project,omitempty"`
+		MaxFragments     int                        `json:"max_fragments,omitempty"`
+		MaxContextTokens int                        `json:"max_context_tokens,omitempty"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -1072,68 +1224,101 @@ func (h *Handlers) HandleAsk(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	switch provider {
-	case "openai":
-		if h.config.GetAPIKey("openai") == "" {
-			http.Error(w, "OpenAI API Key not configured", http.StatusServiceUnavailable)
-			return
-		}
-		if model == "" {
-			model = "gpt-4o-mini"
-		}
-		response, err = h.openaiAPI.Complete(prompt, maxTokens, model)
-	case "claude":
-		if h.config.GetAPIKey("claude") == "" {
-			http.Error(w, "Claude API Key not configured", http.StatusServiceUnavailable)
-			return
-		}
-		if model == "" {
-			model = "claude-3-5-sonnet-20241022"
-		}
-		response, err = h.claudeAPI.Complete(prompt, maxTokens, model)
-	case "deepseek":
-		if h.config.GetAPIKey("deepseek") == "" {
-			http.Error(w, "DeepSeek API Key not configured", http.StatusServiceUnavailable)
-			return
-		}
-		if model == "" {
-			model = "deepseek-chat"
-		}
-		response, err = h.deepseekAPI.Complete(prompt, maxTokens, model)
-	case "ollama":
-		if model == "" {
-			model = "llama3.2"
-		}
-		response, err = h.ollamaAPI.Complete(model, maxTokens, prompt)
-	case "gemini":
-		if h.config.GetAPIKey("gemini") == "" {
-			http.Error(w, "Gemini API Key not configured", http.StatusServiceUnavailable)
+	var citations []rag.Citation
+	if strings.TrimSpace(req.Repo) != "" || req.Project != nil {
+		rp, ok := repoPrompt(r.Context(), w, strings.TrimSpace(req.Repo), req.Project, req.Question, rag.Options{
+			Provider:         provider,
+			Model:            model,
+			K:                req.MaxFragments,
+			MaxContextTokens: req.MaxContextTokens,
+			ReserveTokens:    maxTokens,
+		})
+		if !ok {
 			return
 		}
-		if model == "" {
-			model = "gemini-1.5-flash"
-		}
-		response, err = h.geminiAPI.Complete(prompt, maxTokens, model)
-	case "chatgpt":
-		if h.config.GetAPIKey("chatgpt") == "" {
-			http.Error(w, "ChatGPT API Key not configured", http.StatusServiceUnavailable)
+		prompt, citations = rp.Text(), rp.Citations
+	}
+
+	prompt, maxTokens, ok := h.preflight(w, provider, model, prompt, maxTokens, req.ContextPolicy)
+	if !ok {
+		return
+	}
+
+	var hit *cache.Entry
+	var cacheKey string
+	if h.isAPIEnabled(provider) || provider == "ollama" {
+		hit, cacheKey = h.cacheLookup(w, r, "completion", provider, model, maxTokens, prompt)
+	}
+
+	if hit != nil {
+		response, model = hit.Content, hit.Model
+	} else {
+		switch provider {
+		case "openai":
+			if h.config.GetAPIKey("openai") == "" {
+				http.Error(w, "OpenAI API Key not configured", http.StatusServiceUnavailable)
+				return
+			}
+			if model == "" {
+				model = "gpt-4o-mini"
+			}
+			response, err = h.openaiAPI.Complete(prompt, maxTokens, model)
+		case "claude":
+			if h.config.GetAPIKey("claude") == "" {
+				http.Error(w, "Claude API Key not configured", http.StatusServiceUnavailable)
+				return
+			}
+			if model == "" {
+				model = "claude-3-5-sonnet-20241022"
+			}
+			response, err = h.claudeAPI.Complete(prompt, maxTokens, model)
+		case "deepseek":
+			if h.config.GetAPIKey("deepseek") == "" {
+				http.Error(w, "DeepSeek API Key not configured", http.StatusServiceUnavailable)
+				return
+			}
+			if model == "" {
+				model = "deepseek-chat"
+			}
+			response, err = h.deepseekAPI.Complete(prompt, maxTokens, model)
+		case "ollama":
+			if model == "" {
+				model = "llama3.2"
+			}
+			response, err = h.ollamaAPI.Complete(model, maxTokens, prompt)
+		case "gemini":
+			if h.config.GetAPIKey("gemini") == "" {
+				http.Error(w, "Gemini API Key not configured", http.StatusServiceUnavailable)
+				return
+			}
+			if model == "" {
+				model = "gemini-1.5-flash"
+			}
+			response, err = h.geminiAPI.Complete(prompt, maxTokens, model)
+		case "chatgpt":
+			if h.config.GetAPIKey("chatgpt") == "" {
+				http.Error(w, "ChatGPT API Key not configured", http.StatusServiceUnavailable)
+				return
+			}
+			if model == "" {
+				model = "gpt-4o-mini"
+			}
+			response, err = h.chatGPTAPI.Complete(prompt, maxTokens, model)
+		default:
+			http.Error(w, "Unsupported provider: "+provider, http.StatusBadRequest)
 			return
 		}
-		if model == "" {
-			model = "gpt-4o-mini"
-		}
-		response, err = h.chatGPTAPI.Complete(prompt, maxTokens, model)
-	default:
-		http.Error(w, "Unsupported provider: "+provider, http.StatusBadRequest)
-		return
 	}
 
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Error in %s API: %v", provider, err), http.StatusInternalServerError)
 		return
 	}
+	if hit == nil {
+		h.cacheStore(cacheKey, provider, model, response)
+	}
 
-	res := UnifiedResponse{Response: response, Provider: provider, Model: model}
+	res := UnifiedResponse{Response: response, Provider: provider, Model: model, Usage: estimatedUsage(provider, model, prompt, response), Cached: hit != nil, Citations: citations}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(res)
 }
@@ -1399,9 +1584,10 @@ func (h *Handlers) setCORSHeaders(w http.ResponseWriter) {
 	// These headers allow cross-origin requests from any domain
 	// Adjust as necessary for your security requirements.
 	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
 	// BYOK Support: Allow custom API key headers
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, X-OPENAI-Key, X-CLAUDE-Key, X-GEMINI-Key, X-DEEPSEEK-Key, X-CHATGPT-Key")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Cache-Control, X-API-Key, X-OPENAI-Key, X-CLAUDE-Key, X-GEMINI-Key, X-DEEPSEEK-Key, X-CHATGPT-Key")
+	w.Header().Set("Access-Control-Expose-Headers", cacheHeader)
 	w.Header().Set("Content-Security-Policy", "default-src 'self'; script-src 'self'; style-src 'self'; img-src 'self' data:; font-src 'self'; connect-src 'self' https://api.openai.com https://api.deepseek.com https://api.ollama.com; frame-ancestors 'none'")
 	w.Header().Set("Referrer-Policy", "no-referrer")
 	w.Header().Set("X-Content-Security-Policy", "default-src 'self'; script-src 'self'; style-src 'self'; img-src 'self' data:; font-src 'self'; connect-src 'self' https://api.openai.com https://api.deepseek.com https://api.ollama.com; frame-ancestors 'none'")
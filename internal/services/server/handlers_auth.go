@@ -0,0 +1,58 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	gl "github.com/kubex-ecosystem/logz/logger"
+
+	"github.com/kubex-ecosystem/grompt/internal/gateway/middleware"
+)
+
+// middlewareConfigEnv names the gateway middleware YAML whose auth section
+// also guards the admin routes of this server, such as clearing the cache.
+const middlewareConfigEnv = "GROMPT_MIDDLEWARE_CONFIG"
+
+// loadAuth returns the authenticator of the file middlewareConfigEnv names,
+// nil when it is unset or has auth disabled
+func loadAuth() *middleware.Authenticator {
+	path := os.Getenv(middlewareConfigEnv)
+	if path == "" {
+		return nil
+	}
+	config, err := middleware.LoadProductionConfig(path)
+	if err != nil {
+		gl.Log("warn", fmt.Sprintf("Admin routes are disabled: %v", err))
+		return nil
+	}
+	if !config.Auth.Enabled {
+		return nil
+	}
+	auth, err := middleware.NewAuthenticator(config.Auth)
+	if err != nil {
+		gl.Log("warn", fmt.Sprintf("Admin routes are disabled: %v", err))
+		return nil
+	}
+	return auth
+}
+
+// requireAdmin checks that r carries credentials with the admin scope and
+// answers 401 or 403 when it does not. Without auth nobody is an admin.
+func (h *Handlers) requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if h.auth == nil {
+		http.Error(w, fmt.Sprintf("this needs the admin scope; enable auth in the middleware config %s names", middlewareConfigEnv), http.StatusForbidden)
+		return false
+	}
+	p, err := h.auth.Authenticate(r)
+	if err != nil {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="grompt"`)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return false
+	}
+	if !p.Can(middleware.ScopeAdmin) {
+		http.Error(w, fmt.Sprintf("%s: %s", middleware.ErrForbidden, middleware.ScopeAdmin), http.StatusForbidden)
+		return false
+	}
+	return true
+}
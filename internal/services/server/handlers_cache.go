@@ -0,0 +1,80 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	gl "github.com/kubex-ecosystem/logz/logger"
+
+	"github.com/kubex-ecosystem/grompt/internal/cache"
+)
+
+// cacheHeader reports on every completion whether the response cache
+// answered it: HIT, MISS or BYPASS.
+const cacheHeader = "X-Grompt-Cache"
+
+// cacheLookup returns the cached response for a completion identified by
+// parts, and the key to store a fresh response under. The key is empty when
+// the cache is disabled or the client sent Cache-Control: no-cache.
+func (h *Handlers) cacheLookup(w http.ResponseWriter, r *http.Request, parts ...any) (*cache.Entry, string) {
+	if h.cache == nil {
+		return nil, ""
+	}
+	if cache.NoCache(r.Header.Get("Cache-Control")) {
+		h.cache.Bypassed()
+		w.Header().Set(cacheHeader, "BYPASS")
+		return nil, ""
+	}
+	key := cache.Key(parts...)
+	if entry, ok := h.cache.Get(key); ok {
+		w.Header().Set(cacheHeader, "HIT")
+		return entry, key
+	}
+	w.Header().Set(cacheHeader, "MISS")
+	return nil, key
+}
+
+// cacheStore keeps a fresh response under a key from cacheLookup
+func (h *Handlers) cacheStore(key, provider, model, response string) {
+	if key == "" {
+		return
+	}
+	if err := h.cache.Put(key, provider, model, response, nil); err != nil {
+		gl.Log("warn", fmt.Sprintf("Failed to cache %s response: %v", provider, err))
+	}
+}
+
+// HandleCache reports the response cache statistics and clears it; clearing
+// needs an admin.
+//
+//	GET    /api/v1/cache
+//	DELETE /api/v1/cache
+func (h *Handlers) HandleCache(w http.ResponseWriter, r *http.Request) {
+	h.setCORSHeaders(w)
+
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodDelete:
+		if !h.requireAdmin(w, r) {
+			return
+		}
+		if err := h.cache.Clear(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"enabled": h.cache != nil,
+		"stats":   h.cache.Stats(),
+	})
+}
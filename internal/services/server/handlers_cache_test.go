@@ -0,0 +1,62 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kubex-ecosystem/grompt/internal/cache"
+	"github.com/kubex-ecosystem/grompt/internal/gateway/middleware"
+)
+
+func TestClearingCacheNeedsAdmin(t *testing.T) {
+	responses, err := cache.New(cache.Config{Backend: "memory"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth, err := middleware.NewAuthenticator(middleware.AuthConfig{Enabled: true, Keys: []middleware.APIKey{
+		{Name: "ana", Hash: middleware.HashAPIKey("gk_ana"), Tenant: "acme", Scopes: []string{middleware.ScopeChat}},
+		{Name: "ops", Hash: middleware.HashAPIKey("gk_ops"), Tenant: "acme", Scopes: []string{middleware.ScopeAdmin}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deleteCache := func(h *Handlers, key string) int {
+		if err := responses.Put("k", "openai", "gpt-4o-mini", "cached", nil); err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/cache", nil)
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		rec := httptest.NewRecorder()
+		h.HandleCache(rec, req)
+		return rec.Code
+	}
+
+	for _, tc := range []struct {
+		name string
+		auth *middleware.Authenticator
+		key  string
+		want int
+	}{
+		{"auth disabled", nil, "", http.StatusForbidden},
+		{"no credentials", auth, "", http.StatusUnauthorized},
+		{"chat scope", auth, "gk_ana", http.StatusForbidden},
+	} {
+		if code := deleteCache(&Handlers{cache: responses, auth: tc.auth}, tc.key); code != tc.want {
+			t.Fatalf("%s: expected %d, got %d", tc.name, tc.want, code)
+		}
+		if _, ok := responses.Get("k"); !ok {
+			t.Fatalf("%s: expected the cache to be kept", tc.name)
+		}
+	}
+
+	if code := deleteCache(&Handlers{cache: responses, auth: auth}, "gk_ops"); code != http.StatusOK {
+		t.Fatalf("expected an admin to clear the cache, got %d", code)
+	}
+	if _, ok := responses.Get("k"); ok {
+		t.Fatal("expected the cache to be cleared")
+	}
+}
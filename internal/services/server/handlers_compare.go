@@ -0,0 +1,79 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	ii "github.com/kubex-ecosystem/grompt/internal/interfaces"
+)
+
+// CompareRequest sends one prompt to several providers. Targets may be given
+// as full objects or, for the common case, as "provider" / "provider:model"
+// strings in Providers. Either 'prompt' OR 'ideas' must be provided.
+//
+//	{"prompt": "Summarize this RFC", "providers": ["gemini", "claude", "openai:gpt-4o-mini"]}
+type CompareRequest struct {
+	Prompt      string             `json:"prompt,omitempty"`
+	Ideas       []string           `json:"ideas,omitempty"`
+	Purpose     string             `json:"purpose,omitempty"`
+	PurposeType string             `json:"purpose_type,omitempty"`
+	Lang        string             `json:"lang,omitempty"`
+	MaxTokens   int                `json:"max_tokens,omitempty"`
+	Providers   []string           `json:"providers,omitempty"`
+	Targets     []ii.CompareTarget `json:"targets,omitempty"`
+	Variables   map[string]any     `json:"variables,omitempty"`
+}
+
+// HandleCompare sends a prompt to N providers/models in parallel and returns
+// every response with its latency, token usage and estimated cost. Without
+// targets, all configured providers are compared.
+func (h *Handlers) HandleCompare(w http.ResponseWriter, r *http.Request) {
+	h.setCORSHeaders(w)
+
+	if r.Method == "OPTIONS" {
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.engine == nil {
+		http.Error(w, "Engine not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req CompareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	prompt := req.Prompt
+	if prompt == "" && len(req.Ideas) > 0 {
+		prompt = h.config.GetBaseGenerationPrompt(req.Ideas, req.Purpose, req.PurposeType, req.Lang, req.MaxTokens)
+	}
+	if strings.TrimSpace(prompt) == "" {
+		http.Error(w, "Either 'prompt' or 'ideas' must be provided", http.StatusBadRequest)
+		return
+	}
+
+	targets := append([]ii.CompareTarget(nil), req.Targets...)
+	for _, spec := range req.Providers {
+		provider, model, _ := strings.Cut(strings.TrimSpace(spec), ":")
+		targets = append(targets, ii.CompareTarget{Provider: provider, Model: model})
+	}
+	if len(targets) == 0 && len(h.engine.GetProviders()) == 0 {
+		http.Error(w, "No AI provider configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	comparison, err := h.engine.Compare(r.Context(), prompt, targets, req.Variables)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(comparison)
+}
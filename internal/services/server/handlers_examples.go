@@ -0,0 +1,106 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	ii "github.com/kubex-ecosystem/grompt/internal/interfaces"
+)
+
+// HandleExamples manages the few-shot example library.
+//
+//	GET    /api/v1/examples?purpose=&tag=   list, oldest first
+//	POST   /api/v1/examples                 add an interfaces.Example
+//	POST   /api/v1/examples/search          rank by an interfaces.ExampleQuery
+//	GET    /api/v1/examples/{id}
+//	DELETE /api/v1/examples/{id}
+//
+// A unique prefix of an ID is enough.
+func (h *Handlers) HandleExamples(w http.ResponseWriter, r *http.Request) {
+	h.setCORSHeaders(w)
+
+	if r.Method == "OPTIONS" {
+		return
+	}
+	if h.examples == nil {
+		http.Error(w, "Example library not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/examples"), "/")
+	switch {
+	case id == "search" && r.Method == http.MethodPost:
+		var q ii.ExampleQuery
+		if err := json.NewDecoder(r.Body).Decode(&q); err != nil {
+			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+		if strings.TrimSpace(q.Text) == "" {
+			http.Error(w, "text is required", http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(h.examples.Search(r.Context(), q))
+
+	case id == "" && r.Method == http.MethodGet:
+		params := r.URL.Query()
+		json.NewEncoder(w).Encode(h.examples.List(params.Get("purpose"), params["tag"]))
+
+	case id == "" && r.Method == http.MethodPost:
+		var ex ii.Example
+		if err := json.NewDecoder(r.Body).Decode(&ex); err != nil {
+			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+		added, err := h.examples.Add(ex)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(added)
+
+	case id != "" && r.Method == http.MethodGet:
+		ex, ok := h.examples.Get(id)
+		if !ok {
+			http.Error(w, "Example not found: "+id, http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(ex)
+
+	case id != "" && r.Method == http.MethodDelete:
+		if _, ok := h.examples.Get(id); !ok {
+			http.Error(w, "Example not found: "+id, http.StatusNotFound)
+			return
+		}
+		removed, err := h.examples.Remove(id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(removed)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// requestExamples retrieves the library examples relevant to the ideas of a
+// unified request and their IDs
+func (h *Handlers) requestExamples(r *http.Request, req UnifiedRequest) ([]ii.Example, []string) {
+	if h.examples == nil || req.MaxExamples < 0 {
+		return nil, nil
+	}
+	found := h.examples.Retrieve(r.Context(), ii.ExampleQuery{
+		Text:    strings.Join(append(append([]string{}, req.Ideas...), req.Purpose), "\n"),
+		Purpose: req.PurposeType,
+		K:       req.MaxExamples,
+	})
+	ids := make([]string, len(found))
+	for i, ex := range found {
+		ids[i] = ex.ID
+	}
+	return found, ids
+}
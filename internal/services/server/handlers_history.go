@@ -0,0 +1,114 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubex-ecosystem/grompt/internal/history"
+	ii "github.com/kubex-ecosystem/grompt/internal/interfaces"
+)
+
+const defaultHistoryPageSize = 50
+
+// recordHistory stores a unified response. Demo responses are not real
+// provider output and are skipped.
+func (h *Handlers) recordHistory(prompt string, resp UnifiedResponse) {
+	if h.history == nil || resp.Mode == "demo" {
+		return
+	}
+	metadata := map[string]any{"mode": resp.Mode}
+	if resp.Cached {
+		metadata["cache"] = "hit"
+	}
+	if resp.Strategy != "" {
+		metadata["strategy"] = resp.Strategy
+	}
+	if len(resp.Examples) > 0 {
+		metadata["examples"] = resp.Examples
+	}
+	h.history.Add(ii.Result{
+		ID:        fmt.Sprintf("prompt_%d", time.Now().UnixNano()),
+		Prompt:    prompt,
+		Response:  resp.Response,
+		Provider:  resp.Provider,
+		Model:     resp.Model,
+		Metadata:  metadata,
+		Timestamp: time.Now(),
+	})
+}
+
+// HandleHistory serves the prompt history.
+//
+//	GET /api/v1/history?provider=&model=&tag=&q=&since=&until=&offset=&limit=
+//	GET /api/v1/history/{id}
+//
+// since/until accept RFC 3339 timestamps or YYYY-MM-DD dates. Entries are
+// returned newest first as an interfaces.HistoryPage.
+func (h *Handlers) HandleHistory(w http.ResponseWriter, r *http.Request) {
+	h.setCORSHeaders(w)
+
+	if r.Method == "OPTIONS" {
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.history == nil {
+		http.Error(w, "History not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/history"), "/"); id != "" {
+		entry, ok := h.history.Get(id)
+		if !ok {
+			http.Error(w, "History entry not found: "+id, http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(entry)
+		return
+	}
+
+	query, err := parseHistoryQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	json.NewEncoder(w).Encode(h.history.Query(query))
+}
+
+func parseHistoryQuery(r *http.Request) (ii.HistoryQuery, error) {
+	params := r.URL.Query()
+	q := ii.HistoryQuery{
+		Provider: params.Get("provider"),
+		Model:    params.Get("model"),
+		Tag:      params.Get("tag"),
+		Text:     params.Get("q"),
+		Limit:    defaultHistoryPageSize,
+	}
+
+	var err error
+	if q.Since, err = history.ParseTime(params.Get("since"), false); err != nil {
+		return q, fmt.Errorf("invalid since: %w", err)
+	}
+	if q.Until, err = history.ParseTime(params.Get("until"), true); err != nil {
+		return q, fmt.Errorf("invalid until: %w", err)
+	}
+	if v := params.Get("offset"); v != "" {
+		if q.Offset, err = strconv.Atoi(v); err != nil || q.Offset < 0 {
+			return q, fmt.Errorf("invalid offset: %q", v)
+		}
+	}
+	if v := params.Get("limit"); v != "" {
+		if q.Limit, err = strconv.Atoi(v); err != nil || q.Limit < 0 {
+			return q, fmt.Errorf("invalid limit: %q", v)
+		}
+	}
+	return q, nil
+}
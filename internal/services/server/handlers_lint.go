@@ -0,0 +1,43 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/kubex-ecosystem/grompt/internal/lint"
+)
+
+// LintRequest asks for a static analysis of a prompt. No provider is called.
+//
+//	{"prompt": "Summarise {{.doc}}", "vars": {"doc": "..."}, "disable": ["GP003"]}
+type LintRequest struct {
+	Prompt string `json:"prompt"`
+	lint.Options
+}
+
+// HandleLint runs the prompt linter and returns its report
+func (h *Handlers) HandleLint(w http.ResponseWriter, r *http.Request) {
+	h.setCORSHeaders(w)
+
+	if r.Method == "OPTIONS" {
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req LintRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.Prompt) == "" {
+		http.Error(w, "'prompt' is required", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lint.Lint(req.Prompt, req.Options))
+}
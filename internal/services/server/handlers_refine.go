@@ -0,0 +1,84 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/kubex-ecosystem/grompt/internal/engine"
+	"github.com/kubex-ecosystem/grompt/internal/lint"
+)
+
+// handleRefine answers a unified request with a refine option: the prompt
+// engineered from the ideas is regenerated by the engine until the critic's
+// rubric score crosses the threshold. It runs on the server's providers, so
+// BYOK keys are not used.
+//
+//	{"ideas": ["sql", "indexes"], "provider": "openai",
+//	 "refine": {"critic": "claude", "threshold": 0.85, "max_iterations": 4}}
+func (h *Handlers) handleRefine(w http.ResponseWriter, r *http.Request, req UnifiedRequest, prompt string) {
+	if h.engine == nil {
+		http.Error(w, "Engine not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	opts := *req.Refine
+	if opts.Provider == "" {
+		opts.Provider = req.Provider
+	}
+	if opts.Model == "" {
+		opts.Model = req.Model
+	}
+	if opts.MaxTokens <= 0 {
+		opts.MaxTokens = req.MaxTokens
+	}
+	if opts.Threshold < 0 || opts.Threshold > 1 {
+		http.Error(w, "refine threshold must be between 0 and 1", http.StatusBadRequest)
+		return
+	}
+	if opts.MaxIterations < 0 || opts.MaxIterations > engine.MaxRefineIterations {
+		http.Error(w, fmt.Sprintf("refine max_iterations must be between 0 and %d", engine.MaxRefineIterations), http.StatusBadRequest)
+		return
+	}
+	if opts.Provider != "" && h.engine.Resolve(opts.Provider) == nil {
+		http.Error(w, "Unsupported provider: "+opts.Provider, http.StatusBadRequest)
+		return
+	}
+	if opts.Critic != "" && h.engine.Resolve(opts.Critic) == nil {
+		http.Error(w, "Unsupported critic provider: "+opts.Critic, http.StatusBadRequest)
+		return
+	}
+
+	ref, err := h.engine.Refine(r.Context(), prompt, opts)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error refining prompt: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	best := ref.Iterations[ref.Best-1]
+	result := UnifiedResponse{
+		Response:   ref.Prompt,
+		Provider:   opts.Provider,
+		Model:      best.Usage.Model,
+		Mode:       "server",
+		Refinement: ref,
+	}
+	if u := ref.Usage; u != nil {
+		result.Usage = &UsageInfo{
+			PromptTokens:     u.Prompt,
+			CompletionTokens: u.Completion,
+			TotalTokens:      u.Tokens,
+			EstimatedCost:    u.CostUSD,
+		}
+	}
+	if best.Usage.Provider != "" {
+		result.Provider = best.Usage.Provider
+	}
+	if req.Lint {
+		report := lint.Lint(ref.Prompt, lint.Options{})
+		result.Lint = &report
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
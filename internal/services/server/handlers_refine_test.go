@@ -0,0 +1,29 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kubex-ecosystem/grompt/internal/engine/enginetest"
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"github.com/kubex-ecosystem/grompt/internal/providertest"
+)
+
+func TestRefineRejectsIterationsOutOfRange(t *testing.T) {
+	writer := &providertest.Provider{ProviderName: "writer", Response: "draft"}
+	h := &Handlers{engine: enginetest.New(t, writer)}
+
+	for _, n := range []int{-1, 1000} {
+		rec := httptest.NewRecorder()
+		req := UnifiedRequest{Ideas: []string{"sql"}, Refine: &interfaces.RefineOptions{MaxIterations: n}}
+		h.handleRefine(rec, httptest.NewRequest(http.MethodPost, "/api/v1/unified", nil), req, "Write a prompt about sql")
+
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("max_iterations %d: expected 400, got %d %s", n, rec.Code, rec.Body.String())
+		}
+	}
+	if writer.Calls != 0 {
+		t.Fatalf("rejected requests must not reach the provider, got %d calls", writer.Calls)
+	}
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kubex-ecosystem/grompt/internal/rag"
+	"github.com/kubex-ecosystem/grompt/internal/services/lookatni"
+)
+
+// reposRootEnv names the directory ask requests may read repositories from.
+// Unset, requests must send the lookatni extraction of their repository.
+const reposRootEnv = "GROMPT_ASK_REPOS_ROOT"
+
+// repoPrompt assembles the prompt answering question from a repository: the
+// lookatni extraction sent in project, or the one of repo, a directory or
+// extraction file under the repositories root. Failures are answered on w and
+// return ok=false.
+func repoPrompt(ctx context.Context, w http.ResponseWriter, repo string, project *lookatni.ExtractedProject, question string, opts rag.Options) (*rag.Prompt, bool) {
+	if project == nil {
+		path, err := resolveRepo(repo)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return nil, false
+		}
+		if project, err = rag.Load(ctx, path); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to extract %s: %v", repo, err), http.StatusUnprocessableEntity)
+			return nil, false
+		}
+	}
+
+	p, err := rag.NewIndex(project).Prompt(ctx, question, opts)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return nil, false
+	}
+	return p, true
+}
+
+// resolveRepo maps repo to a path within the repositories root, refusing
+// URLs, paths that lead out of it and every path when no root is set
+func resolveRepo(repo string) (string, error) {
+	if strings.Contains(repo, "://") || strings.HasPrefix(repo, "git@") {
+		return "", fmt.Errorf("'repo' must be a path under %s; send remote repositories as a lookatni 'project'", reposRootEnv)
+	}
+	root := os.Getenv(reposRootEnv)
+	if root == "" {
+		return "", fmt.Errorf("'repo' is not accepted unless %s is set; send the repository as a lookatni 'project'", reposRootEnv)
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+	path := repo
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	path = filepath.Clean(path)
+	// Compare real paths so a symlink cannot lead out of the root
+	if real, err := filepath.EvalSymlinks(root); err == nil {
+		root = real
+	}
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		path = real
+	}
+	if rel, err := filepath.Rel(root, path); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("'repo' %s is outside %s", repo, root)
+	}
+	return path, nil
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAskRefusesRepoWithoutRoot(t *testing.T) {
+	t.Setenv(reposRootEnv, "")
+
+	body := `{"question": "What does main do?", "provider": "ollama", "repo": "."}`
+	rec := httptest.NewRecorder()
+	(&Handlers{}).HandleAsk(rec, httptest.NewRequest(http.MethodPost, "/api/v1/ask", strings.NewReader(body)))
+
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), reposRootEnv) {
+		t.Fatalf("expected repo to be refused without %s, got %d %s", reposRootEnv, rec.Code, rec.Body.String())
+	}
+}
+
+func TestResolveRepoStaysUnderRoot(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "billing"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(reposRootEnv, root)
+
+	if path, err := resolveRepo("billing"); err != nil || filepath.Base(path) != "billing" {
+		t.Fatalf("expected billing under the root, got %q %v", path, err)
+	}
+	for _, repo := range []string{"..", "/etc", "https://github.com/acme/billing"} {
+		if _, err := resolveRepo(repo); err == nil {
+			t.Fatalf("expected %q to be refused", repo)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	ii "github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"github.com/kubex-ecosystem/grompt/internal/lint"
+	"github.com/kubex-ecosystem/grompt/internal/strategy"
+	it "github.com/kubex-ecosystem/grompt/internal/types"
+)
+
+// apiFor returns the API of a unified request's provider with its default
+// model. A BYOK key gets an API of its own.
+func (h *Handlers) apiFor(provider, key string, byok bool) (ii.IAPIConfig, string) {
+	switch provider {
+	case "claude":
+		if byok {
+			return it.NewClaudeAPI(key), "claude-3-5-sonnet-20241022"
+		}
+		return h.claudeAPI, "claude-3-5-sonnet-20241022"
+	case "openai":
+		if byok {
+			return it.NewOpenAIAPI(key), "gpt-4o-mini"
+		}
+		return h.openaiAPI, "gpt-4o-mini"
+	case "deepseek":
+		if byok {
+			return it.NewDeepSeekAPI(key), "deepseek-chat"
+		}
+		return h.deepseekAPI, "deepseek-chat"
+	case "gemini":
+		if byok {
+			return it.NewGeminiAPI(key), "gemini-2.0-flash-exp"
+		}
+		return h.geminiAPI, "gemini-2.0-flash-exp"
+	case "chatgpt":
+		if byok {
+			return it.NewChatGPTAPI(key), "gpt-4o-mini"
+		}
+		return h.chatGPTAPI, "gpt-4o-mini"
+	case "ollama":
+		return h.ollamaAPI, "llama3.2"
+	}
+	return nil, ""
+}
+
+// handleStrategy answers a unified ideas request with a strategy that makes
+// its own model calls, on the request's provider and key
+func (h *Handlers) handleStrategy(w http.ResponseWriter, r *http.Request, req UnifiedRequest, s ii.Strategy, key, mode string) {
+	api, model := h.apiFor(req.Provider, key, mode == "byok")
+	if api == nil {
+		http.Error(w, "Unsupported provider: "+req.Provider, http.StatusBadRequest)
+		return
+	}
+	if req.Model != "" {
+		model = req.Model
+	}
+
+	demos, _ := h.requestExamples(r, req)
+	gen, err := strategy.Run(r.Context(), s, ii.GenerationRequest{
+		Ideas:       req.Ideas,
+		Purpose:     req.Purpose,
+		PurposeType: req.PurposeType,
+		Lang:        req.Lang,
+		MaxLength:   req.MaxTokens,
+		Examples:    demos,
+	}, func(ctx context.Context, prompt string) (string, error) {
+		return api.Complete(prompt, req.MaxTokens, model)
+	})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error in %s API: %v", req.Provider, err), http.StatusInternalServerError)
+		return
+	}
+
+	result := UnifiedResponse{
+		Response: gen.Prompt,
+		Provider: req.Provider,
+		Model:    model,
+		Mode:     mode,
+		Usage:    estimatedUsage(req.Provider, model, gen.Request, gen.Prompt),
+		Strategy: gen.Strategy,
+		Examples: gen.Examples,
+	}
+	if req.Lint {
+		report := lint.Lint(gen.Prompt, lint.Options{})
+		result.Lint = &report
+	}
+	h.recordHistory(gen.Request, result)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
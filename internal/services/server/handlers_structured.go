@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+
+	ii "github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"github.com/kubex-ecosystem/grompt/internal/structured"
+)
+
+// completion runs the provider call of a unified request, in JSON output
+// mode when the request carries a schema.
+type completion struct {
+	schema  map[string]any
+	retries int
+	doc     *structured.Result
+}
+
+// newCompletion reads the JSON output settings of a request; a nil schema
+// leaves completions as plain text.
+func newCompletion(schema json.RawMessage, retries int) (*completion, error) {
+	s, err := structured.SchemaFrom(schema)
+	if err != nil {
+		return nil, err
+	}
+	if retries <= 0 {
+		retries = structured.DefaultRetries
+	}
+	return &completion{schema: s, retries: retries}, nil
+}
+
+// run completes prompt on api. In JSON mode the API's native JSON mode is
+// used when it has one, and the reply is validated and repaired; the JSON
+// text is returned and the parsed document kept in c.doc.
+func (c *completion) run(ctx context.Context, api ii.IAPIConfig, prompt string, maxTokens int, model string) (string, error) {
+	if c.schema == nil {
+		return api.Complete(prompt, maxTokens, model)
+	}
+	doc, err := structured.Generate(ctx, func(ctx context.Context, messages []ii.Message) (string, error) {
+		if native, ok := api.(ii.JSONCompleter); ok {
+			return native.CompleteJSON(structured.Flatten(messages), maxTokens, model, c.schema)
+		}
+		return api.Complete(structured.Flatten(messages), maxTokens, model)
+	}, []ii.Message{{Role: "user", Content: prompt}}, c.schema, c.retries)
+	if err != nil {
+		return "", err
+	}
+	c.doc = doc
+	return doc.Raw, nil
+}
+
+// parse fills c.doc from a response that did not go through run, such as a
+// cached one
+func (c *completion) parse(response string) {
+	if c.schema == nil || c.doc != nil {
+		return
+	}
+	if data, raw, err := structured.Parse(response, c.schema); err == nil {
+		c.doc = &structured.Result{Data: data, Raw: raw}
+	}
+}
@@ -0,0 +1,37 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/kubex-ecosystem/grompt/internal/tokenizer"
+)
+
+// preflight checks a prompt against the context window of provider/model
+// before it is sent. It returns the prompt to send, trimmed when policy is
+// "trim", and maxTokens lowered to the room left in the window. When the
+// prompt cannot fit it answers 413 and returns ok=false.
+func (h *Handlers) preflight(w http.ResponseWriter, provider, model, prompt string, maxTokens int, policy string) (string, int, bool) {
+	sent, maxTokens, _, err := tokenizer.Fit(provider, model, prompt, maxTokens, tokenizer.ParsePolicy(policy))
+	if err != nil {
+		var ce *tokenizer.ContextError
+		if errors.As(err, &ce) {
+			http.Error(w, err.Error()+" (set context_policy to \"trim\" to shorten it)", http.StatusRequestEntityTooLarge)
+		} else {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+		return "", 0, false
+	}
+	return sent, maxTokens, true
+}
+
+// estimatedUsage fills UsageInfo for providers that do not report usage
+func estimatedUsage(provider, model, prompt, response string) *UsageInfo {
+	u := tokenizer.Usage(provider, model, prompt, response)
+	return &UsageInfo{
+		PromptTokens:     u.Prompt,
+		CompletionTokens: u.Completion,
+		TotalTokens:      u.Tokens,
+		Estimated:        true,
+	}
+}
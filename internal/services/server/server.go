@@ -71,6 +71,11 @@ func (s *Server) Start() error {
 	gl.Log("info","   • /api/v1/models - Available Models\n")
 	gl.Log("info","   • /api/v1/test - API Test\n")
 	gl.Log("info","   • /api/v1/unified - Unified API\n")
+	gl.Log("info","   • /api/v1/compare - Multi-provider Comparison\n")
+	gl.Log("info","   • /api/v1/lint - Prompt Linter\n")
+	gl.Log("info","   • /api/v1/history - Prompt History\n")
+	gl.Log("info","   • /api/v1/examples - Few-shot Example Library\n")
+	gl.Log("info","   • /api/v1/cache - Response Cache\n")
 	gl.Log("info","   • /api/v1/openai - OpenAI API\n")
 	gl.Log("info","   • /api/v1/deepseek - DeepSeek API\n")
 	gl.Log("info","   • /api/v1/claude - Claude API\n")
@@ -110,7 +115,13 @@ func (s *Server) setupRoutes() {
 	// Rotas de API (organizadas por categoria) usando builder encadeável
 	// ------------------------------------------------------------------
 	// 1) Núcleo / Saúde / Configuração
-	s.router.HandleFunc("/api/v1/", func(w http.ResponseWriter, r *http.Request) { http.NotFound(w, r) })
+	// The API routes below are registered on the gin engine, so it serves the
+	// whole /api/v1/ tree (and answers 404 for anything it does not know).
+	if api, ok := s.IRouter.(http.Handler); ok {
+		s.router.Handle("/api/v1/", api)
+	} else {
+		s.router.HandleFunc("/api/v1/", func(w http.ResponseWriter, r *http.Request) { http.NotFound(w, r) })
+	}
 	s.GET("/api/v1/health", getGinHandlerFunc(func(w http.ResponseWriter, r *http.Request) { s.handlers.HandleHealth(w, r) }))
 	s.GET("/api/v1/config", getGinHandlerFunc(func(w http.ResponseWriter, r *http.Request) { s.handlers.HandleConfig(w, r) }))
 	s.POST("/api/v1/config", getGinHandlerFunc(func(w http.ResponseWriter, r *http.Request) { s.handlers.HandleConfig(w, r) }))
@@ -129,6 +140,23 @@ func (s *Server) setupRoutes() {
 	s.POST("/api/v1/unified", getGinHandlerFunc(func(w http.ResponseWriter, r *http.Request) { s.handlers.HandleUnified(w, r) }))
 	s.POST("/api/v1/ask", getGinHandlerFunc(func(w http.ResponseWriter, r *http.Request) { s.handlers.HandleAsk(w, r) }))
 	s.POST("/api/v1/squad", getGinHandlerFunc(func(w http.ResponseWriter, r *http.Request) { s.handlers.HandleSquad(w, r) }))
+	s.POST("/api/v1/compare", getGinHandlerFunc(func(w http.ResponseWriter, r *http.Request) { s.handlers.HandleCompare(w, r) }))
+	s.POST("/api/v1/lint", getGinHandlerFunc(func(w http.ResponseWriter, r *http.Request) { s.handlers.HandleLint(w, r) }))
+
+	// 3.1) Histórico
+	s.GET("/api/v1/history", getGinHandlerFunc(func(w http.ResponseWriter, r *http.Request) { s.handlers.HandleHistory(w, r) }))
+	s.GET("/api/v1/history/:id", getGinHandlerFunc(func(w http.ResponseWriter, r *http.Request) { s.handlers.HandleHistory(w, r) }))
+
+	// 3.1.1) Biblioteca de exemplos few-shot
+	s.GET("/api/v1/examples", getGinHandlerFunc(func(w http.ResponseWriter, r *http.Request) { s.handlers.HandleExamples(w, r) }))
+	s.POST("/api/v1/examples", getGinHandlerFunc(func(w http.ResponseWriter, r *http.Request) { s.handlers.HandleExamples(w, r) }))
+	s.POST("/api/v1/examples/search", getGinHandlerFunc(func(w http.ResponseWriter, r *http.Request) { s.handlers.HandleExamples(w, r) }))
+	s.GET("/api/v1/examples/:id", getGinHandlerFunc(func(w http.ResponseWriter, r *http.Request) { s.handlers.HandleExamples(w, r) }))
+	s.DELETE("/api/v1/examples/:id", getGinHandlerFunc(func(w http.ResponseWriter, r *http.Request) { s.handlers.HandleExamples(w, r) }))
+
+	// 3.2) Cache de respostas
+	s.GET("/api/v1/cache", getGinHandlerFunc(func(w http.ResponseWriter, r *http.Request) { s.handlers.HandleCache(w, r) }))
+	s.DELETE("/api/v1/cache", getGinHandlerFunc(func(w http.ResponseWriter, r *http.Request) { s.handlers.HandleCache(w, r) }))
 
 	// 4) Agentes / Squad
 	// s.GET("/api/v1/agents", getGinHandlerFunc(s.handlers.HandleAgents))
@@ -295,6 +323,13 @@ cd ..</code></pre>
 	s.router.HandleFunc("/api/v1/gemini", s.handlers.HandleGemini)
 	s.router.HandleFunc("/api/v1/deepseek", s.handlers.HandleDeepSeek)
 	s.router.HandleFunc("/api/v1/unified", s.handlers.HandleUnified)
+	s.router.HandleFunc("/api/v1/compare", s.handlers.HandleCompare)
+	s.router.HandleFunc("/api/v1/lint", s.handlers.HandleLint)
+	s.router.HandleFunc("/api/v1/history", s.handlers.HandleHistory)
+	s.router.HandleFunc("/api/v1/history/", s.handlers.HandleHistory)
+	s.router.HandleFunc("/api/v1/examples", s.handlers.HandleExamples)
+	s.router.HandleFunc("/api/v1/examples/", s.handlers.HandleExamples)
+	s.router.HandleFunc("/api/v1/cache", s.handlers.HandleCache)
 	// s.router.HandleFunc("/api/v1/agents", s.handlers.HandleAgents)
 	// s.router.HandleFunc("/api/v1/agents/generate", s.handlers.HandleAgentsGenerate)
 	// s.router.HandleFunc("/api/v1/agents/", s.handlers.HandleAgent)
@@ -0,0 +1,340 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"github.com/kubex-ecosystem/grompt/internal/metaprompt"
+	"github.com/kubex-ecosystem/grompt/internal/structured"
+)
+
+// Names of the built-in strategies
+const (
+	ChainOfThought  = "chain_of_thought"
+	FewShot         = "few_shot"
+	RoleConstraints = "role_constraints"
+	Agent           = "agent"
+)
+
+func init() {
+	Register(meta{}, metaprompt.PurposeGeneral, metaprompt.PurposeCreative, metaprompt.PurposeTutorial)
+	Register(chainOfThought{}, metaprompt.PurposeDataAnalysis, "reasoning", "math")
+	Register(fewShot{examples: 3}, "classification", "extraction", "few_shot")
+	Register(roleConstraints{}, metaprompt.PurposeCode)
+	Register(agent{}, metaprompt.PurposeAgent)
+}
+
+// meta sends the catalog meta-prompt once and returns the reply as is
+type meta struct{}
+
+func (meta) Name() string { return Default }
+func (meta) Description() string {
+	return "One call with the meta-prompt of the language and purpose type"
+}
+
+func (meta) Generate(ctx context.Context, req interfaces.GenerationRequest, llm interfaces.LLM) (*interfaces.Generation, error) {
+	reply, err := llm(ctx, metaprompt.Build(req.Ideas, req.Purpose, req.PurposeType, req.Lang, req.MaxLength))
+	if err != nil {
+		return nil, err
+	}
+	return &interfaces.Generation{Prompt: reply}, nil
+}
+
+// chainOfThought drafts the prompt with the meta-prompt and appends a
+// step-by-step reasoning scaffold to it
+type chainOfThought struct{}
+
+func (chainOfThought) Name() string { return ChainOfThought }
+func (chainOfThought) Description() string {
+	return "Meta-prompt draft followed by a step-by-step reasoning scaffold"
+}
+
+func (chainOfThought) Generate(ctx context.Context, req interfaces.GenerationRequest, llm interfaces.LLM) (*interfaces.Generation, error) {
+	draft, err := meta{}.Generate(ctx, req, llm)
+	if err != nil {
+		return nil, err
+	}
+	h := headingsFor(req.Lang)
+	var b strings.Builder
+	b.WriteString(strings.TrimSpace(draft.Prompt))
+	b.WriteString("\n\n## " + h.reasoning + "\n\n")
+	for i, step := range h.reasoningSteps {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, step)
+	}
+	b.WriteString("\n" + h.reasoningAnswer + "\n")
+	return &interfaces.Generation{Prompt: b.String()}, nil
+}
+
+// fewShot drafts the prompt with the meta-prompt and appends demonstrations:
+// the library examples of the request, completed with generated worked
+// examples of the task
+type fewShot struct {
+	examples int
+}
+
+func (fewShot) Name() string { return FewShot }
+func (fewShot) Description() string {
+	return "Meta-prompt draft plus library or generated input/output demonstrations"
+}
+
+func (s fewShot) Generate(ctx context.Context, req interfaces.GenerationRequest, llm interfaces.LLM) (*interfaces.Generation, error) {
+	draft, err := meta{}.Generate(ctx, req, llm)
+	if err != nil {
+		return nil, err
+	}
+
+	demos := append([]interfaces.Example{}, req.Examples...)
+	if missing := s.examples - len(demos); missing > 0 {
+		schema := map[string]any{
+			"type":     "object",
+			"required": []any{"examples"},
+			"properties": map[string]any{
+				"examples": map[string]any{
+					"type":     "array",
+					"minItems": 1,
+					"items": map[string]any{
+						"type":       "object",
+						"required":   []any{"input", "output"},
+						"properties": map[string]any{"input": map[string]any{"type": "string"}, "output": map[string]any{"type": "string"}},
+					},
+				},
+			},
+		}
+		ask := fmt.Sprintf("Write %d short, diverse input/output examples that demonstrate the ideal behaviour for the prompt below. "+
+			"Cover typical and edge cases, and write them in %s.\n\n<prompt>\n%s\n</prompt>", missing, lang(req), draft.Prompt)
+		data, err := generateJSON(ctx, llm, ask, schema)
+		if err != nil {
+			return nil, fmt.Errorf("examples: %w", err)
+		}
+		for _, item := range list(data["examples"]) {
+			example, _ := item.(map[string]any)
+			demos = append(demos, interfaces.Example{Input: text(example["input"]), Output: text(example["output"])})
+		}
+	}
+
+	prompt := strings.TrimSpace(draft.Prompt) + "\n\n" + Demonstrations(req.Lang, demos)
+	return &interfaces.Generation{Prompt: prompt, Examples: exampleIDs(req.Examples)}, nil
+}
+
+// roleConstraints asks for the parts of a prompt as a JSON document and
+// assembles them into role, task, context, constraints and output sections
+type roleConstraints struct{}
+
+func (roleConstraints) Name() string { return RoleConstraints }
+func (roleConstraints) Description() string {
+	return "Role, task, context, explicit constraints and output format assembled from a structured design"
+}
+
+func (roleConstraints) Generate(ctx context.Context, req interfaces.GenerationRequest, llm interfaces.LLM) (*interfaces.Generation, error) {
+	schema := objectSchema(map[string]string{
+		"role": "string", "task": "string", "context": "string", "constraints": "array", "output_format": "string",
+	})
+	data, err := generateJSON(ctx, llm, designPrompt(req,
+		`"role": the persona the model takes, with its expertise; "task": what exactly it must do; `+
+			`"context": background it needs; "constraints": testable rules, one per item; `+
+			`"output_format": the structure of the expected answer`), schema)
+	if err != nil {
+		return nil, err
+	}
+
+	h := headingsFor(req.Lang)
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n## %s\n\n%s\n", text(data["role"]), h.task, text(data["task"]))
+	if c := text(data["context"]); c != "" {
+		fmt.Fprintf(&b, "\n## %s\n\n%s\n", h.context, c)
+	}
+	writeList(&b, h.constraints, data["constraints"])
+	fmt.Fprintf(&b, "\n## %s\n\n%s\n", h.outputFormat, text(data["output_format"]))
+	return &interfaces.Generation{Prompt: b.String()}, nil
+}
+
+// agent designs an agent system prompt as identity, capabilities, boundaries,
+// behaviour and style
+type agent struct{}
+
+func (agent) Name() string { return Agent }
+func (agent) Description() string {
+	return "Agent system prompt built from identity, capabilities, boundaries, behaviour and style"
+}
+
+func (agent) Generate(ctx context.Context, req interfaces.GenerationRequest, llm interfaces.LLM) (*interfaces.Generation, error) {
+	schema := objectSchema(map[string]string{
+		"identity": "string", "capabilities": "array", "boundaries": "array", "behaviour": "array", "style": "string",
+	})
+	data, err := generateJSON(ctx, llm, designPrompt(req,
+		`"identity": who the agent is and whom it serves, in the second person ("You are..."); `+
+			`"capabilities": what it can do and when to use its tools; "boundaries": what it must refuse or escalate; `+
+			`"behaviour": how it handles ambiguity, errors and multi-step tasks; "style": tone and formatting of its replies`), schema)
+	if err != nil {
+		return nil, err
+	}
+
+	h := headingsFor(req.Lang)
+	var b strings.Builder
+	b.WriteString(text(data["identity"]) + "\n")
+	writeList(&b, h.capabilities, data["capabilities"])
+	writeList(&b, h.boundaries, data["boundaries"])
+	writeList(&b, h.behaviour, data["behaviour"])
+	fmt.Fprintf(&b, "\n## %s\n\n%s\n", h.style, text(data["style"]))
+	return &interfaces.Generation{Prompt: b.String()}, nil
+}
+
+// designPrompt asks for the parts of a prompt for the request's ideas
+func designPrompt(req interfaces.GenerationRequest, fields string) string {
+	var b strings.Builder
+	b.WriteString("You are an expert prompt engineer. Design a prompt from the raw ideas below.\n\nIdeas:\n")
+	for i, idea := range req.Ideas {
+		fmt.Fprintf(&b, "%d. %q\n", i+1, idea)
+	}
+	purpose := req.PurposeType
+	if req.Purpose != "" {
+		purpose += " (" + req.Purpose + ")"
+	}
+	if purpose != "" {
+		b.WriteString("\nPurpose: " + purpose + "\n")
+	}
+	maxLength := req.MaxLength
+	if maxLength <= 0 {
+		maxLength = metaprompt.DefaultMaxLength
+	}
+	fmt.Fprintf(&b, "\nFields: %s.\nWrite every value in %s and keep the assembled prompt within %d characters. "+
+		"Cover every idea.", fields, lang(req), maxLength)
+	return b.String()
+}
+
+// generateJSON asks llm for a document valid against schema, repairing
+// invalid replies
+func generateJSON(ctx context.Context, llm interfaces.LLM, prompt string, schema map[string]any) (map[string]any, error) {
+	doc, err := structured.Generate(ctx, func(ctx context.Context, messages []interfaces.Message) (string, error) {
+		return llm(ctx, structured.Flatten(messages))
+	}, []interfaces.Message{{Role: "user", Content: prompt}}, schema, structured.DefaultRetries)
+	if err != nil {
+		return nil, err
+	}
+	data, _ := doc.Data.(map[string]any)
+	return data, nil
+}
+
+// objectSchema requires every field, with arrays of strings for "array"
+func objectSchema(fields map[string]string) map[string]any {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	properties := map[string]any{}
+	required := make([]any, 0, len(fields))
+	for _, name := range names {
+		if kind := fields[name]; kind == "array" {
+			properties[name] = map[string]any{"type": "array", "items": map[string]any{"type": "string"}}
+		} else {
+			properties[name] = map[string]any{"type": fields[name]}
+		}
+		required = append(required, name)
+	}
+	return map[string]any{"type": "object", "required": required, "properties": properties}
+}
+
+// Demonstrations renders examples as a few-shot section of a prompt written
+// in lang
+func Demonstrations(lang string, examples []interfaces.Example) string {
+	h := headingsFor(lang)
+	var b strings.Builder
+	b.WriteString("## " + h.examples + "\n")
+	for i, ex := range examples {
+		fmt.Fprintf(&b, "\n### %s %d\n\n**%s:**\n%s\n\n**%s:**\n%s\n", h.example, i+1, h.input, strings.TrimSpace(ex.Input), h.output, strings.TrimSpace(ex.Output))
+	}
+	return b.String()
+}
+
+// exampleIDs lists the IDs of library examples. The list is never nil, so a
+// strategy returning it marks the examples as used.
+func exampleIDs(examples []interfaces.Example) []string {
+	ids := []string{}
+	for _, ex := range examples {
+		if ex.ID != "" {
+			ids = append(ids, ex.ID)
+		}
+	}
+	return ids
+}
+
+func writeList(b *strings.Builder, heading string, items any) {
+	values := list(items)
+	if len(values) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n## %s\n\n", heading)
+	for _, item := range values {
+		if s := strings.TrimSpace(text(item)); s != "" {
+			b.WriteString("- " + s + "\n")
+		}
+	}
+}
+
+func list(v any) []any {
+	items, _ := v.([]any)
+	return items
+}
+
+func text(v any) string {
+	s, _ := v.(string)
+	return strings.TrimSpace(s)
+}
+
+func lang(req interfaces.GenerationRequest) string {
+	if req.Lang == "" {
+		return metaprompt.DefaultLang
+	}
+	return req.Lang
+}
+
+// headings are the section titles strategies add to a prompt, in the
+// language the prompt is written in
+type headings struct {
+	reasoning, reasoningAnswer                 string
+	reasoningSteps                             []string
+	examples, example, input, output           string
+	task, context, constraints, outputFormat   string
+	capabilities, boundaries, behaviour, style string
+}
+
+var catalogHeadings = map[string]headings{
+	"en": {
+		reasoning: "Reasoning",
+		reasoningSteps: []string{
+			"Restate the question and list the facts and data you are given.",
+			"Identify the assumptions you need and state them explicitly.",
+			"Work through the problem step by step, showing intermediate results.",
+			"Check the result against the data and the constraints above.",
+		},
+		reasoningAnswer: "Then give the final answer under a heading **Answer**, separate from the reasoning.",
+		examples:        "Examples", example: "Example", input: "Input", output: "Output",
+		task: "Task", context: "Context", constraints: "Constraints", outputFormat: "Output format",
+		capabilities: "Capabilities", boundaries: "Boundaries", behaviour: "Behaviour", style: "Style",
+	},
+	"pt": {
+		reasoning: "Raciocínio",
+		reasoningSteps: []string{
+			"Reformule a pergunta e liste os fatos e dados fornecidos.",
+			"Identifique as premissas necessárias e declare-as explicitamente.",
+			"Resolva o problema passo a passo, mostrando os resultados intermediários.",
+			"Confira o resultado com os dados e as restrições acima.",
+		},
+		reasoningAnswer: "Depois, dê a resposta final sob o título **Resposta**, separada do raciocínio.",
+		examples:        "Exemplos", example: "Exemplo", input: "Entrada", output: "Saída",
+		task: "Tarefa", context: "Contexto", constraints: "Restrições", outputFormat: "Formato de saída",
+		capabilities: "Capacidades", boundaries: "Limites", behaviour: "Comportamento", style: "Estilo",
+	},
+}
+
+func headingsFor(lang string) headings {
+	if h, ok := catalogHeadings[metaprompt.Language(lang)]; ok {
+		return h
+	}
+	return catalogHeadings[metaprompt.FallbackLang]
+}
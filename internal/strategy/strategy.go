@@ -0,0 +1,136 @@
+// Package strategy keeps the registry of prompt engineering strategies. A
+// strategy turns raw ideas into a prompt; it is selected by name or by the
+// purpose type of the request, and third parties can register their own.
+package strategy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"github.com/kubex-ecosystem/grompt/internal/metaprompt"
+)
+
+// Default is the strategy used for purpose types without one of their own
+const Default = "meta"
+
+// ErrUnknown is returned when a strategy is requested by an unknown name
+var ErrUnknown = errors.New("unknown strategy")
+
+// Info describes a registered strategy
+type Info struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Purposes    []string `json:"purposes,omitempty"`
+}
+
+var (
+	mu        sync.RWMutex
+	byName    = map[string]interfaces.Strategy{}
+	byPurpose = map[string]string{}
+)
+
+// Register adds s, replacing any strategy of the same name, and makes it the
+// strategy of the given purpose types. Purpose types are normalized like
+// metaprompt.Purpose, so "Data Analysis" and "data_analysis" are the same.
+func Register(s interfaces.Strategy, purposes ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+	byName[s.Name()] = s
+	for _, p := range purposes {
+		byPurpose[metaprompt.Purpose(p)] = s.Name()
+	}
+}
+
+// Lookup returns the strategy registered under name
+func Lookup(name string) (interfaces.Strategy, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	s, ok := byName[name]
+	return s, ok
+}
+
+// For returns the strategy of a purpose type, or the Default one
+func For(purposeType string) interfaces.Strategy {
+	mu.RLock()
+	defer mu.RUnlock()
+	if name, ok := byPurpose[metaprompt.Purpose(purposeType)]; ok {
+		if s, ok := byName[name]; ok {
+			return s
+		}
+	}
+	return byName[Default]
+}
+
+// Select returns the strategy named name, or the one of purposeType when name
+// is empty
+func Select(name, purposeType string) (interfaces.Strategy, error) {
+	if name = strings.TrimSpace(name); name == "" {
+		return For(purposeType), nil
+	}
+	if s, ok := Lookup(name); ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknown, name)
+}
+
+// List describes the registered strategies, sorted by name
+func List() []Info {
+	mu.RLock()
+	defer mu.RUnlock()
+	infos := make([]Info, 0, len(byName))
+	for name, s := range byName {
+		info := Info{Name: name, Description: s.Description()}
+		for purpose, owner := range byPurpose {
+			if owner == name {
+				info.Purposes = append(info.Purposes, purpose)
+			}
+		}
+		sort.Strings(info.Purposes)
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// Run executes s with llm, counting its calls and recording the first prompt
+// it sends. An empty result is an error. The examples of the request are
+// appended as demonstrations unless the strategy reports it used them.
+func Run(ctx context.Context, s interfaces.Strategy, req interfaces.GenerationRequest, llm interfaces.LLM) (*interfaces.Generation, error) {
+	var (
+		callsMu sync.Mutex
+		calls   int
+		first   string
+	)
+	counted := func(ctx context.Context, prompt string) (string, error) {
+		callsMu.Lock()
+		if calls == 0 {
+			first = prompt
+		}
+		calls++
+		callsMu.Unlock()
+		return llm(ctx, prompt)
+	}
+
+	gen, err := s.Generate(ctx, req, counted)
+	if err != nil {
+		return nil, fmt.Errorf("strategy %s: %w", s.Name(), err)
+	}
+	if gen == nil || strings.TrimSpace(gen.Prompt) == "" {
+		return nil, fmt.Errorf("strategy %s produced an empty prompt", s.Name())
+	}
+	gen.Prompt = strings.TrimSpace(gen.Prompt)
+	if len(req.Examples) > 0 && gen.Examples == nil {
+		gen.Prompt += "\n\n" + strings.TrimSpace(Demonstrations(req.Lang, req.Examples))
+		gen.Examples = exampleIDs(req.Examples)
+	}
+	gen.Strategy, gen.Calls = s.Name(), calls
+	if gen.Request == "" {
+		gen.Request = first
+	}
+	return gen, nil
+}
@@ -0,0 +1,167 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+)
+
+// scripted answers each call with the next reply and keeps the prompts
+type scripted struct {
+	replies []string
+	prompts []string
+}
+
+func (s *scripted) llm(ctx context.Context, prompt string) (string, error) {
+	s.prompts = append(s.prompts, prompt)
+	if len(s.replies) == 0 {
+		return "", errors.New("no more replies")
+	}
+	reply := s.replies[0]
+	s.replies = s.replies[1:]
+	return reply, nil
+}
+
+func TestSelectByPurposeType(t *testing.T) {
+	for purpose, want := range map[string]string{
+		"Coding":         RoleConstraints,
+		"data analysis":  ChainOfThought,
+		"agent":          Agent,
+		"classification": FewShot,
+		"creative":       Default,
+		"unheard of":     Default,
+	} {
+		s, err := Select("", purpose)
+		if err != nil || s.Name() != want {
+			t.Errorf("Select(%q) = %v, %v; want %s", purpose, s, err, want)
+		}
+	}
+	if s, err := Select(FewShot, "code"); err != nil || s.Name() != FewShot {
+		t.Fatalf("expected an explicit name to win over the purpose type, got %v, %v", s, err)
+	}
+	if _, err := Select("nope", ""); !errors.Is(err, ErrUnknown) {
+		t.Fatalf("expected ErrUnknown, got %v", err)
+	}
+}
+
+type shout struct{}
+
+func (shout) Name() string        { return "shout" }
+func (shout) Description() string { return "upper-cases the draft" }
+func (shout) Generate(ctx context.Context, req interfaces.GenerationRequest, llm interfaces.LLM) (*interfaces.Generation, error) {
+	reply, err := llm(ctx, strings.Join(req.Ideas, " "))
+	if err != nil {
+		return nil, err
+	}
+	return &interfaces.Generation{Prompt: strings.ToUpper(reply)}, nil
+}
+
+func TestRegisterThirdPartyStrategy(t *testing.T) {
+	Register(shout{}, "Marketing Copy")
+	t.Cleanup(func() {
+		mu.Lock()
+		delete(byName, "shout")
+		delete(byPurpose, "marketing_copy")
+		mu.Unlock()
+	})
+
+	s := For("marketing-copy")
+	llm := &scripted{replies: []string{"buy now"}}
+	gen, err := Run(context.Background(), s, interfaces.GenerationRequest{Ideas: []string{"sale", "today"}}, llm.llm)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if gen.Prompt != "BUY NOW" || gen.Strategy != "shout" || gen.Calls != 1 || gen.Request != "sale today" {
+		t.Fatalf("unexpected generation %+v", gen)
+	}
+
+	var listed bool
+	for _, info := range List() {
+		listed = listed || (info.Name == "shout" && len(info.Purposes) == 1 && info.Purposes[0] == "marketing_copy")
+	}
+	if !listed {
+		t.Fatalf("expected the strategy to be listed with its purpose, got %+v", List())
+	}
+}
+
+func TestRoleConstraintsAssemblesDesign(t *testing.T) {
+	llm := &scripted{replies: []string{
+		"not json",
+		`{"role": "You are a senior Go engineer.", "task": "Add an index.", "context": "", "constraints": ["Keep migrations reversible", "Explain the plan"], "output_format": "A SQL migration."}`,
+	}}
+	s, _ := Lookup(RoleConstraints)
+	gen, err := Run(context.Background(), s, interfaces.GenerationRequest{Ideas: []string{"sql", "indexes"}, Lang: "english"}, llm.llm)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if gen.Calls != 2 {
+		t.Fatalf("expected the invalid design to be repaired, got %d calls", gen.Calls)
+	}
+	for _, want := range []string{"You are a senior Go engineer.", "## Task\n\nAdd an index.", "## Constraints\n\n- Keep migrations reversible\n- Explain the plan", "## Output format"} {
+		if !strings.Contains(gen.Prompt, want) {
+			t.Fatalf("expected %q in:\n%s", want, gen.Prompt)
+		}
+	}
+	if strings.Contains(gen.Prompt, "## Context") {
+		t.Fatalf("expected an empty context to be left out:\n%s", gen.Prompt)
+	}
+}
+
+func TestFewShotAndChainOfThought(t *testing.T) {
+	llm := &scripted{replies: []string{"Classify the ticket.", `{"examples": [{"input": "App crashes", "output": "bug"}]}`}}
+	s, _ := Lookup(FewShot)
+	gen, err := Run(context.Background(), s, interfaces.GenerationRequest{Ideas: []string{"tickets"}}, llm.llm)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if !strings.HasPrefix(gen.Prompt, "Classify the ticket.") || !strings.Contains(gen.Prompt, "### Example 1\n\n**Input:**\nApp crashes\n\n**Output:**\nbug") {
+		t.Fatalf("expected the draft followed by the examples, got:\n%s", gen.Prompt)
+	}
+	if !strings.Contains(llm.prompts[1], "Classify the ticket.") {
+		t.Fatalf("expected the examples to be asked for the draft, got %q", llm.prompts[1])
+	}
+
+	llm = &scripted{replies: []string{"Analise as vendas."}}
+	s, _ = Lookup(ChainOfThought)
+	gen, err = Run(context.Background(), s, interfaces.GenerationRequest{Ideas: []string{"vendas"}, Lang: "português"}, llm.llm)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if !strings.Contains(gen.Prompt, "## Raciocínio") || !strings.Contains(llm.prompts[0], "Você é um especialista") {
+		t.Fatalf("expected a Portuguese meta-prompt and scaffold, got:\n%s", gen.Prompt)
+	}
+}
+
+func TestRunInjectsLibraryExamples(t *testing.T) {
+	library := []interfaces.Example{
+		{ID: "ex_1", Input: "Refund request", Output: "billing"},
+		{ID: "ex_2", Input: "Login fails", Output: "bug"},
+	}
+
+	llm := &scripted{replies: []string{"Route the ticket."}}
+	s, _ := Lookup(Default)
+	gen, err := Run(context.Background(), s, interfaces.GenerationRequest{Ideas: []string{"tickets"}, Examples: library}, llm.llm)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if !strings.Contains(gen.Prompt, "Route the ticket.\n\n## Examples") || len(gen.Examples) != 2 {
+		t.Fatalf("expected the library examples after the prompt, got %v:\n%s", gen.Examples, gen.Prompt)
+	}
+
+	// few_shot completes the library examples with generated ones instead
+	llm = &scripted{replies: []string{"Classify the ticket.", `{"examples": [{"input": "App crashes", "output": "bug"}]}`}}
+	s, _ = Lookup(FewShot)
+	gen, err = Run(context.Background(), s, interfaces.GenerationRequest{Ideas: []string{"tickets"}, Examples: library}, llm.llm)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if strings.Count(gen.Prompt, "## Examples") != 1 || !strings.Contains(gen.Prompt, "### Example 3\n\n**Input:**\nApp crashes") {
+		t.Fatalf("expected one section with library and generated examples, got:\n%s", gen.Prompt)
+	}
+	if !strings.Contains(llm.prompts[1], "Write 1 short") {
+		t.Fatalf("expected only the missing example to be generated, got %q", llm.prompts[1])
+	}
+}
@@ -0,0 +1,193 @@
+// Package structured asks models for JSON documents that satisfy a JSON
+// Schema, re-prompting with the validation errors until they do.
+package structured
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"github.com/kubex-ecosystem/grompt/internal/jsonschema"
+)
+
+const (
+	// MetaSchema is the ChatRequest.Meta (or engine var) key holding the schema
+	MetaSchema = "json_schema"
+	// MetaRetries is the key holding how many repairs to attempt
+	MetaRetries = "json_retries"
+
+	// DefaultRetries is the number of repair prompts after the first reply
+	DefaultRetries = 2
+)
+
+// CompleteFunc sends a conversation to a model and returns its reply
+type CompleteFunc func(ctx context.Context, messages []interfaces.Message) (string, error)
+
+// Result is a reply that satisfied the schema
+type Result struct {
+	Data     any    `json:"data"`
+	Raw      string `json:"raw"` // the JSON text of Data, without fences or prose
+	Attempts int    `json:"attempts"`
+	// Rejected holds the validation errors of the attempts before the last
+	Rejected []string `json:"rejected,omitempty"`
+}
+
+// Error is returned when no attempt produced a valid document
+type Error struct {
+	Attempts  int    `json:"attempts"`
+	LastReply string `json:"last_reply"`
+	Cause     error  `json:"-"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("no valid JSON after %d attempts: %v", e.Attempts, e.Cause)
+}
+
+func (e *Error) Unwrap() error { return e.Cause }
+
+// Generate asks complete for a document matching schema. The schema is
+// appended to the conversation as an instruction; each invalid reply is
+// answered with its validation errors, up to retries times.
+func Generate(ctx context.Context, complete CompleteFunc, messages []interfaces.Message, schema map[string]any, retries int) (*Result, error) {
+	if retries < 0 {
+		retries = 0
+	}
+	conversation := append(append([]interfaces.Message{}, messages...), interfaces.Message{Role: "user", Content: Instruction(schema)})
+
+	var rejected []string
+	for attempt := 1; ; attempt++ {
+		reply, err := complete(ctx, conversation)
+		if err != nil {
+			return nil, err
+		}
+		data, raw, err := Parse(reply, schema)
+		if err == nil {
+			return &Result{Data: data, Raw: raw, Attempts: attempt, Rejected: rejected}, nil
+		}
+		if attempt > retries {
+			return nil, &Error{Attempts: attempt, LastReply: reply, Cause: err}
+		}
+		rejected = append(rejected, err.Error())
+		conversation = append(conversation,
+			interfaces.Message{Role: "assistant", Content: reply},
+			interfaces.Message{Role: "user", Content: repairPrompt(err)},
+		)
+	}
+}
+
+// Parse extracts the JSON document from a reply and validates it
+func Parse(reply string, schema map[string]any) (any, string, error) {
+	raw := jsonschema.Extract(reply)
+	var data any
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		return nil, raw, fmt.Errorf("reply is not valid JSON: %w", err)
+	}
+	if schema != nil {
+		if err := jsonschema.Validate(schema, data); err != nil {
+			return data, raw, err
+		}
+	}
+	return data, raw, nil
+}
+
+// Instruction tells the model to answer with a document matching schema
+func Instruction(schema map[string]any) string {
+	if schema == nil {
+		return "Respond with a single JSON document only, without Markdown fences or commentary."
+	}
+	text, _ := json.MarshalIndent(schema, "", "  ")
+	return "Respond with a single JSON document only, without Markdown fences or commentary. " +
+		"It must be valid against this JSON Schema:\n" + string(text)
+}
+
+func repairPrompt(err error) string {
+	var details string
+	var se *jsonschema.Error
+	if errors.As(err, &se) {
+		details = "- " + strings.Join(se.Violations, "\n- ")
+	} else {
+		details = "- " + err.Error()
+	}
+	return "Your reply was rejected:\n" + details +
+		"\nReply again with only the corrected JSON document."
+}
+
+// Flatten renders a conversation as a single prompt, for APIs that take one
+func Flatten(messages []interfaces.Message) string {
+	if len(messages) == 1 {
+		return messages[0].Text()
+	}
+	parts := make([]string, 0, len(messages))
+	for _, m := range messages {
+		switch m.Role {
+		case "assistant":
+			parts = append(parts, "Your previous reply:\n"+m.Text())
+		default:
+			parts = append(parts, m.Text())
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// SchemaFrom reads a schema given as a decoded object or JSON text. nil and
+// empty values return a nil schema. Schemas come from requests, so "@path"
+// references are refused rather than read from the server's disk.
+func SchemaFrom(v any) (map[string]any, error) {
+	var data []byte
+	switch s := v.(type) {
+	case nil:
+		return nil, nil
+	case map[string]any:
+		return s, nil
+	case json.RawMessage:
+		data = s
+	case []byte:
+		data = s
+	case string:
+		s = strings.TrimSpace(s)
+		if strings.HasPrefix(s, "@") {
+			return nil, errors.New("schema files are not accepted; send the JSON schema inline")
+		}
+		data = []byte(s)
+	default:
+		var err error
+		if data, err = json.Marshal(s); err != nil {
+			return nil, fmt.Errorf("unsupported schema: %w", err)
+		}
+	}
+	if len(strings.TrimSpace(string(data))) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+	return schema, nil
+}
+
+// FromMeta reads the schema and retries from ChatRequest.Meta or engine
+// vars. ok is false when no schema was given.
+func FromMeta(meta map[string]any) (schema map[string]any, retries int, ok bool, err error) {
+	if meta == nil || meta[MetaSchema] == nil {
+		return nil, 0, false, nil
+	}
+	if schema, err = SchemaFrom(meta[MetaSchema]); err != nil || schema == nil {
+		return nil, 0, false, err
+	}
+	retries = DefaultRetries
+	switch v := meta[MetaRetries].(type) {
+	case int:
+		retries = v
+	case float64:
+		retries = int(v)
+	case string:
+		if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+			retries = n
+		}
+	}
+	return schema, retries, true, nil
+}
@@ -0,0 +1,114 @@
+package structured
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+)
+
+var personSchema = map[string]any{
+	"type":     "object",
+	"required": []any{"name", "age"},
+	"properties": map[string]any{
+		"name": map[string]any{"type": "string"},
+		"age":  map[string]any{"type": "integer"},
+	},
+}
+
+// scripted answers with replies in order and records the conversations
+func scripted(replies ...string) (CompleteFunc, *[][]interfaces.Message) {
+	var seen [][]interfaces.Message
+	return func(ctx context.Context, messages []interfaces.Message) (string, error) {
+		seen = append(seen, messages)
+		reply := replies[0]
+		replies = replies[1:]
+		return reply, nil
+	}, &seen
+}
+
+func TestGenerateRepairsInvalidReplies(t *testing.T) {
+	complete, seen := scripted(
+		"Sure! Here it is: {\"name\": \"Ada\"}",
+		"```json\n{\"name\": \"Ada\", \"age\": 36}\n```",
+	)
+	doc, err := Generate(context.Background(), complete, []interfaces.Message{{Role: "user", Content: "Describe Ada"}}, personSchema, 2)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if doc.Attempts != 2 || len(doc.Rejected) != 1 || doc.Raw != `{"name": "Ada", "age": 36}` {
+		t.Fatalf("unexpected result: %+v", doc)
+	}
+	if doc.Data.(map[string]any)["age"] != float64(36) {
+		t.Fatalf("unexpected data: %v", doc.Data)
+	}
+
+	// The repair turn carries the rejected reply and the violation
+	repair := (*seen)[1]
+	if len(repair) != 4 || repair[2].Role != "assistant" || !strings.Contains(repair[3].Content, "age") {
+		t.Fatalf("unexpected repair conversation: %+v", repair)
+	}
+	if !strings.Contains((*seen)[0][1].Content, `"required"`) {
+		t.Fatalf("expected the schema in the instruction, got %q", (*seen)[0][1].Content)
+	}
+}
+
+func TestGenerateGivesUp(t *testing.T) {
+	complete, seen := scripted("not json", "still not json")
+	_, err := Generate(context.Background(), complete, nil, personSchema, 1)
+
+	var invalid *Error
+	if !errors.As(err, &invalid) || invalid.Attempts != 2 || invalid.LastReply != "still not json" {
+		t.Fatalf("expected an *Error after 2 attempts, got %v", err)
+	}
+	if len(*seen) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", len(*seen))
+	}
+}
+
+func TestSchemaFrom(t *testing.T) {
+	for name, v := range map[string]any{
+		"text":  `{"type": "array"}`,
+		"bytes": []byte(`{"type": "array"}`),
+		"map":   map[string]any{"type": "array"},
+	} {
+		schema, err := SchemaFrom(v)
+		if err != nil || schema["type"] != "array" {
+			t.Errorf("%s: got %v, %v", name, schema, err)
+		}
+	}
+	if schema, err := SchemaFrom(""); schema != nil || err != nil {
+		t.Errorf("expected no schema for empty text, got %v, %v", schema, err)
+	}
+	if _, err := SchemaFrom("{oops"); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+
+	path := filepath.Join(t.TempDir(), "schema.json")
+	if err := os.WriteFile(path, []byte(`{"type": "array"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if schema, err := SchemaFrom("@" + path); schema != nil || err == nil {
+		t.Errorf("expected schema files to be refused, got %v, %v", schema, err)
+	}
+	if _, _, ok, err := FromMeta(map[string]any{MetaSchema: "@/etc/hostname"}); ok || err == nil {
+		t.Errorf("expected FromMeta to refuse schema files, got ok=%v err=%v", ok, err)
+	}
+}
+
+func TestFromMeta(t *testing.T) {
+	if _, _, ok, err := FromMeta(map[string]any{"other": 1}); ok || err != nil {
+		t.Fatalf("expected plain mode without a schema, got ok=%v err=%v", ok, err)
+	}
+	schema, retries, ok, err := FromMeta(map[string]any{MetaSchema: `{"type": "object"}`, MetaRetries: float64(4)})
+	if err != nil || !ok || retries != 4 || schema["type"] != "object" {
+		t.Fatalf("unexpected settings: %v %d %v %v", schema, retries, ok, err)
+	}
+	if _, retries, _, _ := FromMeta(map[string]any{MetaSchema: personSchema}); retries != DefaultRetries {
+		t.Fatalf("expected default retries, got %d", retries)
+	}
+}
@@ -0,0 +1,350 @@
+// Package templates implements the filesystem-backed prompt template library.
+package templates
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrTemplateNotFound is returned when a named template does not exist in the library.
+var ErrTemplateNotFound = errors.New("template not found")
+
+// supportedExts lists the file extensions read from the library, in lookup order.
+var supportedExts = []string{".yaml", ".yml", ".json"}
+
+// FileManager stores templates as YAML or JSON files below a root directory.
+// Template names map to relative paths without extension, so "review/go"
+// is stored as <root>/review/go.yaml.
+type FileManager struct {
+	root string
+	mu   sync.RWMutex
+}
+
+// NewManager creates a template manager rooted at dir. The directory is
+// created lazily on the first write.
+func NewManager(dir string) *FileManager {
+	if dir == "" {
+		dir = "./templates"
+	}
+	return &FileManager{root: dir}
+}
+
+// Root returns the library directory
+func (m *FileManager) Root() string { return m.root }
+
+// Process renders a template with vars using text/template. When text is the
+// name of a stored template, that template and its variable schema are used;
+// otherwise text is rendered inline. It fails with a *VariableError listing
+// every variable that is missing or violates its declaration.
+func (m *FileManager) Process(text string, vars map[string]interface{}) (string, error) {
+	if looksLikeName(text) {
+		if tmpl, err := m.GetTemplate(text); err == nil {
+			return render(tmpl.Name, tmpl.Content, tmpl.Variables, vars)
+		}
+	}
+	return render("", text, nil, vars)
+}
+
+// LoadTemplate loads a template content by name
+func (m *FileManager) LoadTemplate(name string) (string, error) {
+	tmpl, err := m.GetTemplate(name)
+	if err != nil {
+		return "", err
+	}
+	return tmpl.Content, nil
+}
+
+// SaveTemplate saves a template content under name, keeping any existing
+// description, category, metadata and variable declarations. Variables that
+// the new content references without a declaration are added as required.
+func (m *FileManager) SaveTemplate(name, content string) error {
+	tmpl, err := m.GetTemplate(name)
+	if err != nil {
+		if !errors.Is(err, ErrTemplateNotFound) {
+			return err
+		}
+		tmpl = &interfaces.Template{Name: name}
+	}
+
+	referenced, err := Variables(content)
+	if err != nil {
+		return fmt.Errorf("invalid template %s: %w", name, err)
+	}
+	for _, ref := range referenced {
+		if !hasVariable(tmpl.Variables, ref) {
+			tmpl.Variables = append(tmpl.Variables, interfaces.Variable{Name: ref, Required: true})
+		}
+	}
+
+	tmpl.Content = content
+	return m.PutTemplate(*tmpl)
+}
+
+// ListTemplates returns all available template names, sorted
+func (m *FileManager) ListTemplates() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	seen := map[string]struct{}{}
+	_ = filepath.WalkDir(m.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !isTemplateFile(path) {
+			return nil
+		}
+		rel, relErr := filepath.Rel(m.root, path)
+		if relErr != nil {
+			return nil
+		}
+		seen[filepath.ToSlash(strings.TrimSuffix(rel, filepath.Ext(rel)))] = struct{}{}
+		return nil
+	})
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DeleteTemplate removes a template
+func (m *FileManager) DeleteTemplate(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path, err := m.find(name)
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// GetTemplate loads the full template record by name
+func (m *FileManager) GetTemplate(name string) (*interfaces.Template, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	path, err := m.find(name)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template %s: %w", name, err)
+	}
+
+	var tmpl interfaces.Template
+	if filepath.Ext(path) == ".json" {
+		err = json.Unmarshal(data, &tmpl)
+	} else {
+		err = yaml.Unmarshal(data, &tmpl)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+	if tmpl.Name == "" {
+		tmpl.Name = name
+	}
+	return &tmpl, nil
+}
+
+// PutTemplate stores a full template record. Variables are inferred from the
+// content when the record does not declare them.
+func (m *FileManager) PutTemplate(tmpl interfaces.Template) error {
+	if _, err := template.New(tmpl.Name).Parse(tmpl.Content); err != nil {
+		return fmt.Errorf("invalid template %s: %w", tmpl.Name, err)
+	}
+	if len(tmpl.Variables) == 0 {
+		names, err := Variables(tmpl.Content)
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			tmpl.Variables = append(tmpl.Variables, interfaces.Variable{Name: name, Required: true})
+		}
+	}
+	if err := validateSchema(tmpl.Name, tmpl.Variables); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path, err := m.find(tmpl.Name)
+	if errors.Is(err, ErrTemplateNotFound) {
+		path, err = m.pathFor(tmpl.Name, ".yaml")
+	}
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	if filepath.Ext(path) == ".json" {
+		data, err = json.MarshalIndent(tmpl, "", "  ")
+	} else {
+		data, err = yaml.Marshal(tmpl)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode template %s: %w", tmpl.Name, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create template directory: %w", err)
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Render loads a template by name and processes it with variables
+func (m *FileManager) Render(name string, vars map[string]interface{}) (string, error) {
+	tmpl, err := m.GetTemplate(name)
+	if err != nil {
+		return "", err
+	}
+	return render(tmpl.Name, tmpl.Content, tmpl.Variables, vars)
+}
+
+// Check reports required, missing and extra variables for a template text
+func (m *FileManager) Check(text string, vars map[string]interface{}) (*interfaces.VariableReport, error) {
+	required, err := Variables(text)
+	if err != nil {
+		return nil, err
+	}
+	return checkVariables(required, vars), nil
+}
+
+// find resolves the on-disk file of an existing template.
+func (m *FileManager) find(name string) (string, error) {
+	for _, ext := range supportedExts {
+		path, err := m.pathFor(name, ext)
+		if err != nil {
+			return "", err
+		}
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+}
+
+// pathFor maps a template name to a file path, rejecting names that would
+// escape the library root.
+func (m *FileManager) pathFor(name, ext string) (string, error) {
+	clean := filepath.FromSlash(strings.TrimSpace(name))
+	if clean == "" || !filepath.IsLocal(clean) {
+		return "", fmt.Errorf("invalid template name: %q", name)
+	}
+	return filepath.Join(m.root, clean+ext), nil
+}
+
+func isTemplateFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, supported := range supportedExts {
+		if ext == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// render validates vars against the declared variables, plus any referenced
+// variable that is not declared, and executes text with the result.
+func render(name, text string, declared []interfaces.Variable, vars map[string]interface{}) (string, error) {
+	referenced, err := Variables(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid template: %w", err)
+	}
+
+	specs := append([]interfaces.Variable(nil), declared...)
+	for _, ref := range referenced {
+		if !hasVariable(declared, ref) {
+			specs = append(specs, interfaces.Variable{Name: ref, Required: true})
+		}
+	}
+
+	values, err := Validate(name, specs, vars)
+	if err != nil {
+		return "", err
+	}
+
+	tpl, err := template.New("grompt").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, values); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// validateSchema rejects declarations that can never be satisfied.
+func validateSchema(name string, specs []interfaces.Variable) error {
+	verr := &VariableError{Template: name}
+	seen := map[string]struct{}{}
+	for _, spec := range specs {
+		if spec.Name == "" {
+			verr.add("(unnamed)", "schema", "variable name is empty")
+			continue
+		}
+		if _, dup := seen[spec.Name]; dup {
+			verr.add(spec.Name, "schema", "is declared more than once")
+		}
+		seen[spec.Name] = struct{}{}
+
+		switch spec.Type {
+		case "", interfaces.VarTypeString, interfaces.VarTypeInt, interfaces.VarTypeList:
+		case interfaces.VarTypeEnum:
+			if len(spec.Enum) == 0 {
+				verr.add(spec.Name, "schema", "enum variables must list their allowed values")
+			}
+		default:
+			verr.add(spec.Name, "schema", fmt.Sprintf("unknown variable type %q", spec.Type))
+		}
+		if spec.Pattern != "" {
+			if _, err := regexp.Compile(spec.Pattern); err != nil {
+				verr.add(spec.Name, "schema", fmt.Sprintf("invalid pattern %q: %v", spec.Pattern, err))
+			}
+		}
+		if spec.Default != nil {
+			if _, violations := validateValue(spec, spec.Default); len(violations) > 0 {
+				verr.add(spec.Name, "schema", "default value "+violations[0].Message)
+			}
+		}
+	}
+	if len(verr.Violations) > 0 {
+		return verr
+	}
+	return nil
+}
+
+func hasVariable(specs []interfaces.Variable, name string) bool {
+	for _, spec := range specs {
+		if spec.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// looksLikeName reports whether text could be a template name rather than an
+// inline template.
+func looksLikeName(text string) bool {
+	if text == "" || strings.ContainsAny(text, " \t\n{}") {
+		return false
+	}
+	return filepath.IsLocal(filepath.FromSlash(text))
+}
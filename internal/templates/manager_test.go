@@ -0,0 +1,119 @@
+package templates
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+)
+
+func TestProcessSubstitutesVariables(t *testing.T) {
+	m := NewManager(t.TempDir())
+
+	out, err := m.Process("Review {{.lang}} code for {{.focus}}.", map[string]interface{}{
+		"lang":  "Go",
+		"focus": "races",
+	})
+	if err != nil {
+		t.Fatalf("Process returned error: %v", err)
+	}
+	if out != "Review Go code for races." {
+		t.Fatalf("unexpected output: %q", out)
+	}
+}
+
+func TestProcessReportsMissingAndExtraVariables(t *testing.T) {
+	m := NewManager(t.TempDir())
+
+	_, err := m.Process("{{.a}} {{.b}}", map[string]interface{}{"a": 1, "c": 2})
+	var verr *VariableError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *VariableError, got %v", err)
+	}
+	if !reflect.DeepEqual(verr.Missing, []string{"b"}) || !reflect.DeepEqual(verr.Extra, []string{"c"}) {
+		t.Fatalf("unexpected report: missing=%v extra=%v", verr.Missing, verr.Extra)
+	}
+}
+
+func TestVariablesIgnoresScopedFields(t *testing.T) {
+	vars, err := Variables("{{range .items}}{{.name}} {{$.sep}}{{end}}{{with .user}}{{.id}}{{end}}{{if .flag}}{{.body}}{{end}}")
+	if err != nil {
+		t.Fatalf("Variables returned error: %v", err)
+	}
+	want := []string{"body", "flag", "items", "sep", "user"}
+	if !reflect.DeepEqual(vars, want) {
+		t.Fatalf("got %v, want %v", vars, want)
+	}
+}
+
+func TestFileManagerLifecycle(t *testing.T) {
+	dir := t.TempDir()
+	m := NewManager(dir)
+
+	err := m.PutTemplate(interfaces.Template{
+		Name:        "review/go",
+		Content:     "Review this {{.lang}} diff:\n{{.diff}}",
+		Description: "Code review",
+		Category:    "code",
+	})
+	if err != nil {
+		t.Fatalf("PutTemplate returned error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "review", "go.yaml")); err != nil {
+		t.Fatalf("expected template file on disk: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "summary.json"), []byte(`{"content":"Summarize {{.text}}"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := m.ListTemplates(); !reflect.DeepEqual(got, []string{"review/go", "summary"}) {
+		t.Fatalf("unexpected template list: %v", got)
+	}
+
+	tmpl, err := m.GetTemplate("review/go")
+	if err != nil {
+		t.Fatalf("GetTemplate returned error: %v", err)
+	}
+	if tmpl.Category != "code" || !reflect.DeepEqual(variableNames(tmpl.Variables), []string{"diff", "lang"}) {
+		t.Fatalf("unexpected record: %+v", tmpl)
+	}
+
+	out, err := m.Render("summary", map[string]interface{}{"text": "notes"})
+	if err != nil || out != "Summarize notes" {
+		t.Fatalf("Render = %q, %v", out, err)
+	}
+
+	if err := m.SaveTemplate("review/go", "Short {{.diff}}"); err != nil {
+		t.Fatalf("SaveTemplate returned error: %v", err)
+	}
+	tmpl, _ = m.GetTemplate("review/go")
+	if tmpl.Description != "Code review" || tmpl.Content != "Short {{.diff}}" {
+		t.Fatalf("SaveTemplate should keep metadata and replace content, got %+v", tmpl)
+	}
+
+	if err := m.DeleteTemplate("review/go"); err != nil {
+		t.Fatalf("DeleteTemplate returned error: %v", err)
+	}
+	if _, err := m.GetTemplate("review/go"); !errors.Is(err, ErrTemplateNotFound) {
+		t.Fatalf("expected ErrTemplateNotFound, got %v", err)
+	}
+}
+
+func TestFileManagerRejectsEscapingNames(t *testing.T) {
+	m := NewManager(t.TempDir())
+	if err := m.SaveTemplate("../outside", "x"); err == nil {
+		t.Fatal("expected an error for a name outside the library root")
+	}
+}
+
+func variableNames(vars []interfaces.Variable) []string {
+	names := make([]string, 0, len(vars))
+	for _, v := range vars {
+		names = append(names, v.Name)
+	}
+	return names
+}
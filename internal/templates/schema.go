@@ -0,0 +1,236 @@
+package templates
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+)
+
+// Violation describes a single variable that does not satisfy its declaration.
+type Violation struct {
+	Variable string `json:"variable"`
+	Rule     string `json:"rule"` // required|type|enum|pattern|min_length|max_length|schema
+	Message  string `json:"message"`
+}
+
+// Validate checks vars against the declared variables and returns a copy of
+// vars with defaults applied and values coerced to their declared type.
+// Every violation is collected; the error is a *VariableError when any exist.
+func Validate(name string, specs []interfaces.Variable, vars map[string]interface{}) (map[string]interface{}, error) {
+	out := make(map[string]interface{}, len(vars)+len(specs))
+	for k, v := range vars {
+		out[k] = v
+	}
+
+	verr := &VariableError{Template: name}
+	declared := make(map[string]struct{}, len(specs))
+	for _, spec := range specs {
+		declared[spec.Name] = struct{}{}
+
+		value, present := vars[spec.Name]
+		if !present {
+			switch {
+			case spec.Default != nil:
+				value = spec.Default
+			case spec.Required:
+				verr.Missing = append(verr.Missing, spec.Name)
+				verr.add(spec.Name, "required", "is required")
+				continue
+			default:
+				out[spec.Name] = zeroValue(spec.Type)
+				continue
+			}
+		}
+
+		coerced, violations := validateValue(spec, value)
+		if len(violations) > 0 {
+			verr.Violations = append(verr.Violations, violations...)
+			continue
+		}
+		out[spec.Name] = coerced
+	}
+
+	for k := range vars {
+		if _, ok := declared[k]; !ok {
+			verr.Extra = append(verr.Extra, k)
+		}
+	}
+
+	if len(verr.Violations) > 0 {
+		sort.Strings(verr.Extra)
+		return nil, verr
+	}
+	return out, nil
+}
+
+func validateValue(spec interfaces.Variable, value any) (any, []Violation) {
+	var violations []Violation
+	fail := func(rule, format string, args ...any) {
+		violations = append(violations, Violation{Variable: spec.Name, Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch spec.Type {
+	case "", interfaces.VarTypeString, interfaces.VarTypeEnum:
+		if spec.Type == "" && !isScalar(value) {
+			// Untyped variables accept any value; only scalar values are constrained.
+			return value, nil
+		}
+		s, ok := value.(string)
+		if !ok {
+			if !isScalar(value) {
+				fail("type", "must be a string, got %T", value)
+				return nil, violations
+			}
+			s = fmt.Sprint(value)
+		}
+		checkString(spec, s, fail)
+		if spec.Type == interfaces.VarTypeEnum || len(spec.Enum) > 0 {
+			if !contains(spec.Enum, s) {
+				fail("enum", "must be one of [%s], got %q", strings.Join(spec.Enum, ", "), s)
+			}
+		}
+		if spec.Type == "" {
+			return value, violations
+		}
+		return s, violations
+
+	case interfaces.VarTypeInt:
+		n, err := toInt(value)
+		if err != nil {
+			fail("type", "must be an integer: %v", err)
+			return nil, violations
+		}
+		return n, violations
+
+	case interfaces.VarTypeList:
+		items, ok := toList(value)
+		if !ok {
+			fail("type", "must be a list, got %T", value)
+			return nil, violations
+		}
+		if spec.MinLength > 0 && len(items) < spec.MinLength {
+			fail("min_length", "must have at least %d items, got %d", spec.MinLength, len(items))
+		}
+		if spec.MaxLength > 0 && len(items) > spec.MaxLength {
+			fail("max_length", "must have at most %d items, got %d", spec.MaxLength, len(items))
+		}
+		for i, item := range items {
+			s := fmt.Sprint(item)
+			if spec.Pattern != "" {
+				if re, err := regexp.Compile(spec.Pattern); err == nil && !re.MatchString(s) {
+					fail("pattern", "item %d %q does not match %s", i, s, spec.Pattern)
+				}
+			}
+			if len(spec.Enum) > 0 && !contains(spec.Enum, s) {
+				fail("enum", "item %d must be one of [%s], got %q", i, strings.Join(spec.Enum, ", "), s)
+			}
+		}
+		return items, violations
+
+	default:
+		fail("schema", "unknown variable type %q", spec.Type)
+		return nil, violations
+	}
+}
+
+func checkString(spec interfaces.Variable, s string, fail func(rule, format string, args ...any)) {
+	length := utf8.RuneCountInString(s)
+	if spec.MinLength > 0 && length < spec.MinLength {
+		fail("min_length", "must be at least %d characters, got %d", spec.MinLength, length)
+	}
+	if spec.MaxLength > 0 && length > spec.MaxLength {
+		fail("max_length", "must be at most %d characters, got %d", spec.MaxLength, length)
+	}
+	if spec.Pattern != "" {
+		re, err := regexp.Compile(spec.Pattern)
+		if err != nil {
+			fail("schema", "invalid pattern %q: %v", spec.Pattern, err)
+		} else if !re.MatchString(s) {
+			fail("pattern", "%q does not match %s", s, spec.Pattern)
+		}
+	}
+}
+
+func toInt(value any) (int, error) {
+	switch v := value.(type) {
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case int32:
+		return int(v), nil
+	case uint64:
+		return int(v), nil
+	case float64:
+		if v != math.Trunc(v) {
+			return 0, fmt.Errorf("%v has a fractional part", v)
+		}
+		return int(v), nil
+	case string:
+		return strconv.Atoi(strings.TrimSpace(v))
+	default:
+		return 0, fmt.Errorf("unsupported value of type %T", value)
+	}
+}
+
+// toList accepts slices of any element type and comma-separated strings,
+// the latter being how list values arrive from CLI flags.
+func toList(value any) ([]any, bool) {
+	if s, ok := value.(string); ok {
+		if strings.TrimSpace(s) == "" {
+			return []any{}, true
+		}
+		parts := strings.Split(s, ",")
+		items := make([]any, 0, len(parts))
+		for _, p := range parts {
+			items = append(items, strings.TrimSpace(p))
+		}
+		return items, true
+	}
+
+	rv := reflect.ValueOf(value)
+	if !rv.IsValid() || (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) {
+		return nil, false
+	}
+	items := make([]any, rv.Len())
+	for i := range items {
+		items[i] = rv.Index(i).Interface()
+	}
+	return items, true
+}
+
+func isScalar(value any) bool {
+	switch value.(type) {
+	case string, bool, int, int32, int64, uint, uint32, uint64, float32, float64:
+		return true
+	default:
+		return false
+	}
+}
+
+func zeroValue(varType string) any {
+	switch varType {
+	case interfaces.VarTypeInt:
+		return 0
+	case interfaces.VarTypeList:
+		return []any{}
+	default:
+		return ""
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package templates
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+)
+
+const schemaTemplate = `name: summarize
+content: "Summarize in {{.words}} words ({{.tone}}): {{range .topics}}[{{.}}]{{end}} {{.ticket}}"
+variables:
+  - name: words
+    type: int
+    default: 100
+  - name: tone
+    type: enum
+    enum: [formal, casual]
+    required: true
+  - name: topics
+    type: list
+    min_length: 1
+    max_length: 3
+  - name: ticket
+    pattern: "^[A-Z]+-[0-9]+$"
+`
+
+func writeSchemaTemplate(t *testing.T) *FileManager {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "summarize.yaml"), []byte(schemaTemplate), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return NewManager(dir)
+}
+
+func TestRenderAppliesDefaultsAndCoercion(t *testing.T) {
+	m := writeSchemaTemplate(t)
+
+	out, err := m.Process("summarize", map[string]interface{}{
+		"tone":   "casual",
+		"topics": "go, testing",
+		"ticket": "GR-12",
+	})
+	if err != nil {
+		t.Fatalf("Process returned error: %v", err)
+	}
+	if want := "Summarize in 100 words (casual): [go][testing] GR-12"; out != want {
+		t.Fatalf("got %q, want %q", out, want)
+	}
+}
+
+func TestRenderCollectsEveryViolation(t *testing.T) {
+	m := writeSchemaTemplate(t)
+
+	_, err := m.Render("summarize", map[string]interface{}{
+		"words":  "many",
+		"topics": []string{"a", "b", "c", "d"},
+		"ticket": "nope",
+	})
+	var verr *VariableError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *VariableError, got %v", err)
+	}
+
+	rules := map[string]string{}
+	for _, v := range verr.Violations {
+		rules[v.Variable] = v.Rule
+	}
+	want := map[string]string{"words": "type", "tone": "required", "topics": "max_length", "ticket": "pattern"}
+	for variable, rule := range want {
+		if rules[variable] != rule {
+			t.Errorf("variable %s: got rule %q, want %q (all: %+v)", variable, rules[variable], rule, verr.Violations)
+		}
+	}
+}
+
+func TestPutTemplateRejectsInvalidSchema(t *testing.T) {
+	m := NewManager(t.TempDir())
+
+	err := m.PutTemplate(interfaces.Template{
+		Name:    "bad",
+		Content: "{{.mode}}",
+		Variables: []interfaces.Variable{
+			{Name: "mode", Type: interfaces.VarTypeEnum},
+			{Name: "id", Pattern: "("},
+		},
+	})
+	var verr *VariableError
+	if !errors.As(err, &verr) || len(verr.Violations) != 2 {
+		t.Fatalf("expected two schema violations, got %v", err)
+	}
+}
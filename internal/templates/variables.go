@@ -0,0 +1,141 @@
+package templates
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/template/parse"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+)
+
+// VariableError is returned when the variables supplied to a template do not
+// satisfy its declarations. It lists every violation, not only the first one.
+type VariableError struct {
+	Template   string      `json:"template,omitempty"`
+	Missing    []string    `json:"missing,omitempty"`
+	Extra      []string    `json:"extra,omitempty"`
+	Violations []Violation `json:"violations"`
+}
+
+func (e *VariableError) add(variable, rule, message string) {
+	e.Violations = append(e.Violations, Violation{Variable: variable, Rule: rule, Message: message})
+}
+
+func (e *VariableError) Error() string {
+	name := e.Template
+	if name == "" {
+		name = "template"
+	}
+	msgs := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		msgs = append(msgs, v.Variable+" "+v.Message)
+	}
+	msg := fmt.Sprintf("%s: invalid variables: %s", name, strings.Join(msgs, "; "))
+	if len(e.Extra) > 0 {
+		msg += fmt.Sprintf(" (unused: %s)", strings.Join(e.Extra, ", "))
+	}
+	return msg
+}
+
+// Variables returns the sorted top-level variables referenced by a template
+// text, e.g. {{.topic}} or {{$.topic}}. Fields accessed inside range/with
+// blocks are relative to the block and are not reported.
+func Variables(text string) ([]string, error) {
+	trees, err := parse.Parse("grompt", text, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]struct{}{}
+	for _, tree := range trees {
+		if tree != nil && tree.Root != nil {
+			collectNode(tree.Root, true, seen)
+		}
+	}
+
+	out := make([]string, 0, len(seen))
+	for name := range seen {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// checkVariables compares required variables with the supplied ones.
+func checkVariables(required []string, vars map[string]interface{}) *interfaces.VariableReport {
+	report := &interfaces.VariableReport{Required: required}
+
+	wanted := make(map[string]struct{}, len(required))
+	for _, name := range required {
+		wanted[name] = struct{}{}
+		if _, ok := vars[name]; !ok {
+			report.Missing = append(report.Missing, name)
+		}
+	}
+	for name := range vars {
+		if _, ok := wanted[name]; !ok {
+			report.Extra = append(report.Extra, name)
+		}
+	}
+	sort.Strings(report.Extra)
+	return report
+}
+
+func collectNode(node parse.Node, atRoot bool, seen map[string]struct{}) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			collectNode(child, atRoot, seen)
+		}
+	case *parse.ActionNode:
+		collectPipe(n.Pipe, atRoot, seen)
+	case *parse.IfNode:
+		collectBranch(&n.BranchNode, atRoot, atRoot, seen)
+	case *parse.RangeNode:
+		collectBranch(&n.BranchNode, false, atRoot, seen)
+	case *parse.WithNode:
+		collectBranch(&n.BranchNode, false, atRoot, seen)
+	case *parse.TemplateNode:
+		collectPipe(n.Pipe, atRoot, seen)
+	case *parse.PipeNode:
+		collectPipe(n, atRoot, seen)
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			collectNode(arg, atRoot, seen)
+		}
+	case *parse.ChainNode:
+		collectNode(n.Node, atRoot, seen)
+	case *parse.FieldNode:
+		if atRoot && len(n.Ident) > 0 {
+			seen[n.Ident[0]] = struct{}{}
+		}
+	case *parse.VariableNode:
+		// $ always refers to the root data, regardless of the current dot
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			seen[n.Ident[1]] = struct{}{}
+		}
+	}
+}
+
+// collectBranch walks if/range/with nodes. The pipeline is evaluated with the
+// outer dot; the body uses bodyAtRoot, while else branches keep the outer dot.
+func collectBranch(b *parse.BranchNode, bodyAtRoot, atRoot bool, seen map[string]struct{}) {
+	collectPipe(b.Pipe, atRoot, seen)
+	collectNode(b.List, bodyAtRoot, seen)
+	if b.ElseList != nil {
+		collectNode(b.ElseList, atRoot, seen)
+	}
+}
+
+func collectPipe(p *parse.PipeNode, atRoot bool, seen map[string]struct{}) {
+	if p == nil {
+		return
+	}
+	for _, cmd := range p.Cmds {
+		collectNode(cmd, atRoot, seen)
+	}
+}
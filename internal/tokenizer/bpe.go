@@ -0,0 +1,178 @@
+package tokenizer
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// bpe approximates OpenAI's byte-pair encoding. Text is split the way the
+// cl100k/o200k pre-tokenizer does — contractions, words with one leading
+// non-letter, digit groups of up to three, punctuation runs and whitespace —
+// and, since most common English words are then a single token, each piece is
+// priced by its shape instead of running the merges.
+type bpe struct{}
+
+func (bpe) Family() Family { return FamilyOpenAI }
+
+func (bpe) Count(text string) int {
+	total := 0
+	for i := 0; i < len(text); {
+		n := nextPiece(text[i:])
+		total += pieceTokens(text[i : i+n])
+		i += n
+	}
+	return total
+}
+
+// nextPiece returns the byte length of the pre-token at the start of s
+func nextPiece(s string) int {
+	r, size := utf8.DecodeRuneInString(s)
+	next, _ := utf8.DecodeRuneInString(s[size:])
+
+	switch {
+	case r == '\'':
+		if n := contraction(s[size:]); n > 0 {
+			return size + n
+		}
+	case unicode.IsLetter(r):
+		return size + letters(s[size:])
+	case unicode.IsNumber(r):
+		n := size
+		for k := 1; k < 3 && n < len(s); k++ {
+			d, dsize := utf8.DecodeRuneInString(s[n:])
+			if !unicode.IsNumber(d) {
+				break
+			}
+			n += dsize
+		}
+		return n
+	}
+
+	// One non-letter (a space, quote or symbol) may lead a word
+	if !isNewline(r) && unicode.IsLetter(next) {
+		return size + letters(s[size:])
+	}
+	// Punctuation run, optionally led by a space and followed by newlines
+	if isPunct(r) || (r == ' ' && isPunct(next)) {
+		n := size
+		for n < len(s) {
+			p, psize := utf8.DecodeRuneInString(s[n:])
+			if !isPunct(p) {
+				break
+			}
+			n += psize
+		}
+		for n < len(s) && (s[n] == '\r' || s[n] == '\n') {
+			n++
+		}
+		return n
+	}
+
+	// Whitespace run; its last space is left to lead the following word or
+	// punctuation, as the original pattern's lookahead does.
+	n, last := 0, 0
+	for n < len(s) {
+		w, wsize := utf8.DecodeRuneInString(s[n:])
+		if !unicode.IsSpace(w) {
+			break
+		}
+		last = n
+		n += wsize
+	}
+	if n == 0 {
+		return size
+	}
+	if n < len(s) && last > 0 && !isNewline(rune(s[last])) {
+		return last
+	}
+	return n
+}
+
+func contraction(s string) int {
+	for _, c := range []string{"s", "t", "re", "ve", "m", "ll", "d"} {
+		if len(s) >= len(c) && equalFoldASCII(s[:len(c)], c) {
+			return len(c)
+		}
+	}
+	return 0
+}
+
+func letters(s string) int {
+	n := 0
+	for n < len(s) {
+		r, size := utf8.DecodeRuneInString(s[n:])
+		if !unicode.IsLetter(r) {
+			break
+		}
+		n += size
+	}
+	return n
+}
+
+func pieceTokens(piece string) int {
+	first, _ := utf8.DecodeRuneInString(piece)
+	switch {
+	case unicode.IsSpace(first) && isSpace(piece):
+		return 1
+	case unicode.IsNumber(first):
+		return 1
+	}
+
+	letters, ascii := 0, true
+	for _, r := range piece {
+		if unicode.IsLetter(r) {
+			letters++
+		}
+		if r >= utf8.RuneSelf {
+			ascii = false
+		}
+	}
+
+	switch {
+	case letters == 0:
+		// Punctuation: frequent pairs such as "):" or "**" are merged
+		return ceilDiv(utf8.RuneCountInString(piece), 2)
+	case ascii:
+		// Words up to eight letters are usually in the vocabulary; longer
+		// ones split into roughly five-letter chunks.
+		if letters <= 8 {
+			return 1
+		}
+		return 1 + ceilDiv(letters-8, 5)
+	default:
+		// Accented Latin takes two bytes and CJK three; both merge poorly.
+		return max(1, ceilDiv(len(piece), 3))
+	}
+}
+
+func isPunct(r rune) bool {
+	return r != utf8.RuneError && !unicode.IsSpace(r) && !unicode.IsLetter(r) && !unicode.IsNumber(r)
+}
+
+func isNewline(r rune) bool { return r == '\r' || r == '\n' }
+
+func isSpace(s string) bool {
+	for _, r := range s {
+		if !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalFoldASCII(a, b string) bool {
+	for i := 0; i < len(a); i++ {
+		c := a[i]
+		if 'A' <= c && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		if c != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func ceilDiv(a, b int) int {
+	return (a + b - 1) / b
+}
@@ -0,0 +1,249 @@
+package tokenizer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+)
+
+// Policy decides what happens to a prompt that does not fit the context window
+type Policy string
+
+const (
+	// PolicyReject fails the request with a *ContextError (the default)
+	PolicyReject Policy = "reject"
+	// PolicyTrim cuts the middle of the prompt until it fits
+	PolicyTrim Policy = "trim"
+)
+
+// ParsePolicy reads a policy name; anything other than "trim" rejects
+func ParsePolicy(s string) Policy {
+	if strings.EqualFold(strings.TrimSpace(s), string(PolicyTrim)) {
+		return PolicyTrim
+	}
+	return PolicyReject
+}
+
+// contextWindows lists context sizes by model name prefix; the longest
+// matching prefix wins.
+var contextWindows = map[string]int{
+	"gpt-5":          400000,
+	"gpt-4.1":        1047576,
+	"gpt-4o":         128000,
+	"chatgpt-4o":     128000,
+	"gpt-4-turbo":    128000,
+	"gpt-4-32k":      32768,
+	"gpt-4":          8192,
+	"gpt-3.5-turbo":  16385,
+	"o1":             200000,
+	"o3":             200000,
+	"o4":             200000,
+	"claude":         200000,
+	"gemini-1.0":     32760,
+	"gemini-1.5-pro": 2097152,
+	"gemini":         1048576,
+	"deepseek":       128000,
+	"llama2":         4096,
+	"llama3":         8192,
+	"llama-3":        8192,
+	"llama3.1":       131072,
+	"llama3.2":       131072,
+	"llama3.3":       131072,
+	"llama-3.1":      131072,
+	"llama-3.2":      131072,
+	"llama-3.3":      131072,
+	"mistral":        32768,
+	"mixtral":        32768,
+	"qwen":           32768,
+}
+
+// providerWindows is used when the model is unknown or not given. Local
+// runtimes such as Ollama are left out: their window depends on how the
+// model was loaded.
+var providerWindows = map[string]int{
+	"openai":    128000,
+	"chatgpt":   128000,
+	"claude":    200000,
+	"anthropic": 200000,
+	"gemini":    1048576,
+	"deepseek":  128000,
+}
+
+// ContextWindow returns the context size in tokens of a model, or 0 when it
+// is unknown.
+func ContextWindow(provider, model string) int {
+	m := strings.ToLower(model)
+	if i := strings.LastIndexByte(m, '/'); i >= 0 {
+		m = m[i+1:] // "meta-llama/llama-3.1-8b"
+	}
+	best := ""
+	for prefix := range contextWindows {
+		if strings.HasPrefix(m, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best != "" {
+		return contextWindows[best]
+	}
+	return providerWindows[strings.ToLower(provider)]
+}
+
+// Estimate is the token budget of one request
+type Estimate struct {
+	Provider      string `json:"provider"`
+	Model         string `json:"model,omitempty"`
+	Family        Family `json:"family"`
+	PromptTokens  int    `json:"prompt_tokens"`
+	MaxOutput     int    `json:"max_output,omitempty"`
+	ContextWindow int    `json:"context_window,omitempty"`
+	// TrimmedTokens is how many prompt tokens PolicyTrim removed
+	TrimmedTokens int `json:"trimmed_tokens,omitempty"`
+}
+
+// ContextError reports a prompt that does not fit the model's context window
+type ContextError struct {
+	Estimate
+}
+
+func (e *ContextError) Error() string {
+	target := e.Provider
+	if e.Model != "" {
+		target += ":" + e.Model
+	}
+	return fmt.Sprintf("prompt of ~%d tokens exceeds the %d-token context window of %s",
+		e.PromptTokens, e.ContextWindow, target)
+}
+
+// Fit checks a prompt against the context window of provider/model before it
+// is sent. When the prompt plus maxOutput does not fit but the prompt alone
+// does, maxOutput is lowered to the room that is left. When the prompt alone
+// is too large, PolicyReject returns a *ContextError and PolicyTrim removes
+// text from the middle of the prompt, keeping room for an answer. Unknown
+// windows are not checked.
+func Fit(provider, model, prompt string, maxOutput int, policy Policy) (string, int, Estimate, error) {
+	tk := For(provider, model)
+	est := Estimate{
+		Provider:      provider,
+		Model:         model,
+		Family:        tk.Family(),
+		PromptTokens:  tk.Count(prompt),
+		MaxOutput:     maxOutput,
+		ContextWindow: ContextWindow(provider, model),
+	}
+	window := est.ContextWindow
+	if window == 0 {
+		return prompt, maxOutput, est, nil
+	}
+
+	if est.PromptTokens < window {
+		if maxOutput > 0 && est.PromptTokens+maxOutput > window {
+			est.MaxOutput = window - est.PromptTokens
+		}
+		return prompt, est.MaxOutput, est, nil
+	}
+
+	if policy != PolicyTrim {
+		return prompt, maxOutput, est, &ContextError{Estimate: est}
+	}
+
+	// Keep room for the answer: the requested output, capped at half the
+	// window, or an eighth of the window when no output size was given.
+	reserve := window / 8
+	if maxOutput > 0 {
+		reserve = min(maxOutput, window/2)
+	}
+	trimmed := Trim(tk, prompt, window-reserve)
+	after := tk.Count(trimmed)
+	est.TrimmedTokens = est.PromptTokens - after
+	est.PromptTokens = after
+	if maxOutput > 0 {
+		est.MaxOutput = min(maxOutput, window-after)
+	}
+	return trimmed, est.MaxOutput, est, nil
+}
+
+// trimMarker replaces the text removed by Trim
+const trimMarker = "\n\n[... trimmed to fit the context window ...]\n\n"
+
+// Trim shortens text to at most maxTokens by cutting out its middle, keeping
+// two thirds of the budget for the beginning (usually the instructions) and
+// one third for the end (usually the question). Cuts fall between words.
+func Trim(tk Tokenizer, text string, maxTokens int) string {
+	if tk.Count(text) <= maxTokens {
+		return text
+	}
+	budget := maxTokens - tk.Count(trimMarker)
+	if budget <= 0 {
+		return ""
+	}
+
+	// Counting word by word keeps this linear; the per-word sum is close to
+	// the count of the whole text for every family.
+	words := wordPattern.FindAllString(text, -1)
+	costs := make([]int, len(words))
+	for i, w := range words {
+		costs[i] = tk.Count(w)
+	}
+
+	head, used := 0, 0
+	for head < len(words) && used+costs[head] <= budget*2/3 {
+		used += costs[head]
+		head++
+	}
+	tail := 0
+	for tail < len(words)-head && used+costs[len(words)-1-tail] <= budget {
+		used += costs[len(words)-1-tail]
+		tail++
+	}
+
+	join := func() string {
+		return strings.Join(words[:head], "") + trimMarker + strings.Join(words[len(words)-tail:], "")
+	}
+	out := join()
+	// Joining can merge or split tokens at the seams; drop tail words until
+	// the estimate is within budget.
+	for tail > 0 && tk.Count(out) > maxTokens {
+		tail--
+		out = join()
+	}
+	return out
+}
+
+// wordPattern splits text into words with their trailing whitespace
+var wordPattern = regexp.MustCompile(`\S+\s*|\s+`)
+
+// TrimMessages drops the oldest turns of a conversation until it fits in
+// maxTokens, returning the kept messages and how many were dropped. System
+// messages and the last message are always kept, and tool results are never
+// left without the call they answer.
+func TrimMessages(tk Tokenizer, messages []interfaces.Message, maxTokens int) ([]interfaces.Message, int) {
+	if maxTokens <= 0 || CountMessages(tk, messages) <= maxTokens {
+		return messages, 0
+	}
+
+	var system, turns []interfaces.Message
+	for _, m := range messages {
+		if m.Role == "system" {
+			system = append(system, m)
+		} else {
+			turns = append(turns, m)
+		}
+	}
+
+	fits := func(turns []interfaces.Message) bool {
+		return CountMessages(tk, append(append([]interfaces.Message{}, system...), turns...)) <= maxTokens
+	}
+	start := 0
+	for start < len(turns)-1 && !fits(turns[start:]) {
+		start++
+		for start < len(turns)-1 && turns[start].Role == "tool" {
+			start++
+		}
+	}
+
+	kept := make([]interfaces.Message, 0, len(system)+len(turns)-start)
+	kept = append(append(kept, system...), turns[start:]...)
+	return kept, len(messages) - len(kept)
+}
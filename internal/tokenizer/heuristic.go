@@ -0,0 +1,36 @@
+package tokenizer
+
+import (
+	"math"
+	"unicode/utf8"
+)
+
+// heuristic estimates tokens from character counts. ASCII text is divided by
+// the family's characters-per-token ratio; non-ASCII runes are priced on
+// their own because SentencePiece-style vocabularies cover them sparsely:
+// accented Latin at half a token and wider scripts (CJK, emoji) at one.
+type heuristic struct {
+	family        Family
+	charsPerToken float64
+}
+
+func (h heuristic) Family() Family { return h.family }
+
+func (h heuristic) Count(text string) int {
+	if text == "" {
+		return 0
+	}
+	ascii, latin, wide := 0, 0, 0
+	for _, r := range text {
+		switch n := utf8.RuneLen(r); {
+		case n == 1:
+			ascii++
+		case n == 2:
+			latin++
+		default:
+			wide++
+		}
+	}
+	estimate := float64(ascii)/h.charsPerToken + float64(latin)*0.5 + float64(wide)
+	return max(1, int(math.Ceil(estimate)))
+}
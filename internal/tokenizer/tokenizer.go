@@ -0,0 +1,130 @@
+// Package tokenizer estimates token counts per provider family and checks
+// requests against model context windows before they are sent.
+//
+// The counts are approximations: providers do not ship their vocabularies
+// with the API, so OpenAI text is split the way its BPE pre-tokenizer does and
+// priced per piece, while Claude, Gemini and Llama use calibrated
+// characters-per-token heuristics. They are meant for budgeting, not billing.
+package tokenizer
+
+import (
+	"strings"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+)
+
+// Family groups models that share a tokenizer
+type Family string
+
+const (
+	FamilyOpenAI  Family = "openai"
+	FamilyClaude  Family = "claude"
+	FamilyGemini  Family = "gemini"
+	FamilyLlama   Family = "llama"
+	FamilyGeneric Family = "generic"
+)
+
+// Tokenizer counts the tokens of a text for one family
+type Tokenizer interface {
+	Family() Family
+	Count(text string) int
+}
+
+// messageOverhead is the per-message framing (role markers, separators) and
+// replyOverhead the tokens priming the assistant reply in chat formats.
+// mediaPartTokens is charged per image or file part, roughly a high-detail
+// image tile grid; the real cost depends on the provider and the media.
+const (
+	messageOverhead = 4
+	replyOverhead   = 3
+	mediaPartTokens = 765
+)
+
+// FamilyOf maps a provider and model to a tokenizer family. The model name
+// wins when it is recognisable, so "ollama" serving "llama3.1" is Llama and
+// "groq" serving "gpt-oss" is OpenAI.
+func FamilyOf(provider, model string) Family {
+	m := strings.ToLower(model)
+	switch {
+	case strings.Contains(m, "claude"):
+		return FamilyClaude
+	case strings.Contains(m, "gemini"), strings.Contains(m, "gemma"):
+		return FamilyGemini
+	case strings.HasPrefix(m, "gpt"), strings.HasPrefix(m, "o1"), strings.HasPrefix(m, "o3"),
+		strings.HasPrefix(m, "o4"), strings.HasPrefix(m, "chatgpt"), strings.HasPrefix(m, "text-embedding"),
+		strings.HasPrefix(m, "deepseek"):
+		return FamilyOpenAI
+	case strings.Contains(m, "llama"), strings.Contains(m, "mistral"), strings.Contains(m, "mixtral"),
+		strings.Contains(m, "qwen"), strings.Contains(m, "phi"):
+		return FamilyLlama
+	}
+
+	switch strings.ToLower(provider) {
+	case "openai", "chatgpt", "deepseek", "azure":
+		return FamilyOpenAI
+	case "claude", "anthropic":
+		return FamilyClaude
+	case "gemini", "google":
+		return FamilyGemini
+	case "ollama", "groq":
+		return FamilyLlama
+	}
+	return FamilyGeneric
+}
+
+// New returns the tokenizer for a family
+func New(family Family) Tokenizer {
+	switch family {
+	case FamilyOpenAI:
+		return bpe{}
+	case FamilyClaude:
+		return heuristic{family: family, charsPerToken: 3.5}
+	case FamilyGemini:
+		return heuristic{family: family, charsPerToken: 4}
+	case FamilyLlama:
+		return heuristic{family: family, charsPerToken: 3.3}
+	default:
+		return heuristic{family: FamilyGeneric, charsPerToken: 4}
+	}
+}
+
+// For returns the tokenizer for a provider and model
+func For(provider, model string) Tokenizer {
+	return New(FamilyOf(provider, model))
+}
+
+// Count estimates the tokens of text for a provider and model
+func Count(provider, model, text string) int {
+	return For(provider, model).Count(text)
+}
+
+// CountMessages estimates the prompt tokens of a chat request, including the
+// framing added around each message.
+func CountMessages(tk Tokenizer, messages []interfaces.Message) int {
+	if len(messages) == 0 {
+		return 0
+	}
+	total := replyOverhead
+	for _, m := range messages {
+		total += messageOverhead + tk.Count(m.Role) + tk.Count(m.Text())
+		for _, p := range m.Parts {
+			if p.Type != interfaces.PartText {
+				total += mediaPartTokens
+			}
+		}
+	}
+	return total
+}
+
+// Usage returns estimated usage for a prompt/completion pair
+func Usage(provider, model, prompt, completion string) *interfaces.Usage {
+	tk := For(provider, model)
+	u := &interfaces.Usage{
+		Prompt:     tk.Count(prompt),
+		Completion: tk.Count(completion),
+		Provider:   provider,
+		Model:      model,
+	}
+	u.Tokens = u.Prompt + u.Completion
+	return u
+}
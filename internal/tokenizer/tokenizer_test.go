@@ -0,0 +1,153 @@
+package tokenizer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+)
+
+func TestFamilyOf(t *testing.T) {
+	cases := []struct {
+		provider, model string
+		want            Family
+	}{
+		{"openai", "gpt-4o-mini", FamilyOpenAI},
+		{"chatgpt", "", FamilyOpenAI},
+		{"deepseek", "deepseek-chat", FamilyOpenAI},
+		{"claude", "", FamilyClaude},
+		{"openrouter", "anthropic/claude-3-haiku", FamilyClaude},
+		{"gemini", "gemini-2.0-flash", FamilyGemini},
+		{"ollama", "llama3.2", FamilyLlama},
+		{"groq", "gpt-oss-20b", FamilyOpenAI},
+		{"custom", "", FamilyGeneric},
+	}
+	for _, c := range cases {
+		if got := FamilyOf(c.provider, c.model); got != c.want {
+			t.Errorf("FamilyOf(%q, %q) = %s, want %s", c.provider, c.model, got, c.want)
+		}
+	}
+}
+
+func TestBPEApproximation(t *testing.T) {
+	tk := New(FamilyOpenAI)
+	// Reference counts from cl100k_base; the approximation should stay close.
+	cases := []struct {
+		text      string
+		want, tol int
+	}{
+		{"", 0, 0},
+		{"Hello, world!", 4, 0},
+		{"The quick brown fox jumps over the lazy dog.", 10, 1},
+		{"12345678", 3, 0},
+		{"internationalization", 3, 1},
+	}
+	for _, c := range cases {
+		got := tk.Count(c.text)
+		if got < c.want-c.tol || got > c.want+c.tol {
+			t.Errorf("Count(%q) = %d, want %d±%d", c.text, got, c.want, c.tol)
+		}
+	}
+}
+
+func TestHeuristicRatios(t *testing.T) {
+	text := strings.Repeat("abcdefghij ", 100) // 1100 ASCII characters
+	claude := New(FamilyClaude).Count(text)
+	gemini := New(FamilyGemini).Count(text)
+	if claude != 315 || gemini != 275 {
+		t.Fatalf("unexpected counts: claude=%d gemini=%d", claude, gemini)
+	}
+	// Wide scripts cost a token per rune
+	if got := New(FamilyLlama).Count("日本語"); got != 3 {
+		t.Fatalf("expected 3 tokens for three CJK runes, got %d", got)
+	}
+}
+
+func TestCountMessages(t *testing.T) {
+	tk := New(FamilyOpenAI)
+	msgs := []interfaces.Message{{Role: "system", Content: "Be brief."}, {Role: "user", Content: "Hi"}}
+	want := replyOverhead + 2*messageOverhead + tk.Count("system") + tk.Count("Be brief.") + tk.Count("user") + tk.Count("Hi")
+	if got := CountMessages(tk, msgs); got != want {
+		t.Fatalf("CountMessages = %d, want %d", got, want)
+	}
+}
+
+func TestContextWindow(t *testing.T) {
+	cases := []struct {
+		provider, model string
+		want            int
+	}{
+		{"openai", "gpt-4o-mini", 128000},
+		{"openai", "gpt-4-0613", 8192},
+		{"openai", "", 128000},
+		{"groq", "meta-llama/llama-3.1-8b-instant", 131072},
+		{"ollama", "llama3", 8192},
+		{"ollama", "my-finetune", 0},
+	}
+	for _, c := range cases {
+		if got := ContextWindow(c.provider, c.model); got != c.want {
+			t.Errorf("ContextWindow(%q, %q) = %d, want %d", c.provider, c.model, got, c.want)
+		}
+	}
+}
+
+func TestFit(t *testing.T) {
+	short := "Summarise the release notes."
+	prompt, maxOutput, est, err := Fit("openai", "gpt-4", short, 100000, PolicyReject)
+	if err != nil || prompt != short {
+		t.Fatalf("short prompt should pass, got %v", err)
+	}
+	if maxOutput != 8192-est.PromptTokens {
+		t.Fatalf("max output should be clamped to the room left, got %d", maxOutput)
+	}
+
+	long := strings.Repeat("lorem ipsum dolor sit amet ", 3000) // ~15k tokens
+	_, _, _, err = Fit("openai", "gpt-4", long, 0, PolicyReject)
+	var ce *ContextError
+	if !errors.As(err, &ce) || ce.ContextWindow != 8192 || ce.PromptTokens <= 8192 {
+		t.Fatalf("expected a ContextError, got %v", err)
+	}
+
+	trimmed, maxOutput, est, err := Fit("openai", "gpt-4", "BEGIN "+long+" END", 1000, PolicyTrim)
+	if err != nil {
+		t.Fatalf("trim should not fail: %v", err)
+	}
+	if est.PromptTokens > 8192-1000 || est.TrimmedTokens == 0 || maxOutput != 1000 {
+		t.Fatalf("unexpected estimate after trimming: %+v (max output %d)", est, maxOutput)
+	}
+	if !strings.HasPrefix(trimmed, "BEGIN ") || !strings.HasSuffix(trimmed, " END") || !strings.Contains(trimmed, "trimmed") {
+		t.Fatalf("trim should keep both ends and mark the cut")
+	}
+
+	// Unknown windows are not checked
+	if _, _, _, err := Fit("ollama", "my-finetune", long, 0, PolicyReject); err != nil {
+		t.Fatalf("unknown window should not be enforced: %v", err)
+	}
+}
+
+func TestTrimMessages(t *testing.T) {
+	tk := New(FamilyOpenAI)
+	long := strings.Repeat("word ", 200)
+	msgs := []interfaces.Message{
+		{Role: "system", Content: "Be brief."},
+		{Role: "user", Content: long},
+		{Role: "assistant", ToolCalls: []interfaces.ToolCall{{ID: "1", Name: "lookup"}}},
+		{Role: "tool", ToolCallID: "1", Content: long},
+		{Role: "assistant", Content: "Done."},
+		{Role: "user", Content: "Thanks"},
+	}
+
+	kept, dropped := TrimMessages(tk, msgs, 100)
+	if dropped != 3 || len(kept) != 3 || kept[0].Role != "system" || kept[1].Content != "Done." {
+		t.Fatalf("unexpected trim: dropped=%d kept=%+v", dropped, kept)
+	}
+	// Dropping the call drops its result too
+	kept, _ = TrimMessages(tk, msgs, CountMessages(tk, msgs)-10)
+	if kept[1].Role == "tool" {
+		t.Fatalf("expected no orphaned tool result, got %+v", kept[1])
+	}
+	if kept, dropped := TrimMessages(tk, msgs, 0); dropped != 0 || len(kept) != len(msgs) {
+		t.Fatal("expected no limit to keep everything")
+	}
+}
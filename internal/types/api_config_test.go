@@ -0,0 +1,22 @@
+package types
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kubex-ecosystem/grompt/internal/metaprompt"
+)
+
+func TestBaseGenerationPromptDefaultsToGeneral(t *testing.T) {
+	t.Setenv("GROMPT_METAPROMPTS_DIR", t.TempDir())
+	c := &Config{}
+	ideas := []string{"weekly newsletter"}
+
+	out := c.GetBaseGenerationPrompt(ideas, "", "", "english", 0)
+	if want := metaprompt.Build(ideas, "", metaprompt.PurposeGeneral, "english", 0); out != want {
+		t.Fatalf("expected the general meta-prompt for an empty purpose type, got:\n%s", out)
+	}
+	if strings.Contains(out, "senior software engineer") {
+		t.Fatalf("expected no code meta-prompt for an empty purpose type, got:\n%s", out)
+	}
+}
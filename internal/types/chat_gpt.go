@@ -2,6 +2,7 @@ package types
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -20,11 +21,12 @@ type ChatGPTRequest struct {
 }
 
 type ChatGPTAPIRequest struct {
-	Model       string           `json:"model"`
-	Messages    []ChatGPTMessage `json:"messages"`
-	MaxTokens   int              `json:"max_tokens"`
-	Temperature float64          `json:"temperature"`
-	Stream      bool             `json:"stream"`
+	Model          string           `json:"model"`
+	Messages       []ChatGPTMessage `json:"messages"`
+	MaxTokens      int              `json:"max_tokens"`
+	Temperature    float64          `json:"temperature"`
+	Stream         bool             `json:"stream"`
+	ResponseFormat map[string]any   `json:"response_format,omitempty"`
 }
 
 type ChatGPTMessage struct {
@@ -73,7 +75,17 @@ func NewChatGPTAPI(apiKey string) interfaces.IAPIConfig {
 	}
 }
 
+// CompleteJSON completes prompt in OpenAI's JSON mode, constrained to schema
+// when one is given.
+func (o *ChatGPTAPI) CompleteJSON(prompt string, maxTokens int, model string, schema map[string]any) (string, error) {
+	return o.complete(prompt, maxTokens, model, openAIResponseFormat(schema))
+}
+
 func (o *ChatGPTAPI) Complete(prompt string, maxTokens int, model string) (string, error) {
+	return o.complete(prompt, maxTokens, model, nil)
+}
+
+func (o *ChatGPTAPI) complete(prompt string, maxTokens int, model string, format map[string]any) (string, error) {
 	if o.apiKey == "" {
 		return "", fmt.Errorf("API key não configurada")
 	}
@@ -91,9 +103,10 @@ func (o *ChatGPTAPI) Complete(prompt string, maxTokens int, model string) (strin
 				Content: prompt,
 			},
 		},
-		MaxTokens:   maxTokens,
-		Temperature: 0.7,
-		Stream:      false,
+		MaxTokens:      maxTokens,
+		Temperature:    0.7,
+		Stream:         false,
+		ResponseFormat: format,
 	}
 
 	jsonData, err := json.Marshal(requestBody)
@@ -141,6 +154,12 @@ func (o *ChatGPTAPI) Complete(prompt string, maxTokens int, model string) (strin
 	return response.Choices[0].Message.Content, nil
 }
 
+// Chat sends the conversation, with its tools and content parts, to the chat
+// completions API, which takes OpenAI's wire format.
+func (o *ChatGPTAPI) Chat(ctx context.Context, req interfaces.ChatRequest) (<-chan interfaces.ChatChunk, error) {
+	return openAIChat(ctx, o.APIConfig, "chatgpt", "ChatGPT", req)
+}
+
 func (o *ChatGPTAPI) IsAvailable() bool {
 	if o.apiKey == "" {
 		return false
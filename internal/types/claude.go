@@ -2,6 +2,7 @@ package types
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -124,6 +125,33 @@ func (o *ClaudeAPI) Complete(prompt string, maxTokens int, model string) (string
 	return response.Choices[0].Message.Content, nil
 }
 
+// Chat sends the conversation, with its tools, to the Anthropic messages
+// API. Tool calls arrive as tool_use blocks and are returned as tool-call
+// chunks after the text.
+func (o *ClaudeAPI) Chat(ctx context.Context, req interfaces.ChatRequest) (<-chan interfaces.ChatChunk, error) {
+	if o.apiKey == "" {
+		return nil, fmt.Errorf("API key não configurada")
+	}
+	model := req.Model
+	if model == "" {
+		model = "claude-3-5-sonnet-latest"
+	}
+
+	var resp anthropicResponse
+	headers := map[string]string{"x-api-key": o.apiKey, "anthropic-version": anthropicVersion}
+	if err := postChat(ctx, o.httpClient, anthropicMessagesURL, headers, newAnthropicRequest(req, model), &resp); err != nil {
+		return nil, fmt.Errorf("claude: %w", err)
+	}
+
+	content, calls := anthropicReply(resp)
+	return chatReply(content, calls, &interfaces.Usage{
+		Prompt:     resp.Usage.InputTokens,
+		Completion: resp.Usage.OutputTokens,
+		Provider:   "claude",
+		Model:      model,
+	}), nil
+}
+
 func (o *ClaudeAPI) IsAvailable() bool {
 	if o.apiKey == "" {
 		return false
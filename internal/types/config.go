@@ -11,6 +11,7 @@ import (
 
 	"github.com/goccy/go-yaml"
 	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+	"github.com/kubex-ecosystem/grompt/internal/metaprompt"
 	"github.com/kubex-ecosystem/logz"
 )
 
@@ -174,28 +175,7 @@ func (c *configImpl) GetAPIEndpoint(provider string) string {
 }
 
 func (c *configImpl) GetBaseGenerationPrompt(ideas []string, purpose, purposeType, lang string, maxLength int) string {
-	var builder strings.Builder
-	builder.WriteString("You are Kubex Grompt assistant.")
-	if purpose != "" {
-		builder.WriteString("Purpose: " + purpose + "")
-	}
-	if purposeType != "" {
-		builder.WriteString("Type: " + purposeType + "")
-	}
-	if lang != "" {
-		builder.WriteString("Language: " + lang + "")
-	}
-	if maxLength > 0 {
-		builder.WriteString(fmt.Sprintf("Limit response to %d characters.", maxLength))
-	}
-	if len(ideas) > 0 {
-		builder.WriteString("Ideas:")
-		for _, idea := range ideas {
-			builder.WriteString("- " + idea + "")
-		}
-	}
-	builder.WriteString("Respond with detailed, actionable output.")
-	return builder.String()
+	return metaprompt.Build(ideas, purpose, purposeType, lang, maxLength)
 }
 
 func (c *configImpl) loadFromEnv() {
@@ -0,0 +1,161 @@
+package types
+
+import (
+	"mime"
+	"path"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+)
+
+// Wire formats of multimodal messages: each API's shape for the content
+// parts of interfaces.Message. Messages without parts are sent as plain text.
+
+// inputCapabilities reports the non-text inputs the chat of api accepts
+func inputCapabilities(api interfaces.IAPIConfig) *interfaces.Capabilities {
+	if a, ok := api.(interfaces.InputAccepter); ok {
+		return a.AcceptedInputs()
+	}
+	return &interfaces.Capabilities{}
+}
+
+// multimodalInputs are the inputs of the APIs whose wire formats below carry
+// images and files
+func multimodalInputs() *interfaces.Capabilities {
+	return &interfaces.Capabilities{SupportsImages: true, SupportsFiles: true}
+}
+
+func (o *OpenAIAPI) AcceptedInputs() *interfaces.Capabilities  { return multimodalInputs() }
+func (o *ChatGPTAPI) AcceptedInputs() *interfaces.Capabilities { return multimodalInputs() }
+func (o *ClaudeAPI) AcceptedInputs() *interfaces.Capabilities  { return multimodalInputs() }
+func (g *GeminiAPI) AcceptedInputs() *interfaces.Capabilities  { return multimodalInputs() }
+
+// openAIContent is the content of a chat completions message: its text, or
+// an array of text, image_url and file parts
+func openAIContent(m interfaces.Message) any {
+	if len(m.Parts) == 0 {
+		return m.Content
+	}
+	parts := make([]map[string]any, 0, len(m.Parts)+1)
+	if m.Content != "" {
+		parts = append(parts, map[string]any{"type": "text", "text": m.Content})
+	}
+	for _, p := range m.Parts {
+		switch p.Type {
+		case interfaces.PartText:
+			parts = append(parts, map[string]any{"type": "text", "text": p.Text})
+		case interfaces.PartImageURL:
+			parts = append(parts, map[string]any{"type": "image_url", "image_url": map[string]any{"url": p.URL}})
+		case interfaces.PartImage:
+			parts = append(parts, map[string]any{"type": "image_url", "image_url": map[string]any{"url": dataURL(p)}})
+		case interfaces.PartFile:
+			file := map[string]any{}
+			if p.FileID != "" {
+				file["file_id"] = p.FileID
+			} else {
+				file["file_data"] = dataURL(p)
+				file["filename"] = fileName(p)
+			}
+			parts = append(parts, map[string]any{"type": "file", "file": file})
+		}
+	}
+	return parts
+}
+
+// openAIText is the text of a chat completions reply
+func openAIText(content any) string {
+	text, _ := content.(string)
+	return text
+}
+
+// anthropicSource is the source of an image or document block
+type anthropicSource struct {
+	Type      string `json:"type"` // base64, url or file
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
+	FileID    string `json:"file_id,omitempty"`
+}
+
+// anthropicContent is the text, image and document blocks of a message
+func anthropicContent(m interfaces.Message) []anthropicBlock {
+	var blocks []anthropicBlock
+	if m.Content != "" {
+		blocks = append(blocks, anthropicBlock{Type: "text", Text: m.Content})
+	}
+	for _, p := range m.Parts {
+		switch p.Type {
+		case interfaces.PartText:
+			blocks = append(blocks, anthropicBlock{Type: "text", Text: p.Text})
+		case interfaces.PartImageURL:
+			blocks = append(blocks, anthropicBlock{Type: "image", Source: &anthropicSource{Type: "url", URL: p.URL}})
+		case interfaces.PartImage:
+			blocks = append(blocks, anthropicBlock{Type: "image", Source: &anthropicSource{Type: "base64", MediaType: p.MimeType, Data: p.Data}})
+		case interfaces.PartFile:
+			source := &anthropicSource{Type: "base64", MediaType: p.MimeType, Data: p.Data}
+			if p.FileID != "" {
+				source = &anthropicSource{Type: "file", FileID: p.FileID}
+			}
+			blocks = append(blocks, anthropicBlock{Type: "document", Source: source})
+		}
+	}
+	return blocks
+}
+
+// geminiBlob is inline media
+type geminiBlob struct {
+	MimeType string `json:"mimeType"`
+	Data     string `json:"data"`
+}
+
+// geminiFileData is media Gemini fetches itself, from the File API or a URL
+type geminiFileData struct {
+	MimeType string `json:"mimeType,omitempty"`
+	FileURI  string `json:"fileUri"`
+}
+
+// geminiContentParts is the text, inlineData and fileData parts of a message
+func geminiContentParts(m interfaces.Message) []geminiPart {
+	var parts []geminiPart
+	if m.Content != "" || (len(m.Parts) == 0 && len(m.ToolCalls) == 0) {
+		parts = append(parts, geminiPart{Text: m.Content})
+	}
+	for _, p := range m.Parts {
+		switch p.Type {
+		case interfaces.PartText:
+			parts = append(parts, geminiPart{Text: p.Text})
+		case interfaces.PartImageURL:
+			parts = append(parts, geminiPart{FileData: &geminiFileData{MimeType: mimeTypeOf(p), FileURI: p.URL}})
+		case interfaces.PartImage:
+			parts = append(parts, geminiPart{InlineData: &geminiBlob{MimeType: p.MimeType, Data: p.Data}})
+		case interfaces.PartFile:
+			if p.FileID != "" {
+				parts = append(parts, geminiPart{FileData: &geminiFileData{MimeType: p.MimeType, FileURI: p.FileID}})
+			} else {
+				parts = append(parts, geminiPart{InlineData: &geminiBlob{MimeType: p.MimeType, Data: p.Data}})
+			}
+		}
+	}
+	return parts
+}
+
+func dataURL(p interfaces.ContentPart) string {
+	return "data:" + p.MimeType + ";base64," + p.Data
+}
+
+func fileName(p interfaces.ContentPart) string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return "document"
+}
+
+// mimeTypeOf is the part's MIME type, guessed from its URL when not given
+func mimeTypeOf(p interfaces.ContentPart) string {
+	if p.MimeType != "" {
+		return p.MimeType
+	}
+	if t := mime.TypeByExtension(path.Ext(p.URL)); t != "" {
+		return t
+	}
+	return "image/jpeg"
+}
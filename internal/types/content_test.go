@@ -0,0 +1,93 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+)
+
+func screenshotMessage() interfaces.Message {
+	return interfaces.Message{Role: "user", Content: "Review this screen.", Parts: []interfaces.ContentPart{
+		{Type: interfaces.PartImage, Data: "iVBORw0KGgo=", MimeType: "image/png"},
+		{Type: interfaces.PartImageURL, URL: "https://example.com/mockup.webp"},
+		{Type: interfaces.PartFile, FileID: "file-123", MimeType: "application/pdf"},
+	}}
+}
+
+func TestMultimodalTranslation(t *testing.T) {
+	msg := screenshotMessage()
+
+	parts, ok := openAIContent(msg).([]map[string]any)
+	if !ok || len(parts) != 4 {
+		t.Fatalf("expected 4 OpenAI content parts, got %v", openAIContent(msg))
+	}
+	if url := parts[1]["image_url"].(map[string]any)["url"]; url != "data:image/png;base64,iVBORw0KGgo=" {
+		t.Fatalf("expected a data URL for the inline image, got %v", url)
+	}
+	if parts[3]["file"].(map[string]any)["file_id"] != "file-123" {
+		t.Fatalf("unexpected file part: %v", parts[3])
+	}
+	if text := openAIContent(interfaces.Message{Content: "plain"}); text != "plain" {
+		t.Fatalf("expected plain messages to stay text, got %v", text)
+	}
+
+	blocks := anthropicContent(msg)
+	if len(blocks) != 4 || blocks[1].Source.Type != "base64" || blocks[2].Source.URL == "" || blocks[3].Type != "document" || blocks[3].Source.FileID != "file-123" {
+		t.Fatalf("unexpected Anthropic blocks: %+v", blocks)
+	}
+
+	gemini := geminiContentParts(msg)
+	if len(gemini) != 4 || gemini[1].InlineData == nil || gemini[2].FileData.MimeType != "image/webp" || gemini[3].FileData.FileURI != "file-123" {
+		t.Fatalf("unexpected Gemini parts: %+v", gemini)
+	}
+}
+
+func TestChatRejectsUnsupportedInputs(t *testing.T) {
+	p := &ProviderImpl{VName: "deepseek", VAPI: NewDeepSeekAPI("key")}
+	_, err := p.Chat(context.Background(), interfaces.ChatRequest{Messages: []interfaces.Message{screenshotMessage()}})
+
+	var unsupported *interfaces.UnsupportedInputError
+	if !errors.As(err, &unsupported) || unsupported.Input != "image" {
+		t.Fatalf("expected an unsupported image error, got %v", err)
+	}
+
+	bad := interfaces.Message{Role: "user", Parts: []interfaces.ContentPart{{Type: interfaces.PartImage}}}
+	if _, err := p.Chat(context.Background(), interfaces.ChatRequest{Messages: []interfaces.Message{bad}}); !errors.Is(err, interfaces.ErrInvalidInput) {
+		t.Fatalf("expected an invalid input error, got %v", err)
+	}
+}
+
+func TestChatGPTAliasAcceptsImages(t *testing.T) {
+	var sent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		raw, _ := json.Marshal(body["messages"])
+		sent = string(raw)
+		_, _ = w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "Looks fine."}}]}`))
+	}))
+	defer srv.Close()
+
+	api := NewChatGPTAPI("key").(*ChatGPTAPI)
+	api.baseURL = srv.URL
+	p := &ProviderImpl{VName: "chatgpt", VAPI: api}
+	if !inputCapabilities(api).SupportsImages {
+		t.Fatalf("expected the chatgpt alias to declare image input")
+	}
+
+	stream, err := p.Chat(context.Background(), interfaces.ChatRequest{Messages: []interfaces.Message{screenshotMessage()}})
+	if err != nil {
+		t.Fatalf("expected the chatgpt alias to accept an image, got %v", err)
+	}
+	for range stream {
+	}
+	if !strings.Contains(sent, "image_url") {
+		t.Fatalf("expected the image to reach the API, sent %s", sent)
+	}
+}
@@ -25,6 +25,7 @@ type DeepSeekAPIRequest struct {
 	MaxTokens   int       `json:"max_tokens"`
 	Temperature float64   `json:"temperature"`
 	Stream      bool      `json:"stream"`
+	ResponseFormat map[string]any `json:"response_format,omitempty"`
 }
 
 type DeepSeekAPIResponse struct {
@@ -56,7 +57,17 @@ func NewDeepSeekAPI(apiKey string) *DeepSeekAPI {
 	}
 }
 
+// CompleteJSON completes prompt in DeepSeek's JSON mode. DeepSeek takes no
+// schema, so it is only enforced by the caller.
+func (o *DeepSeekAPI) CompleteJSON(prompt string, maxTokens int, model string, schema map[string]any) (string, error) {
+	return o.complete(prompt, maxTokens, model, map[string]any{"type": "json_object"})
+}
+
 func (o *DeepSeekAPI) Complete(prompt string, maxTokens int, model string) (string, error) {
+	return o.complete(prompt, maxTokens, model, nil)
+}
+
+func (o *DeepSeekAPI) complete(prompt string, maxTokens int, model string, format map[string]any) (string, error) {
 	if o.apiKey == "" {
 		return "", fmt.Errorf("API key não configurada")
 	}
@@ -77,6 +88,7 @@ func (o *DeepSeekAPI) Complete(prompt string, maxTokens int, model string) (stri
 		MaxTokens:   maxTokens,
 		Temperature: 0.7,
 		Stream:      false,
+		ResponseFormat: format,
 	}
 
 	jsonData, err := json.Marshal(requestBody)
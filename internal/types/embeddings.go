@@ -0,0 +1,173 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+)
+
+// Default embedding models of the APIs that implement interfaces.Embedder
+const (
+	DefaultOpenAIEmbeddingModel = "text-embedding-3-small"
+	DefaultGeminiEmbeddingModel = "text-embedding-004"
+	DefaultOllamaEmbeddingModel = "nomic-embed-text"
+)
+
+// Embed computes embeddings with OpenAI's /v1/embeddings endpoint
+func (o *OpenAIAPI) Embed(ctx context.Context, req interfaces.EmbeddingRequest) (*interfaces.Embeddings, error) {
+	if o.apiKey == "" {
+		return nil, fmt.Errorf("OpenAI API key not configured")
+	}
+	if len(req.Input) == 0 {
+		return &interfaces.Embeddings{Model: req.Model, Vectors: [][]float64{}}, nil
+	}
+	model := req.Model
+	if model == "" {
+		model = DefaultOpenAIEmbeddingModel
+	}
+
+	var resp struct {
+		Model string `json:"model"`
+		Data  []struct {
+			Index     int       `json:"index"`
+			Embedding []float64 `json:"embedding"`
+		} `json:"data"`
+		Usage struct {
+			PromptTokens int `json:"prompt_tokens"`
+			TotalTokens  int `json:"total_tokens"`
+		} `json:"usage"`
+	}
+	url := strings.TrimSuffix(o.baseURL, "/chat/completions") + "/embeddings"
+	headers := map[string]string{"Authorization": "Bearer " + o.apiKey}
+	body := map[string]any{"model": model, "input": req.Input}
+	if err := postChat(ctx, o.httpClient, url, headers, body, &resp); err != nil {
+		return nil, fmt.Errorf("OpenAI embeddings: %w", err)
+	}
+
+	vectors := make([][]float64, len(req.Input))
+	for _, d := range resp.Data {
+		if d.Index < 0 || d.Index >= len(vectors) {
+			return nil, fmt.Errorf("OpenAI embeddings: unexpected index %d", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	if err := checkVectors(vectors); err != nil {
+		return nil, fmt.Errorf("OpenAI embeddings: %w", err)
+	}
+	return &interfaces.Embeddings{
+		Model:   model,
+		Vectors: vectors,
+		Usage:   &interfaces.Usage{Prompt: resp.Usage.PromptTokens, Tokens: resp.Usage.TotalTokens, Provider: "openai", Model: model},
+	}, nil
+}
+
+// Embed computes embeddings with Gemini's batchEmbedContents method
+func (g *GeminiAPI) Embed(ctx context.Context, req interfaces.EmbeddingRequest) (*interfaces.Embeddings, error) {
+	if g.apiKey == "" {
+		return nil, fmt.Errorf("gemini API key not configured")
+	}
+	if len(req.Input) == 0 {
+		return &interfaces.Embeddings{Model: req.Model, Vectors: [][]float64{}}, nil
+	}
+	model := strings.TrimPrefix(req.Model, "models/")
+	if model == "" {
+		model = DefaultGeminiEmbeddingModel
+	}
+
+	requests := make([]map[string]any, len(req.Input))
+	for i, text := range req.Input {
+		requests[i] = map[string]any{
+			"model":   "models/" + model,
+			"content": map[string]any{"parts": []map[string]any{{"text": text}}},
+		}
+	}
+	var resp struct {
+		Embeddings []struct {
+			Values []float64 `json:"values"`
+		} `json:"embeddings"`
+	}
+	url := fmt.Sprintf("%s%s/models/%s:batchEmbedContents?key=%s", g.baseURL, g.Version(), model, g.apiKey)
+	if err := postChat(ctx, g.httpClient, url, nil, map[string]any{"requests": requests}, &resp); err != nil {
+		return nil, fmt.Errorf("gemini embeddings: %w", err)
+	}
+	if len(resp.Embeddings) != len(req.Input) {
+		return nil, fmt.Errorf("gemini embeddings: got %d vectors for %d inputs", len(resp.Embeddings), len(req.Input))
+	}
+
+	vectors := make([][]float64, len(resp.Embeddings))
+	for i, e := range resp.Embeddings {
+		vectors[i] = e.Values
+	}
+	if err := checkVectors(vectors); err != nil {
+		return nil, fmt.Errorf("gemini embeddings: %w", err)
+	}
+	return &interfaces.Embeddings{Model: model, Vectors: vectors, Usage: &interfaces.Usage{Provider: "gemini", Model: model}}, nil
+}
+
+// Embed computes embeddings with Ollama's /api/embed endpoint
+func (o *OllamaAPI) Embed(ctx context.Context, req interfaces.EmbeddingRequest) (*interfaces.Embeddings, error) {
+	if len(req.Input) == 0 {
+		return &interfaces.Embeddings{Model: req.Model, Vectors: [][]float64{}}, nil
+	}
+	model := req.Model
+	if model == "" {
+		model = DefaultOllamaEmbeddingModel
+	}
+
+	var resp struct {
+		Model           string      `json:"model"`
+		Embeddings      [][]float64 `json:"embeddings"`
+		PromptEvalCount int         `json:"prompt_eval_count"`
+	}
+	url := strings.TrimSuffix(o.baseURL, "/") + "/api/embed"
+	if err := postChat(ctx, o.httpClient, url, nil, map[string]any{"model": model, "input": req.Input}, &resp); err != nil {
+		return nil, fmt.Errorf("ollama embeddings: %w", err)
+	}
+	if len(resp.Embeddings) != len(req.Input) {
+		return nil, fmt.Errorf("ollama embeddings: got %d vectors for %d inputs", len(resp.Embeddings), len(req.Input))
+	}
+	if err := checkVectors(resp.Embeddings); err != nil {
+		return nil, fmt.Errorf("ollama embeddings: %w", err)
+	}
+	return &interfaces.Embeddings{
+		Model:   model,
+		Vectors: resp.Embeddings,
+		Usage:   &interfaces.Usage{Prompt: resp.PromptEvalCount, Tokens: resp.PromptEvalCount, Provider: "ollama", Model: model},
+	}, nil
+}
+
+// checkVectors rejects missing vectors and vectors of different dimensions
+func checkVectors(vectors [][]float64) error {
+	for i, v := range vectors {
+		if len(v) == 0 {
+			return fmt.Errorf("no vector for input %d", i)
+		}
+		if len(v) != len(vectors[0]) {
+			return fmt.Errorf("input %d has %d dimensions, expected %d", i, len(v), len(vectors[0]))
+		}
+	}
+	return nil
+}
+
+// Embed computes embeddings with the provider's API, when it has one
+func (cp *ProviderImpl) Embed(ctx context.Context, req interfaces.EmbeddingRequest) (*interfaces.Embeddings, error) {
+	if cp == nil || cp.VAPI == nil {
+		return nil, fmt.Errorf("provider is not available")
+	}
+	embedder, ok := cp.VAPI.(interfaces.Embedder)
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", cp.VName, interfaces.ErrEmbeddingsUnsupported)
+	}
+	return embedder.Embed(ctx, req)
+}
+
+// SupportsEmbeddings reports whether the provider's API computes embeddings
+func (cp *ProviderImpl) SupportsEmbeddings() bool {
+	if cp == nil || cp.VAPI == nil {
+		return false
+	}
+	_, ok := cp.VAPI.(interfaces.Embedder)
+	return ok
+}
@@ -0,0 +1,94 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+)
+
+// embeddingServer answers every request with reply and records the path and
+// body of the last one
+func embeddingServer(t *testing.T, reply string, path *string, body *map[string]any) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*path = r.URL.Path
+		json.NewDecoder(r.Body).Decode(body)
+		w.Write([]byte(reply))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestOpenAIEmbed(t *testing.T) {
+	var path string
+	var body map[string]any
+	srv := embeddingServer(t, `{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}],"usage":{"prompt_tokens":4,"total_tokens":4}}`, &path, &body)
+	api := NewOpenAIAPI("sk-test")
+	api.baseURL = srv.URL + "/v1/chat/completions"
+
+	res, err := api.Embed(context.Background(), interfaces.EmbeddingRequest{Input: []string{"a", "b"}})
+	if err != nil {
+		t.Fatalf("Embed returned error: %v", err)
+	}
+	if path != "/v1/embeddings" || body["model"] != DefaultOpenAIEmbeddingModel {
+		t.Fatalf("unexpected request %s %v", path, body)
+	}
+	if res.Vectors[0][0] != 1 || res.Vectors[1][1] != 1 || res.Usage.Prompt != 4 {
+		t.Fatalf("expected vectors in input order, got %+v", res)
+	}
+}
+
+func TestGeminiEmbed(t *testing.T) {
+	var path string
+	var body map[string]any
+	srv := embeddingServer(t, `{"embeddings":[{"values":[0.5,0.5]}]}`, &path, &body)
+	api := NewGeminiAPI("key")
+	api.baseURL = srv.URL + "/"
+
+	res, err := api.Embed(context.Background(), interfaces.EmbeddingRequest{Input: []string{"a"}})
+	if err != nil {
+		t.Fatalf("Embed returned error: %v", err)
+	}
+	if path != "/v1beta/models/"+DefaultGeminiEmbeddingModel+":batchEmbedContents" || len(body["requests"].([]any)) != 1 {
+		t.Fatalf("unexpected request %s %v", path, body)
+	}
+	if res.Model != DefaultGeminiEmbeddingModel || len(res.Vectors) != 1 || len(res.Vectors[0]) != 2 {
+		t.Fatalf("unexpected embeddings %+v", res)
+	}
+
+	if _, err := api.Embed(context.Background(), interfaces.EmbeddingRequest{Input: []string{"a", "b"}}); err == nil {
+		t.Fatal("expected a short batch to be an error")
+	}
+}
+
+func TestOllamaEmbedThroughProvider(t *testing.T) {
+	var path string
+	var body map[string]any
+	srv := embeddingServer(t, `{"embeddings":[[1,2,3]],"prompt_eval_count":2}`, &path, &body)
+	p := &ProviderImpl{VName: "ollama", VAPI: NewOllamaAPI(srv.URL)}
+
+	embedder, ok := interfaces.EmbedderOf(p)
+	if !ok {
+		t.Fatal("expected the ollama provider to compute embeddings")
+	}
+	res, err := embedder.Embed(context.Background(), interfaces.EmbeddingRequest{Model: "all-minilm", Input: []string{"a"}})
+	if err != nil {
+		t.Fatalf("Embed returned error: %v", err)
+	}
+	if path != "/api/embed" || body["model"] != "all-minilm" || len(res.Vectors[0]) != 3 {
+		t.Fatalf("unexpected round trip %s %v %+v", path, body, res)
+	}
+
+	claude := &ProviderImpl{VName: "claude", VAPI: NewClaudeAPI("key")}
+	if _, ok := interfaces.EmbedderOf(claude); ok {
+		t.Fatal("expected claude to have no embeddings")
+	}
+	if _, err := claude.Embed(context.Background(), interfaces.EmbeddingRequest{Input: []string{"a"}}); !errors.Is(err, interfaces.ErrEmbeddingsUnsupported) {
+		t.Fatalf("expected ErrEmbeddingsUnsupported, got %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package types
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -22,8 +23,9 @@ type GeminiRequest struct {
 		} `json:"parts"`
 	} `json:"contents"`
 	GenerationConfig struct {
-		MaxOutputTokens int     `json:"maxOutputTokens,omitempty"`
-		Temperature     float64 `json:"temperature,omitempty"`
+		MaxOutputTokens  int     `json:"maxOutputTokens,omitempty"`
+		Temperature      float64 `json:"temperature,omitempty"`
+		ResponseMimeType string  `json:"responseMimeType,omitempty"`
 	} `json:"generationConfig,omitempty"`
 }
 
@@ -66,6 +68,17 @@ func NewGeminiAPI(apiKey string) *GeminiAPI {
 
 // Complete sends a completion request to the Gemini API
 func (g *GeminiAPI) Complete(prompt string, maxTokens int, model string) (string, error) {
+	return g.complete(prompt, maxTokens, model, "")
+}
+
+// CompleteJSON completes prompt with a JSON response MIME type. Gemini's
+// responseSchema only takes an OpenAPI subset, so schema is left to the
+// caller to enforce.
+func (g *GeminiAPI) CompleteJSON(prompt string, maxTokens int, model string, schema map[string]any) (string, error) {
+	return g.complete(prompt, maxTokens, model, "application/json")
+}
+
+func (g *GeminiAPI) complete(prompt string, maxTokens int, model, mimeType string) (string, error) {
 	if g.apiKey == "" {
 		gl.Log("debug", "Gemini API key not configured")
 		return "", fmt.Errorf("gemini API key not configured")
@@ -104,6 +117,7 @@ func (g *GeminiAPI) Complete(prompt string, maxTokens int, model string) (string
 		requestBody.GenerationConfig.MaxOutputTokens = maxTokens
 		requestBody.GenerationConfig.Temperature = 0.7
 	}
+	requestBody.GenerationConfig.ResponseMimeType = mimeType
 
 	jsonData, err := json.Marshal(requestBody)
 	if err != nil {
@@ -159,6 +173,36 @@ func (g *GeminiAPI) Complete(prompt string, maxTokens int, model string) (string
 	return response.Candidates[0].Content.Parts[0].Text, nil
 }
 
+// Chat sends the conversation, with its tools as functionDeclarations, to
+// the Gemini API. Function calls are returned as tool-call chunks.
+func (g *GeminiAPI) Chat(ctx context.Context, req interfaces.ChatRequest) (<-chan interfaces.ChatChunk, error) {
+	if g.apiKey == "" {
+		return nil, fmt.Errorf("gemini API key not configured")
+	}
+	model := req.Model
+	if model == "" {
+		model = "gemini-2.0-flash"
+	}
+
+	url := fmt.Sprintf("https://generativelanguage.googleapis.com/%s/models/%s:generateContent?key=%s", g.Version(), model, g.apiKey)
+	var resp geminiChatResponse
+	if err := postChat(ctx, g.httpClient, url, nil, newGeminiChatRequest(req), &resp); err != nil {
+		gl.Log("error", fmt.Sprintf("Gemini chat error: %v", err))
+		return nil, fmt.Errorf("gemini: %w", err)
+	}
+	if len(resp.Candidates) == 0 {
+		return nil, fmt.Errorf("no response generated from Gemini API")
+	}
+
+	content, calls := geminiReply(resp)
+	return chatReply(content, calls, &interfaces.Usage{
+		Prompt:     resp.UsageMetadata.PromptTokenCount,
+		Completion: resp.UsageMetadata.CandidatesTokenCount,
+		Provider:   "gemini",
+		Model:      model,
+	}), nil
+}
+
 // IsAvailable checks if the Gemini API is available
 func (g *GeminiAPI) IsAvailable() bool {
 	if g == nil {
@@ -17,6 +17,7 @@ type OllamaRequest struct {
 	Model  string `json:"model"`
 	Prompt string `json:"prompt"`
 	Stream bool   `json:"stream"`
+	Format any    `json:"format,omitempty"` // "json" or a JSON Schema
 }
 
 type OllamaResponse struct {
@@ -35,13 +36,28 @@ func NewOllamaAPI(baseURL string) *OllamaAPI {
 	}
 }
 
+// CompleteJSON completes prompt in Ollama's structured output mode,
+// constrained to schema when one is given.
+func (o *OllamaAPI) CompleteJSON(prompt string, maxTokens int, model string, schema map[string]any) (string, error) {
+	var format any = "json"
+	if schema != nil {
+		format = schema
+	}
+	return o.complete(prompt, maxTokens, model, format)
+}
+
 func (o *OllamaAPI) Complete(prompt string, stream int, model string) (string, error) {
+	return o.complete(prompt, stream, model, nil)
+}
+
+func (o *OllamaAPI) complete(prompt string, stream int, model string, format any) (string, error) {
 	endpoint := fmt.Sprintf("%s/api/generate", o.baseURL)
 
 	requestBody := OllamaRequest{
 		Model:  model,
 		Prompt: prompt,
 		Stream: false,
+		Format: format,
 	}
 
 	jsonData, err := json.Marshal(requestBody)
@@ -27,6 +27,7 @@ type OpenAIAPIRequest struct {
 	MaxTokens   int       `json:"max_tokens"`
 	Temperature float64   `json:"temperature"`
 	Stream      bool      `json:"stream"`
+	ResponseFormat map[string]any `json:"response_format,omitempty"`
 }
 
 type OpenAIAPIResponse struct {
@@ -64,7 +65,17 @@ func NewOpenAIAPI(apiKey string) *OpenAIAPI {
 	}
 }
 
+// CompleteJSON completes prompt in OpenAI's JSON mode, constrained to schema
+// when one is given.
+func (o *OpenAIAPI) CompleteJSON(prompt string, maxTokens int, model string, schema map[string]any) (string, error) {
+	return o.complete(prompt, maxTokens, model, openAIResponseFormat(schema))
+}
+
 func (o *OpenAIAPI) Complete(prompt string, maxTokens int, model string) (string, error) {
+	return o.complete(prompt, maxTokens, model, nil)
+}
+
+func (o *OpenAIAPI) complete(prompt string, maxTokens int, model string, format map[string]any) (string, error) {
 	if o.apiKey == "" {
 		return "", fmt.Errorf("API key não configurada")
 	}
@@ -85,6 +96,7 @@ func (o *OpenAIAPI) Complete(prompt string, maxTokens int, model string) (string
 		MaxTokens:   maxTokens,
 		Temperature: 0.7,
 		Stream:      false,
+		ResponseFormat: format,
 	}
 
 	jsonData, err := json.Marshal(requestBody)
@@ -132,6 +144,18 @@ func (o *OpenAIAPI) Complete(prompt string, maxTokens int, model string) (string
 	return response.Choices[0].Message.Content, nil
 }
 
+// openAIResponseFormat is the response_format of OpenAI-compatible APIs:
+// a JSON Schema when given, otherwise any JSON object
+func openAIResponseFormat(schema map[string]any) map[string]any {
+	if schema == nil {
+		return map[string]any{"type": "json_object"}
+	}
+	return map[string]any{
+		"type":        "json_schema",
+		"json_schema": map[string]any{"name": "response", "schema": schema},
+	}
+}
+
 func (o *OpenAIAPI) IsAvailable() bool {
 	if o.apiKey == "" {
 		return false
@@ -244,8 +268,11 @@ func (o *OpenAIAPI) GetBaseURL() string { return o.baseURL }
 
 func (o *OpenAIAPI) GetAPIKey() string { return o.apiKey }
 
+// Chat sends the conversation, with its tools, to the chat completions API.
+// The reply is not streamed: it arrives as its text, its tool calls and the
+// done chunk.
 func (o *OpenAIAPI) Chat(ctx context.Context, req interfaces.ChatRequest) (<-chan interfaces.ChatChunk, error) {
-	return nil, fmt.Errorf("chat streaming não implementado para OpenAIAPI")
+	return openAIChat(ctx, o.APIConfig, "openai", "OpenAI", req)
 }
 
 func (o *OpenAIAPI) Execute(ctx context.Context, template string, vars map[string]any) (*interfaces.Result, error) {
@@ -282,6 +309,8 @@ func (o *OpenAIAPI) GetCapabilities(ctx context.Context) *interfaces.Capabilitie
 		MaxTokens:         4096,
 		SupportsBatch:     false,
 		SupportsStreaming: true,
+		SupportsImages:    true,
+		SupportsFiles:     true,
 		Models:            models,
 	}
 }
@@ -28,11 +28,20 @@ func (cp *ProviderImpl) Version() string {
 	return cp.VVersion
 }
 
-// Execute sends a prompt to the provider and returns the response
+// Execute sends a prompt to the provider and returns the response. A
+// vars["json_schema"] switches APIs with a native JSON mode into it.
 func (cp *ProviderImpl) Execute(ctx context.Context, prompt string, vars map[string]any) (*interfaces.Result, error) {
 	if cp == nil || cp.VAPI == nil {
 		return nil, fmt.Errorf("provider is not available")
 	}
+	if native, ok := cp.VAPI.(interfaces.JSONCompleter); ok && vars["json_schema"] != nil {
+		schema, _ := vars["json_schema"].(map[string]any)
+		response, err := native.CompleteJSON(prompt, 2048, "", schema)
+		if err != nil {
+			return nil, err
+		}
+		return &interfaces.Result{Response: response}, nil
+	}
 	response, err := cp.VAPI.Complete(prompt, 2048, "") // Default max tokens
 	if err != nil {
 		return nil, err
@@ -97,6 +106,9 @@ func (cp *ProviderImpl) GetCapabilities(ctx context.Context) *interfaces.Capabil
 		SupportsStreaming: false, // For now, streaming is not implemented
 		Models:            models,
 		Pricing:           getPricingForProvider(cp.VName),
+		SupportsImages:    inputCapabilities(cp.VAPI).SupportsImages,
+		SupportsFiles:     inputCapabilities(cp.VAPI).SupportsFiles,
+		SupportsEmbeddings: cp.SupportsEmbeddings(),
 	}
 }
 
@@ -105,11 +117,24 @@ func (cp *ProviderImpl) Chat(ctx context.Context, req interfaces.ChatRequest) (<
 	if cp == nil || cp.VAPI == nil {
 		return nil, fmt.Errorf("provider is not available")
 	}
-	if _, ok := cp.VAPI.(interfaces.Provider); !ok {
+	if err := interfaces.CheckInputs(cp.VName, inputCapabilities(cp.VAPI), req.Messages); err != nil {
+		return nil, err
+	}
+	chatter, ok := cp.VAPI.(interfaces.Chatter)
+	if !ok {
 		return nil, fmt.Errorf("provider does not support chat")
 	}
-	chatProvider := cp.VAPI.(interfaces.Provider)
-	return chatProvider.Chat(ctx, req)
+	return chatter.Chat(ctx, req)
+}
+
+// SupportsChat reports whether the provider's API holds chats; see
+// interfaces.CanChat
+func (cp *ProviderImpl) SupportsChat() bool {
+	if cp == nil || cp.VAPI == nil {
+		return false
+	}
+	_, ok := cp.VAPI.(interfaces.Chatter)
+	return ok
 }
 
 // Notify sends a notification event to the provider if supported
@@ -141,6 +166,12 @@ func getMaxTokensForProvider(name string) int {
 	}
 }
 
+// PricingForProvider returns the built-in per-1K token pricing of a provider,
+// or nil when it is unknown
+func PricingForProvider(name string) *interfaces.Pricing {
+	return getPricingForProvider(name)
+}
+
 func getPricingForProvider(name string) *interfaces.Pricing {
 	switch name {
 	case "openai":
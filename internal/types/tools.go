@@ -0,0 +1,522 @@
+package types
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+)
+
+// Wire formats of multi-turn chat with tools. Each API translates the
+// provider-neutral interfaces.ChatRequest into its own shape and its tool
+// calls back into interfaces.ToolCall.
+
+const (
+	anthropicMessagesURL = "https://api.anthropic.com/v1/messages"
+	anthropicVersion     = "2023-06-01"
+
+	// defaultChatMaxTokens is used when ChatRequest.Meta has no "max_tokens"
+	defaultChatMaxTokens = 2048
+)
+
+// OpenAI chat completions
+
+type openAIChatRequest struct {
+	Model          string              `json:"model"`
+	Messages       []openAIChatMessage `json:"messages"`
+	MaxTokens      int                 `json:"max_tokens,omitempty"`
+	Temperature    float32             `json:"temperature,omitempty"`
+	Tools          []openAITool        `json:"tools,omitempty"`
+	ToolChoice     any                 `json:"tool_choice,omitempty"`
+	ResponseFormat map[string]any      `json:"response_format,omitempty"`
+}
+
+type openAIChatMessage struct {
+	Role       string           `json:"role"`
+	Content    any              `json:"content"` // text, or content parts
+	ToolCalls  []openAIToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+}
+
+type openAITool struct {
+	Type     string         `json:"type"`
+	Function openAIFunction `json:"function"`
+}
+
+type openAIFunction struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  map[string]any `json:"parameters"`
+}
+
+type openAIToolCall struct {
+	ID       string             `json:"id"`
+	Type     string             `json:"type"`
+	Function openAIFunctionCall `json:"function"`
+}
+
+type openAIFunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+type openAIChatResponse struct {
+	Choices []struct {
+		Message      openAIChatMessage `json:"message"`
+		FinishReason string            `json:"finish_reason"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+func newOpenAIChatRequest(req interfaces.ChatRequest, model string) openAIChatRequest {
+	out := openAIChatRequest{
+		Model:       model,
+		MaxTokens:   ChatMaxTokens(req),
+		Temperature: req.Temp,
+	}
+	for _, m := range req.Messages {
+		msg := openAIChatMessage{Role: m.Role, Content: openAIContent(m), ToolCallID: m.ToolCallID}
+		for _, call := range m.ToolCalls {
+			msg.ToolCalls = append(msg.ToolCalls, openAIToolCall{
+				ID:       call.ID,
+				Type:     "function",
+				Function: openAIFunctionCall{Name: call.Name, Arguments: string(toolArguments(call.Arguments))},
+			})
+		}
+		out.Messages = append(out.Messages, msg)
+	}
+	for _, t := range req.Tools {
+		out.Tools = append(out.Tools, openAITool{
+			Type:     "function",
+			Function: openAIFunction{Name: t.Name, Description: t.Description, Parameters: toolParameters(t)},
+		})
+	}
+	if len(out.Tools) > 0 {
+		switch req.ToolChoice {
+		case "":
+		case "auto", "none", "required":
+			out.ToolChoice = req.ToolChoice
+		default:
+			out.ToolChoice = map[string]any{"type": "function", "function": map[string]any{"name": req.ToolChoice}}
+		}
+	}
+	if schema, ok := req.Meta["json_schema"].(map[string]any); ok {
+		out.ResponseFormat = openAIResponseFormat(schema)
+	}
+	return out
+}
+
+func openAIToolCalls(calls []openAIToolCall) []interfaces.ToolCall {
+	out := make([]interfaces.ToolCall, 0, len(calls))
+	for _, call := range calls {
+		out = append(out, interfaces.ToolCall{
+			ID:        call.ID,
+			Name:      call.Function.Name,
+			Arguments: toolArguments(json.RawMessage(call.Function.Arguments)),
+		})
+	}
+	return out
+}
+
+// Anthropic messages
+
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature float32            `json:"temperature,omitempty"`
+	Tools       []anthropicTool    `json:"tools,omitempty"`
+	ToolChoice  map[string]any     `json:"tool_choice,omitempty"`
+}
+
+type anthropicMessage struct {
+	Role    string           `json:"role"`
+	Content []anthropicBlock `json:"content"`
+}
+
+// anthropicBlock is a text, tool_use or tool_result content block
+type anthropicBlock struct {
+	Type      string           `json:"type"`
+	Text      string           `json:"text,omitempty"`
+	ID        string           `json:"id,omitempty"`
+	Name      string           `json:"name,omitempty"`
+	Input     json.RawMessage  `json:"input,omitempty"`
+	ToolUseID string           `json:"tool_use_id,omitempty"`
+	Content   string           `json:"content,omitempty"`
+	Source    *anthropicSource `json:"source,omitempty"`
+}
+
+type anthropicTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	InputSchema map[string]any `json:"input_schema"`
+}
+
+type anthropicResponse struct {
+	Content    []anthropicBlock `json:"content"`
+	StopReason string           `json:"stop_reason"`
+	Usage      struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+func newAnthropicRequest(req interfaces.ChatRequest, model string) anthropicRequest {
+	out := anthropicRequest{
+		Model:       model,
+		MaxTokens:   ChatMaxTokens(req),
+		Temperature: req.Temp,
+	}
+	var system []string
+	for _, m := range req.Messages {
+		role := m.Role
+		var blocks []anthropicBlock
+		switch m.Role {
+		case "system":
+			system = append(system, m.Text())
+			continue
+		case "tool":
+			// Tool results are user content in the messages API
+			role = "user"
+			blocks = append(blocks, anthropicBlock{Type: "tool_result", ToolUseID: m.ToolCallID, Content: m.Content})
+		default:
+			blocks = anthropicContent(m)
+			for _, call := range m.ToolCalls {
+				blocks = append(blocks, anthropicBlock{Type: "tool_use", ID: call.ID, Name: call.Name, Input: toolArguments(call.Arguments)})
+			}
+		}
+		// Roles must alternate, so consecutive turns of one role are merged
+		if n := len(out.Messages); n > 0 && out.Messages[n-1].Role == role {
+			out.Messages[n-1].Content = append(out.Messages[n-1].Content, blocks...)
+			continue
+		}
+		out.Messages = append(out.Messages, anthropicMessage{Role: role, Content: blocks})
+	}
+	out.System = strings.Join(system, "\n\n")
+
+	for _, t := range req.Tools {
+		out.Tools = append(out.Tools, anthropicTool{Name: t.Name, Description: t.Description, InputSchema: toolParameters(t)})
+	}
+	if len(out.Tools) > 0 {
+		switch req.ToolChoice {
+		case "":
+		case "auto", "none":
+			out.ToolChoice = map[string]any{"type": req.ToolChoice}
+		case "required":
+			out.ToolChoice = map[string]any{"type": "any"}
+		default:
+			out.ToolChoice = map[string]any{"type": "tool", "name": req.ToolChoice}
+		}
+	}
+	return out
+}
+
+func anthropicReply(resp anthropicResponse) (string, []interfaces.ToolCall) {
+	var text strings.Builder
+	var calls []interfaces.ToolCall
+	for _, block := range resp.Content {
+		switch block.Type {
+		case "text":
+			text.WriteString(block.Text)
+		case "tool_use":
+			calls = append(calls, interfaces.ToolCall{ID: block.ID, Name: block.Name, Arguments: toolArguments(block.Input)})
+		}
+	}
+	return text.String(), calls
+}
+
+// Gemini generateContent
+
+type geminiChatRequest struct {
+	Contents          []geminiContent   `json:"contents"`
+	SystemInstruction *geminiContent    `json:"systemInstruction,omitempty"`
+	Tools             []geminiTool      `json:"tools,omitempty"`
+	ToolConfig        *geminiToolConfig `json:"toolConfig,omitempty"`
+	GenerationConfig  map[string]any    `json:"generationConfig,omitempty"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiPart struct {
+	Text             string                  `json:"text,omitempty"`
+	InlineData       *geminiBlob             `json:"inlineData,omitempty"`
+	FileData         *geminiFileData         `json:"fileData,omitempty"`
+	FunctionCall     *geminiFunctionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *geminiFunctionResponse `json:"functionResponse,omitempty"`
+}
+
+type geminiFunctionCall struct {
+	Name string          `json:"name"`
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+type geminiFunctionResponse struct {
+	Name     string         `json:"name"`
+	Response map[string]any `json:"response"`
+}
+
+type geminiTool struct {
+	FunctionDeclarations []geminiFunctionDeclaration `json:"functionDeclarations"`
+}
+
+type geminiFunctionDeclaration struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  map[string]any `json:"parameters,omitempty"`
+}
+
+type geminiToolConfig struct {
+	FunctionCallingConfig struct {
+		Mode                 string   `json:"mode"`
+		AllowedFunctionNames []string `json:"allowedFunctionNames,omitempty"`
+	} `json:"functionCallingConfig"`
+}
+
+type geminiChatResponse struct {
+	Candidates []struct {
+		Content      geminiContent `json:"content"`
+		FinishReason string        `json:"finishReason"`
+	} `json:"candidates"`
+	UsageMetadata struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+	} `json:"usageMetadata"`
+}
+
+func newGeminiChatRequest(req interfaces.ChatRequest) geminiChatRequest {
+	out := geminiChatRequest{
+		GenerationConfig: map[string]any{"maxOutputTokens": ChatMaxTokens(req)},
+	}
+	if req.Temp > 0 {
+		out.GenerationConfig["temperature"] = req.Temp
+	}
+	if req.Meta["json_schema"] != nil {
+		out.GenerationConfig["responseMimeType"] = "application/json"
+	}
+
+	// Function responses are matched by name, which the neutral format keeps
+	// on the call they answer
+	names := map[string]string{}
+	var system []geminiPart
+	for _, m := range req.Messages {
+		role := "user"
+		var parts []geminiPart
+		switch m.Role {
+		case "system":
+			system = append(system, geminiPart{Text: m.Text()})
+			continue
+		case "tool":
+			parts = append(parts, geminiPart{FunctionResponse: &geminiFunctionResponse{
+				Name:     names[m.ToolCallID],
+				Response: geminiToolResponse(m.Content),
+			}})
+		case "assistant":
+			role = "model"
+			parts = geminiContentParts(m)
+			for _, call := range m.ToolCalls {
+				names[call.ID] = call.Name
+				parts = append(parts, geminiPart{FunctionCall: &geminiFunctionCall{Name: call.Name, Args: toolArguments(call.Arguments)}})
+			}
+		default:
+			parts = geminiContentParts(m)
+		}
+		if n := len(out.Contents); n > 0 && out.Contents[n-1].Role == role {
+			out.Contents[n-1].Parts = append(out.Contents[n-1].Parts, parts...)
+			continue
+		}
+		out.Contents = append(out.Contents, geminiContent{Role: role, Parts: parts})
+	}
+	if len(system) > 0 {
+		out.SystemInstruction = &geminiContent{Parts: system}
+	}
+
+	if len(req.Tools) > 0 {
+		declarations := make([]geminiFunctionDeclaration, 0, len(req.Tools))
+		for _, t := range req.Tools {
+			declarations = append(declarations, geminiFunctionDeclaration{Name: t.Name, Description: t.Description, Parameters: t.Parameters})
+		}
+		out.Tools = []geminiTool{{FunctionDeclarations: declarations}}
+
+		if req.ToolChoice != "" {
+			config := &geminiToolConfig{}
+			switch req.ToolChoice {
+			case "auto", "none":
+				config.FunctionCallingConfig.Mode = strings.ToUpper(req.ToolChoice)
+			case "required":
+				config.FunctionCallingConfig.Mode = "ANY"
+			default:
+				config.FunctionCallingConfig.Mode = "ANY"
+				config.FunctionCallingConfig.AllowedFunctionNames = []string{req.ToolChoice}
+			}
+			out.ToolConfig = config
+		}
+	}
+	return out
+}
+
+// geminiToolResponse wraps a tool result in the object Gemini expects,
+// keeping JSON object results as they are
+func geminiToolResponse(content string) map[string]any {
+	var object map[string]any
+	if err := json.Unmarshal([]byte(content), &object); err == nil {
+		return object
+	}
+	return map[string]any{"content": content}
+}
+
+func geminiReply(resp geminiChatResponse) (string, []interfaces.ToolCall) {
+	if len(resp.Candidates) == 0 {
+		return "", nil
+	}
+	var text strings.Builder
+	var calls []interfaces.ToolCall
+	for _, part := range resp.Candidates[0].Content.Parts {
+		text.WriteString(part.Text)
+		if part.FunctionCall != nil {
+			// Gemini does not identify its calls; results are matched by name
+			calls = append(calls, interfaces.ToolCall{
+				ID:        "call_" + uuid.NewString(),
+				Name:      part.FunctionCall.Name,
+				Arguments: toolArguments(part.FunctionCall.Args),
+			})
+		}
+	}
+	return text.String(), calls
+}
+
+// Shared helpers
+
+// toolParameters is the argument schema of t; APIs that require one get an
+// empty object schema for tools without arguments
+func toolParameters(t interfaces.Tool) map[string]any {
+	if t.Parameters != nil {
+		return t.Parameters
+	}
+	return map[string]any{"type": "object", "properties": map[string]any{}}
+}
+
+// toolArguments normalises empty arguments to an empty JSON object
+func toolArguments(args json.RawMessage) json.RawMessage {
+	if len(bytes.TrimSpace(args)) == 0 {
+		return json.RawMessage("{}")
+	}
+	return args
+}
+
+// ChatMaxTokens is the completion limit of a chat: ChatRequest.Meta["max_tokens"],
+// or a default when it is unset
+func ChatMaxTokens(req interfaces.ChatRequest) int {
+	switch v := req.Meta["max_tokens"].(type) {
+	case int:
+		if v > 0 {
+			return v
+		}
+	case float64:
+		if v > 0 {
+			return int(v)
+		}
+	}
+	return defaultChatMaxTokens
+}
+
+// openAIChat sends req to the chat completions endpoint of api, the wire
+// format of OpenAI and the APIs compatible with it. Usage is reported for
+// provider and errors are prefixed with label.
+func openAIChat(ctx context.Context, api *APIConfig, provider, label string, req interfaces.ChatRequest) (<-chan interfaces.ChatChunk, error) {
+	if api.apiKey == "" {
+		return nil, fmt.Errorf("API key não configurada")
+	}
+	model := req.Model
+	if model == "" {
+		model = "gpt-3.5-turbo"
+	}
+
+	var resp openAIChatResponse
+	headers := map[string]string{"Authorization": "Bearer " + api.apiKey}
+	if err := postChat(ctx, api.httpClient, api.baseURL, headers, newOpenAIChatRequest(req, model), &resp); err != nil {
+		return nil, fmt.Errorf("%s: %w", label, err)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("resposta vazia da API")
+	}
+
+	message := resp.Choices[0].Message
+	return chatReply(openAIText(message.Content), openAIToolCalls(message.ToolCalls), &interfaces.Usage{
+		Prompt:     resp.Usage.PromptTokens,
+		Completion: resp.Usage.CompletionTokens,
+		Provider:   provider,
+		Model:      model,
+	}), nil
+}
+
+// chatReply emits a complete reply as a chat stream: its text, one chunk per
+// tool call, then the done chunk with usage.
+func chatReply(content string, calls []interfaces.ToolCall, usage *interfaces.Usage) <-chan interfaces.ChatChunk {
+	ch := make(chan interfaces.ChatChunk, len(calls)+2)
+	if content != "" {
+		ch <- interfaces.ChatChunk{Content: content}
+	}
+	for i := range calls {
+		ch <- interfaces.ChatChunk{ToolCall: &calls[i]}
+	}
+	usage.Tokens = usage.Prompt + usage.Completion
+	ch <- interfaces.ChatChunk{Done: true, Usage: usage}
+	close(ch)
+	return ch
+}
+
+// postChat posts body as JSON and decodes a successful reply into out.
+// Error replies are returned with the provider's message when it has one.
+func postChat(ctx context.Context, client *http.Client, url string, headers map[string]string, body, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("error serializing request: %v", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("error creating request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	reply, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(reply, &apiErr) == nil && apiErr.Error.Message != "" {
+			return fmt.Errorf("API error (status %d): %s", resp.StatusCode, apiErr.Error.Message)
+		}
+		return fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(reply))
+	}
+	if err := json.Unmarshal(reply, out); err != nil {
+		return fmt.Errorf("error parsing response: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kubex-ecosystem/grompt/internal/interfaces"
+)
+
+// toolConversation is a tool round trip: a question, the model's call and
+// the tool's result
+func toolConversation() interfaces.ChatRequest {
+	return interfaces.ChatRequest{
+		Messages: []interfaces.Message{
+			{Role: "system", Content: "Be brief."},
+			{Role: "user", Content: "Weather in Lisbon?"},
+			{Role: "assistant", ToolCalls: []interfaces.ToolCall{{ID: "call_1", Name: "weather", Arguments: json.RawMessage(`{"city":"Lisbon"}`)}}},
+			{Role: "tool", ToolCallID: "call_1", Content: `{"sky":"clear"}`},
+		},
+		Tools:      []interfaces.Tool{{Name: "weather", Description: "Current weather", Parameters: map[string]any{"type": "object"}}},
+		ToolChoice: "weather",
+	}
+}
+
+func TestOpenAIToolTranslation(t *testing.T) {
+	out := newOpenAIChatRequest(toolConversation(), "gpt-4o")
+	if len(out.Messages) != 4 || out.Messages[2].ToolCalls[0].Function.Arguments != `{"city":"Lisbon"}` || out.Messages[3].ToolCallID != "call_1" {
+		t.Fatalf("unexpected messages: %+v", out.Messages)
+	}
+	if out.Tools[0].Type != "function" || out.Tools[0].Function.Name != "weather" {
+		t.Fatalf("unexpected tools: %+v", out.Tools)
+	}
+	if choice, _ := out.ToolChoice.(map[string]any); choice["type"] != "function" {
+		t.Fatalf("expected a forced function choice, got %v", out.ToolChoice)
+	}
+
+	calls := openAIToolCalls([]openAIToolCall{{ID: "x", Function: openAIFunctionCall{Name: "weather"}}})
+	if string(calls[0].Arguments) != "{}" {
+		t.Fatalf("expected empty arguments as an object, got %s", calls[0].Arguments)
+	}
+}
+
+func TestAnthropicToolTranslation(t *testing.T) {
+	out := newAnthropicRequest(toolConversation(), "claude")
+	if out.System != "Be brief." || len(out.Messages) != 3 {
+		t.Fatalf("unexpected request: %+v", out)
+	}
+	if use := out.Messages[1].Content[0]; use.Type != "tool_use" || use.ID != "call_1" || string(use.Input) != `{"city":"Lisbon"}` {
+		t.Fatalf("unexpected tool_use block: %+v", use)
+	}
+	if result := out.Messages[2]; result.Role != "user" || result.Content[0].Type != "tool_result" || result.Content[0].ToolUseID != "call_1" {
+		t.Fatalf("unexpected tool_result message: %+v", result)
+	}
+	if out.Tools[0].InputSchema["type"] != "object" || out.ToolChoice["type"] != "tool" {
+		t.Fatalf("unexpected tools: %+v %v", out.Tools, out.ToolChoice)
+	}
+
+	text, calls := anthropicReply(anthropicResponse{Content: []anthropicBlock{
+		{Type: "text", Text: "Checking."},
+		{Type: "tool_use", ID: "toolu_1", Name: "weather", Input: json.RawMessage(`{"city":"Porto"}`)},
+	}})
+	if text != "Checking." || len(calls) != 1 || calls[0].ID != "toolu_1" {
+		t.Fatalf("unexpected reply: %q %+v", text, calls)
+	}
+}
+
+func TestGeminiToolTranslation(t *testing.T) {
+	out := newGeminiChatRequest(toolConversation())
+	if out.SystemInstruction == nil || len(out.Contents) != 3 {
+		t.Fatalf("unexpected request: %+v", out)
+	}
+	if call := out.Contents[1]; call.Role != "model" || call.Parts[0].FunctionCall.Name != "weather" {
+		t.Fatalf("unexpected function call: %+v", call)
+	}
+	response := out.Contents[2].Parts[0].FunctionResponse
+	if response == nil || response.Name != "weather" || response.Response["sky"] != "clear" {
+		t.Fatalf("unexpected function response: %+v", response)
+	}
+	config := out.ToolConfig.FunctionCallingConfig
+	if len(out.Tools[0].FunctionDeclarations) != 1 || config.Mode != "ANY" || config.AllowedFunctionNames[0] != "weather" {
+		t.Fatalf("unexpected tools: %+v %+v", out.Tools, config)
+	}
+}
@@ -0,0 +1,119 @@
+package vectorindex
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kubex-ecosystem/grompt/internal/module/kbx"
+)
+
+// DefaultName is the index used when a request names none
+const DefaultName = "default"
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// DefaultDir returns the directory of the named indexes. GROMPT_VECTORS_DIR
+// overrides the default under ~/.kubex/grompt.
+func DefaultDir() string {
+	if dir := os.Getenv("GROMPT_VECTORS_DIR"); dir != "" {
+		return dir
+	}
+	return os.ExpandEnv(kbx.DefaultGromptVectorsDir)
+}
+
+// Catalog is a directory of named indexes, one <name>.jsonl file each. Every
+// operation is scoped to a tenant: the indexes of the empty tenant sit at the
+// top of the directory and those of a tenant under tenants/<tenant>, so names
+// never collide across tenants. Open indexes are shared, so every caller of a
+// tenant sees the same records.
+type Catalog struct {
+	dir string
+
+	mu   sync.Mutex
+	open map[string]*Index
+}
+
+// NewCatalog returns the catalog of indexes in dir, DefaultDir when empty
+func NewCatalog(dir string) *Catalog {
+	if dir == "" {
+		dir = DefaultDir()
+	}
+	return &Catalog{dir: dir, open: map[string]*Index{}}
+}
+
+// Dir returns the catalog directory
+func (c *Catalog) Dir() string { return c.dir }
+
+// Open returns the named index of tenant, creating it empty when it does not
+// exist. An empty name is DefaultName.
+func (c *Catalog) Open(tenant, name string) (*Index, error) {
+	if name == "" {
+		name = DefaultName
+	}
+	if !validName.MatchString(name) {
+		return nil, fmt.Errorf("invalid index name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+	dir, err := c.tenantDir(tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := tenant + "/" + name
+	if idx, ok := c.open[key]; ok {
+		return idx, nil
+	}
+	idx, err := Open(filepath.Join(dir, name+".jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	c.open[key] = idx
+	return idx, nil
+}
+
+// List describes every index of tenant, by name
+func (c *Catalog) List(tenant string) ([]Info, error) {
+	dir, err := c.tenantDir(tenant)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".jsonl")
+		if !e.IsDir() && ok && validName.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	infos := make([]Info, 0, len(names))
+	for _, name := range names {
+		idx, err := c.Open(tenant, name)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, idx.Info())
+	}
+	return infos, nil
+}
+
+// tenantDir returns the directory of the indexes of tenant
+func (c *Catalog) tenantDir(tenant string) (string, error) {
+	if tenant == "" {
+		return c.dir, nil
+	}
+	if !validName.MatchString(tenant) {
+		return "", fmt.Errorf("tenant %q cannot hold vector indexes: use letters, digits, '.', '_' and '-'", tenant)
+	}
+	return filepath.Join(c.dir, "tenants", tenant), nil
+}
@@ -0,0 +1,443 @@
+// Package vectorindex is a small on-disk vector index for retrieval: records
+// of an embedding, a text and string metadata, searched by cosine similarity
+// with metadata filters. It is pure Go and keeps every vector in memory,
+// which is plenty for the document counts grompt works with. BM25 and
+// Tokenize give the lexical ranking used where there are no embeddings.
+package vectorindex
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	gl "github.com/kubex-ecosystem/logz/logger"
+)
+
+// Record is an indexed vector and what it was computed from
+type Record struct {
+	ID       string            `json:"id"`
+	Vector   []float64         `json:"vector"`
+	Text     string            `json:"text,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Hit is a search result
+type Hit struct {
+	ID       string            `json:"id"`
+	Score    float64           `json:"score"`
+	Text     string            `json:"text,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Filter restricts a search to records whose metadata has every key with
+// the given value. A value ending in * matches by prefix, so
+// {"path": "internal/*"} selects a directory.
+type Filter map[string]string
+
+// Matches reports whether metadata satisfies f
+func (f Filter) Matches(metadata map[string]string) bool {
+	for k, want := range f {
+		have, ok := metadata[k]
+		if !ok {
+			return false
+		}
+		if prefix, wildcard := strings.CutSuffix(want, "*"); wildcard {
+			if !strings.HasPrefix(have, prefix) {
+				return false
+			}
+		} else if have != want {
+			return false
+		}
+	}
+	return true
+}
+
+// Info describes an index. Provider and Model record where its vectors came
+// from, since vectors of different embedding models are not comparable.
+type Info struct {
+	Name      string    `json:"name"`
+	Provider  string    `json:"provider,omitempty"`
+	Model     string    `json:"model,omitempty"`
+	Dims      int       `json:"dims"`
+	Count     int       `json:"count"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+// op is a line of the index log
+type op struct {
+	Op       string    `json:"op"` // meta, put or del
+	Record   *Record   `json:"record,omitempty"`
+	ID       string    `json:"id,omitempty"`
+	Provider string    `json:"provider,omitempty"`
+	Model    string    `json:"model,omitempty"`
+	At       time.Time `json:"at"`
+}
+
+type entry struct {
+	Record
+	norm float64
+}
+
+// Index is a vector index persisted as an append-only JSON Lines log of
+// puts and deletes that is replayed on open. Compact rewrites the log with
+// only the live records. An Index without a path lives in memory.
+type Index struct {
+	path string
+	name string
+
+	mu       sync.RWMutex
+	records  map[string]*entry
+	provider string
+	model    string
+	dims     int
+	updated  time.Time
+	garbage  int // log lines superseded by later ones
+}
+
+// New returns an empty index that is not persisted
+func New() *Index { return &Index{records: map[string]*entry{}} }
+
+// Open loads the index at path, creating its directory when needed. A
+// missing file is an empty index, and a log holding more superseded lines
+// than live records is compacted.
+func Open(path string) (*Index, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create index directory: %w", err)
+	}
+	idx := New()
+	idx.path = path
+	idx.name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if err := idx.load(); err != nil {
+		return nil, err
+	}
+	if idx.garbage > len(idx.records) {
+		if err := idx.Compact(); err != nil {
+			gl.Log("warn", fmt.Sprintf("Failed to compact index %s: %v", path, err))
+		}
+	}
+	return idx, nil
+}
+
+// Path returns the index file, empty for an in-memory index
+func (x *Index) Path() string { return x.path }
+
+// Info describes the index
+func (x *Index) Info() Info {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return Info{Name: x.name, Provider: x.provider, Model: x.model, Dims: x.dims, Count: len(x.records), UpdatedAt: x.updated}
+}
+
+// Len returns the number of records
+func (x *Index) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.records)
+}
+
+// Bind records the provider and model the vectors of the index come from.
+// An empty index takes any; a non-empty one refuses a different model so
+// incomparable vectors are never mixed.
+func (x *Index) Bind(provider, model string) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if provider == x.provider && model == x.model {
+		return nil
+	}
+	if len(x.records) > 0 && (x.provider != "" || x.model != "") {
+		return fmt.Errorf("index %s holds %s/%s vectors, not %s/%s", x.name, x.provider, x.model, provider, model)
+	}
+	if err := x.appendLocked(op{Op: "meta", Provider: provider, Model: model}); err != nil {
+		return err
+	}
+	x.provider, x.model = provider, model
+	return nil
+}
+
+// Upsert adds records, replacing those with the same ID. Every vector must
+// have the dimensions of the index, set by its first record.
+func (x *Index) Upsert(records ...Record) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	dims := x.dims
+	if len(x.records) == 0 {
+		dims = 0
+	}
+	for i, r := range records {
+		if strings.TrimSpace(r.ID) == "" {
+			return fmt.Errorf("record %d has no id", i)
+		}
+		if len(r.Vector) == 0 {
+			return fmt.Errorf("record %s has no vector", r.ID)
+		}
+		if dims == 0 {
+			dims = len(r.Vector)
+		}
+		if len(r.Vector) != dims {
+			return fmt.Errorf("record %s has %d dimensions, the index has %d", r.ID, len(r.Vector), dims)
+		}
+	}
+
+	ops := make([]op, len(records))
+	for i := range records {
+		ops[i] = op{Op: "put", Record: &records[i]}
+	}
+	if err := x.appendLocked(ops...); err != nil {
+		return err
+	}
+	for _, r := range records {
+		x.putLocked(r)
+	}
+	return nil
+}
+
+// Delete removes records by ID and returns how many existed
+func (x *Index) Delete(ids ...string) (int, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	var ops []op
+	for _, id := range ids {
+		if _, ok := x.records[id]; ok {
+			ops = append(ops, op{Op: "del", ID: id})
+		}
+	}
+	if err := x.appendLocked(ops...); err != nil {
+		return 0, err
+	}
+	for _, o := range ops {
+		x.deleteLocked(o.ID)
+	}
+	return len(ops), nil
+}
+
+// Get returns the record with the given ID
+func (x *Index) Get(id string) (Record, bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	e, ok := x.records[id]
+	if !ok {
+		return Record{}, false
+	}
+	return e.Record, true
+}
+
+// IDs returns the IDs of every record, sorted
+func (x *Index) IDs() []string {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return x.idsLocked()
+}
+
+func (x *Index) idsLocked() []string {
+	ids := make([]string, 0, len(x.records))
+	for id := range x.records {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Search returns up to k records matching filter, most similar to vector
+// first. k <= 0 returns every match.
+func (x *Index) Search(vector []float64, k int, filter Filter) ([]Hit, error) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	if len(x.records) == 0 {
+		return []Hit{}, nil
+	}
+	if len(vector) != x.dims {
+		return nil, fmt.Errorf("query has %d dimensions, the index has %d", len(vector), x.dims)
+	}
+	qnorm := norm(vector)
+	if qnorm == 0 {
+		return nil, fmt.Errorf("query vector is zero")
+	}
+
+	hits := make([]Hit, 0, len(x.records))
+	for _, e := range x.records {
+		if !filter.Matches(e.Metadata) {
+			continue
+		}
+		score := 0.0
+		if e.norm > 0 {
+			score = dot(vector, e.Vector) / (qnorm * e.norm)
+		}
+		hits = append(hits, Hit{ID: e.ID, Score: score, Text: e.Text, Metadata: e.Metadata})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	if k > 0 && len(hits) > k {
+		hits = hits[:k]
+	}
+	return hits, nil
+}
+
+// Compact rewrites the log with only the live records, through a temporary
+// file
+func (x *Index) Compact() error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.path == "" {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	now := time.Now()
+	if x.provider != "" || x.model != "" {
+		if err := enc.Encode(op{Op: "meta", Provider: x.provider, Model: x.model, At: now}); err != nil {
+			return err
+		}
+	}
+	for _, id := range x.idsLocked() {
+		r := x.records[id].Record
+		if err := enc.Encode(op{Op: "put", Record: &r, At: now}); err != nil {
+			return err
+		}
+	}
+	tmp := x.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, x.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	x.garbage = 0
+	return nil
+}
+
+// load replays the log. Corrupt lines are skipped.
+func (x *Index) load() error {
+	f, err := os.Open(x.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			var o op
+			if jerr := json.Unmarshal(line, &o); jerr != nil {
+				gl.Log("warn", fmt.Sprintf("Skipping corrupt index line in %s: %v", x.path, jerr))
+			} else {
+				x.replay(o)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (x *Index) replay(o op) {
+	switch o.Op {
+	case "meta":
+		x.provider, x.model = o.Provider, o.Model
+	case "put":
+		if o.Record == nil || o.Record.ID == "" || len(o.Record.Vector) == 0 {
+			return
+		}
+		if len(x.records) > 0 && len(o.Record.Vector) != x.dims {
+			gl.Log("warn", fmt.Sprintf("Skipping record %s of %s: wrong dimensions", o.Record.ID, x.path))
+			return
+		}
+		x.putLocked(*o.Record)
+	case "del":
+		x.deleteLocked(o.ID)
+	}
+	if o.At.After(x.updated) {
+		x.updated = o.At
+	}
+}
+
+func (x *Index) putLocked(r Record) {
+	if _, ok := x.records[r.ID]; ok {
+		x.garbage++
+	}
+	x.records[r.ID] = &entry{Record: r, norm: norm(r.Vector)}
+	x.dims = len(r.Vector)
+}
+
+func (x *Index) deleteLocked(id string) {
+	if _, ok := x.records[id]; ok {
+		delete(x.records, id)
+		x.garbage += 2
+	}
+}
+
+// appendLocked writes ops to the log
+func (x *Index) appendLocked(ops ...op) error {
+	if len(ops) == 0 {
+		return nil
+	}
+	now := time.Now()
+	x.updated = now
+	if x.path == "" {
+		return nil
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for i := range ops {
+		ops[i].At = now
+		if err := enc.Encode(ops[i]); err != nil {
+			return err
+		}
+	}
+	f, err := os.OpenFile(x.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(buf.Bytes())
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Cosine returns the cosine similarity of a and b, 0 when either is empty,
+// zero, or their lengths differ
+func Cosine(a, b []float64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	na, nb := norm(a), norm(b)
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot(a, b) / (na * nb)
+}
+
+func dot(a, b []float64) float64 {
+	var s float64
+	for i := range a {
+		s += a[i] * b[i]
+	}
+	return s
+}
+
+func norm(v []float64) float64 {
+	return math.Sqrt(dot(v, v))
+}
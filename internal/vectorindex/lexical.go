@@ -0,0 +1,85 @@
+package vectorindex
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// BM25 parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// BM25 scores every document against the query terms. Documents and
+// query are term lists, usually from Tokenize.
+func BM25(query []string, docs [][]string) []float64 {
+	scores := make([]float64, len(docs))
+	if len(query) == 0 || len(docs) == 0 {
+		return scores
+	}
+
+	df := map[string]int{}
+	var total int
+	for _, doc := range docs {
+		total += len(doc)
+		seen := map[string]bool{}
+		for _, t := range doc {
+			if !seen[t] {
+				seen[t] = true
+				df[t]++
+			}
+		}
+	}
+	avgdl := float64(total) / float64(len(docs))
+	if avgdl == 0 {
+		return scores
+	}
+
+	terms := map[string]bool{}
+	for _, t := range query {
+		terms[t] = true
+	}
+	n := float64(len(docs))
+	for i, doc := range docs {
+		tf := map[string]int{}
+		for _, t := range doc {
+			if terms[t] {
+				tf[t]++
+			}
+		}
+		dl := float64(len(doc))
+		for t, f := range tf {
+			idf := math.Log(1 + (n-float64(df[t])+0.5)/(float64(df[t])+0.5))
+			freq := float64(f)
+			scores[i] += idf * freq * (bm25K1 + 1) / (freq + bm25K1*(1-bm25B+bm25B*dl/avgdl))
+		}
+	}
+	return scores
+}
+
+// Tokenize lower-cases text and splits it into words, dropping single
+// characters and common English and Portuguese stop words
+func Tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	out := words[:0]
+	for _, w := range words {
+		if len([]rune(w)) < 2 || stopWords[w] {
+			continue
+		}
+		out = append(out, w)
+	}
+	return out
+}
+
+var stopWords = func() map[string]bool {
+	m := map[string]bool{}
+	for _, w := range strings.Fields(`an and are as at be by for from has have in is it its of on or that the this to was were will with
+		ao com da das de do dos em na nas no nos os para por que se um uma`) {
+		m[w] = true
+	}
+	return m
+}()
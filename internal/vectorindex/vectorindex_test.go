@@ -0,0 +1,134 @@
+package vectorindex
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func TestIndexPersistsAndSearches(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "docs.jsonl")
+	idx, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+	if err := idx.Bind("openai", "text-embedding-3-small"); err != nil {
+		t.Fatalf("Bind returned error: %v", err)
+	}
+	err = idx.Upsert(
+		Record{ID: "a", Vector: []float64{1, 0, 0}, Text: "alpha", Metadata: map[string]string{"path": "internal/a.go"}},
+		Record{ID: "b", Vector: []float64{0.9, 0.1, 0}, Text: "beta", Metadata: map[string]string{"path": "cmd/b.go"}},
+		Record{ID: "c", Vector: []float64{0, 0, 1}, Text: "gamma", Metadata: map[string]string{"path": "internal/c.go"}},
+	)
+	if err != nil {
+		t.Fatalf("Upsert returned error: %v", err)
+	}
+	if err := idx.Upsert(Record{ID: "d", Vector: []float64{1, 0}}); err == nil {
+		t.Fatal("expected a vector of other dimensions to be rejected")
+	}
+	if err := idx.Bind("gemini", "text-embedding-004"); err == nil {
+		t.Fatal("expected a non-empty index to refuse another model")
+	}
+	if _, err := idx.Delete("c", "missing"); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	if err := idx.Upsert(Record{ID: "a", Vector: []float64{0, 1, 0}, Text: "alpha v2", Metadata: map[string]string{"path": "internal/a.go"}}); err != nil {
+		t.Fatalf("Upsert returned error: %v", err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+	info := reopened.Info()
+	if info.Name != "docs" || info.Count != 2 || info.Dims != 3 || info.Model != "text-embedding-3-small" {
+		t.Fatalf("unexpected info %+v", info)
+	}
+
+	hits, err := reopened.Search([]float64{1, 0, 0}, 1, nil)
+	if err != nil {
+		t.Fatalf("Search returned error: %v", err)
+	}
+	if len(hits) != 1 || hits[0].ID != "b" {
+		t.Fatalf("expected b to be nearest after a was replaced, got %+v", hits)
+	}
+	hits, err = reopened.Search([]float64{1, 0, 0}, 0, Filter{"path": "internal/*"})
+	if err != nil {
+		t.Fatalf("Search returned error: %v", err)
+	}
+	if len(hits) != 1 || hits[0].ID != "a" || hits[0].Text != "alpha v2" {
+		t.Fatalf("expected the filter to keep only a, got %+v", hits)
+	}
+	if _, err := reopened.Search([]float64{1, 0}, 1, nil); err == nil {
+		t.Fatal("expected a query of other dimensions to be rejected")
+	}
+
+	if err := reopened.Compact(); err != nil {
+		t.Fatalf("Compact returned error: %v", err)
+	}
+	compacted, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+	if got := compacted.Info(); got.Count != 2 || got.Provider != "openai" {
+		t.Fatalf("expected compaction to keep the records and binding, got %+v", got)
+	}
+}
+
+func TestCatalogNamesIndexes(t *testing.T) {
+	c := NewCatalog(t.TempDir())
+	if _, err := c.Open("", "../escape"); err == nil {
+		t.Fatal("expected an invalid name to be rejected")
+	}
+	if _, err := c.Open("../acme", "docs"); err == nil {
+		t.Fatal("expected an invalid tenant to be rejected")
+	}
+	idx, err := c.Open("", "")
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+	if err := idx.Upsert(Record{ID: "x", Vector: []float64{1, 2}}); err != nil {
+		t.Fatalf("Upsert returned error: %v", err)
+	}
+	if again, _ := c.Open("", DefaultName); again != idx {
+		t.Fatal("expected the catalog to share open indexes")
+	}
+	infos, err := c.List("")
+	if err != nil {
+		t.Fatalf("List returned error: %v", err)
+	}
+	if len(infos) != 1 || infos[0].Name != DefaultName || infos[0].Count != 1 {
+		t.Fatalf("unexpected catalog %+v", infos)
+	}
+
+	// Tenants see only their own indexes under the same names
+	acme, err := c.Open("acme", DefaultName)
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+	if acme == idx || acme.Len() != 0 {
+		t.Fatalf("expected a separate index for the tenant, got %d records", acme.Len())
+	}
+	if infos, _ := c.List("acme"); len(infos) != 0 {
+		t.Fatalf("expected the tenant's empty index to be unsaved, got %+v", infos)
+	}
+	acme.Upsert(Record{ID: "y", Vector: []float64{2, 1}})
+	if infos, _ := c.List("acme"); len(infos) != 1 || infos[0].Count != 1 {
+		t.Fatalf("unexpected tenant catalog %+v", infos)
+	}
+	if infos, _ := c.List(""); len(infos) != 1 || infos[0].Count != 1 {
+		t.Fatalf("expected the tenant's index to stay out of the default catalog, got %+v", infos)
+	}
+}
+
+func TestCosine(t *testing.T) {
+	if got := Cosine([]float64{1, 1}, []float64{2, 2}); math.Abs(got-1) > 1e-9 {
+		t.Fatalf("expected parallel vectors to score 1, got %f", got)
+	}
+	if got := Cosine([]float64{1, 0}, []float64{0, 0}); got != 0 {
+		t.Fatalf("expected a zero vector to score 0, got %f", got)
+	}
+	if got := Cosine([]float64{1}, []float64{1, 0}); got != 0 {
+		t.Fatalf("expected mismatched lengths to score 0, got %f", got)
+	}
+}